@@ -44,8 +44,7 @@ resource "aws_ecs_task_definition" "frameshot-api-task" {
         { name = "AWS_S3_QUEUE_URL", value = var.s3_queue_url },
         { name = "AWS_VIDEO_INPUT_QUEUE_URL", value = var.video_input_queue_url },
         { name = "AWS_VIDEO_OUTPUT_QUEUE_URL", value = var.video_output_queue_url },
-        { name = "SENDGRID_API_KEY", value = var.sendgrid_api_key },
-        { name = "SENDGRID_TEMPLATE_ID", value = var.sendgrid_template_id }
+        { name = "SENDGRID_API_KEY", value = var.sendgrid_api_key }
       ]
       portMappings = [
         {
@@ -93,11 +93,6 @@ variable "sendgrid_api_key" {
   type        = string
 }
 
-variable "sendgrid_template_id" {
-    description = "Template utilizado para email"
-    type = string
-}
-
 variable "s3_queue_url" {
     description = "URL da fila do S3"
     type = string
@@ -2,17 +2,31 @@ package main
 
 import (
 	"context"
+	"example/web-service-gin/src/adapters/broker"
+	"example/web-service-gin/src/adapters/chat"
 	"example/web-service-gin/src/adapters/handler/http"
+	"example/web-service-gin/src/adapters/handler/notification"
 	"example/web-service-gin/src/adapters/handler/queue"
+	"example/web-service-gin/src/adapters/handler/scheduler"
 	"example/web-service-gin/src/adapters/mail"
+	"example/web-service-gin/src/adapters/metrics"
 	"example/web-service-gin/src/adapters/storage/bucket"
+	"example/web-service-gin/src/adapters/storage/filesystem"
 	"example/web-service-gin/src/adapters/storage/postgres"
 	"example/web-service-gin/src/adapters/storage/postgres/repository"
+	"example/web-service-gin/src/adapters/storage/ratelimit"
+	"example/web-service-gin/src/adapters/webhook"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/port"
 	"example/web-service-gin/src/core/usecase"
 	"example/web-service-gin/src/infra/configuration"
 	"example/web-service-gin/src/infra/middleware"
+	"example/web-service-gin/src/utils"
+	"example/web-service-gin/src/utils/network"
 	"log/slog"
 	"os"
+	"slices"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -26,31 +40,146 @@ func main() {
 	db := loadDatabase(ctx, &config)
 	defer db.Close()
 
-	//Setting Mail Service
-	mailService := mail.NewMailService(config.Mail)
+	// Setting Metrics, before the AWS clients are created
+	requestRepository := repository.NewPGRequestRepository(db)
+	appMetrics, metricsHandler := loadMetrics(&config, requestRepository)
+
+	//Setting Notification Channels
+	notificationChannels := loadNotificationChannels(&config, appMetrics)
 
-	// Setting SQS
-	queueHandler := queue.NewSQSHandler(config.AWS)
-	queueProducer := queue.NewSQSProducer(queueHandler, config.AWS.VideoInputQueueUrl)
+	// Setting Queues
+	queueConsumer, messageProducer := loadQueue(&config, db)
+	queueProducer := queue.NewRequestProducer(messageProducer, config.AWS.VideoInputQueueUrl)
 
 	//Dependency Injection
-	s3Storage := bucket.NewS3Bucket(config.AWS, ctx)
-	requestRepository := repository.NewPGRequestRepository(db)
-	requestUseCase := usecase.NewRequestUseCase(requestRepository, s3Storage, queueProducer, mailService)
+	storage, fileHandler := loadStorage(&config, messageProducer, appMetrics)
+	quotaUseCase := loadQuotas(&config, requestRepository)
+	eventBroker := loadEventBroker(&config, db, ctx)
+	webhookUseCase := usecase.NewWebhookUseCase(repository.NewPGWebhookRepository(db), webhook.NewHTTPSender(config.Webhook.Timeout, config.Webhook.AllowPrivateNetworks),
+		usecase.WebhookSettings{
+			MaxAttempts:          config.Webhook.MaxAttempts,
+			RetryDelay:           config.Webhook.RetryDelay,
+			MaxRetryDelay:        config.Webhook.MaxRetryDelay,
+			AllowHttp:            config.Webhook.AllowHttp,
+			AllowPrivateNetworks: config.Webhook.AllowPrivateNetworks,
+		})
+	notificationUseCase := usecase.NewNotificationUseCase(notificationChannels, loadNotificationRenderer(&config), repository.NewPGNotificationRepository(db), requestRepository, storage,
+		usecase.NotificationSettings{
+			RequireVerifiedEmail: config.Auth.RequireVerifiedEmail,
+			DefaultLocale:        config.Mail.DefaultLocale,
+			MaxAttempts:          config.Mail.MaxAttempts,
+			RetryDelay:           config.Mail.RetryDelay,
+			MaxRetryDelay:        config.Mail.MaxRetryDelay,
+		})
+	// Without quotas the requests are only limited by the default file size, the usage still shows an unlimited plan
+	requestQuotas := quotaUseCase
+	if !config.Quota.Enabled {
+		requestQuotas = nil
+	}
+	// The same history records the changes of the requests made by every use case
+	requestHistory := usecase.NewRequestHistory(requestRepository, loadEventPublisher(&config, ctx), webhookUseCase, eventBroker)
+	requestUseCase := usecase.NewRequestUseCase(requestRepository, storage, queueProducer, notificationUseCase,
+		usecase.WithVideoLimits(usecase.VideoLimits{
+			MaxDuration: config.Video.MaxDuration,
+			MaxWidth:    config.Video.MaxWidth,
+			MaxHeight:   config.Video.MaxHeight,
+		}),
+		usecase.WithDeduplication(loadDedupMode(&config)),
+		usecase.WithHistory(requestHistory),
+		usecase.WithQuotas(requestQuotas),
+		usecase.WithMetrics(appMetrics))
 	requestHandler := http.NewRequestHandler(requestUseCase)
+	usageHandler := http.NewUsageHandler(quotaUseCase)
+	webhookHandler := http.NewWebhookHandler(webhookUseCase)
+	notificationHandler := http.NewNotificationHandler(notificationUseCase)
+	eventStreamHandler := http.NewEventStreamHandler(usecase.NewEventStreamUseCase(requestRepository, eventBroker), config.Events.Heartbeat)
+	apiKeyUseCase := usecase.NewApiKeyUseCase(repository.NewPGApiKeyRepository(db))
+	apiKeyHandler := http.NewApiKeyHandler(apiKeyUseCase)
+	auditLogRepository := repository.NewPGAuditLogRepository(db)
+	adminUseCase := usecase.NewAdminUseCase(requestRepository, requestRepository, requestHistory, storage, auditLogRepository, queueProducer, notificationUseCase, notificationUseCase)
+	adminHandler := http.NewAdminHandler(adminUseCase)
+	watchdogUseCase := usecase.NewWatchdogUseCase(requestRepository, requestHistory, storage, queueProducer, notificationUseCase, config.Watchdog.SLA, config.Watchdog.MaxAttempts)
 
 	// Starting Queue Consumers
-	go queue.StartQueueConsumer(queueHandler, config.AWS.S3QueueUrl, requestUseCase.HandleUploadNotification, ctx)
-	go queue.StartQueueConsumer(queueHandler, config.AWS.VideoOutputQueueUrl, requestUseCase.HandleVideoOutputNotification, ctx)
+	go queue.StartQueueConsumer(queueConsumer, config.AWS.S3QueueUrl, requestUseCase.HandleUploadNotification, appMetrics, ctx)
+	go queue.StartQueueConsumer(queueConsumer, config.AWS.VideoOutputQueueUrl, requestUseCase.HandleVideoOutputNotification, appMetrics, ctx)
+
+	if config.Queue.SimulateWorker {
+		go queue.StartSimulatedWorker(queueConsumer, messageProducer, config.AWS.VideoInputQueueUrl, config.AWS.VideoOutputQueueUrl, ctx)
+	}
+
+	// Starting Background Jobs
+	go scheduler.StartScheduler("watchdog", config.Watchdog.Interval, watchdogUseCase.HandleStuckRequests, ctx)
+	go scheduler.StartScheduler("webhooks", config.Webhook.Interval, webhookUseCase.DeliverPending, ctx)
+	go scheduler.StartScheduler("notifications", config.Mail.RetryInterval, notificationUseCase.RetryPending, ctx)
+	go scheduler.StartScheduler("digests", config.Mail.DigestInterval, notificationUseCase.SendDigests, ctx)
+
+	// Rate Limit Settings
+	rateLimitStore := loadRateLimitStore(&config, db, ctx)
+	rateLimits := config.RateLimit
+
+	// Auth Settings
+	jwtService := loadJwtService(&config)
+	defer jwtService.Close()
+
+	// The metrics are served apart from the API, so only the scrapers reach them
+	if metricsHandler != nil {
+		go serveMetrics(config.Metrics.Address, metricsHandler)
+	}
 
 	// Routes and Middlewares Settings
-	router := gin.Default()
-	router.Use(middleware.JwtServiceMiddleware(config.AWS.CognitoJwksUrl))
+	// The metrics are recorded before the logger and the recovery, so the panics are counted as 500
+	router := gin.New()
+	loadTrustedProxies(&config, router)
+	router.Use(middleware.Metrics(appMetrics), gin.Logger(), gin.Recovery(), middleware.ErrorHandler())
 	router.MaxMultipartMemory = 8 << 20
-	router.POST("/requests", requestHandler.Register)
-	router.GET("/requests", requestHandler.ListUsers)
 	router.GET("/healthcheck", requestHandler.HealthCheck)
 
+	// The file URLs are signed, so they don't need a token
+	if fileHandler != nil {
+		router.GET("/files/*filepath", middleware.RateLimit(rateLimitStore, "public", toRateLimit(rateLimits.Public)), fileHandler.Download)
+	}
+
+	// The IP is limited before authenticating, so invalid tokens and API keys are limited too
+	authorized := router.Group("/", middleware.RateLimit(rateLimitStore, "auth", toRateLimit(rateLimits.Auth)),
+		middleware.Authenticate(jwtService, apiKeyUseCase),
+		middleware.RateLimit(rateLimitStore, "api", toRateLimit(rateLimits.Api)))
+	authorized.POST("/requests", middleware.RateLimit(rateLimitStore, "uploads", toRateLimit(rateLimits.Uploads)),
+		middleware.RequireScope(entity.ScopeRequestsWrite),
+		middleware.RequireVerifiedEmail(config.Auth.RequireVerifiedEmail), requestHandler.Register)
+	authorized.GET("/requests", middleware.RequireScope(entity.ScopeRequestsRead), requestHandler.ListUsers)
+	authorized.GET("/requests/events", middleware.RequireScope(entity.ScopeRequestsRead), eventStreamHandler.Stream)
+	authorized.GET("/me/usage", middleware.RequireScope(entity.ScopeRequestsRead), usageHandler.GetUsage)
+
+	apiKeys := authorized.Group("/me/api-keys", middleware.RequireToken())
+	apiKeys.POST("", apiKeyHandler.Create)
+	apiKeys.GET("", apiKeyHandler.List)
+	apiKeys.DELETE("/:id", apiKeyHandler.Revoke)
+
+	webhooks := authorized.Group("/me/webhooks", middleware.RequireToken())
+	webhooks.POST("", webhookHandler.Create)
+	webhooks.GET("", webhookHandler.List)
+	webhooks.GET("/:id", webhookHandler.Get)
+	webhooks.PATCH("/:id", webhookHandler.Update)
+	webhooks.DELETE("/:id", webhookHandler.Delete)
+	webhooks.GET("/:id/deliveries", webhookHandler.ListDeliveries)
+	webhooks.POST("/:id/deliveries/:deliveryId/redeliver", webhookHandler.Redeliver)
+
+	notificationPreferences := authorized.Group("/me/notification-preferences", middleware.RequireToken())
+	notificationPreferences.GET("", notificationHandler.GetPreferences)
+	notificationPreferences.PUT("", notificationHandler.UpdatePreferences)
+
+	admin := authorized.Group("/admin", middleware.RequireGroup(config.Auth.AdminGroups))
+	admin.GET("/requests", adminHandler.SearchRequests)
+	admin.GET("/requests/:id", adminHandler.GetRequest)
+	admin.POST("/requests/:id/retry", adminHandler.RetryRequest)
+	admin.POST("/requests/:id/fail", adminHandler.FailRequest)
+	admin.GET("/stats", adminHandler.Stats)
+	admin.GET("/audit-logs", adminHandler.ListAuditLogs)
+	admin.GET("/notifications", adminHandler.ListNotifications)
+	admin.POST("/notifications/:id/resend", adminHandler.ResendNotification)
+	admin.GET("/notifications/preview", notificationHandler.Preview)
+
 	defer router.Run("0.0.0.0:8080")
 }
 
@@ -87,3 +216,235 @@ func loadDatabase(ctx context.Context, config *configuration.Container) *postgre
 	slog.Info("Successfully migrated the database")
 	return db
 }
+
+// Trust the X-Forwarded-For headers only from the proxies informed on the configuration, so the
+// clients can't choose the IP of their rate limits
+func loadTrustedProxies(config *configuration.Container, router *gin.Engine) {
+	if err := router.SetTrustedProxies(config.HTTP.TrustedProxies); err != nil {
+		slog.Error("Invalid trusted proxies", "proxies", config.HTTP.TrustedProxies, "error", err)
+		os.Exit(1)
+	}
+
+	slog.Info("Using trusted proxies", "proxies", config.HTTP.TrustedProxies)
+}
+
+// Fetch the JWKS once, the keys are refreshed in background while the application runs
+func loadJwtService(config *configuration.Container) *utils.JwtService {
+	jwtService, err := utils.NewJwtService(config.Auth)
+
+	if err != nil {
+		slog.Error("Error loading the JWKS", "url", config.Auth.JwksUrl, "error", err)
+		os.Exit(1)
+	}
+
+	return jwtService
+}
+
+// Select the queue adapter informed on the configuration
+func loadQueue(config *configuration.Container, db *postgres.DB) (port.MessageConsumer, port.MessageProducer) {
+	slog.Info("Using queue driver", "driver", config.Queue.Driver)
+
+	switch config.Queue.Driver {
+	case "memory":
+		memoryQueue := queue.NewMemoryQueue(config.Queue.Visibility)
+		return memoryQueue, memoryQueue
+	case "postgres":
+		pgQueue := queue.NewPGQueue(db, config.Queue.Visibility)
+		return pgQueue, pgQueue
+	case "sqs":
+		sqsHandler := queue.NewSQSHandler(config.AWS)
+		return sqsHandler, sqsHandler
+	}
+
+	slog.Error("Invalid queue driver", "driver", config.Queue.Driver)
+	os.Exit(1)
+	return nil, nil
+}
+
+// Validate the deduplication mode informed on the configuration
+func loadDedupMode(config *configuration.Container) usecase.DedupMode {
+	mode := usecase.DedupMode(config.Dedup.Mode)
+
+	switch mode {
+	case usecase.DedupOff, usecase.DedupUser, usecase.DedupOrganization:
+		slog.Info("Using deduplication mode", "mode", mode)
+		return mode
+	}
+
+	slog.Error("Invalid deduplication mode", "mode", config.Dedup.Mode)
+	os.Exit(1)
+	return usecase.DedupOff
+}
+
+// Map the quota plans of the configuration, when the quotas are disabled every user has an unlimited plan
+// on the usage, and the requests are not created with them
+func loadQuotas(config *configuration.Container, repo port.RequestRepository) *usecase.QuotaUseCase {
+	plans := map[string]entity.Plan{}
+
+	if config.Quota.Enabled {
+		for name, plan := range config.Quota.Plans {
+			plans[name] = entity.Plan{
+				Name:              name,
+				MaxConcurrentJobs: plan.MaxConcurrentJobs,
+				MaxRequestsPerDay: plan.MaxRequestsPerDay,
+				MaxBytesPerMonth:  plan.MaxBytesPerMonth,
+				MaxFileSize:       plan.MaxFileSize,
+			}
+		}
+	}
+
+	slog.Info("Using quota plans", "enabled", config.Quota.Enabled, "default", config.Quota.DefaultPlan, "plans", len(plans))
+	return usecase.NewQuotaUseCase(repo, plans, config.Quota.DefaultPlan)
+}
+
+// Select the email transport informed on the configuration, the chat channels
+// post to the webhooks of the users so they are always available, only on public IPs
+func loadNotificationChannels(config *configuration.Container, appMetrics port.Metrics) map[entity.NotificationChannel]port.NotificationChannel {
+	slog.Info("Using mail driver", "driver", config.Mail.Driver)
+
+	chatClient := network.NewClient(config.Mail.Timeout, false)
+	channels := map[entity.NotificationChannel]port.NotificationChannel{
+		entity.ChannelSlack: chat.NewSlackChannel(chatClient),
+		entity.ChannelTeams: chat.NewTeamsChannel(chatClient),
+	}
+
+	switch config.Mail.Driver {
+	case "sendgrid":
+		channels[entity.ChannelEmail] = mail.NewMailService(config.Mail, appMetrics)
+		return channels
+	case "smtp":
+		channels[entity.ChannelEmail] = mail.NewSMTPService(config.Mail, appMetrics)
+		return channels
+	}
+
+	slog.Error("Invalid mail driver", "driver", config.Mail.Driver)
+	os.Exit(1)
+	return nil
+}
+
+// Parse the templates of the notifications, the application doesn't start with an invalid template
+func loadNotificationRenderer(config *configuration.Container) *mail.TemplateRenderer {
+	if !slices.Contains(entity.Locales, config.Mail.DefaultLocale) {
+		slog.Error("Invalid mail default locale", "locale", config.Mail.DefaultLocale, "allowed", entity.Locales)
+		os.Exit(1)
+	}
+
+	renderer, err := mail.NewTemplateRenderer()
+
+	if err != nil {
+		slog.Error("Error loading notification templates", "error", err)
+		os.Exit(1)
+	}
+
+	return renderer
+}
+
+// Select the rate limit store informed on the configuration, nil when the limits are disabled. The
+// postgres buckets are deleted once they are full again, as they behave as the missing ones
+func loadRateLimitStore(config *configuration.Container, db *postgres.DB, ctx context.Context) port.RateLimitStore {
+	if !config.RateLimit.Enabled {
+		slog.Info("Rate limits are disabled")
+		return nil
+	}
+
+	slog.Info("Using rate limit store", "store", config.RateLimit.Store)
+
+	switch config.RateLimit.Store {
+	case "memory":
+		return ratelimit.NewMemoryStore()
+	case "postgres":
+		pgStore := ratelimit.NewPGStore(db)
+		go scheduler.StartScheduler("rate-limit-cleanup", 5*time.Minute, pgStore.DeleteFullBuckets, ctx)
+		return pgStore
+	}
+
+	slog.Error("Invalid rate limit store", "store", config.RateLimit.Store)
+	os.Exit(1)
+	return nil
+}
+
+// Select the event broker informed on the configuration, the postgres broker
+// listens to the events of the other instances while the application runs
+func loadEventBroker(config *configuration.Container, db *postgres.DB, ctx context.Context) port.RequestEventBroker {
+	slog.Info("Using event broker", "broker", config.Events.Broker)
+
+	switch config.Events.Broker {
+	case "memory":
+		return broker.NewMemoryBroker()
+	case "postgres":
+		pgBroker := broker.NewPGBroker(db)
+		go pgBroker.Listen(ctx)
+		return pgBroker
+	}
+
+	slog.Error("Invalid event broker", "broker", config.Events.Broker)
+	os.Exit(1)
+	return nil
+}
+
+// Publish the domain events to the SNS topic informed on the configuration, nil when it is missing
+func loadEventPublisher(config *configuration.Container, ctx context.Context) port.EventPublisher {
+	if config.AWS.EventsTopicArn == "" {
+		slog.Info("Domain events are disabled, AWS_SNS_EVENTS_TOPIC_ARN is empty")
+		return nil
+	}
+
+	slog.Info("Publishing domain events", "topic", config.AWS.EventsTopicArn)
+	return notification.NewSNSEventPublisher(notification.NewSNSHandler(config.AWS, ctx), config.AWS.EventsTopicArn)
+}
+
+// Create the Prometheus metrics and record the calls of the AWS clients, the handler serving
+// them is only returned when they are enabled
+func loadMetrics(config *configuration.Container, counter metrics.StatusCounter) (port.Metrics, gin.HandlerFunc) {
+	if !config.Metrics.Enabled {
+		slog.Info("Metrics are disabled")
+		return metrics.Nop{}, nil
+	}
+
+	prometheusMetrics := metrics.NewPrometheusMetrics(counter)
+	metrics.InstrumentAWS(&config.AWS.Config, prometheusMetrics)
+
+	return prometheusMetrics, gin.WrapH(prometheusMetrics.Handler())
+}
+
+// Serve the metrics on their own address, the application keeps running without them
+func serveMetrics(address string, handler gin.HandlerFunc) {
+	router := gin.New()
+	router.GET("/metrics", handler)
+
+	slog.Info("Serving metrics", "address", address)
+	if err := router.Run(address); err != nil {
+		slog.Error("Error serving metrics", "address", address, "error", err)
+	}
+}
+
+func toRateLimit(rule configuration.RateLimitRule) entity.RateLimit {
+	return entity.RateLimit{Requests: rule.Requests, Period: rule.Period}
+}
+
+// Select the storage adapter informed on the configuration, the file handler
+// is only returned when the storage serves its own files
+func loadStorage(config *configuration.Container, producer port.MessageProducer, appMetrics port.Metrics) (port.StoragePort, *http.FileHandler) {
+	slog.Info("Using storage driver", "driver", config.Storage.Driver)
+
+	switch config.Storage.Driver {
+	case "filesystem":
+		if config.Storage.SigningKey == "" {
+			slog.Error("STORAGE_SIGNING_KEY is required by the filesystem storage")
+			os.Exit(1)
+		}
+
+		fileStorage, err := filesystem.NewFileSystemStorage(config.Storage, producer, config.AWS.S3QueueUrl, appMetrics)
+		if err != nil {
+			slog.Error("Error initializing filesystem storage", "error", err)
+			os.Exit(1)
+		}
+		return fileStorage, http.NewFileHandler(fileStorage)
+	case "s3":
+		return bucket.NewS3Bucket(config.AWS, appMetrics), nil
+	}
+
+	slog.Error("Invalid storage driver", "driver", config.Storage.Driver)
+	os.Exit(1)
+	return nil, nil
+}
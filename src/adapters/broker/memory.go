@@ -0,0 +1,84 @@
+package broker
+
+import (
+	"context"
+	"example/web-service-gin/src/core/entity"
+	"sync"
+)
+
+// subscriberBuffer is the amount of events kept for a subscriber before it is considered behind
+const subscriberBuffer = 32
+
+// MemoryBroker implements port.RequestEventBroker interface and fans the events out to
+// the subscribers of this instance only, it also delivers the events of the PGBroker
+type MemoryBroker struct {
+	mutex       sync.Mutex
+	subscribers map[string]map[chan entity.RequestEvent]struct{}
+}
+
+// NewMemoryBroker creates a new broker without subscribers
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		subscribers: map[string]map[chan entity.RequestEvent]struct{}{},
+	}
+}
+
+// Publish sends the event to the subscribers of the user. A subscriber that doesn't keep up
+// is dropped, closing its channel, instead of blocking the publisher
+func (broker *MemoryBroker) Publish(_ context.Context, userId string, event entity.RequestEvent) error {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	for events := range broker.subscribers[userId] {
+		select {
+		case events <- event:
+		default:
+			broker.remove(userId, events)
+		}
+	}
+
+	return nil
+}
+
+// Subscribe returns the events of the user published from now on
+func (broker *MemoryBroker) Subscribe(userId string) (<-chan entity.RequestEvent, func()) {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	events := make(chan entity.RequestEvent, subscriberBuffer)
+	if broker.subscribers[userId] == nil {
+		broker.subscribers[userId] = map[chan entity.RequestEvent]struct{}{}
+	}
+	broker.subscribers[userId][events] = struct{}{}
+
+	return events, func() {
+		broker.mutex.Lock()
+		defer broker.mutex.Unlock()
+		broker.remove(userId, events)
+	}
+}
+
+// CloseAll drops all the subscribers, so they resume from the history once the missed events can't be delivered
+func (broker *MemoryBroker) CloseAll() {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	for userId, subscribers := range broker.subscribers {
+		for events := range subscribers {
+			broker.remove(userId, events)
+		}
+	}
+}
+
+// remove closes the channel of the subscriber once, it must be called holding the mutex
+func (broker *MemoryBroker) remove(userId string, events chan entity.RequestEvent) {
+	if _, ok := broker.subscribers[userId][events]; !ok {
+		return
+	}
+
+	delete(broker.subscribers[userId], events)
+	if len(broker.subscribers[userId]) == 0 {
+		delete(broker.subscribers, userId)
+	}
+	close(events)
+}
@@ -0,0 +1,66 @@
+package broker_test
+
+import (
+	"context"
+	"example/web-service-gin/src/adapters/broker"
+	"example/web-service-gin/src/core/entity"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryBroker_PublishToUserSubscribers(t *testing.T) {
+	memoryBroker := broker.NewMemoryBroker()
+	ctx := context.Background()
+
+	events, unsubscribe := memoryBroker.Subscribe("user-1")
+	defer unsubscribe()
+	otherEvents, unsubscribeOther := memoryBroker.Subscribe("user-2")
+	defer unsubscribeOther()
+
+	memoryBroker.Publish(ctx, "user-1", entity.RequestEvent{ID: 1, Status: entity.InProgress})
+
+	assert.Equal(t, uint64(1), (<-events).ID)
+	assert.Empty(t, otherEvents)
+}
+
+func TestMemoryBroker_Unsubscribe(t *testing.T) {
+	memoryBroker := broker.NewMemoryBroker()
+
+	events, unsubscribe := memoryBroker.Subscribe("user-1")
+	unsubscribe()
+	unsubscribe()
+	memoryBroker.Publish(context.Background(), "user-1", entity.RequestEvent{ID: 1})
+
+	_, open := <-events
+	assert.False(t, open)
+}
+
+func TestMemoryBroker_DropsSlowSubscriber(t *testing.T) {
+	memoryBroker := broker.NewMemoryBroker()
+	ctx := context.Background()
+
+	events, unsubscribe := memoryBroker.Subscribe("user-1")
+	defer unsubscribe()
+
+	for i := 0; i <= 32; i++ {
+		memoryBroker.Publish(ctx, "user-1", entity.RequestEvent{ID: uint64(i)})
+	}
+
+	received := 0
+	for range events {
+		received++
+	}
+	assert.Equal(t, 32, received)
+}
+
+func TestMemoryBroker_CloseAll(t *testing.T) {
+	memoryBroker := broker.NewMemoryBroker()
+
+	events, unsubscribe := memoryBroker.Subscribe("user-1")
+	defer unsubscribe()
+	memoryBroker.CloseAll()
+
+	_, open := <-events
+	assert.False(t, open)
+}
@@ -0,0 +1,125 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"example/web-service-gin/src/adapters/storage/postgres"
+	"example/web-service-gin/src/core/entity"
+	"log/slog"
+	"time"
+)
+
+const (
+	// notifyChannel is the postgres channel of the events
+	notifyChannel = "request_events"
+	// reconnectDelay is the wait before listening again after the connection fails
+	reconnectDelay = 5 * time.Second
+)
+
+// PGBroker implements port.RequestEventBroker interface and fans the events out to the
+// subscribers of all the instances through the postgres LISTEN/NOTIFY
+type PGBroker struct {
+	db    *postgres.DB
+	local *MemoryBroker
+}
+
+// notification is the payload of the NOTIFY, limited by postgres to 8000 bytes
+type notification struct {
+	UserId    string    `json:"user_id"`
+	ID        uint64    `json:"id"`
+	RequestId uint64    `json:"request_id"`
+	Status    string    `json:"status"`
+	Message   string    `json:"message"`
+	Actor     string    `json:"actor"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewPGBroker creates a new broker, the events are only received while Listen runs
+func NewPGBroker(db *postgres.DB) *PGBroker {
+	return &PGBroker{db, NewMemoryBroker()}
+}
+
+// Publish notifies the event to all the instances, including this one
+func (broker *PGBroker) Publish(ctx context.Context, userId string, event entity.RequestEvent) error {
+	payload, err := json.Marshal(notification{
+		UserId:    userId,
+		ID:        event.ID,
+		RequestId: event.RequestId,
+		Status:    string(event.Status),
+		Message:   event.Message,
+		Actor:     event.Actor,
+		CreatedAt: event.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = broker.db.Exec(ctx, "SELECT pg_notify($1, $2)", notifyChannel, string(payload))
+	return err
+}
+
+// Subscribe returns the events of the user published from now on by any instance
+func (broker *PGBroker) Subscribe(userId string) (<-chan entity.RequestEvent, func()) {
+	return broker.local.Subscribe(userId)
+}
+
+// Listen receives the notifications on a dedicated connection until the context is done. The
+// events notified while the connection is lost are missed, so the subscribers are dropped to
+// resume from the history
+func (broker *PGBroker) Listen(ctx context.Context) {
+	slog.Info("Listening to request events", "channel", notifyChannel)
+
+	for {
+		err := broker.listen(ctx)
+		if ctx.Err() != nil {
+			slog.Info("Stopping request events listener", "channel", notifyChannel)
+			return
+		}
+
+		slog.Error("Error listening to request events", "channel", notifyChannel, "error", err)
+		broker.local.CloseAll()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+func (broker *PGBroker) listen(ctx context.Context) error {
+	conn, err := broker.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The connection is closed instead of released, so it never returns to the pool listening
+	defer conn.Release()
+	defer conn.Conn().Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+notifyChannel); err != nil {
+		return err
+	}
+
+	for {
+		received, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var payload notification
+		if err := json.Unmarshal([]byte(received.Payload), &payload); err != nil {
+			slog.Error("Error decoding request event", "payload", received.Payload, "error", err)
+			continue
+		}
+
+		broker.local.Publish(ctx, payload.UserId, entity.RequestEvent{
+			ID:        payload.ID,
+			RequestId: payload.RequestId,
+			Status:    entity.RequestStatus(payload.Status),
+			Message:   payload.Message,
+			Actor:     payload.Actor,
+			CreatedAt: payload.CreatedAt,
+		})
+	}
+}
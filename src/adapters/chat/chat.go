@@ -0,0 +1,46 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxResponseBodySize is how much of the response body is kept on the notification log
+const maxResponseBodySize = 512
+
+// post sends the message to the incoming webhook, the chat services answer 2xx when it is accepted.
+// It returns the status and the beginning of the body of the response
+func post(ctx context.Context, client *http.Client, webhookUrl string, message any) (string, error) {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookUrl, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error posting message: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	text, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodySize))
+	response := strings.TrimSpace(fmt.Sprintf("%d %s", resp.StatusCode, text))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return response, fmt.Errorf("unexpected response status %d: %s", resp.StatusCode, text)
+	}
+
+	return response, nil
+}
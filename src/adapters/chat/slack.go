@@ -0,0 +1,29 @@
+package chat
+
+import (
+	"context"
+	"example/web-service-gin/src/core/entity"
+	"net/http"
+)
+
+// SlackChannel implements port.NotificationChannel interface and
+// posts the notifications to the Slack incoming webhooks of the users
+type SlackChannel struct {
+	client *http.Client
+}
+
+// NewSlackChannel creates the channel posting with the client, which must not follow the redirects
+// and only connect to public IPs, as the webhook URLs are informed by the users
+func NewSlackChannel(client *http.Client) *SlackChannel {
+	return &SlackChannel{client}
+}
+
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+func (channel *SlackChannel) Send(ctx context.Context, recipient string, notification entity.Notification) (string, error) {
+	return post(ctx, channel.client, recipient, slackMessage{
+		Text: "*" + notification.Subject + "*\n" + notification.Text,
+	})
+}
@@ -0,0 +1,67 @@
+package chat_test
+
+import (
+	"context"
+	"encoding/json"
+	"example/web-service-gin/src/adapters/chat"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/utils/network"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlackChannel_Send(t *testing.T) {
+	// Given
+	var received map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	notification := entity.Notification{Subject: "Your request #22 is ready", Text: "Download the frames"}
+
+	// When
+	response, err := chat.NewSlackChannel(network.NewClient(time.Second, true)).Send(context.Background(), server.URL, notification)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, "200 ok", response)
+	assert.Equal(t, "*Your request #22 is ready*\nDownload the frames", received["text"])
+}
+
+func TestSlackChannel_Rejected(t *testing.T) {
+	// Given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("no_service"))
+	}))
+	defer server.Close()
+
+	// When
+	response, err := chat.NewSlackChannel(network.NewClient(time.Second, true)).Send(context.Background(), server.URL, entity.Notification{})
+
+	// Then
+	assert.EqualError(t, err, "unexpected response status 404: no_service")
+	assert.Equal(t, "404 no_service", response)
+}
+
+func TestSlackChannel_RefusesPrivateNetworks(t *testing.T) {
+	// Given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the private network was reached")
+	}))
+	defer server.Close()
+
+	// When
+	_, err := chat.NewSlackChannel(network.NewClient(time.Second, false)).Send(context.Background(), server.URL, entity.Notification{})
+
+	// Then
+	assert.ErrorIs(t, err, network.ErrNonPublicAddress)
+}
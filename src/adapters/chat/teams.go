@@ -0,0 +1,50 @@
+package chat
+
+import (
+	"context"
+	"example/web-service-gin/src/core/entity"
+	"net/http"
+)
+
+// Colors of the Teams cards of the completed requests and of the other notifications
+const (
+	colorCompleted = "2EB67D"
+	colorFailed    = "E01E5A"
+)
+
+// TeamsChannel implements port.NotificationChannel interface and posts
+// the notifications as message cards to the Teams webhooks of the users
+type TeamsChannel struct {
+	client *http.Client
+}
+
+// NewTeamsChannel creates the channel posting with the client, which must not follow the redirects
+// and only connect to public IPs, as the webhook URLs are informed by the users
+func NewTeamsChannel(client *http.Client) *TeamsChannel {
+	return &TeamsChannel{client}
+}
+
+type teamsMessageCard struct {
+	Type       string `json:"@type"`
+	Context    string `json:"@context"`
+	Summary    string `json:"summary"`
+	ThemeColor string `json:"themeColor"`
+	Title      string `json:"title"`
+	Text       string `json:"text"`
+}
+
+func (channel *TeamsChannel) Send(ctx context.Context, recipient string, notification entity.Notification) (string, error) {
+	color := colorFailed
+	if notification.Kind == entity.NotificationRequestCompleted {
+		color = colorCompleted
+	}
+
+	return post(ctx, channel.client, recipient, teamsMessageCard{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		Summary:    notification.Subject,
+		ThemeColor: color,
+		Title:      notification.Subject,
+		Text:       notification.Text,
+	})
+}
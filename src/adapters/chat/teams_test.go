@@ -0,0 +1,57 @@
+package chat_test
+
+import (
+	"context"
+	"encoding/json"
+	"example/web-service-gin/src/adapters/chat"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/utils/network"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeamsChannel_Send(t *testing.T) {
+	// Given
+	var received map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte("1"))
+	}))
+	defer server.Close()
+
+	notification := entity.Notification{
+		Subject: "Your request #22 failed",
+		Text:    "We couldn't extract the frames of your request #22",
+		Kind:    entity.NotificationRequestFailed,
+	}
+
+	// When
+	response, err := chat.NewTeamsChannel(network.NewClient(time.Second, true)).Send(context.Background(), server.URL, notification)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, "200 1", response)
+	assert.Equal(t, "MessageCard", received["@type"])
+	assert.Equal(t, "Your request #22 failed", received["title"])
+	assert.Equal(t, "We couldn't extract the frames of your request #22", received["text"])
+	assert.Equal(t, "E01E5A", received["themeColor"])
+}
+
+func TestTeamsChannel_DoesNotFollowRedirects(t *testing.T) {
+	// Given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/", http.StatusFound)
+	}))
+	defer server.Close()
+
+	// When
+	_, err := chat.NewTeamsChannel(network.NewClient(time.Second, true)).Send(context.Background(), server.URL, entity.Notification{})
+
+	// Then
+	assert.ErrorContains(t, err, "unexpected response status 302")
+}
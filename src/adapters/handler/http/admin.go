@@ -0,0 +1,472 @@
+package http
+
+import (
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/port"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler struct {
+	service port.AdminService
+}
+
+func NewAdminHandler(service port.AdminService) *AdminHandler {
+	return &AdminHandler{
+		service,
+	}
+}
+
+type failRequestBody struct {
+	Reason string `json:"reason" binding:"required" example:"video is corrupted"`
+}
+
+var requestStatuses = []entity.RequestStatus{entity.Pending, entity.InProgress, entity.Completed, entity.Failed}
+
+var deliveryStatuses = []entity.DeliveryStatus{entity.DeliveryPending, entity.DeliverySucceeded, entity.DeliveryFailed}
+
+// SearchRequests lists the requests of all users, filtered by the query parameters
+// user_id, status, created_from and created_to, and paginated by limit and offset
+func (handler *AdminHandler) SearchRequests(ctx *gin.Context) {
+
+	admin := getAuthUser(ctx)
+
+	if admin == nil {
+		return
+	}
+
+	filter, err := parseRequestFilter(ctx)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	requests, err := handler.service.SearchRequests(ctx, admin, filter)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	requestList := []adminRequestResponse{}
+	for _, request := range requests {
+		requestList = append(requestList, newAdminRequestResponse(&request, nil))
+	}
+
+	ctx.JSON(http.StatusOK, requestList)
+}
+
+// GetRequest shows any request with its history
+func (handler *AdminHandler) GetRequest(ctx *gin.Context) {
+
+	admin := getAuthUser(ctx)
+
+	if admin == nil {
+		return
+	}
+
+	id, err := parseIdParam(ctx)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	request, events, err := handler.service.GetRequest(ctx, admin, id)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAdminRequestResponse(request, events))
+}
+
+// RetryRequest starts a new processing attempt of the request
+func (handler *AdminHandler) RetryRequest(ctx *gin.Context) {
+
+	admin := getAuthUser(ctx)
+
+	if admin == nil {
+		return
+	}
+
+	id, err := parseIdParam(ctx)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	request, err := handler.service.RetryRequest(ctx, admin, id)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAdminRequestResponse(request, nil))
+}
+
+// FailRequest marks the request as FAILED with the informed reason
+func (handler *AdminHandler) FailRequest(ctx *gin.Context) {
+
+	admin := getAuthUser(ctx)
+
+	if admin == nil {
+		return
+	}
+
+	id, err := parseIdParam(ctx)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var body failRequestBody
+
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.Error(core.NewValidationError(core.ErrInvalidInput, "reason is required"))
+		return
+	}
+
+	request, err := handler.service.FailRequest(ctx, admin, id, body.Reason)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAdminRequestResponse(request, nil))
+}
+
+// Stats returns the amount of requests of each status
+func (handler *AdminHandler) Stats(ctx *gin.Context) {
+
+	admin := getAuthUser(ctx)
+
+	if admin == nil {
+		return
+	}
+
+	counts, err := handler.service.CountByStatus(ctx, admin)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"total": total, "by_status": counts})
+}
+
+// ListAuditLogs lists the actions of the administrators, paginated by limit and offset
+func (handler *AdminHandler) ListAuditLogs(ctx *gin.Context) {
+
+	admin := getAuthUser(ctx)
+
+	if admin == nil {
+		return
+	}
+
+	limit, offset, err := parsePagination(ctx)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	logs, err := handler.service.ListAuditLogs(ctx, admin, limit, offset)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	logList := []auditLogResponse{}
+	for _, log := range logs {
+		logList = append(logList, auditLogResponse{
+			ID:         log.ID,
+			ActorId:    log.ActorId,
+			ActorEmail: log.ActorEmail,
+			Action:     log.Action,
+			TargetId:   log.TargetId,
+			Details:    log.Details,
+			CreatedAt:  log.CreatedAt,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, logList)
+}
+
+// ListNotifications lists the logged notifications of all users, filtered by the query parameters
+// request_id and status, and paginated by limit and offset
+func (handler *AdminHandler) ListNotifications(ctx *gin.Context) {
+
+	admin := getAuthUser(ctx)
+
+	if admin == nil {
+		return
+	}
+
+	filter, err := parseNotificationFilter(ctx)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	deliveries, err := handler.service.ListNotifications(ctx, admin, filter)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	notificationList := []notificationResponse{}
+	for _, delivery := range deliveries {
+		notificationList = append(notificationList, newNotificationResponse(&delivery))
+	}
+
+	ctx.JSON(http.StatusOK, notificationList)
+}
+
+// ResendNotification sends a logged notification again, the new delivery is retried when it fails
+func (handler *AdminHandler) ResendNotification(ctx *gin.Context) {
+
+	admin := getAuthUser(ctx)
+
+	if admin == nil {
+		return
+	}
+
+	id, err := parseIdParam(ctx)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	delivery, err := handler.service.ResendNotification(ctx, admin, id)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newNotificationResponse(delivery))
+}
+
+// parseNotificationFilter reads the criteria of the logged notifications
+func parseNotificationFilter(ctx *gin.Context) (entity.NotificationFilter, error) {
+	var filter entity.NotificationFilter
+	var err error
+
+	if requestId := ctx.Query("request_id"); requestId != "" {
+		filter.RequestId, err = strconv.ParseUint(requestId, 10, 64)
+		if err != nil {
+			return filter, core.NewValidationError(core.ErrInvalidInput, "request_id must be a number")
+		}
+	}
+
+	if status := entity.DeliveryStatus(ctx.Query("status")); status != "" {
+		if !slices.Contains(deliveryStatuses, status) {
+			return filter, core.NewValidationError(core.ErrInvalidInput,
+				fmt.Sprintf("status must be one of %s, %s or %s", entity.DeliveryPending, entity.DeliverySucceeded, entity.DeliveryFailed))
+		}
+		filter.Status = status
+	}
+
+	filter.Limit, filter.Offset, err = parsePagination(ctx)
+	return filter, err
+}
+
+// parseRequestFilter reads the search criteria, the dates are RFC 3339 timestamps or
+// days (2006-01-02). A day on created_to includes the whole day
+func parseRequestFilter(ctx *gin.Context) (entity.RequestFilter, error) {
+	var filter entity.RequestFilter
+	var err error
+
+	filter.UserId = ctx.Query("user_id")
+
+	if status := entity.RequestStatus(ctx.Query("status")); status != "" {
+		if !slices.Contains(requestStatuses, status) {
+			return filter, core.NewValidationError(core.ErrInvalidInput,
+				fmt.Sprintf("status must be one of %s, %s, %s or %s", entity.Pending, entity.InProgress, entity.Completed, entity.Failed))
+		}
+		filter.Status = status
+	}
+
+	filter.CreatedFrom, _, err = parseDateQuery(ctx, "created_from")
+	if err != nil {
+		return filter, err
+	}
+
+	createdTo, isDay, err := parseDateQuery(ctx, "created_to")
+	if err != nil {
+		return filter, err
+	}
+
+	if isDay {
+		createdTo = createdTo.AddDate(0, 0, 1)
+	}
+	filter.CreatedTo = createdTo
+
+	filter.Limit, filter.Offset, err = parsePagination(ctx)
+	return filter, err
+}
+
+// parseDateQuery reads a timestamp or a day from the query, telling when it was a day
+func parseDateQuery(ctx *gin.Context, name string) (time.Time, bool, error) {
+	value := ctx.Query(name)
+
+	if value == "" {
+		return time.Time{}, false, nil
+	}
+
+	if date, err := time.Parse(time.DateOnly, value); err == nil {
+		return date, true, nil
+	}
+
+	date, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, core.NewValidationError(core.ErrInvalidInput,
+			fmt.Sprintf("%s must be a date (2006-01-02) or a RFC 3339 timestamp", name))
+	}
+
+	return date, false, nil
+}
+
+// parsePagination reads the limit and offset of the query, zero uses the default page size
+func parsePagination(ctx *gin.Context) (uint64, uint64, error) {
+	limit, err := strconv.ParseUint(ctx.DefaultQuery("limit", "0"), 10, 64)
+	if err != nil {
+		return 0, 0, core.NewValidationError(core.ErrInvalidInput, "limit must be a positive number")
+	}
+
+	offset, err := strconv.ParseUint(ctx.DefaultQuery("offset", "0"), 10, 64)
+	if err != nil {
+		return 0, 0, core.NewValidationError(core.ErrInvalidInput, "offset must be a positive number")
+	}
+
+	return limit, offset, nil
+}
+
+func parseIdParam(ctx *gin.Context) (uint64, error) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return 0, core.NewValidationError(core.ErrInvalidInput, "id must be a number")
+	}
+	return id, nil
+}
+
+type adminRequestResponse struct {
+	requestResponse
+	OrganizationId string          `json:"organization_id,omitempty" example:"org-1"`
+	Attempts       int             `json:"attempts" example:"1"`
+	FailureReason  string          `json:"failure_reason,omitempty" example:"processing timed out"`
+	StartedAt      *time.Time      `json:"started_at" example:"1970-01-01T00:00:00Z"`
+	Events         []eventResponse `json:"events,omitempty"`
+}
+
+type eventResponse struct {
+	Status    entity.RequestStatus `json:"status" example:"IN_PROGRESS"`
+	Message   string               `json:"message" example:"sent to processing"`
+	Actor     string               `json:"actor" example:"system"`
+	CreatedAt time.Time            `json:"created_at" example:"1970-01-01T00:00:00Z"`
+}
+
+type auditLogResponse struct {
+	ID         uint64            `json:"id" example:"1"`
+	ActorId    string            `json:"actor_id" example:"123456"`
+	ActorEmail string            `json:"actor_email" example:"support@example.com"`
+	Action     string            `json:"action" example:"requests.retry"`
+	TargetId   string            `json:"target_id,omitempty" example:"1"`
+	Details    map[string]string `json:"details"`
+	CreatedAt  time.Time         `json:"created_at" example:"1970-01-01T00:00:00Z"`
+}
+
+func newAdminRequestResponse(request *entity.Request, events []entity.RequestEvent) adminRequestResponse {
+	rsp := adminRequestResponse{
+		requestResponse: newRequestResponse(request),
+		OrganizationId:  request.OrganizationId,
+		Attempts:        request.Attempts,
+		FailureReason:   request.FailureReason,
+		StartedAt:       optionalTime(request.StartedAt),
+	}
+
+	for _, event := range events {
+		rsp.Events = append(rsp.Events, eventResponse{
+			Status:    event.Status,
+			Message:   event.Message,
+			Actor:     event.Actor,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+
+	return rsp
+}
+
+type notificationResponse struct {
+	ID               uint64     `json:"id" example:"1"`
+	RequestId        uint64     `json:"request_id" example:"1"`
+	UserId           string     `json:"user_id" example:"123456"`
+	Kind             string     `json:"kind" example:"completed"`
+	Channel          string     `json:"channel" example:"email"`
+	Recipient        string     `json:"recipient" example:"user@example.com"`
+	Locale           string     `json:"locale" example:"pt-BR"`
+	Subject          string     `json:"subject" example:"Your request #1 is ready"`
+	Status           string     `json:"status" example:"FAILED"`
+	Attempts         int        `json:"attempts" example:"5"`
+	ProviderResponse string     `json:"provider_response,omitempty" example:"202 X-Message-Id: abc123"`
+	Error            string     `json:"error,omitempty" example:"error sending email: unexpected response status 400"`
+	NextAttemptAt    *time.Time `json:"next_attempt_at" example:"1970-01-01T00:00:00Z"`
+	LastAttemptAt    *time.Time `json:"last_attempt_at" example:"1970-01-01T00:00:00Z"`
+	CreatedAt        time.Time  `json:"created_at" example:"1970-01-01T00:00:00Z"`
+}
+
+func newNotificationResponse(delivery *entity.NotificationDelivery) notificationResponse {
+	rsp := notificationResponse{
+		ID:               delivery.ID,
+		RequestId:        delivery.RequestId,
+		UserId:           delivery.UserId,
+		Kind:             string(delivery.Kind),
+		Channel:          string(delivery.Channel),
+		Recipient:        delivery.Recipient,
+		Locale:           delivery.Locale,
+		Subject:          delivery.Subject,
+		Status:           string(delivery.Status),
+		Attempts:         delivery.Attempts,
+		ProviderResponse: delivery.ProviderResponse,
+		Error:            delivery.Error,
+		LastAttemptAt:    optionalTime(delivery.LastAttemptAt),
+		CreatedAt:        delivery.CreatedAt,
+	}
+
+	// The incoming webhook URLs of the chat channels are credentials, only their host is shown
+	if delivery.Channel != entity.ChannelEmail {
+		if webhookUrl, err := url.Parse(delivery.Recipient); err == nil {
+			rsp.Recipient = webhookUrl.Scheme + "://" + webhookUrl.Host + "/..."
+		}
+	}
+
+	// Only the pending deliveries have a next attempt
+	if delivery.Status == entity.DeliveryPending {
+		rsp.NextAttemptAt = optionalTime(delivery.NextAttemptAt)
+	}
+
+	return rsp
+}
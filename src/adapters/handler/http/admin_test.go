@@ -0,0 +1,297 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	controller "example/web-service-gin/src/adapters/handler/http"
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/infra/middleware"
+	"example/web-service-gin/src/utils/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAdminService struct {
+	mock.Mock
+}
+
+func (m *MockAdminService) SearchRequests(ctx context.Context, admin *entity.User, filter entity.RequestFilter) ([]entity.Request, error) {
+	args := m.Called(ctx, admin, filter)
+	return args.Get(0).([]entity.Request), args.Error(1)
+}
+
+func (m *MockAdminService) GetRequest(ctx context.Context, admin *entity.User, id uint64) (*entity.Request, []entity.RequestEvent, error) {
+	args := m.Called(ctx, admin, id)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*entity.Request), args.Get(1).([]entity.RequestEvent), args.Error(2)
+}
+
+func (m *MockAdminService) RetryRequest(ctx context.Context, admin *entity.User, id uint64) (*entity.Request, error) {
+	args := m.Called(ctx, admin, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Request), args.Error(1)
+}
+
+func (m *MockAdminService) FailRequest(ctx context.Context, admin *entity.User, id uint64, reason string) (*entity.Request, error) {
+	args := m.Called(ctx, admin, id, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Request), args.Error(1)
+}
+
+func (m *MockAdminService) CountByStatus(ctx context.Context, admin *entity.User) (map[entity.RequestStatus]int, error) {
+	args := m.Called(ctx, admin)
+	return args.Get(0).(map[entity.RequestStatus]int), args.Error(1)
+}
+
+func (m *MockAdminService) ListAuditLogs(ctx context.Context, admin *entity.User, limit uint64, offset uint64) ([]entity.AuditLog, error) {
+	args := m.Called(ctx, admin, limit, offset)
+	return args.Get(0).([]entity.AuditLog), args.Error(1)
+}
+
+func (m *MockAdminService) ListNotifications(ctx context.Context, admin *entity.User, filter entity.NotificationFilter) ([]entity.NotificationDelivery, error) {
+	args := m.Called(ctx, admin, filter)
+	return args.Get(0).([]entity.NotificationDelivery), args.Error(1)
+}
+
+func (m *MockAdminService) ResendNotification(ctx context.Context, admin *entity.User, id uint64) (*entity.NotificationDelivery, error) {
+	args := m.Called(ctx, admin, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.NotificationDelivery), args.Error(1)
+}
+
+func setUpAdmin() (*gin.Engine, *MockAdminService) {
+	mockJwtService := new(mocks.MockJwtService)
+	mockService := new(MockAdminService)
+	handler := controller.NewAdminHandler(mockService)
+
+	mockJwtService.On("GetUser", "admin-token").Return(&entity.User{Id: "admin-1", Groups: []string{"admin"}}, nil)
+	mockJwtService.On("GetUser", "valid-token").Return(&entity.User{Id: "123456"}, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+
+	admin := router.Group("/admin", middleware.Authenticate(mockJwtService, nil), middleware.RequireGroup([]string{"admin"}))
+	admin.GET("/requests", handler.SearchRequests)
+	admin.GET("/requests/:id", handler.GetRequest)
+	admin.POST("/requests/:id/retry", handler.RetryRequest)
+	admin.POST("/requests/:id/fail", handler.FailRequest)
+	admin.GET("/stats", handler.Stats)
+	admin.GET("/audit-logs", handler.ListAuditLogs)
+	admin.GET("/notifications", handler.ListNotifications)
+	admin.POST("/notifications/:id/resend", handler.ResendNotification)
+
+	return router, mockService
+}
+
+func TestAdminHandler_SearchRequests(t *testing.T) {
+	router, service := setUpAdmin()
+	service.On("SearchRequests", mock.Anything, mock.Anything, entity.RequestFilter{
+		UserId:      "123456",
+		Status:      entity.Failed,
+		CreatedFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		CreatedTo:   time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		Limit:       20,
+	}).Return([]entity.Request{{ID: 1, Status: entity.Failed, FailureReason: "timed out"}}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/admin/requests?user_id=123456&status=FAILED&created_from=2024-01-01&created_to=2024-01-31&limit=20", nil)
+	req.Header.Add("Authorization", "admin-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"failure_reason":"timed out"`)
+}
+
+func TestAdminHandler_SearchRequestsInvalidFilter(t *testing.T) {
+	router, service := setUpAdmin()
+
+	for _, query := range []string{"status=DONE", "created_from=yesterday", "limit=-1"} {
+		req, _ := http.NewRequest(http.MethodGet, "/admin/requests?"+query, nil)
+		req.Header.Add("Authorization", "admin-token")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	service.AssertNotCalled(t, "SearchRequests", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAdminHandler_ForbiddenForUsers(t *testing.T) {
+	router, service := setUpAdmin()
+
+	req, _ := http.NewRequest(http.MethodGet, "/admin/requests", nil)
+	req.Header.Add("Authorization", "valid-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	service.AssertNotCalled(t, "SearchRequests", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAdminHandler_GetRequest(t *testing.T) {
+	router, service := setUpAdmin()
+	service.On("GetRequest", mock.Anything, mock.Anything, uint64(1)).Return(&entity.Request{ID: 1, Status: entity.InProgress},
+		[]entity.RequestEvent{{Status: entity.InProgress, Message: "sent to processing", Actor: entity.SystemActor}}, nil)
+	service.On("GetRequest", mock.Anything, mock.Anything, uint64(2)).Return(nil, nil, core.ErrDataNotFound)
+
+	req, _ := http.NewRequest(http.MethodGet, "/admin/requests/1", nil)
+	req.Header.Add("Authorization", "admin-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"message":"sent to processing"`)
+
+	req, _ = http.NewRequest(http.MethodGet, "/admin/requests/2", nil)
+	req.Header.Add("Authorization", "admin-token")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAdminHandler_RetryRequest(t *testing.T) {
+	router, service := setUpAdmin()
+	service.On("RetryRequest", mock.Anything, mock.Anything, uint64(1)).Return(&entity.Request{ID: 1, Status: entity.InProgress}, nil)
+	service.On("RetryRequest", mock.Anything, mock.Anything, uint64(2)).
+		Return(nil, core.NewValidationError(core.ErrConflictingData, "the video upload of the request was not received yet"))
+
+	cases := map[string]int{
+		"/admin/requests/1/retry": http.StatusOK,
+		"/admin/requests/2/retry": http.StatusConflict,
+	}
+
+	for path, expectedStatus := range cases {
+		req, _ := http.NewRequest(http.MethodPost, path, nil)
+		req.Header.Add("Authorization", "admin-token")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, expectedStatus, w.Code, path)
+	}
+}
+
+func TestAdminHandler_FailRequest(t *testing.T) {
+	router, service := setUpAdmin()
+	service.On("FailRequest", mock.Anything, mock.Anything, uint64(1), "video is corrupted").
+		Return(&entity.Request{ID: 1, Status: entity.Failed, FailureReason: "video is corrupted"}, nil)
+
+	req, _ := http.NewRequest(http.MethodPost, "/admin/requests/1/fail", bytes.NewBufferString(`{"reason": "video is corrupted"}`))
+	req.Header.Add("Authorization", "admin-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"FAILED"`)
+
+	req, _ = http.NewRequest(http.MethodPost, "/admin/requests/1/fail", bytes.NewBufferString(`{}`))
+	req.Header.Add("Authorization", "admin-token")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAdminHandler_Stats(t *testing.T) {
+	router, service := setUpAdmin()
+	service.On("CountByStatus", mock.Anything, mock.Anything).
+		Return(map[entity.RequestStatus]int{entity.Completed: 3, entity.Failed: 1}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/admin/stats", nil)
+	req.Header.Add("Authorization", "admin-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"total":4`)
+	assert.Contains(t, w.Body.String(), `"COMPLETED":3`)
+}
+
+func TestAdminHandler_ListAuditLogs(t *testing.T) {
+	router, service := setUpAdmin()
+	service.On("ListAuditLogs", mock.Anything, mock.Anything, uint64(10), uint64(20)).
+		Return([]entity.AuditLog{{ID: 1, ActorId: "admin-1", Action: "requests.retry", TargetId: "1"}}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/admin/audit-logs?limit=10&offset=20", nil)
+	req.Header.Add("Authorization", "admin-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"action":"requests.retry"`)
+}
+
+func TestAdminHandler_ListNotifications(t *testing.T) {
+	router, service := setUpAdmin()
+	service.On("ListNotifications", mock.Anything, mock.Anything, entity.NotificationFilter{RequestId: 22, Status: entity.DeliveryFailed, Limit: 10}).
+		Return([]entity.NotificationDelivery{
+			{ID: 1, RequestId: 22, Channel: entity.ChannelEmail, Recipient: "user@example.com", Status: entity.DeliveryFailed, Attempts: 5,
+				ProviderResponse: "400 invalid address", Error: "error sending email: unexpected response status 400"},
+			{ID: 2, RequestId: 22, Channel: entity.ChannelSlack, Recipient: "https://hooks.slack.com/services/T000/B000/XXXX", Status: entity.DeliveryFailed},
+		}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/admin/notifications?request_id=22&status=FAILED&limit=10", nil)
+	req.Header.Add("Authorization", "admin-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"recipient":"user@example.com"`)
+	assert.Contains(t, w.Body.String(), `"provider_response":"400 invalid address"`)
+	assert.Contains(t, w.Body.String(), `"recipient":"https://hooks.slack.com/..."`)
+	assert.NotContains(t, w.Body.String(), "XXXX")
+}
+
+func TestAdminHandler_ListNotificationsInvalidFilter(t *testing.T) {
+	router, service := setUpAdmin()
+
+	for _, query := range []string{"status=SENT", "request_id=abc", "offset=-1"} {
+		req, _ := http.NewRequest(http.MethodGet, "/admin/notifications?"+query, nil)
+		req.Header.Add("Authorization", "admin-token")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	service.AssertNotCalled(t, "ListNotifications", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAdminHandler_ResendNotification(t *testing.T) {
+	router, service := setUpAdmin()
+	service.On("ResendNotification", mock.Anything, mock.Anything, uint64(1)).
+		Return(&entity.NotificationDelivery{ID: 3, RequestId: 22, Status: entity.DeliverySucceeded, Attempts: 1}, nil)
+	service.On("ResendNotification", mock.Anything, mock.Anything, uint64(2)).
+		Return(nil, core.NewValidationError(core.ErrConflictingData, "the notification is still being retried"))
+
+	cases := map[string]int{
+		"/admin/notifications/1/resend": http.StatusOK,
+		"/admin/notifications/2/resend": http.StatusConflict,
+	}
+
+	for path, expectedStatus := range cases {
+		req, _ := http.NewRequest(http.MethodPost, path, nil)
+		req.Header.Add("Authorization", "admin-token")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, expectedStatus, w.Code, path)
+	}
+}
@@ -0,0 +1,137 @@
+package http
+
+import (
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/port"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ApiKeyHandler struct {
+	service port.ApiKeyService
+}
+
+func NewApiKeyHandler(service port.ApiKeyService) *ApiKeyHandler {
+	return &ApiKeyHandler{
+		service,
+	}
+}
+
+type createApiKeyBody struct {
+	Name      string     `json:"name" binding:"required" example:"CI pipeline"`
+	Scopes    []string   `json:"scopes" binding:"required" example:"requests:write"`
+	ExpiresAt *time.Time `json:"expires_at" example:"2030-01-01T00:00:00Z"`
+}
+
+// Create generates a new key for the user, the response is the only moment the key is shown
+func (handler *ApiKeyHandler) Create(ctx *gin.Context) {
+
+	user := getAuthUser(ctx)
+
+	if user == nil {
+		return
+	}
+
+	var body createApiKeyBody
+
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.Error(core.NewValidationError(core.ErrInvalidInput, "name and scopes are required"))
+		return
+	}
+
+	var expiresAt time.Time
+	if body.ExpiresAt != nil {
+		expiresAt = *body.ExpiresAt
+	}
+
+	key, plainKey, err := handler.service.Create(ctx, user, body.Name, body.Scopes, expiresAt)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	rsp := newApiKeyResponse(key)
+	rsp.Key = plainKey
+	ctx.JSON(http.StatusCreated, rsp)
+}
+
+func (handler *ApiKeyHandler) List(ctx *gin.Context) {
+
+	user := getAuthUser(ctx)
+
+	if user == nil {
+		return
+	}
+
+	keys, err := handler.service.List(ctx, user.Id)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	keyList := []apiKeyResponse{}
+	for _, key := range keys {
+		keyList = append(keyList, newApiKeyResponse(&key))
+	}
+
+	ctx.JSON(http.StatusOK, keyList)
+}
+
+func (handler *ApiKeyHandler) Revoke(ctx *gin.Context) {
+
+	user := getAuthUser(ctx)
+
+	if user == nil {
+		return
+	}
+
+	id, err := parseIdParam(ctx)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if err := handler.service.Revoke(ctx, id, user.Id); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+type apiKeyResponse struct {
+	ID         uint64     `json:"id" example:"1"`
+	Name       string     `json:"name" example:"CI pipeline"`
+	Key        string     `json:"key,omitempty" example:"vsk_..."`
+	Prefix     string     `json:"prefix" example:"vsk_AbCdEfGh"`
+	Scopes     []string   `json:"scopes" example:"requests:write"`
+	CreatedAt  time.Time  `json:"created_at" example:"1970-01-01T00:00:00Z"`
+	LastUsedAt *time.Time `json:"last_used_at" example:"1970-01-01T00:00:00Z"`
+	ExpiresAt  *time.Time `json:"expires_at" example:"1970-01-01T00:00:00Z"`
+}
+
+func newApiKeyResponse(key *entity.ApiKey) apiKeyResponse {
+	return apiKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		CreatedAt:  key.CreatedAt,
+		LastUsedAt: optionalTime(key.LastUsedAt),
+		ExpiresAt:  optionalTime(key.ExpiresAt),
+	}
+}
+
+// optionalTime informs the zero time as null
+func optionalTime(value time.Time) *time.Time {
+	if value.IsZero() {
+		return nil
+	}
+	return &value
+}
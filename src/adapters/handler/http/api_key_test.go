@@ -0,0 +1,124 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	controller "example/web-service-gin/src/adapters/handler/http"
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/infra/middleware"
+	"example/web-service-gin/src/utils/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockApiKeyService struct {
+	mock.Mock
+}
+
+func (m *MockApiKeyService) Create(ctx context.Context, user *entity.User, name string, scopes []string, expiresAt time.Time) (*entity.ApiKey, string, error) {
+	args := m.Called(ctx, user, name, scopes, expiresAt)
+	if args.Get(0) == nil {
+		return nil, "", args.Error(2)
+	}
+	return args.Get(0).(*entity.ApiKey), args.String(1), args.Error(2)
+}
+
+func (m *MockApiKeyService) List(ctx context.Context, userId string) ([]entity.ApiKey, error) {
+	args := m.Called(ctx, userId)
+	return args.Get(0).([]entity.ApiKey), args.Error(1)
+}
+
+func (m *MockApiKeyService) Revoke(ctx context.Context, id uint64, userId string) error {
+	args := m.Called(ctx, id, userId)
+	return args.Error(0)
+}
+
+func setUpApiKeys() (*gin.Engine, *MockApiKeyService) {
+	mockJwtService := new(mocks.MockJwtService)
+	mockService := new(MockApiKeyService)
+	handler := controller.NewApiKeyHandler(mockService)
+
+	mockJwtService.On("GetUser", "valid-token").Return(&entity.User{Id: "123456", Email: "user@example.com"}, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.Authenticate(mockJwtService, nil))
+	router.POST("/me/api-keys", handler.Create)
+	router.GET("/me/api-keys", handler.List)
+	router.DELETE("/me/api-keys/:id", handler.Revoke)
+
+	return router, mockService
+}
+
+func TestApiKeyHandler_Create(t *testing.T) {
+	router, service := setUpApiKeys()
+	service.On("Create", mock.Anything, mock.Anything, "CI pipeline", []string{entity.ScopeRequestsWrite}, time.Time{}).
+		Return(&entity.ApiKey{ID: 1, Name: "CI pipeline", Prefix: "vsk_AbCdEfGh"}, "vsk_AbCdEfGhSecret", nil)
+
+	body := bytes.NewBufferString(`{"name": "CI pipeline", "scopes": ["requests:write"]}`)
+	req, _ := http.NewRequest(http.MethodPost, "/me/api-keys", body)
+	req.Header.Add("Authorization", "valid-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"key":"vsk_AbCdEfGhSecret"`)
+	assert.Contains(t, w.Body.String(), `"expires_at":null`)
+}
+
+func TestApiKeyHandler_CreateInvalidBody(t *testing.T) {
+	router, service := setUpApiKeys()
+
+	req, _ := http.NewRequest(http.MethodPost, "/me/api-keys", bytes.NewBufferString(`{"scopes": []}`))
+	req.Header.Add("Authorization", "valid-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	service.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestApiKeyHandler_List(t *testing.T) {
+	router, service := setUpApiKeys()
+	service.On("List", mock.Anything, "123456").
+		Return([]entity.ApiKey{{ID: 1, Name: "CI pipeline", KeyHash: "9f86d081", LastUsedAt: time.Now()}}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/me/api-keys", nil)
+	req.Header.Add("Authorization", "valid-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"CI pipeline"`)
+	assert.NotContains(t, w.Body.String(), "9f86d081")
+	assert.NotContains(t, w.Body.String(), `"key"`)
+}
+
+func TestApiKeyHandler_Revoke(t *testing.T) {
+	router, service := setUpApiKeys()
+	service.On("Revoke", mock.Anything, uint64(1), "123456").Return(nil)
+	service.On("Revoke", mock.Anything, uint64(2), "123456").Return(core.ErrDataNotFound)
+
+	cases := map[string]int{
+		"/me/api-keys/1":   http.StatusNoContent,
+		"/me/api-keys/2":   http.StatusNotFound,
+		"/me/api-keys/abc": http.StatusBadRequest,
+	}
+
+	for path, expectedStatus := range cases {
+		req, _ := http.NewRequest(http.MethodDelete, path, nil)
+		req.Header.Add("Authorization", "valid-token")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, expectedStatus, w.Code, path)
+	}
+}
@@ -0,0 +1,118 @@
+package http
+
+import (
+	"encoding/json"
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/port"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EventStreamHandler struct {
+	service   port.EventStreamService
+	heartbeat time.Duration
+}
+
+// NewEventStreamHandler creates a new handler that writes a heartbeat comment on each interval,
+// so the proxies keep the idle streams open
+func NewEventStreamHandler(service port.EventStreamService, heartbeat time.Duration) *EventStreamHandler {
+	return &EventStreamHandler{
+		service,
+		heartbeat,
+	}
+}
+
+type statusEventResponse struct {
+	RequestId uint64               `json:"request_id" example:"1"`
+	Status    entity.RequestStatus `json:"status" example:"COMPLETED"`
+	Message   string               `json:"message" example:"processing finished with success"`
+	CreatedAt time.Time            `json:"created_at" example:"1970-01-01T00:00:00Z"`
+}
+
+// Stream sends the status changes of the requests of the user as Server-Sent Events. The id of
+// each event is the id on the history, so a reconnection with Last-Event-ID replays the missed ones
+func (handler *EventStreamHandler) Stream(ctx *gin.Context) {
+
+	user := getAuthUser(ctx)
+
+	if user == nil {
+		return
+	}
+
+	var lastEventId uint64
+	if header := ctx.GetHeader("Last-Event-ID"); header != "" {
+		id, err := strconv.ParseUint(header, 10, 64)
+
+		if err != nil {
+			ctx.Error(core.NewValidationError(core.ErrInvalidInput, "Last-Event-ID must be a number"))
+			return
+		}
+
+		lastEventId = id
+	}
+
+	stream, err := handler.service.Subscribe(ctx, user.Id, lastEventId)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	defer stream.Close()
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+
+	for _, event := range stream.Missed {
+		writeStatusEvent(ctx.Writer, event)
+		lastEventId = event.ID
+	}
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(handler.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(ctx.Writer, ": heartbeat\n\n")
+		case event, open := <-stream.Events:
+			// The subscription is closed when it falls behind, the client reconnects and resumes from the history
+			if !open {
+				return
+			}
+
+			// The events replayed from the history may also be received live
+			if event.ID <= lastEventId {
+				continue
+			}
+
+			writeStatusEvent(ctx.Writer, event)
+			lastEventId = event.ID
+		}
+
+		ctx.Writer.Flush()
+	}
+}
+
+func writeStatusEvent(writer io.Writer, event entity.RequestEvent) {
+	data, _ := json.Marshal(statusEventResponse{
+		RequestId: event.RequestId,
+		Status:    event.Status,
+		Message:   event.Message,
+		CreatedAt: event.CreatedAt,
+	})
+
+	fmt.Fprintf(writer, "id: %d\nevent: status\ndata: %s\n\n", event.ID, data)
+}
@@ -0,0 +1,108 @@
+package http_test
+
+import (
+	"context"
+	controller "example/web-service-gin/src/adapters/handler/http"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/infra/middleware"
+	"example/web-service-gin/src/utils/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockEventStreamService struct {
+	mock.Mock
+}
+
+func (m *MockEventStreamService) Subscribe(ctx context.Context, userId string, lastEventId uint64) (*entity.RequestEventStream, error) {
+	args := m.Called(ctx, userId, lastEventId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.RequestEventStream), args.Error(1)
+}
+
+func setUpEventStream(heartbeat time.Duration) (*gin.Engine, *MockEventStreamService) {
+	mockJwtService := new(mocks.MockJwtService)
+	mockService := new(MockEventStreamService)
+	handler := controller.NewEventStreamHandler(mockService, heartbeat)
+
+	mockJwtService.On("GetUser", "valid-token").Return(&entity.User{Id: "123456", Email: "user@example.com"}, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.Authenticate(mockJwtService, nil))
+	router.GET("/requests/events", handler.Stream)
+
+	return router, mockService
+}
+
+func TestEventStreamHandler_ResumesAndSkipsReplayedEvents(t *testing.T) {
+	router, service := setUpEventStream(time.Minute)
+	createdAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	live := make(chan entity.RequestEvent, 2)
+	live <- entity.RequestEvent{ID: 11, RequestId: 1, Status: entity.InProgress, CreatedAt: createdAt}
+	live <- entity.RequestEvent{ID: 12, RequestId: 1, Status: entity.Completed, Message: "done", CreatedAt: createdAt}
+	close(live)
+
+	closed := false
+	service.On("Subscribe", mock.Anything, "123456", uint64(10)).Return(&entity.RequestEventStream{
+		Missed: []entity.RequestEvent{{ID: 11, RequestId: 1, Status: entity.InProgress, CreatedAt: createdAt}},
+		Events: live,
+		Close:  func() { closed = true },
+	}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/requests/events", nil)
+	req.Header.Add("Authorization", "valid-token")
+	req.Header.Add("Last-Event-ID", "10")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Equal(t, "id: 11\nevent: status\n"+
+		`data: {"request_id":1,"status":"IN_PROGRESS","message":"","created_at":"2024-01-15T10:00:00Z"}`+"\n\n"+
+		"id: 12\nevent: status\n"+
+		`data: {"request_id":1,"status":"COMPLETED","message":"done","created_at":"2024-01-15T10:00:00Z"}`+"\n\n",
+		w.Body.String())
+	assert.True(t, closed)
+}
+
+func TestEventStreamHandler_Heartbeat(t *testing.T) {
+	router, service := setUpEventStream(5 * time.Millisecond)
+	service.On("Subscribe", mock.Anything, "123456", uint64(0)).Return(&entity.RequestEventStream{
+		Events: make(chan entity.RequestEvent),
+		Close:  func() {},
+	}, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/requests/events", nil)
+	req.Header.Add("Authorization", "valid-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), ": heartbeat\n\n")
+}
+
+func TestEventStreamHandler_InvalidLastEventId(t *testing.T) {
+	router, service := setUpEventStream(time.Minute)
+
+	req, _ := http.NewRequest(http.MethodGet, "/requests/events", nil)
+	req.Header.Add("Authorization", "valid-token")
+	req.Header.Add("Last-Event-ID", "abc")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	service.AssertNotCalled(t, "Subscribe", mock.Anything, mock.Anything, mock.Anything)
+}
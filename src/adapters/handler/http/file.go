@@ -0,0 +1,98 @@
+package http
+
+import (
+	"example/web-service-gin/src/core/port"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FileHandler struct {
+	storage port.SignedStoragePort
+}
+
+func NewFileHandler(storage port.SignedStoragePort) *FileHandler {
+	return &FileHandler{
+		storage,
+	}
+}
+
+// Download serves a file of the storage when the URL signature is valid,
+// a single "Range: bytes=start-end" header is answered with partial content
+func (handler *FileHandler) Download(ctx *gin.Context) {
+
+	fileKey := strings.TrimPrefix(ctx.Param("filepath"), "/")
+	expires := ctx.Query("expires")
+	signature := ctx.Query("signature")
+
+	err := handler.storage.VerifyFileUrl(fileKey, expires, signature)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	offset, length, isRange := parseRangeHeader(ctx.GetHeader("Range"))
+	file, info, err := handler.storage.DownloadFileRange(ctx, fileKey, offset, length)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	defer file.Close()
+
+	headers := map[string]string{
+		"Accept-Ranges":       "bytes",
+		"ETag":                info.ETag,
+		"Last-Modified":       info.LastModified.UTC().Format(http.TimeFormat),
+		"Content-Disposition": `attachment; filename="` + path.Base(fileKey) + `"`,
+	}
+
+	if !isRange {
+		ctx.DataFromReader(http.StatusOK, info.Size, info.ContentType, file, headers)
+		return
+	}
+
+	end := info.Size - 1
+	if length > 0 && offset+length < info.Size {
+		end = offset + length - 1
+	}
+
+	headers["Content-Range"] = fmt.Sprintf("bytes %d-%d/%d", offset, end, info.Size)
+	ctx.DataFromReader(http.StatusPartialContent, end-offset+1, info.ContentType, file, headers)
+}
+
+// parseRangeHeader reads a single "bytes=start-" or "bytes=start-end" range,
+// any other format is ignored and the whole file is served
+func parseRangeHeader(header string) (int64, int64, bool) {
+	spec, found := strings.CutPrefix(header, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, 0, false
+	}
+
+	startText, endText, found := strings.Cut(spec, "-")
+	if !found {
+		return 0, 0, false
+	}
+
+	start, err := strconv.ParseInt(startText, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, false
+	}
+
+	if endText == "" {
+		return start, 0, true
+	}
+
+	end, err := strconv.ParseInt(endText, 10, 64)
+	if err != nil || end < start {
+		return 0, 0, false
+	}
+
+	return start, end - start + 1, true
+}
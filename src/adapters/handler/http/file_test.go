@@ -0,0 +1,171 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	controller "example/web-service-gin/src/adapters/handler/http"
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/infra/middleware"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSignedStorage struct {
+	mock.Mock
+}
+
+func (m *MockSignedStorage) UploadFile(ctx context.Context, content io.ReadSeeker, size int64, contentType string, fileKey string) (string, error) {
+	args := m.Called(size, fileKey)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockSignedStorage) DownloadFile(ctx context.Context, fileKey string) (io.ReadCloser, *entity.FileInfo, error) {
+	args := m.Called(fileKey)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(io.ReadCloser), args.Get(1).(*entity.FileInfo), args.Error(2)
+}
+
+func (m *MockSignedStorage) DownloadFileRange(ctx context.Context, fileKey string, offset int64, length int64) (io.ReadCloser, *entity.FileInfo, error) {
+	args := m.Called(fileKey, offset, length)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(io.ReadCloser), args.Get(1).(*entity.FileInfo), args.Error(2)
+}
+
+func (m *MockSignedStorage) StatFile(ctx context.Context, fileKey string) (*entity.FileInfo, error) {
+	args := m.Called(fileKey)
+	return args.Get(0).(*entity.FileInfo), args.Error(1)
+}
+
+func (m *MockSignedStorage) FileExists(ctx context.Context, fileKey string) (bool, error) {
+	args := m.Called(fileKey)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSignedStorage) DeleteFile(ctx context.Context, fileKey string) error {
+	args := m.Called(fileKey)
+	return args.Error(0)
+}
+
+func (m *MockSignedStorage) ListFiles(ctx context.Context, prefix string) ([]entity.FileInfo, error) {
+	args := m.Called(prefix)
+	return args.Get(0).([]entity.FileInfo), args.Error(1)
+}
+
+func (m *MockSignedStorage) GetFileUrl(fileKey string) string {
+	args := m.Called(fileKey)
+	return args.String(0)
+}
+
+func (m *MockSignedStorage) VerifyFileUrl(fileKey string, expires string, signature string) error {
+	args := m.Called(fileKey, expires, signature)
+	return args.Error(0)
+}
+
+func setUpFiles() (*gin.Engine, *MockSignedStorage) {
+	storage := new(MockSignedStorage)
+	handler := controller.NewFileHandler(storage)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+	router.GET("/files/*filepath", handler.Download)
+
+	return router, storage
+}
+
+func getFileInfo() *entity.FileInfo {
+	return &entity.FileInfo{
+		Key:          "zip_output/file.zip",
+		Size:         11,
+		ContentType:  "application/zip",
+		ETag:         `"etag"`,
+		LastModified: time.Now(),
+	}
+}
+
+func TestFileHandler_Download(t *testing.T) {
+	router, storage := setUpFiles()
+
+	storage.On("VerifyFileUrl", "zip_output/file.zip", "123", "abc").Return(nil)
+	storage.On("DownloadFileRange", "zip_output/file.zip", int64(0), int64(0)).
+		Return(io.NopCloser(strings.NewReader("zip content")), getFileInfo(), nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/files/zip_output/file.zip?expires=123&signature=abc", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "zip content", w.Body.String())
+	assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+	assert.Equal(t, "11", w.Header().Get("Content-Length"))
+	assert.Equal(t, `"etag"`, w.Header().Get("ETag"))
+}
+
+func TestFileHandler_DownloadRange(t *testing.T) {
+	router, storage := setUpFiles()
+
+	storage.On("VerifyFileUrl", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	storage.On("DownloadFileRange", "zip_output/file.zip", int64(4), int64(3)).
+		Return(io.NopCloser(strings.NewReader("con")), getFileInfo(), nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/files/zip_output/file.zip?expires=123&signature=abc", nil)
+	req.Header.Set("Range", "bytes=4-6")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "con", w.Body.String())
+	assert.Equal(t, "bytes 4-6/11", w.Header().Get("Content-Range"))
+}
+
+func TestFileHandler_InvalidSignature(t *testing.T) {
+	router, storage := setUpFiles()
+
+	storage.On("VerifyFileUrl", mock.Anything, mock.Anything, mock.Anything).Return(core.ErrForbidden)
+
+	req, _ := http.NewRequest(http.MethodGet, "/files/zip_output/file.zip?expires=123&signature=invalid", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	storage.AssertNotCalled(t, "DownloadFileRange", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestFileHandler_NotFound(t *testing.T) {
+	router, storage := setUpFiles()
+
+	storage.On("VerifyFileUrl", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	storage.On("DownloadFileRange", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil, core.ErrDataNotFound)
+
+	req, _ := http.NewRequest(http.MethodGet, "/files/zip_output/file.zip?expires=123&signature=abc", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestFileHandler_StorageError(t *testing.T) {
+	router, storage := setUpFiles()
+
+	storage.On("VerifyFileUrl", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	storage.On("DownloadFileRange", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil, errors.New("disk error"))
+
+	req, _ := http.NewRequest(http.MethodGet, "/files/zip_output/file.zip?expires=123&signature=abc", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,143 @@
+package http
+
+import (
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/port"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	service port.NotificationService
+}
+
+func NewNotificationHandler(service port.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		service,
+	}
+}
+
+type preferencesBody struct {
+	Channels        []entity.NotificationChannel `json:"channels" binding:"required" example:"email"`
+	SlackWebhookUrl string                       `json:"slack_webhook_url" example:"https://hooks.slack.com/services/..."`
+	TeamsWebhookUrl string                       `json:"teams_webhook_url" example:"https://example.webhook.office.com/..."`
+	Locale          string                       `json:"locale" example:"pt-BR"`
+	Digest          entity.DigestMode            `json:"digest" example:"daily"`
+}
+
+func (handler *NotificationHandler) GetPreferences(ctx *gin.Context) {
+
+	user := getAuthUser(ctx)
+
+	if user == nil {
+		return
+	}
+
+	preferences, err := handler.service.GetPreferences(ctx, user.Id)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newPreferencesResponse(preferences))
+}
+
+// UpdatePreferences replaces all the preferences, an empty list of channels disables the notifications
+func (handler *NotificationHandler) UpdatePreferences(ctx *gin.Context) {
+
+	user := getAuthUser(ctx)
+
+	if user == nil {
+		return
+	}
+
+	var body preferencesBody
+
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.Error(core.NewValidationError(core.ErrInvalidInput, "channels is required"))
+		return
+	}
+
+	preferences, err := handler.service.UpdatePreferences(ctx, &entity.NotificationPreferences{
+		UserId:          user.Id,
+		Channels:        body.Channels,
+		SlackWebhookUrl: body.SlackWebhookUrl,
+		TeamsWebhookUrl: body.TeamsWebhookUrl,
+		Locale:          body.Locale,
+		Digest:          body.Digest,
+	})
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newPreferencesResponse(preferences))
+}
+
+// Preview renders a notification with sample data for the administrators, as JSON or
+// only its html or text when the format is informed
+func (handler *NotificationHandler) Preview(ctx *gin.Context) {
+
+	notification, err := handler.service.Preview(entity.NotificationKind(ctx.Query("kind")), ctx.Query("locale"))
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	switch ctx.Query("format") {
+	case "html":
+		ctx.Data(http.StatusOK, "text/html; charset=utf-8", []byte(notification.Html))
+	case "text":
+		ctx.String(http.StatusOK, notification.Text)
+	case "":
+		ctx.JSON(http.StatusOK, previewResponse{
+			Kind:    notification.Kind,
+			Locale:  notification.Locale,
+			Subject: notification.Subject,
+			Text:    notification.Text,
+			Html:    notification.Html,
+		})
+	default:
+		ctx.Error(core.NewValidationError(core.ErrInvalidInput, "format must be html or text"))
+	}
+}
+
+type previewResponse struct {
+	Kind    entity.NotificationKind `json:"kind" example:"completed"`
+	Locale  string                  `json:"locale" example:"pt-BR"`
+	Subject string                  `json:"subject" example:"Your request #42 is ready"`
+	Text    string                  `json:"text"`
+	Html    string                  `json:"html"`
+}
+
+type preferencesResponse struct {
+	Channels        []entity.NotificationChannel `json:"channels" example:"email"`
+	SlackWebhookUrl string                       `json:"slack_webhook_url" example:"https://hooks.slack.com/services/..."`
+	TeamsWebhookUrl string                       `json:"teams_webhook_url" example:"https://example.webhook.office.com/..."`
+	Locale          string                       `json:"locale" example:"pt-BR"`
+	Digest          entity.DigestMode            `json:"digest" example:"daily"`
+	UpdatedAt       *time.Time                   `json:"updated_at" example:"1970-01-01T00:00:00Z"`
+}
+
+func newPreferencesResponse(preferences *entity.NotificationPreferences) preferencesResponse {
+	rsp := preferencesResponse{
+		Channels:        preferences.Channels,
+		SlackWebhookUrl: preferences.SlackWebhookUrl,
+		TeamsWebhookUrl: preferences.TeamsWebhookUrl,
+		Locale:          preferences.Locale,
+		Digest:          preferences.Digest,
+		UpdatedAt:       optionalTime(preferences.UpdatedAt),
+	}
+
+	if rsp.Channels == nil {
+		rsp.Channels = []entity.NotificationChannel{}
+	}
+
+	return rsp
+}
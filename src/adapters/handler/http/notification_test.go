@@ -0,0 +1,195 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	controller "example/web-service-gin/src/adapters/handler/http"
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/infra/middleware"
+	"example/web-service-gin/src/utils/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockNotificationService struct {
+	mock.Mock
+}
+
+func (m *MockNotificationService) GetPreferences(ctx context.Context, userId string) (*entity.NotificationPreferences, error) {
+	args := m.Called(ctx, userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.NotificationPreferences), args.Error(1)
+}
+
+func (m *MockNotificationService) UpdatePreferences(ctx context.Context, preferences *entity.NotificationPreferences) (*entity.NotificationPreferences, error) {
+	args := m.Called(ctx, preferences)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.NotificationPreferences), args.Error(1)
+}
+
+func (m *MockNotificationService) Preview(kind entity.NotificationKind, locale string) (*entity.Notification, error) {
+	args := m.Called(kind, locale)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Notification), args.Error(1)
+}
+
+func setUpNotificationPreferences() (*gin.Engine, *MockNotificationService) {
+	mockJwtService := new(mocks.MockJwtService)
+	mockService := new(MockNotificationService)
+	handler := controller.NewNotificationHandler(mockService)
+
+	mockJwtService.On("GetUser", "valid-token").Return(&entity.User{Id: "123456", Email: "user@example.com"}, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.Authenticate(mockJwtService, nil))
+	router.GET("/me/notification-preferences", handler.GetPreferences)
+	router.PUT("/me/notification-preferences", handler.UpdatePreferences)
+	router.GET("/admin/notifications/preview", handler.Preview)
+
+	return router, mockService
+}
+
+func TestNotificationHandler_GetDefaultPreferences(t *testing.T) {
+	router, service := setUpNotificationPreferences()
+	service.On("GetPreferences", mock.Anything, "123456").Return(entity.DefaultNotificationPreferences("123456"), nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/me/notification-preferences", nil)
+	req.Header.Add("Authorization", "valid-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"channels": ["email"], "slack_webhook_url": "", "teams_webhook_url": "", "locale": "", "digest": "immediate", "updated_at": null}`, w.Body.String())
+}
+
+func TestNotificationHandler_UpdatePreferences(t *testing.T) {
+	router, service := setUpNotificationPreferences()
+	updatedAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	expected := &entity.NotificationPreferences{
+		UserId:          "123456",
+		Channels:        []entity.NotificationChannel{entity.ChannelSlack},
+		SlackWebhookUrl: "https://hooks.slack.com/services/T0/B0/X",
+		Locale:          "es",
+		Digest:          entity.DigestDaily,
+	}
+	service.On("UpdatePreferences", mock.Anything, expected).Return(&entity.NotificationPreferences{
+		UserId:          "123456",
+		Channels:        []entity.NotificationChannel{entity.ChannelSlack},
+		SlackWebhookUrl: "https://hooks.slack.com/services/T0/B0/X",
+		Locale:          "es",
+		Digest:          entity.DigestDaily,
+		UpdatedAt:       updatedAt,
+	}, nil)
+
+	body := bytes.NewBufferString(`{"channels": ["slack"], "slack_webhook_url": "https://hooks.slack.com/services/T0/B0/X", "locale": "es", "digest": "daily"}`)
+	req, _ := http.NewRequest(http.MethodPut, "/me/notification-preferences", body)
+	req.Header.Add("Authorization", "valid-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"channels": ["slack"],
+		"slack_webhook_url": "https://hooks.slack.com/services/T0/B0/X",
+		"teams_webhook_url": "",
+		"locale": "es",
+		"digest": "daily",
+		"updated_at": "2024-01-15T10:00:00Z"
+	}`, w.Body.String())
+}
+
+func TestNotificationHandler_UpdateMissingChannels(t *testing.T) {
+	router, service := setUpNotificationPreferences()
+
+	body := bytes.NewBufferString(`{"slack_webhook_url": "https://hooks.slack.com/services/T0/B0/X"}`)
+	req, _ := http.NewRequest(http.MethodPut, "/me/notification-preferences", body)
+	req.Header.Add("Authorization", "valid-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	service.AssertNotCalled(t, "UpdatePreferences", mock.Anything, mock.Anything)
+}
+
+func TestNotificationHandler_UpdateInvalidPreferences(t *testing.T) {
+	router, service := setUpNotificationPreferences()
+	service.On("UpdatePreferences", mock.Anything, mock.Anything).
+		Return(nil, core.NewValidationError(core.ErrInvalidInput, "slack_webhook_url is required by the slack channel"))
+
+	body := bytes.NewBufferString(`{"channels": ["slack"]}`)
+	req, _ := http.NewRequest(http.MethodPut, "/me/notification-preferences", body)
+	req.Header.Add("Authorization", "valid-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "slack_webhook_url is required")
+}
+
+func TestNotificationHandler_Preview(t *testing.T) {
+	router, service := setUpNotificationPreferences()
+	service.On("Preview", entity.NotificationRequestCompleted, "es").Return(&entity.Notification{
+		Kind:    entity.NotificationRequestCompleted,
+		Locale:  "es",
+		Subject: "Tu solicitud #42 está lista",
+		Text:    "Hola",
+		Html:    "<p>Hola</p>",
+	}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/admin/notifications/preview?kind=completed&locale=es", nil)
+	req.Header.Add("Authorization", "valid-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"kind": "completed",
+		"locale": "es",
+		"subject": "Tu solicitud #42 está lista",
+		"text": "Hola",
+		"html": "<p>Hola</p>"
+	}`, w.Body.String())
+}
+
+func TestNotificationHandler_PreviewHtml(t *testing.T) {
+	router, service := setUpNotificationPreferences()
+	service.On("Preview", entity.NotificationRequestFailed, "").
+		Return(&entity.Notification{Kind: entity.NotificationRequestFailed, Html: "<p>Olá</p>"}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/admin/notifications/preview?kind=failed&format=html", nil)
+	req.Header.Add("Authorization", "valid-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "<p>Olá</p>", w.Body.String())
+}
+
+func TestNotificationHandler_PreviewInvalidKind(t *testing.T) {
+	router, service := setUpNotificationPreferences()
+	service.On("Preview", entity.NotificationKind("unknown"), "").
+		Return(nil, core.NewValidationError(core.ErrInvalidInput, `invalid kind "unknown"`))
+
+	req, _ := http.NewRequest(http.MethodGet, "/admin/notifications/preview?kind=unknown", nil)
+	req.Header.Add("Authorization", "valid-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -1,10 +1,13 @@
 package http
 
 import (
+	"example/web-service-gin/src/core"
 	"example/web-service-gin/src/core/entity"
 	"example/web-service-gin/src/core/port"
+	"example/web-service-gin/src/infra/middleware"
 	"log"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -32,18 +35,41 @@ func (r *RequestHandler) HealthCheck(c *gin.Context) {
 func (handler *RequestHandler) Register(ctx *gin.Context) {
 
 	user := getAuthUser(ctx)
-	file, _ := ctx.FormFile("video_file")
+
+	if user == nil {
+		return
+	}
+
+	file, err := ctx.FormFile("video_file")
+
+	if err != nil {
+		ctx.Error(core.NewValidationError(core.ErrInvalidInput, "video_file is required"))
+		return
+	}
+
 	log.Println(file.Filename)
 
+	options, err := parseExtractionOptions(ctx)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
 	request := entity.Request{
-		UserId:    user.Id,
-		UserEmail: user.Email,
+		UserId:            user.Id,
+		UserEmail:         user.Email,
+		UserEmailVerified: user.EmailVerified,
+		UserLocale:        user.Locale,
+		OrganizationId:    user.OrganizationId,
+		Plan:              user.Plan,
+		Options:           options,
 	}
 
 	createdRequest, createError := handler.service.Create(ctx, &request, file)
 
 	if createError != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "request not saved. Try again later."})
+		ctx.Error(createError)
 		return
 	}
 
@@ -56,7 +82,6 @@ func (handler *RequestHandler) ListUsers(ctx *gin.Context) {
 	user := getAuthUser(ctx)
 
 	if user == nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
 
@@ -65,7 +90,7 @@ func (handler *RequestHandler) ListUsers(ctx *gin.Context) {
 	requests, err := handler.service.List(ctx, user.Id)
 
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "request not saved. Try again later."})
+		ctx.Error(err)
 		return
 	}
 
@@ -81,43 +106,112 @@ func (handler *RequestHandler) ListUsers(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, requestList)
 }
 
-func getAuthUser(ctx *gin.Context) *entity.User {
-	jwtServiceInterface, _ := ctx.Get("jwtService")
-	jwtService := jwtServiceInterface.(port.JwtService)
+// parseExtractionOptions reads the optional form fields of the extraction,
+// frame_interval is the amount of seconds between two frames (e.g. 0.5)
+func parseExtractionOptions(ctx *gin.Context) (entity.ExtractionOptions, error) {
+	var options entity.ExtractionOptions
+
+	if value := ctx.PostForm("frame_interval"); value != "" {
+		seconds, err := strconv.ParseFloat(value, 64)
+		if err != nil || seconds < 0.1 || seconds > 3600 {
+			return options, core.NewValidationError(core.ErrInvalidInput, "frame_interval must be a number of seconds between 0.1 and 3600")
+		}
+		options.FrameInterval = time.Duration(seconds * float64(time.Second))
+	}
 
-	jwtToken := ctx.Request.Header.Get("Authorization")
-	user, err := jwtService.GetUser(jwtToken)
+	return options, nil
+}
 
-	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
-		return nil
+// getAuthUser returns the user authenticated by the middleware, the
+// routes of the handler must always be behind middleware.Authenticate
+func getAuthUser(ctx *gin.Context) *entity.User {
+	user := middleware.GetAuthUser(ctx)
+
+	if user == nil {
+		ctx.Error(core.ErrUnauthorized)
 	}
 
 	return user
 }
 
 type requestResponse struct {
-	ID           uint64               `json:"id" example:"1"`
-	UserId       string               `json:"user_id" example:"1231231231"`
-	UserEmail    string               `json:"user_email" example:"user@example.com"`
-	VideoSize    int64                `json:"video_size" example:"1048576"`
-	VideoKey     string               `json:"video_url" example:"https://google.com"`
-	ZipOutputKey string               `json:"zip_output_key" example:"123456"`
-	Status       entity.RequestStatus `json:"status" example:"PENDING"`
-	CreatedAt    time.Time            `json:"created_at" example:"1970-01-01T00:00:00Z"`
-	FinishedAt   time.Time            `json:"finished_at" example:"1970-01-01T00:00:00Z"`
+	ID                 uint64                    `json:"id" example:"1"`
+	UserId             string                    `json:"user_id" example:"1231231231"`
+	UserEmail          string                    `json:"user_email" example:"user@example.com"`
+	VideoSize          int64                     `json:"video_size" example:"1048576"`
+	VideoKey           string                    `json:"video_url" example:"https://google.com"`
+	Metadata           *metadataResponse         `json:"metadata,omitempty"`
+	ContentHash        string                    `json:"content_hash,omitempty" example:"9f86d081884c7d65..."`
+	FrameInterval      float64                   `json:"frame_interval" example:"1"`
+	DuplicateOf        uint64                    `json:"duplicate_of,omitempty" example:"1"`
+	ZipOutputUrl       string                    `json:"zip_output_key" example:"https://example.com/frames.zip"`
+	Status             entity.RequestStatus      `json:"status" example:"PENDING"`
+	NotificationStatus entity.NotificationStatus `json:"notification_status,omitempty" example:"SENT"`
+	Progress           *progressResponse         `json:"progress,omitempty"`
+	CreatedAt          time.Time                 `json:"created_at" example:"1970-01-01T00:00:00Z"`
+	FinishedAt         time.Time                 `json:"finished_at" example:"1970-01-01T00:00:00Z"`
+}
+
+type progressResponse struct {
+	Percent         float64    `json:"percent" example:"42.5"`
+	FramesExtracted int        `json:"frames_extracted" example:"120"`
+	Eta             *time.Time `json:"eta" example:"1970-01-01T00:00:00Z"`
+	UpdatedAt       time.Time  `json:"updated_at" example:"1970-01-01T00:00:00Z"`
+}
+
+type metadataResponse struct {
+	Format          string  `json:"format" example:"mp4"`
+	DurationSeconds float64 `json:"duration_seconds" example:"90.5"`
+	Width           int     `json:"width" example:"1920"`
+	Height          int     `json:"height" example:"1080"`
+	Codec           string  `json:"codec" example:"h264"`
 }
 
 func newRequestResponse(request *entity.Request) requestResponse {
 	return requestResponse{
-		ID:           request.ID,
-		UserId:       request.UserId,
-		UserEmail:    request.UserEmail,
-		VideoSize:    request.VideoSize,
-		VideoKey:     request.VideoKey,
-		ZipOutputKey: request.ZipOutputKey,
-		Status:       request.Status,
-		CreatedAt:    request.CreatedAt,
-		FinishedAt:   request.FinishedAt,
+		ID:                 request.ID,
+		UserId:             request.UserId,
+		UserEmail:          request.UserEmail,
+		VideoSize:          request.VideoSize,
+		VideoKey:           request.VideoKey,
+		Metadata:           newMetadataResponse(request.VideoMetadata),
+		ContentHash:        request.ContentHash,
+		FrameInterval:      request.Options.FrameInterval.Seconds(),
+		DuplicateOf:        request.DuplicateOf,
+		ZipOutputUrl:       request.ZipOutputUrl,
+		Status:             request.Status,
+		NotificationStatus: request.NotificationStatus,
+		Progress:           newProgressResponse(request.Progress),
+		CreatedAt:          request.CreatedAt,
+		FinishedAt:         request.FinishedAt,
+	}
+}
+
+// newProgressResponse omits the progress until the worker reports it
+func newProgressResponse(progress entity.Progress) *progressResponse {
+	if progress.IsZero() {
+		return nil
+	}
+
+	return &progressResponse{
+		Percent:         progress.Percent,
+		FramesExtracted: progress.FramesExtracted,
+		Eta:             optionalTime(progress.Eta),
+		UpdatedAt:       progress.UpdatedAt,
+	}
+}
+
+// newMetadataResponse omits the metadata of the requests created before it was extracted
+func newMetadataResponse(metadata entity.VideoMetadata) *metadataResponse {
+	if metadata.Format == "" {
+		return nil
+	}
+
+	return &metadataResponse{
+		Format:          metadata.Format,
+		DurationSeconds: metadata.Duration.Seconds(),
+		Width:           metadata.Width,
+		Height:          metadata.Height,
+		Codec:           metadata.Codec,
 	}
 }
@@ -3,9 +3,12 @@ package http_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	controller "example/web-service-gin/src/adapters/handler/http"
+	"example/web-service-gin/src/core"
 	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/infra/middleware"
 	"example/web-service-gin/src/utils/mocks"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -14,6 +17,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 // Mocks
@@ -42,14 +46,14 @@ func (m *MockRequestService) Get(ctx context.Context, id uint64) (*entity.Reques
 	return args.Get(0).(*entity.Request), args.Error(1)
 }
 
-func (m *MockRequestService) HandleUploadNotification(ctx context.Context, msg entity.EventMessage) {
-	m.Called(ctx, msg)
-	return
+func (m *MockRequestService) HandleUploadNotification(ctx context.Context, msg entity.EventMessage) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
 }
 
-func (m *MockRequestService) HandleVideoOutputNotification(ctx context.Context, msg entity.EventMessage) {
-	m.Called(ctx, msg)
-	return
+func (m *MockRequestService) HandleVideoOutputNotification(ctx context.Context, msg entity.EventMessage) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
 }
 
 // Testing Cases
@@ -61,10 +65,8 @@ func setUp(passAuth bool) (*controller.RequestHandler, *gin.Engine, *MockRequest
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.Use(func(c *gin.Context) {
-		c.Set("jwtService", mockJwtService)
-		c.Next()
-	})
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.Authenticate(mockJwtService, nil))
 
 	if passAuth {
 		mockJwtService.On("GetUser", "valid-token").Return(&entity.User{
@@ -144,6 +146,102 @@ func TestRequestHandler_RegisterError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
 
+func TestRequestHandler_RegisterValidationErrors(t *testing.T) {
+
+	cases := map[int]*core.ValidationError{
+		http.StatusBadRequest:            core.NewValidationError(core.ErrInvalidFile, "file is empty"),
+		http.StatusRequestEntityTooLarge: core.NewValidationError(core.ErrFileTooLarge, "file size is greater than 500Mb"),
+		http.StatusUnsupportedMediaType:  core.NewValidationError(core.ErrUnsupportedMediaType, "file extension not allowed"),
+	}
+
+	for expectedStatus, validationError := range cases {
+		handler, router, service := setUp(true)
+		router.POST("/requests", handler.Register)
+
+		body, contentType := generateFileForm(t)
+		service.On("Create", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return((*entity.Request)(nil), validationError)
+
+		req, _ := http.NewRequest(http.MethodPost, "/requests", body)
+		req.Header.Add("Content-Type", contentType)
+		req.Header.Add("Authorization", "valid-token")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, expectedStatus, w.Code)
+		assert.Equal(t, middleware.ProblemContentType, w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), validationError.Message)
+	}
+}
+
+func generateFileFormWithInterval(t *testing.T, frameInterval string) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	fileWriter, _ := writer.CreateFormFile("video_file", "test.mp4")
+	_, _ = fileWriter.Write([]byte("conteúdo fictício do arquivo"))
+	assert.NoError(t, writer.WriteField("frame_interval", frameInterval))
+	assert.NoError(t, writer.Close())
+
+	return body, writer.FormDataContentType()
+}
+
+func TestRequestHandler_RegisterWithFrameInterval(t *testing.T) {
+
+	handler, router, service := setUp(true)
+	router.POST("/requests", handler.Register)
+
+	body, contentType := generateFileFormWithInterval(t, "0.5")
+	service.On("Create", mock.Anything, mock.MatchedBy(func(request *entity.Request) bool {
+		return request.Options.FrameInterval == 500*time.Millisecond
+	}), mock.Anything).Return(&entity.Request{ID: 1}, nil)
+
+	req, _ := http.NewRequest(http.MethodPost, "/requests", body)
+	req.Header.Add("Content-Type", contentType)
+	req.Header.Add("Authorization", "valid-token")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	service.AssertExpectations(t)
+}
+
+func TestRequestHandler_RegisterInvalidFrameInterval(t *testing.T) {
+
+	for _, frameInterval := range []string{"abc", "0", "-1", "7200"} {
+		handler, router, service := setUp(true)
+		router.POST("/requests", handler.Register)
+
+		body, contentType := generateFileFormWithInterval(t, frameInterval)
+		req, _ := http.NewRequest(http.MethodPost, "/requests", body)
+		req.Header.Add("Content-Type", contentType)
+		req.Header.Add("Authorization", "valid-token")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, frameInterval)
+		service.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	}
+}
+
+func TestRequestHandler_RegisterWithoutFile(t *testing.T) {
+
+	handler, router, service := setUp(true)
+	router.POST("/requests", handler.Register)
+
+	req, _ := http.NewRequest(http.MethodPost, "/requests", nil)
+	req.Header.Add("Authorization", "valid-token")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	service.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestRequestHandler_ListUsers(t *testing.T) {
 
 	handler, router, service := setUp(true)
@@ -163,6 +261,38 @@ func TestRequestHandler_ListUsers(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 }
 
+func TestRequestHandler_ListUsersWithProgress(t *testing.T) {
+
+	handler, router, service := setUp(true)
+	router.GET("/requests", handler.ListUsers)
+
+	updatedAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	inProgress := mocks.MockGetRequest()
+	inProgress.Status = entity.InProgress
+	inProgress.Progress = entity.Progress{Percent: 42.5, FramesExtracted: 120, Eta: updatedAt.Add(time.Minute), UpdatedAt: updatedAt}
+	pending := mocks.MockGetRequest()
+	pending.ID = 2
+
+	service.On("List", mock.Anything, mock.Anything).Return([]entity.Request{inProgress, pending}, nil)
+	req, _ := http.NewRequest(http.MethodGet, "/requests", nil)
+	req.Header.Add("Authorization", "valid-token")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var body []map[string]any
+	json.Unmarshal(w.Body.Bytes(), &body)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, map[string]any{
+		"percent":          42.5,
+		"frames_extracted": float64(120),
+		"eta":              "2024-01-15T10:01:00Z",
+		"updated_at":       "2024-01-15T10:00:00Z",
+	}, body[0]["progress"])
+	assert.NotContains(t, body[1], "progress")
+}
+
 func TestRequestHandler_ListUsersNoContent(t *testing.T) {
 
 	handler, router, service := setUp(true)
@@ -214,7 +344,11 @@ func TestRequestHandler_InvalidToken(t *testing.T) {
 
 func TestRequestHandler_HealthCheck(t *testing.T) {
 
-	handler, router, _ := setUp(false)
+	handler := controller.NewRequestHandler(new(MockRequestService))
+
+	// The health check is public, so it runs without the authentication middleware
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
 	router.GET("/healthcheck", handler.HealthCheck)
 
 	req, _ := http.NewRequest(http.MethodGet, "/healthcheck", nil)
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/port"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UsageHandler struct {
+	service port.QuotaService
+}
+
+func NewUsageHandler(service port.QuotaService) *UsageHandler {
+	return &UsageHandler{
+		service,
+	}
+}
+
+// GetUsage informs the plan of the user and how much of each quota is left
+func (handler *UsageHandler) GetUsage(ctx *gin.Context) {
+
+	user := getAuthUser(ctx)
+
+	if user == nil {
+		return
+	}
+
+	plan, usage, err := handler.service.GetUsage(ctx, user)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUsageResponse(plan, usage))
+}
+
+type usageResponse struct {
+	Plan           string        `json:"plan" example:"free"`
+	MaxFileSize    *int64        `json:"max_file_size" example:"524288000"`
+	ConcurrentJobs quotaResponse `json:"concurrent_jobs"`
+	RequestsPerDay quotaResponse `json:"requests_per_day"`
+	BytesPerMonth  quotaResponse `json:"bytes_per_month"`
+}
+
+// quotaResponse is the usage of a quota, the limit and remaining amount are null when it is unlimited
+type quotaResponse struct {
+	Limit     *int64     `json:"limit" example:"20"`
+	Used      int64      `json:"used" example:"5"`
+	Remaining *int64     `json:"remaining" example:"15"`
+	ResetAt   *time.Time `json:"reset_at,omitempty" example:"1970-01-01T00:00:00Z"`
+}
+
+func newUsageResponse(plan *entity.Plan, usage *entity.Usage) usageResponse {
+	return usageResponse{
+		Plan:           plan.Name,
+		MaxFileSize:    optionalLimit(plan.MaxFileSize),
+		ConcurrentJobs: newQuotaResponse(int64(plan.MaxConcurrentJobs), int64(usage.ActiveJobs), time.Time{}),
+		RequestsPerDay: newQuotaResponse(int64(plan.MaxRequestsPerDay), int64(usage.RequestsToday), usage.DailyResetAt()),
+		BytesPerMonth:  newQuotaResponse(plan.MaxBytesPerMonth, usage.BytesThisMonth, usage.MonthlyResetAt()),
+	}
+}
+
+func newQuotaResponse(limit int64, used int64, resetAt time.Time) quotaResponse {
+	quota := quotaResponse{
+		Limit:   optionalLimit(limit),
+		Used:    used,
+		ResetAt: optionalTime(resetAt),
+	}
+
+	if quota.Limit != nil {
+		remaining := max(limit-used, 0)
+		quota.Remaining = &remaining
+	}
+
+	return quota
+}
+
+// optionalLimit informs the disabled (zero) limits as null
+func optionalLimit(limit int64) *int64 {
+	if limit <= 0 {
+		return nil
+	}
+	return &limit
+}
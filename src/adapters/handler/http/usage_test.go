@@ -0,0 +1,85 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	controller "example/web-service-gin/src/adapters/handler/http"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/infra/middleware"
+	"example/web-service-gin/src/utils/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockQuotaService struct {
+	mock.Mock
+}
+
+func (m *MockQuotaService) GetUsage(ctx context.Context, user *entity.User) (*entity.Plan, *entity.Usage, error) {
+	args := m.Called(ctx, user)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*entity.Plan), args.Get(1).(*entity.Usage), args.Error(2)
+}
+
+func setUpUsage() (*gin.Engine, *MockQuotaService) {
+	mockJwtService := new(mocks.MockJwtService)
+	mockService := new(MockQuotaService)
+	handler := controller.NewUsageHandler(mockService)
+
+	mockJwtService.On("GetUser", "valid-token").Return(&entity.User{Id: "123456", Email: "user@example.com"}, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.Authenticate(mockJwtService, nil))
+	router.GET("/me/usage", handler.GetUsage)
+
+	return router, mockService
+}
+
+func TestUsageHandler_GetUsage(t *testing.T) {
+	router, service := setUpUsage()
+	plan := &entity.Plan{Name: "free", MaxConcurrentJobs: 3, MaxRequestsPerDay: 20}
+	usage := &entity.Usage{
+		ActiveJobs:     1,
+		RequestsToday:  5,
+		BytesThisMonth: 2048,
+		DayStart:       time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		MonthStart:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	service.On("GetUsage", mock.Anything, mock.Anything).Return(plan, usage, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/me/usage", nil)
+	req.Header.Add("Authorization", "valid-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"plan": "free",
+		"max_file_size": null,
+		"concurrent_jobs": {"limit": 3, "used": 1, "remaining": 2},
+		"requests_per_day": {"limit": 20, "used": 5, "remaining": 15, "reset_at": "2024-01-16T00:00:00Z"},
+		"bytes_per_month": {"limit": null, "used": 2048, "remaining": null, "reset_at": "2024-02-01T00:00:00Z"}
+	}`, w.Body.String())
+}
+
+func TestUsageHandler_GetUsageError(t *testing.T) {
+	router, service := setUpUsage()
+	service.On("GetUsage", mock.Anything, mock.Anything).Return(nil, nil, errors.New("connection refused"))
+
+	req, _ := http.NewRequest(http.MethodGet, "/me/usage", nil)
+	req.Header.Add("Authorization", "valid-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
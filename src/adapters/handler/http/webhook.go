@@ -0,0 +1,292 @@
+package http
+
+import (
+	"encoding/json"
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/port"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	service port.WebhookService
+}
+
+func NewWebhookHandler(service port.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		service,
+	}
+}
+
+type createWebhookBody struct {
+	Url    string   `json:"url" binding:"required" example:"https://example.com/webhooks"`
+	Events []string `json:"events" binding:"required" example:"request.completed"`
+}
+
+type updateWebhookBody struct {
+	Url    *string  `json:"url" example:"https://example.com/webhooks"`
+	Events []string `json:"events" example:"request.completed"`
+	Active *bool    `json:"active" example:"true"`
+}
+
+// Create registers a new webhook of the user, the response is the only moment the secret is shown
+func (handler *WebhookHandler) Create(ctx *gin.Context) {
+
+	user := getAuthUser(ctx)
+
+	if user == nil {
+		return
+	}
+
+	var body createWebhookBody
+
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.Error(core.NewValidationError(core.ErrInvalidInput, "url and events are required"))
+		return
+	}
+
+	webhook, err := handler.service.Create(ctx, user.Id, body.Url, body.Events)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	rsp := newWebhookResponse(webhook)
+	rsp.Secret = webhook.Secret
+	ctx.JSON(http.StatusCreated, rsp)
+}
+
+func (handler *WebhookHandler) List(ctx *gin.Context) {
+
+	user := getAuthUser(ctx)
+
+	if user == nil {
+		return
+	}
+
+	webhooks, err := handler.service.List(ctx, user.Id)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	webhookList := []webhookResponse{}
+	for _, webhook := range webhooks {
+		webhookList = append(webhookList, newWebhookResponse(&webhook))
+	}
+
+	ctx.JSON(http.StatusOK, webhookList)
+}
+
+func (handler *WebhookHandler) Get(ctx *gin.Context) {
+
+	user := getAuthUser(ctx)
+
+	if user == nil {
+		return
+	}
+
+	id, err := parseIdParam(ctx)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	webhook, err := handler.service.Get(ctx, id, user.Id)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newWebhookResponse(webhook))
+}
+
+// Update changes only the informed fields, a webhook is paused setting active to false
+func (handler *WebhookHandler) Update(ctx *gin.Context) {
+
+	user := getAuthUser(ctx)
+
+	if user == nil {
+		return
+	}
+
+	id, err := parseIdParam(ctx)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var body updateWebhookBody
+
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.Error(core.NewValidationError(core.ErrInvalidInput, "invalid webhook changes"))
+		return
+	}
+
+	webhook, err := handler.service.Update(ctx, id, user.Id, entity.WebhookChanges{
+		Url:    body.Url,
+		Events: body.Events,
+		Active: body.Active,
+	})
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newWebhookResponse(webhook))
+}
+
+func (handler *WebhookHandler) Delete(ctx *gin.Context) {
+
+	user := getAuthUser(ctx)
+
+	if user == nil {
+		return
+	}
+
+	id, err := parseIdParam(ctx)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if err := handler.service.Delete(ctx, id, user.Id); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListDeliveries returns the last deliveries of the webhook, the newest first
+func (handler *WebhookHandler) ListDeliveries(ctx *gin.Context) {
+
+	user := getAuthUser(ctx)
+
+	if user == nil {
+		return
+	}
+
+	id, err := parseIdParam(ctx)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	deliveries, err := handler.service.ListDeliveries(ctx, id, user.Id)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	deliveryList := []deliveryResponse{}
+	for _, delivery := range deliveries {
+		deliveryList = append(deliveryList, newDeliveryResponse(&delivery))
+	}
+
+	ctx.JSON(http.StatusOK, deliveryList)
+}
+
+// Redeliver queues the payload of a delivery again, it is sent on the next run of the deliveries
+func (handler *WebhookHandler) Redeliver(ctx *gin.Context) {
+
+	user := getAuthUser(ctx)
+
+	if user == nil {
+		return
+	}
+
+	id, err := parseIdParam(ctx)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	deliveryId, err := strconv.ParseUint(ctx.Param("deliveryId"), 10, 64)
+
+	if err != nil {
+		ctx.Error(core.NewValidationError(core.ErrInvalidInput, "delivery id must be a number"))
+		return
+	}
+
+	delivery, err := handler.service.Redeliver(ctx, id, deliveryId, user.Id)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, newDeliveryResponse(delivery))
+}
+
+type webhookResponse struct {
+	ID        uint64    `json:"id" example:"1"`
+	Url       string    `json:"url" example:"https://example.com/webhooks"`
+	Secret    string    `json:"secret,omitempty" example:"whsec_..."`
+	Events    []string  `json:"events" example:"request.completed"`
+	Active    bool      `json:"active" example:"true"`
+	CreatedAt time.Time `json:"created_at" example:"1970-01-01T00:00:00Z"`
+	UpdatedAt time.Time `json:"updated_at" example:"1970-01-01T00:00:00Z"`
+}
+
+func newWebhookResponse(webhook *entity.Webhook) webhookResponse {
+	return webhookResponse{
+		ID:        webhook.ID,
+		Url:       webhook.Url,
+		Events:    webhook.Events,
+		Active:    webhook.Active,
+		CreatedAt: webhook.CreatedAt,
+		UpdatedAt: webhook.UpdatedAt,
+	}
+}
+
+type deliveryResponse struct {
+	ID            uint64          `json:"id" example:"1"`
+	Event         string          `json:"event" example:"request.completed"`
+	Status        string          `json:"status" example:"SUCCEEDED"`
+	Attempts      int             `json:"attempts" example:"1"`
+	ResponseCode  *int            `json:"response_code" example:"200"`
+	Error         string          `json:"error,omitempty" example:"unexpected response status 500"`
+	Payload       json.RawMessage `json:"payload"`
+	NextAttemptAt *time.Time      `json:"next_attempt_at" example:"1970-01-01T00:00:00Z"`
+	LastAttemptAt *time.Time      `json:"last_attempt_at" example:"1970-01-01T00:00:00Z"`
+	CreatedAt     time.Time       `json:"created_at" example:"1970-01-01T00:00:00Z"`
+}
+
+func newDeliveryResponse(delivery *entity.WebhookDelivery) deliveryResponse {
+	rsp := deliveryResponse{
+		ID:            delivery.ID,
+		Event:         delivery.Event,
+		Status:        string(delivery.Status),
+		Attempts:      delivery.Attempts,
+		Error:         delivery.Error,
+		Payload:       delivery.Payload,
+		LastAttemptAt: optionalTime(delivery.LastAttemptAt),
+		CreatedAt:     delivery.CreatedAt,
+	}
+
+	if delivery.ResponseCode != 0 {
+		rsp.ResponseCode = &delivery.ResponseCode
+	}
+
+	// Only the pending deliveries have a next attempt
+	if delivery.Status == entity.DeliveryPending {
+		rsp.NextAttemptAt = optionalTime(delivery.NextAttemptAt)
+	}
+
+	return rsp
+}
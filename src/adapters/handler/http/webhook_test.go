@@ -0,0 +1,222 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	controller "example/web-service-gin/src/adapters/handler/http"
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/infra/middleware"
+	"example/web-service-gin/src/utils/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockWebhookService) Create(ctx context.Context, userId string, url string, events []string) (*entity.Webhook, error) {
+	args := m.Called(ctx, userId, url, events)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) List(ctx context.Context, userId string) ([]entity.Webhook, error) {
+	args := m.Called(ctx, userId)
+	return args.Get(0).([]entity.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) Get(ctx context.Context, id uint64, userId string) (*entity.Webhook, error) {
+	args := m.Called(ctx, id, userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) Update(ctx context.Context, id uint64, userId string, changes entity.WebhookChanges) (*entity.Webhook, error) {
+	args := m.Called(ctx, id, userId, changes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) Delete(ctx context.Context, id uint64, userId string) error {
+	args := m.Called(ctx, id, userId)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) ListDeliveries(ctx context.Context, id uint64, userId string) ([]entity.WebhookDelivery, error) {
+	args := m.Called(ctx, id, userId)
+	return args.Get(0).([]entity.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookService) Redeliver(ctx context.Context, id uint64, deliveryId uint64, userId string) (*entity.WebhookDelivery, error) {
+	args := m.Called(ctx, id, deliveryId, userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.WebhookDelivery), args.Error(1)
+}
+
+func setUpWebhooks() (*gin.Engine, *MockWebhookService) {
+	mockJwtService := new(mocks.MockJwtService)
+	mockService := new(MockWebhookService)
+	handler := controller.NewWebhookHandler(mockService)
+
+	mockJwtService.On("GetUser", "valid-token").Return(&entity.User{Id: "123456", Email: "user@example.com"}, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.Authenticate(mockJwtService, nil))
+	router.POST("/me/webhooks", handler.Create)
+	router.GET("/me/webhooks", handler.List)
+	router.PATCH("/me/webhooks/:id", handler.Update)
+	router.DELETE("/me/webhooks/:id", handler.Delete)
+	router.GET("/me/webhooks/:id/deliveries", handler.ListDeliveries)
+	router.POST("/me/webhooks/:id/deliveries/:deliveryId/redeliver", handler.Redeliver)
+
+	return router, mockService
+}
+
+func TestWebhookHandler_Create(t *testing.T) {
+	router, service := setUpWebhooks()
+	service.On("Create", mock.Anything, "123456", "https://example.com/hook", []string{entity.EventRequestCompleted}).
+		Return(&entity.Webhook{ID: 1, Url: "https://example.com/hook", Secret: "whsec_secret", Events: []string{entity.EventRequestCompleted}, Active: true}, nil)
+
+	body := bytes.NewBufferString(`{"url": "https://example.com/hook", "events": ["request.completed"]}`)
+	req, _ := http.NewRequest(http.MethodPost, "/me/webhooks", body)
+	req.Header.Add("Authorization", "valid-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"secret":"whsec_secret"`)
+}
+
+func TestWebhookHandler_CreateMissingEvents(t *testing.T) {
+	router, service := setUpWebhooks()
+
+	body := bytes.NewBufferString(`{"url": "https://example.com/hook"}`)
+	req, _ := http.NewRequest(http.MethodPost, "/me/webhooks", body)
+	req.Header.Add("Authorization", "valid-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	service.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWebhookHandler_ListHidesSecret(t *testing.T) {
+	router, service := setUpWebhooks()
+	service.On("List", mock.Anything, "123456").Return([]entity.Webhook{{ID: 1, Secret: "whsec_secret"}}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/me/webhooks", nil)
+	req.Header.Add("Authorization", "valid-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "whsec_secret")
+}
+
+func TestWebhookHandler_Update(t *testing.T) {
+	router, service := setUpWebhooks()
+	active := false
+	service.On("Update", mock.Anything, uint64(1), "123456", entity.WebhookChanges{Active: &active}).
+		Return(&entity.Webhook{ID: 1, Active: false}, nil)
+
+	body := bytes.NewBufferString(`{"active": false}`)
+	req, _ := http.NewRequest(http.MethodPatch, "/me/webhooks/1", body)
+	req.Header.Add("Authorization", "valid-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"active":false`)
+}
+
+func TestWebhookHandler_DeleteNotFound(t *testing.T) {
+	router, service := setUpWebhooks()
+	service.On("Delete", mock.Anything, uint64(1), "123456").Return(core.ErrDataNotFound)
+
+	req, _ := http.NewRequest(http.MethodDelete, "/me/webhooks/1", nil)
+	req.Header.Add("Authorization", "valid-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestWebhookHandler_ListDeliveries(t *testing.T) {
+	router, service := setUpWebhooks()
+	createdAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	service.On("ListDeliveries", mock.Anything, uint64(1), "123456").Return([]entity.WebhookDelivery{{
+		ID:            5,
+		Event:         entity.EventRequestCompleted,
+		Payload:       []byte(`{"id":"evt_1"}`),
+		Status:        entity.DeliveryFailed,
+		Attempts:      3,
+		ResponseCode:  500,
+		Error:         "unexpected response status 500",
+		NextAttemptAt: createdAt,
+		LastAttemptAt: createdAt,
+		CreatedAt:     createdAt,
+	}}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/me/webhooks/1/deliveries", nil)
+	req.Header.Add("Authorization", "valid-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{
+		"id": 5,
+		"event": "request.completed",
+		"status": "FAILED",
+		"attempts": 3,
+		"response_code": 500,
+		"error": "unexpected response status 500",
+		"payload": {"id": "evt_1"},
+		"next_attempt_at": null,
+		"last_attempt_at": "2024-01-15T10:00:00Z",
+		"created_at": "2024-01-15T10:00:00Z"
+	}]`, w.Body.String())
+}
+
+func TestWebhookHandler_Redeliver(t *testing.T) {
+	router, service := setUpWebhooks()
+	service.On("Redeliver", mock.Anything, uint64(1), uint64(5), "123456").
+		Return(&entity.WebhookDelivery{ID: 6, Status: entity.DeliveryPending, Payload: []byte(`{}`)}, nil)
+
+	req, _ := http.NewRequest(http.MethodPost, "/me/webhooks/1/deliveries/5/redeliver", nil)
+	req.Header.Add("Authorization", "valid-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":6`)
+}
+
+func TestWebhookHandler_RedeliverInvalidDeliveryId(t *testing.T) {
+	router, service := setUpWebhooks()
+
+	req, _ := http.NewRequest(http.MethodPost, "/me/webhooks/1/deliveries/abc/redeliver", nil)
+	req.Header.Add("Authorization", "valid-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	service.AssertNotCalled(t, "Redeliver", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
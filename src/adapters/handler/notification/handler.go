@@ -22,7 +22,11 @@ type SNSHandler struct {
 }
 
 func NewSNSHandler(conf *configuration.Aws, ctx context.Context) *SNSHandler {
-	snsClient := sns.NewFromConfig(conf.Config)
+	snsClient := sns.NewFromConfig(conf.Config, func(options *sns.Options) {
+		if endpoint := conf.SNSEndpoint(); endpoint != "" {
+			options.BaseEndpoint = aws.String(endpoint)
+		}
+	})
 	return &SNSHandler{
 		Client:  snsClient,
 		Configs: conf,
@@ -0,0 +1,36 @@
+package notification
+
+import (
+	"context"
+	"example/web-service-gin/src/core/entity"
+	"sync"
+)
+
+// MemoryEventPublisher implements port.EventPublisher interface keeping the published
+// events in memory, for the tests
+type MemoryEventPublisher struct {
+	mutex  sync.Mutex
+	events []entity.DomainEvent
+}
+
+// NewMemoryEventPublisher creates a new publisher without events
+func NewMemoryEventPublisher() *MemoryEventPublisher {
+	return &MemoryEventPublisher{}
+}
+
+// Publish appends the event to the published ones
+func (publisher *MemoryEventPublisher) Publish(_ context.Context, event entity.DomainEvent) error {
+	publisher.mutex.Lock()
+	defer publisher.mutex.Unlock()
+
+	publisher.events = append(publisher.events, event)
+	return nil
+}
+
+// Events returns the published events, the oldest first
+func (publisher *MemoryEventPublisher) Events() []entity.DomainEvent {
+	publisher.mutex.Lock()
+	defer publisher.mutex.Unlock()
+
+	return append([]entity.DomainEvent{}, publisher.events...)
+}
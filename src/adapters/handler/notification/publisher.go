@@ -0,0 +1,82 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"example/web-service-gin/src/core/entity"
+	"time"
+)
+
+// SNSEventPublisher implements port.EventPublisher interface and publishes the domain events
+// to an SNS topic. The event_type, user_id, organization_id and status attributes allow the
+// subscribers to filter the events with SNS filter policies
+type SNSEventPublisher struct {
+	handler  *SNSHandler
+	topicArn string
+}
+
+// NewSNSEventPublisher creates a new publisher of the domain events to the topic
+func NewSNSEventPublisher(handler *SNSHandler, topicArn string) *SNSEventPublisher {
+	return &SNSEventPublisher{handler, topicArn}
+}
+
+type domainEventMessage struct {
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	Request    domainEventRequestData `json:"request"`
+}
+
+type domainEventRequestData struct {
+	ID             uint64               `json:"id"`
+	UserId         string               `json:"user_id"`
+	OrganizationId string               `json:"organization_id,omitempty"`
+	Status         entity.RequestStatus `json:"status"`
+	VideoSize      int64                `json:"video_size"`
+	ContentHash    string               `json:"content_hash,omitempty"`
+	DuplicateOf    uint64               `json:"duplicate_of,omitempty"`
+	ZipOutputUrl   string               `json:"zip_output_key,omitempty"`
+	Attempts       int                  `json:"attempts"`
+	FailureReason  string               `json:"failure_reason,omitempty"`
+	CreatedAt      time.Time            `json:"created_at"`
+}
+
+// Publish sends the event as a JSON message with its filter attributes
+func (publisher *SNSEventPublisher) Publish(_ context.Context, event entity.DomainEvent) error {
+	request := event.Request
+
+	message, err := json.Marshal(domainEventMessage{
+		ID:         event.ID,
+		Type:       event.Type,
+		OccurredAt: event.OccurredAt,
+		Request: domainEventRequestData{
+			ID:             request.ID,
+			UserId:         request.UserId,
+			OrganizationId: request.OrganizationId,
+			Status:         request.Status,
+			VideoSize:      request.VideoSize,
+			ContentHash:    request.ContentHash,
+			DuplicateOf:    request.DuplicateOf,
+			ZipOutputUrl:   request.ZipOutputUrl,
+			Attempts:       request.Attempts,
+			FailureReason:  request.FailureReason,
+			CreatedAt:      request.CreatedAt,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	attributes := map[string]string{
+		"event_type": event.Type,
+		"user_id":    request.UserId,
+		"status":     string(request.Status),
+	}
+
+	// SNS rejects the attributes with empty values
+	if request.OrganizationId != "" {
+		attributes["organization_id"] = request.OrganizationId
+	}
+
+	return publisher.handler.PublishMessage(publisher.topicArn, string(message), attributes)
+}
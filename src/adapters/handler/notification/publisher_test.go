@@ -0,0 +1,92 @@
+package notification_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"example/web-service-gin/src/adapters/handler/notification"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/infra/configuration"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const eventsTopicArn = "arn:aws:sns:us-east-1:123456789012:request-events"
+
+func setUpEventPublisher() (*MockSNSClient, *notification.SNSEventPublisher) {
+	mockSNSClient := new(MockSNSClient)
+	handler := &notification.SNSHandler{
+		Client:  mockSNSClient,
+		Configs: &configuration.Aws{},
+		Ctx:     context.Background(),
+	}
+	return mockSNSClient, notification.NewSNSEventPublisher(handler, eventsTopicArn)
+}
+
+func TestSNSEventPublisher_Publish(t *testing.T) {
+	client, publisher := setUpEventPublisher()
+	event := entity.DomainEvent{
+		ID:         "evt_1",
+		Type:       entity.EventRequestCompleted,
+		OccurredAt: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+		Request:    entity.Request{ID: 7, UserId: "123456", OrganizationId: "org-1", Status: entity.Completed, Attempts: 1},
+	}
+
+	var input *sns.PublishInput
+	client.On("Publish", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		input = args.Get(1).(*sns.PublishInput)
+	}).Return(&sns.PublishOutput{}, nil)
+
+	err := publisher.Publish(context.Background(), event)
+
+	assert.NoError(t, err)
+	assert.Equal(t, eventsTopicArn, *input.TopicArn)
+	assert.Equal(t, entity.EventRequestCompleted, *input.MessageAttributes["event_type"].StringValue)
+	assert.Equal(t, "123456", *input.MessageAttributes["user_id"].StringValue)
+	assert.Equal(t, "org-1", *input.MessageAttributes["organization_id"].StringValue)
+	assert.Equal(t, "COMPLETED", *input.MessageAttributes["status"].StringValue)
+
+	var message map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(*input.Message), &message))
+	assert.Equal(t, "evt_1", message["id"])
+	assert.Equal(t, entity.EventRequestCompleted, message["type"])
+	assert.Equal(t, float64(7), message["request"].(map[string]any)["id"])
+}
+
+func TestSNSEventPublisher_PublishWithoutOrganization(t *testing.T) {
+	client, publisher := setUpEventPublisher()
+
+	var input *sns.PublishInput
+	client.On("Publish", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		input = args.Get(1).(*sns.PublishInput)
+	}).Return(&sns.PublishOutput{}, nil)
+
+	err := publisher.Publish(context.Background(), entity.DomainEvent{Type: entity.EventRequestCreated, Request: entity.Request{UserId: "123456"}})
+
+	assert.NoError(t, err)
+	assert.NotContains(t, input.MessageAttributes, "organization_id")
+}
+
+func TestSNSEventPublisher_PublishError(t *testing.T) {
+	client, publisher := setUpEventPublisher()
+	client.On("Publish", mock.Anything, mock.Anything).Return(&sns.PublishOutput{}, errors.New("throttled"))
+
+	err := publisher.Publish(context.Background(), entity.DomainEvent{Type: entity.EventRequestFailed, Request: entity.Request{UserId: "123456"}})
+
+	assert.ErrorContains(t, err, "throttled")
+}
+
+func TestMemoryEventPublisher_Events(t *testing.T) {
+	publisher := notification.NewMemoryEventPublisher()
+
+	publisher.Publish(context.Background(), entity.DomainEvent{ID: "evt_1"})
+	publisher.Publish(context.Background(), entity.DomainEvent{ID: "evt_2"})
+
+	events := publisher.Events()
+	assert.Len(t, events, 2)
+	assert.Equal(t, "evt_2", events[1].ID)
+}
@@ -3,41 +3,55 @@ package queue
 import (
 	"context"
 	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/port"
 	"log"
 	"log/slog"
+	"path"
+	"strings"
 	"time"
 )
 
-// MessageProcessor it's a function tha process each message
-type MessageProcessor func(ctx context.Context, msg entity.EventMessage)
+// MessageProcessor it's a function tha process each message, returning why it failed
+type MessageProcessor func(ctx context.Context, msg entity.EventMessage) error
 
 // StartQueueConsumer inicia o consumo de uma fila
-func StartQueueConsumer(handler *SQSHandler, queueURL string, processor MessageProcessor, ctx context.Context) {
+func StartQueueConsumer(consumer port.MessageConsumer, queueURL string, processor MessageProcessor, metrics port.Metrics, ctx context.Context) {
 	slog.Info("Starting queue consumer:", "queueUrl", queueURL)
+	queue := queueName(queueURL)
 
-	for {
-		messages, err := handler.ReceiveMessages(queueURL, 5, 5)
+	for ctx.Err() == nil {
+		messages, err := consumer.ReceiveMessages(queueURL, 5, 5)
 		if err != nil {
 			log.Printf("Error receiving messages from %s: %v", queueURL, err)
 			time.Sleep(5 * time.Second) // Retry with backoff
 			continue
 		}
 
+		metrics.ObserveMessagesReceived(queue, len(messages))
+
 		for _, message := range messages {
 
-			toDomain := entity.EventMessage{
-				MessageID: *message.MessageId,
-				Source:    queueURL,
-				Body:      *message.Body,
-				Date:      message.Attributes["SentTimestamp"],
+			start := time.Now()
+			err := processor(ctx, message)
+			metrics.ObserveMessage(queue, time.Since(start), err)
+
+			// The failed message is delivered again after the visibility timeout, or moved to the
+			// dead-letter queue of the SQS queue after its maximum receives
+			if err != nil {
+				slog.Error("Error processing message", "queue", queue, "message", message.MessageID, "error", err)
+				continue
 			}
 
-			processor(ctx, toDomain)
 			// Delete the message from queue after finish the process
-			err := handler.DeleteMessage(queueURL, *message.ReceiptHandle)
+			err = consumer.DeleteMessage(queueURL, message.ReceiptHandle)
 			if err != nil {
 				log.Printf("Error deleting message from %s: %v", queueURL, err)
 			}
 		}
 	}
 }
+
+// queueName returns the name of the queue on the end of its URL, used to label its metrics
+func queueName(queueURL string) string {
+	return path.Base(strings.TrimRight(queueURL, "/"))
+}
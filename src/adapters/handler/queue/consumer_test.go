@@ -0,0 +1,78 @@
+package queue_test
+
+import (
+	"context"
+	"errors"
+	"example/web-service-gin/src/adapters/handler/queue"
+	"example/web-service-gin/src/core/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type queueMetrics struct {
+	received  map[string]int
+	processed map[string]int
+	failed    map[string]int
+}
+
+func newQueueMetrics() *queueMetrics {
+	return &queueMetrics{received: map[string]int{}, processed: map[string]int{}, failed: map[string]int{}}
+}
+
+func (m *queueMetrics) ObserveHTTPRequest(method string, route string, status int, duration time.Duration) {
+}
+
+func (m *queueMetrics) ObserveUpload(bytes int64, duration time.Duration, err error) {}
+
+func (m *queueMetrics) ObserveCall(service string, operation string, duration time.Duration, err error) {
+}
+
+func (m *queueMetrics) ObserveMessagesReceived(queue string, count int) {
+	m.received[queue] += count
+}
+
+func (m *queueMetrics) ObserveMessage(queue string, duration time.Duration, err error) {
+	if err != nil {
+		m.failed[queue]++
+	} else {
+		m.processed[queue]++
+	}
+}
+
+func (m *queueMetrics) ObserveProcessing(status entity.RequestStatus, duration time.Duration) {}
+
+func TestStartQueueConsumer_Metrics(t *testing.T) {
+	queueURL := "https://sqs.us-east-1.amazonaws.com/123456789012/video-output"
+	memoryQueue := queue.NewMemoryQueue(0)
+	_ = memoryQueue.SendMessage(queueURL, "ok")
+	_ = memoryQueue.SendMessage(queueURL, "invalid")
+	_ = memoryQueue.SendMessage(queueURL, "ok")
+
+	metrics := newQueueMetrics()
+	ctx, cancel := context.WithCancel(context.Background())
+	handled := 0
+
+	// When
+	queue.StartQueueConsumer(memoryQueue, queueURL, func(ctx context.Context, msg entity.EventMessage) error {
+		handled++
+		if handled == 3 {
+			cancel()
+		}
+		if msg.Body == "invalid" {
+			return errors.New("invalid message")
+		}
+		return nil
+	}, metrics, ctx)
+
+	// Then
+	assert.Equal(t, map[string]int{"video-output": 3}, metrics.received)
+	assert.Equal(t, map[string]int{"video-output": 2}, metrics.processed)
+	assert.Equal(t, map[string]int{"video-output": 1}, metrics.failed)
+
+	// Only the failed message is kept, to be delivered again
+	remaining, _ := memoryQueue.ReceiveMessages(queueURL, 5, 0)
+	assert.Len(t, remaining, 1)
+	assert.Equal(t, "invalid", remaining[0].Body)
+}
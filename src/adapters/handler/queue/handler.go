@@ -2,13 +2,14 @@ package queue
 
 import (
 	"context"
+	"example/web-service-gin/src/core/entity"
 	"example/web-service-gin/src/infra/configuration"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 )
 
-// SQSHandler structure
+// SQSHandler implements port.MessageConsumer and port.MessageProducer using AWS SQS
 type SQSHandler struct {
 	Configs *configuration.Aws
 	Client  *sqs.Client
@@ -16,21 +17,40 @@ type SQSHandler struct {
 
 // NewSQSHandler creates a new instance of SQSHandler
 func NewSQSHandler(configs *configuration.Aws) *SQSHandler {
-	sqsClient := sqs.NewFromConfig(configs.Config)
+	sqsClient := sqs.NewFromConfig(configs.Config, func(options *sqs.Options) {
+		if endpoint := configs.SQSEndpoint(); endpoint != "" {
+			options.BaseEndpoint = aws.String(endpoint)
+		}
+	})
 	return &SQSHandler{Configs: configs, Client: sqsClient}
 }
 
 // ReceiveMessages reads the messages from queue
-func (h *SQSHandler) ReceiveMessages(queueURL string, maxMessages int32, waitTime int32) ([]types.Message, error) {
+func (h *SQSHandler) ReceiveMessages(queueURL string, maxMessages int32, waitTime int32) ([]entity.EventMessage, error) {
 	output, err := h.Client.ReceiveMessage(context.TODO(), &sqs.ReceiveMessageInput{
 		QueueUrl:            aws.String(queueURL),
 		MaxNumberOfMessages: maxMessages,
 		WaitTimeSeconds:     waitTime,
+		MessageSystemAttributeNames: []types.MessageSystemAttributeName{
+			types.MessageSystemAttributeNameSentTimestamp,
+		},
 	})
 	if err != nil {
 		return nil, err
 	}
-	return output.Messages, nil
+
+	messages := make([]entity.EventMessage, 0, len(output.Messages))
+	for _, message := range output.Messages {
+		messages = append(messages, entity.EventMessage{
+			MessageID:     aws.ToString(message.MessageId),
+			ReceiptHandle: aws.ToString(message.ReceiptHandle),
+			Source:        queueURL,
+			Body:          aws.ToString(message.Body),
+			Date:          message.Attributes[string(types.MessageSystemAttributeNameSentTimestamp)],
+		})
+	}
+
+	return messages, nil
 }
 
 // SendMessage sends a message to a specif queue
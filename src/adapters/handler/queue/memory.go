@@ -0,0 +1,115 @@
+package queue
+
+import (
+	"errors"
+	"example/web-service-gin/src/core/entity"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// pollInterval is how often the local adapters look for new messages while waiting
+const pollInterval = 200 * time.Millisecond
+
+type memoryMessage struct {
+	id            uint64
+	receiptHandle string
+	body          string
+	sentAt        time.Time
+	visibleAt     time.Time
+}
+
+// MemoryQueue implements port.MessageConsumer and port.MessageProducer in memory,
+// it is meant for tests and local development with a single process
+type MemoryQueue struct {
+	mutex      sync.Mutex
+	queues     map[string][]*memoryMessage
+	sequence   uint64
+	visibility time.Duration
+}
+
+// NewMemoryQueue creates a new in-memory queue, received messages not deleted
+// before the visibility timeout are delivered again
+func NewMemoryQueue(visibility time.Duration) *MemoryQueue {
+	return &MemoryQueue{
+		queues:     make(map[string][]*memoryMessage),
+		visibility: visibility,
+	}
+}
+
+// SendMessage appends a message to the queue
+func (q *MemoryQueue) SendMessage(queueURL, messageBody string) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.sequence++
+	now := time.Now()
+	q.queues[queueURL] = append(q.queues[queueURL], &memoryMessage{
+		id:        q.sequence,
+		body:      messageBody,
+		sentAt:    now,
+		visibleAt: now,
+	})
+
+	return nil
+}
+
+// ReceiveMessages reads the visible messages from queue, waiting for them up to waitTime seconds
+func (q *MemoryQueue) ReceiveMessages(queueURL string, maxMessages int32, waitTime int32) ([]entity.EventMessage, error) {
+	deadline := time.Now().Add(time.Duration(waitTime) * time.Second)
+
+	for {
+		messages := q.receive(queueURL, maxMessages)
+		if len(messages) > 0 || !time.Now().Before(deadline) {
+			return messages, nil
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// DeleteMessage deletes a message from the queue
+func (q *MemoryQueue) DeleteMessage(queueURL, receiptHandle string) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	messages := q.queues[queueURL]
+	for i, message := range messages {
+		if message.receiptHandle == receiptHandle {
+			q.queues[queueURL] = append(messages[:i], messages[i+1:]...)
+			return nil
+		}
+	}
+
+	return errors.New("message not found for receipt handle")
+}
+
+func (q *MemoryQueue) receive(queueURL string, maxMessages int32) []entity.EventMessage {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	now := time.Now()
+	var messages []entity.EventMessage
+
+	for _, message := range q.queues[queueURL] {
+		if int32(len(messages)) >= maxMessages {
+			break
+		}
+		if message.visibleAt.After(now) {
+			continue
+		}
+
+		q.sequence++
+		message.visibleAt = now.Add(q.visibility)
+		message.receiptHandle = strconv.FormatUint(message.id, 10) + ":" + strconv.FormatUint(q.sequence, 10)
+
+		messages = append(messages, entity.EventMessage{
+			MessageID:     strconv.FormatUint(message.id, 10),
+			ReceiptHandle: message.receiptHandle,
+			Source:        queueURL,
+			Body:          message.body,
+			Date:          strconv.FormatInt(message.sentAt.UnixMilli(), 10),
+		})
+	}
+
+	return messages
+}
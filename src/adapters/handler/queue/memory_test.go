@@ -0,0 +1,72 @@
+package queue_test
+
+import (
+	"example/web-service-gin/src/adapters/handler/queue"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryQueue_SendAndReceive(t *testing.T) {
+	memoryQueue := queue.NewMemoryQueue(time.Minute)
+
+	err := memoryQueue.SendMessage("video-input", `{"id": 1}`)
+	assert.NoError(t, err)
+
+	messages, err := memoryQueue.ReceiveMessages("video-input", 5, 0)
+
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, `{"id": 1}`, messages[0].Body)
+	assert.Equal(t, "video-input", messages[0].Source)
+	assert.NotEmpty(t, messages[0].ReceiptHandle)
+}
+
+func TestMemoryQueue_ReceivedMessageIsHidden(t *testing.T) {
+	memoryQueue := queue.NewMemoryQueue(time.Minute)
+	_ = memoryQueue.SendMessage("video-input", "body")
+
+	first, _ := memoryQueue.ReceiveMessages("video-input", 5, 0)
+	second, _ := memoryQueue.ReceiveMessages("video-input", 5, 0)
+
+	assert.Len(t, first, 1)
+	assert.Empty(t, second)
+}
+
+func TestMemoryQueue_RedeliveryAfterVisibilityTimeout(t *testing.T) {
+	memoryQueue := queue.NewMemoryQueue(0)
+	_ = memoryQueue.SendMessage("video-input", "body")
+
+	first, _ := memoryQueue.ReceiveMessages("video-input", 5, 0)
+	second, _ := memoryQueue.ReceiveMessages("video-input", 5, 0)
+
+	assert.Len(t, second, 1)
+	assert.Equal(t, first[0].MessageID, second[0].MessageID)
+	assert.NotEqual(t, first[0].ReceiptHandle, second[0].ReceiptHandle)
+
+	// The first delivery is no longer valid
+	assert.Error(t, memoryQueue.DeleteMessage("video-input", first[0].ReceiptHandle))
+	assert.NoError(t, memoryQueue.DeleteMessage("video-input", second[0].ReceiptHandle))
+}
+
+func TestMemoryQueue_DeleteMessage(t *testing.T) {
+	memoryQueue := queue.NewMemoryQueue(0)
+	_ = memoryQueue.SendMessage("video-input", "body")
+
+	messages, _ := memoryQueue.ReceiveMessages("video-input", 5, 0)
+	err := memoryQueue.DeleteMessage("video-input", messages[0].ReceiptHandle)
+	remaining, _ := memoryQueue.ReceiveMessages("video-input", 5, 0)
+
+	assert.NoError(t, err)
+	assert.Empty(t, remaining)
+}
+
+func TestMemoryQueue_QueuesAreIsolated(t *testing.T) {
+	memoryQueue := queue.NewMemoryQueue(time.Minute)
+	_ = memoryQueue.SendMessage("video-input", "body")
+
+	messages, _ := memoryQueue.ReceiveMessages("video-output", 5, 0)
+
+	assert.Empty(t, messages)
+}
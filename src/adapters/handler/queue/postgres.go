@@ -0,0 +1,148 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"example/web-service-gin/src/adapters/storage/postgres"
+	"example/web-service-gin/src/core/entity"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// receiveQuery locks the next visible messages, skipping the ones already locked
+// by other consumers, and hides them for the visibility timeout
+const receiveQuery = `
+UPDATE queue_messages
+SET visible_at = now() + make_interval(secs => $1), receive_count = receive_count + 1
+WHERE id IN (
+    SELECT id FROM queue_messages
+    WHERE queue = $2 AND visible_at <= now()
+    ORDER BY id
+    LIMIT $3
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, body, receive_count, created_at`
+
+// PGQueue implements port.MessageConsumer and port.MessageProducer on top of
+// the application database, so the pipeline can run with no SQS queues
+type PGQueue struct {
+	db         *postgres.DB
+	visibility time.Duration
+}
+
+// NewPGQueue creates a new postgres queue, received messages not deleted
+// before the visibility timeout are delivered again
+func NewPGQueue(db *postgres.DB, visibility time.Duration) *PGQueue {
+	return &PGQueue{db: db, visibility: visibility}
+}
+
+// SendMessage inserts a message in the queue table
+func (q *PGQueue) SendMessage(queueURL, messageBody string) error {
+	query := q.db.QueryBuilder.Insert("queue_messages").
+		Columns("queue", "body").
+		Values(queueURL, messageBody)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = q.db.Exec(context.TODO(), sql, args...)
+	return err
+}
+
+// ReceiveMessages reads the visible messages from queue, waiting for them up to waitTime seconds
+func (q *PGQueue) ReceiveMessages(queueURL string, maxMessages int32, waitTime int32) ([]entity.EventMessage, error) {
+	deadline := time.Now().Add(time.Duration(waitTime) * time.Second)
+
+	for {
+		messages, err := q.receive(queueURL, maxMessages)
+		if err != nil || len(messages) > 0 || !time.Now().Before(deadline) {
+			return messages, err
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// DeleteMessage deletes a message from the queue, the receipt handle identifies
+// the delivery so a consumer whose visibility expired can't delete a redelivered message
+func (q *PGQueue) DeleteMessage(queueURL, receiptHandle string) error {
+	id, receiveCount, err := parseReceiptHandle(receiptHandle)
+	if err != nil {
+		return err
+	}
+
+	query := q.db.QueryBuilder.Delete("queue_messages").
+		Where(sq.Eq{"id": id, "queue": queueURL, "receive_count": receiveCount})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := q.db.Exec(context.TODO(), sql, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return errors.New("message not found for receipt handle")
+	}
+
+	return nil
+}
+
+func (q *PGQueue) receive(queueURL string, maxMessages int32) ([]entity.EventMessage, error) {
+	rows, err := q.db.Query(context.TODO(), receiveQuery, q.visibility.Seconds(), queueURL, maxMessages)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var messages []entity.EventMessage
+	for rows.Next() {
+		var id uint64
+		var body string
+		var receiveCount int
+		var createdAt time.Time
+
+		if err := rows.Scan(&id, &body, &receiveCount, &createdAt); err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, entity.EventMessage{
+			MessageID:     strconv.FormatUint(id, 10),
+			ReceiptHandle: fmt.Sprintf("%d:%d", id, receiveCount),
+			Source:        queueURL,
+			Body:          body,
+			Date:          strconv.FormatInt(createdAt.UnixMilli(), 10),
+		})
+	}
+
+	return messages, rows.Err()
+}
+
+// parseReceiptHandle splits the "<id>:<receive count>" receipt handle
+func parseReceiptHandle(receiptHandle string) (uint64, int, error) {
+	parts := strings.Split(receiptHandle, ":")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid receipt handle: %s", receiptHandle)
+	}
+
+	id, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid receipt handle: %s", receiptHandle)
+	}
+
+	receiveCount, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid receipt handle: %s", receiptHandle)
+	}
+
+	return id, receiveCount, nil
+}
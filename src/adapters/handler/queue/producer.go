@@ -3,40 +3,50 @@ package queue
 import (
 	"encoding/json"
 	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/port"
 	"log/slog"
 )
 
-type SQSProducer struct {
-	SQSHandler *SQSHandler
-	QueueUrl   string
+// RequestProducer implements port.QueuePort over any port.MessageProducer
+type RequestProducer struct {
+	Producer port.MessageProducer
+	QueueUrl string
 }
 
-func NewSQSProducer(handler *SQSHandler, url string) *SQSProducer {
-	return &SQSProducer{SQSHandler: handler, QueueUrl: url}
+func NewRequestProducer(producer port.MessageProducer, url string) *RequestProducer {
+	return &RequestProducer{Producer: producer, QueueUrl: url}
 }
 
-// SQSHandler structure
-func (h *SQSProducer) SendVideoProccessToQueue(request *entity.Request) error {
+// SendVideoProccessToQueue sends the request to the video processing queue
+func (h *RequestProducer) SendVideoProccessToQueue(request *entity.Request) error {
 
 	// Map domain to reciver contract
 	bodyData := SnapVideoRequest{
-		Id:           request.ID,
-		IdUser:       request.UserId,
-		FileSize:     request.VideoSize,
-		S3FileKey:    request.VideoKey,
-		CreationDate: request.CreatedAt,
+		Id:              request.ID,
+		IdUser:          request.UserId,
+		FileSize:        request.VideoSize,
+		S3FileKey:       request.VideoKey,
+		CreationDate:    request.CreatedAt,
+		FrameIntervalMs: request.Options.FrameInterval.Milliseconds(),
+		VideoFormat:     request.VideoMetadata.Format,
+		DurationMs:      request.VideoMetadata.Duration.Milliseconds(),
+		Width:           request.VideoMetadata.Width,
+		Height:          request.VideoMetadata.Height,
+		Codec:           request.VideoMetadata.Codec,
 	}
 
 	jsonData, parseError := json.Marshal(bodyData)
 
 	if parseError != nil {
 		slog.Error("Error trying to conver entity to JSON", "error", parseError)
+		return parseError
 	}
 
-	err := h.SQSHandler.SendMessage(h.QueueUrl, string(jsonData))
+	err := h.Producer.SendMessage(h.QueueUrl, string(jsonData))
 
 	if err != nil {
 		slog.Error("Error trying to send message", "destination", h.QueueUrl)
+		return err
 	}
 
 	return nil
@@ -3,18 +3,36 @@ package queue
 import "time"
 
 type SnapVideoRequest struct {
-	Id           uint64    `json:"id" example:"1"`
-	IdUser       string    `json:"id_user" example:"1231231231"`
-	FileSize     int64     `json:" file_size" example:"1048576"`
-	S3FileKey    string    `json:"s3_file_key" example:"https://google.com"`
-	CreationDate time.Time `json:"creation_date" example:"1970-01-01T00:00:00Z"`
+	Id              uint64    `json:"id" example:"1"`
+	IdUser          string    `json:"id_user" example:"1231231231"`
+	FileSize        int64     `json:" file_size" example:"1048576"`
+	S3FileKey       string    `json:"s3_file_key" example:"https://google.com"`
+	CreationDate    time.Time `json:"creation_date" example:"1970-01-01T00:00:00Z"`
+	FrameIntervalMs int64     `json:"frame_interval_ms" example:"1000"`
+	VideoFormat     string    `json:"video_format,omitempty" example:"mp4"`
+	DurationMs      int64     `json:"duration_ms,omitempty" example:"90000"`
+	Width           int       `json:"width,omitempty" example:"1920"`
+	Height          int       `json:"height,omitempty" example:"1080"`
+	Codec           string    `json:"codec,omitempty" example:"h264"`
 }
 
+// Types of the messages of the video output queue, the messages without type are results
+const (
+	MessageTypeResult   = "result"
+	MessageTypeProgress = "progress"
+)
+
+// SnapVideoResponse is a message of the video output queue, the progress messages
+// only inform the Percent, FramesExtracted and EtaSeconds of the processing
 type SnapVideoResponse struct {
-	Id           uint64    `json:"id" example:"1"`
-	IdUser       string    `json:"id_user" example:"1231231231"`
-	Status       string    `json:"status" example:"SUCCESS"`
-	S3ZipFileKey string    `json:"s3_zip_file_key" example:"https://google.com"`
-	CreationDate time.Time `json:"creation_date" example:"2025-01-23T20:38:08.792075"`
-	FinishedDate time.Time `json:"finished_date" example:"2025-01-23T20:38:08.792075"`
+	Id              uint64    `json:"id" example:"1"`
+	IdUser          string    `json:"id_user" example:"1231231231"`
+	Type            string    `json:"type,omitempty" example:"result"`
+	Status          string    `json:"status" example:"SUCCESS"`
+	S3ZipFileKey    string    `json:"s3_zip_file_key" example:"https://google.com"`
+	CreationDate    time.Time `json:"creation_date" example:"2025-01-23T20:38:08.792075"`
+	FinishedDate    time.Time `json:"finished_date" example:"2025-01-23T20:38:08.792075"`
+	Percent         float64   `json:"percent,omitempty" example:"42.5"`
+	FramesExtracted int       `json:"frames_extracted,omitempty" example:"120"`
+	EtaSeconds      int64     `json:"eta_seconds,omitempty" example:"95"`
 }
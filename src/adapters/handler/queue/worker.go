@@ -0,0 +1,63 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"example/web-service-gin/src/adapters/metrics"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/port"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// StartSimulatedWorker emulates the video processing worker for local development,
+// answering each message of the input queue with a progress and a successful output message.
+// Its messages are not measured, the input queue belongs to the real worker
+func StartSimulatedWorker(consumer port.MessageConsumer, producer port.MessageProducer, inputQueueURL, outputQueueURL string, ctx context.Context) {
+	slog.Warn("Starting simulated video worker, no frames will be extracted", "queueUrl", inputQueueURL)
+
+	StartQueueConsumer(consumer, inputQueueURL, func(ctx context.Context, msg entity.EventMessage) error {
+		var request SnapVideoRequest
+
+		err := json.Unmarshal([]byte(msg.Body), &request)
+		if err != nil {
+			return fmt.Errorf("simulated worker received an invalid message: %w", err)
+		}
+
+		// The real worker reports the progress while extracting the frames, before the result
+		sendSimulatedResponse(producer, outputQueueURL, SnapVideoResponse{
+			Id:              request.Id,
+			IdUser:          request.IdUser,
+			Type:            MessageTypeProgress,
+			CreationDate:    request.CreationDate,
+			Percent:         50,
+			FramesExtracted: int(request.DurationMs / max(request.FrameIntervalMs, 1) / 2),
+			EtaSeconds:      1,
+		})
+
+		sendSimulatedResponse(producer, outputQueueURL, SnapVideoResponse{
+			Id:           request.Id,
+			IdUser:       request.IdUser,
+			Type:         MessageTypeResult,
+			Status:       "OK",
+			S3ZipFileKey: fmt.Sprintf("zip_output/%d.zip", request.Id),
+			CreationDate: request.CreationDate,
+			FinishedDate: time.Now(),
+		})
+		return nil
+	}, metrics.Nop{}, ctx)
+}
+
+func sendSimulatedResponse(producer port.MessageProducer, outputQueueURL string, response SnapVideoResponse) {
+	jsonData, err := json.Marshal(response)
+	if err != nil {
+		slog.Error("Simulated worker failed to build the response", "error", err)
+		return
+	}
+
+	err = producer.SendMessage(outputQueueURL, string(jsonData))
+	if err != nil {
+		slog.Error("Simulated worker failed to send the response", "error", err)
+	}
+}
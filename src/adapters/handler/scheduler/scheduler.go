@@ -0,0 +1,28 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Job it's a function executed on each scheduler tick
+type Job func(ctx context.Context)
+
+// StartScheduler runs the job periodically until the context is done
+func StartScheduler(name string, interval time.Duration, job Job, ctx context.Context) {
+	slog.Info("Starting scheduler:", "job", name, "interval", interval.String())
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("Stopping scheduler:", "job", name)
+			return
+		case <-ticker.C:
+			job(ctx)
+		}
+	}
+}
@@ -1,52 +1,82 @@
 package mail
 
 import (
+	"context"
+	"errors"
 	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/port"
 	"example/web-service-gin/src/infra/configuration"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/sendgrid/rest"
 	"github.com/sendgrid/sendgrid-go"
 	mailer "github.com/sendgrid/sendgrid-go/helpers/mail"
 )
 
+// maxResponseBodySize is how much of the response body is kept on the notification log
+const maxResponseBodySize = 512
+
+// MailService implements port.NotificationChannel interface and sends
+// the rendered emails through SendGrid
 type MailService struct {
-	Config *configuration.Mail
+	Config  *configuration.Mail
+	metrics port.Metrics
 }
 
-func NewMailService(conf *configuration.Mail) *MailService {
-	return &MailService{Config: conf}
+func NewMailService(conf *configuration.Mail, metrics port.Metrics) *MailService {
+	return &MailService{Config: conf, metrics: metrics}
 }
 
-func (service *MailService) NotifyRequestStatus(data *entity.Request, status string) error {
-	idString := strconv.FormatUint(data.ID, 10)
+func (service *MailService) Send(ctx context.Context, recipient string, notification entity.Notification) (string, error) {
+	start := time.Now()
+	description, err := service.send(ctx, recipient, notification)
+	service.metrics.ObserveCall("sendgrid", "send", time.Since(start), err)
 
-	m := mailer.NewV3Mail()
-	from := mailer.NewEmail("Frameshot Notification", "no_reply@frameshot.com.br")
-	m.SetFrom(from)
-	m.SetTemplateID(service.Config.TemplateId)
+	return description, err
+}
 
-	p := mailer.NewPersonalization()
-	tos := []*mailer.Email{
-		mailer.NewEmail("User", data.UserEmail),
-	}
-	p.AddTos(tos...)
-	p.SetDynamicTemplateData("request_id", idString)
-	p.SetDynamicTemplateData("status_text", status)
-	p.SetDynamicTemplateData("download_link", data.ZipOutputKey)
-	m.AddPersonalizations(p)
+// send posts the email to the SendGrid API, returning the description of its response
+func (service *MailService) send(ctx context.Context, recipient string, notification entity.Notification) (string, error) {
+	from := mailer.NewEmail(service.Config.FromName, service.Config.From)
+	to := mailer.NewEmail("", recipient)
+	m := mailer.NewSingleEmail(from, notification.Subject, to, notification.Text, notification.Html)
 
 	request := sendgrid.GetRequest(service.Config.Key, "/v3/mail/send", "https://api.sendgrid.com")
 	request.Method = "POST"
 	request.Body = mailer.GetRequestBody(m)
-	response, _ := sendgrid.API(request)
+	response, err := sendgrid.MakeRequestWithContext(ctx, request)
+
+	if err != nil {
+		return "", fmt.Errorf("error sending email: %w", err)
+	}
+
+	if response == nil {
+		return "", errors.New("error sending email: empty response")
+	}
+
+	description := describeResponse(response)
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return description, fmt.Errorf("error sending email: unexpected response status %d", response.StatusCode)
+	}
+
+	return description, nil
+}
+
+// describeResponse returns the status of the SendGrid response with the id of the accepted
+// message, or the beginning of the body explaining why it was rejected
+func describeResponse(response *rest.Response) string {
+	description := strconv.Itoa(response.StatusCode)
+
+	if messageId := response.Headers["X-Message-Id"]; len(messageId) > 0 {
+		description += " X-Message-Id: " + messageId[0]
+	}
 
-	if response.StatusCode >= 200 && response.StatusCode <= 299 {
-		fmt.Println("E-mail enviado com sucesso!")
-		fmt.Println(response.StatusCode)
-		fmt.Println(response.Body)
-		return nil
+	if body := strings.TrimSpace(response.Body); body != "" {
+		description += " " + body[:min(len(body), maxResponseBodySize)]
 	}
 
-	return fmt.Errorf("error sending email: %s", response.Body)
+	return description
 }
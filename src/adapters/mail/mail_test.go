@@ -1,7 +1,9 @@
 package mail_test
 
 import (
+	"context"
 	"example/web-service-gin/src/adapters/mail"
+	"example/web-service-gin/src/adapters/metrics"
 	"example/web-service-gin/src/core/entity"
 	"example/web-service-gin/src/infra/configuration"
 	"github.com/h2non/gock"
@@ -11,11 +13,12 @@ import (
 
 func setUp() *mail.MailService {
 	configs := &configuration.Mail{
-		Key:        "mock-api-key",
-		TemplateId: "mock-template-id",
+		Key:      "mock-api-key",
+		From:     "no_reply@frameshot.com.br",
+		FromName: "Frameshot Notification",
 	}
 
-	return mail.NewMailService(configs)
+	return mail.NewMailService(configs, metrics.Nop{})
 }
 
 func TestNotifyRequestStatus_Success(t *testing.T) {
@@ -28,12 +31,19 @@ func TestNotifyRequestStatus_Success(t *testing.T) {
 
 	gock.New("https://api.sendgrid.com").
 		Post("/v3/mail/send").
+		BodyString("Your request #22 is ready").
 		Reply(202).
-		JSON(map[string]interface{}{})
+		SetHeader("X-Message-Id", "abc123")
 
-	err := mockSendGrid.NotifyRequestStatus(request, "sucesso")
+	response, err := mockSendGrid.Send(context.Background(), request.UserEmail, entity.Notification{
+		Subject: "Your request #22 is ready",
+		Text:    "The frames of your request #22 were extracted",
+		Html:    "<p>The frames of your request #22 were extracted</p>",
+		Request: request,
+	})
 
 	assert.NoError(t, err)
+	assert.Equal(t, "202 X-Message-Id: abc123", response)
 }
 
 func TestNotifyRequestStatus_Error(t *testing.T) {
@@ -46,10 +56,11 @@ func TestNotifyRequestStatus_Error(t *testing.T) {
 
 	gock.New("https://api.sendgrid.com").
 		Post("/v3/mail/send").
-		Reply(500).
-		JSON(map[string]interface{}{})
+		Reply(400).
+		BodyString(`{"errors":[{"message":"Does not contain a valid address.","field":"personalizations.0.to.0.email"}]}`)
 
-	err := mockSendGrid.NotifyRequestStatus(request, "error")
+	response, err := mockSendGrid.Send(context.Background(), request.UserEmail, entity.Notification{Subject: "Your request #22 failed", Request: request})
 
-	assert.Error(t, err)
+	assert.EqualError(t, err, "error sending email: unexpected response status 400")
+	assert.Contains(t, response, "400 {\"errors\"")
 }
@@ -0,0 +1,167 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/port"
+	"example/web-service-gin/src/infra/configuration"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"net/textproto"
+	"time"
+)
+
+// SMTPService implements port.NotificationChannel interface and sends the rendered emails
+// through an SMTP server. The connection is upgraded with STARTTLS when the server
+// supports it, and the client only authenticates when a username is configured
+type SMTPService struct {
+	Config  *configuration.Mail
+	metrics port.Metrics
+}
+
+func NewSMTPService(conf *configuration.Mail, metrics port.Metrics) *SMTPService {
+	return &SMTPService{Config: conf, metrics: metrics}
+}
+
+func (service *SMTPService) Send(ctx context.Context, recipient string, notification entity.Notification) (string, error) {
+	to, err := netmail.ParseAddress(recipient)
+	if err != nil {
+		return "", fmt.Errorf("invalid recipient address: %w", err)
+	}
+
+	message, err := service.buildMessage(to, notification)
+	if err != nil {
+		return "", err
+	}
+
+	address := net.JoinHostPort(service.Config.SmtpHost, service.Config.SmtpPort)
+	start := time.Now()
+	err = service.send(ctx, address, to, message)
+	service.metrics.ObserveCall("smtp", "send", time.Since(start), err)
+
+	if err != nil {
+		// The replies rejecting the email are the response of the server
+		var reply *textproto.Error
+		if errors.As(err, &reply) {
+			return fmt.Sprintf("%d %s", reply.Code, reply.Msg), err
+		}
+		return "", err
+	}
+
+	return "250 accepted by " + address, nil
+}
+
+// send delivers the message to the recipient on a new connection to the server
+func (service *SMTPService) send(ctx context.Context, address string, to *netmail.Address, message []byte) error {
+	dialer := net.Dialer{Timeout: service.Config.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("error connecting to the SMTP server: %w", err)
+	}
+
+	if service.Config.Timeout > 0 {
+		_ = conn.SetDeadline(time.Now().Add(service.Config.Timeout))
+	}
+
+	client, err := smtp.NewClient(conn, service.Config.SmtpHost)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("error connecting to the SMTP server: %w", err)
+	}
+
+	defer client.Close()
+
+	if supported, _ := client.Extension("STARTTLS"); supported {
+		if err = client.StartTLS(&tls.Config{ServerName: service.Config.SmtpHost}); err != nil {
+			return fmt.Errorf("error starting TLS: %w", err)
+		}
+	}
+
+	if service.Config.SmtpUsername != "" {
+		auth := smtp.PlainAuth("", service.Config.SmtpUsername, service.Config.SmtpPassword, service.Config.SmtpHost)
+		if err = client.Auth(auth); err != nil {
+			return fmt.Errorf("error authenticating on the SMTP server: %w", err)
+		}
+	}
+
+	if err = client.Mail(service.Config.From); err != nil {
+		return fmt.Errorf("error sending email: %w", err)
+	}
+
+	if err = client.Rcpt(to.Address); err != nil {
+		return fmt.Errorf("error sending email: %w", err)
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("error sending email: %w", err)
+	}
+
+	if _, err = writer.Write(message); err != nil {
+		return fmt.Errorf("error sending email: %w", err)
+	}
+
+	if err = writer.Close(); err != nil {
+		return fmt.Errorf("error sending email: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// buildMessage writes the headers and the text and html alternatives of the email
+func (service *SMTPService) buildMessage(to *netmail.Address, notification entity.Notification) ([]byte, error) {
+	from := netmail.Address{Name: service.Config.FromName, Address: service.Config.From}
+
+	var message bytes.Buffer
+	parts := multipart.NewWriter(&message)
+
+	fmt.Fprintf(&message, "From: %s\r\n", from.String())
+	fmt.Fprintf(&message, "To: %s\r\n", to.String())
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", notification.Subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&message, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", parts.Boundary())
+
+	// The clients show the last alternative they support, so the html goes last
+	if err := writePart(parts, "text/plain", notification.Text); err != nil {
+		return nil, err
+	}
+
+	if notification.Html != "" {
+		if err := writePart(parts, "text/html", notification.Html); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	return message.Bytes(), nil
+}
+
+// writePart adds the content to the message as a quoted-printable part
+func writePart(parts *multipart.Writer, contentType string, content string) error {
+	part, err := parts.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType + "; charset=UTF-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+
+	writer := quotedprintable.NewWriter(part)
+	if _, err = writer.Write([]byte(content)); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
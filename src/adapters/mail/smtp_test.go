@@ -0,0 +1,123 @@
+package mail_test
+
+import (
+	"context"
+	"example/web-service-gin/src/adapters/mail"
+	"example/web-service-gin/src/adapters/metrics"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/infra/configuration"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startSMTPStub accepts one connection and answers the commands of a plain SMTP server,
+// the recipients are answered with rcptReply and the received messages are sent to the channel
+func startSMTPStub(t *testing.T, rcptReply string) (string, <-chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	messages := make(chan string, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		text := textproto.NewConn(conn)
+		defer text.Close()
+
+		_ = text.PrintfLine("220 stub ESMTP")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+
+			switch command := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); command {
+			case "EHLO", "HELO", "MAIL":
+				_ = text.PrintfLine("250 OK")
+			case "RCPT":
+				_ = text.PrintfLine(rcptReply)
+			case "DATA":
+				_ = text.PrintfLine("354 Go ahead")
+				data, _ := text.ReadDotBytes()
+				messages <- string(data)
+				_ = text.PrintfLine("250 Queued")
+			case "QUIT":
+				_ = text.PrintfLine("221 Bye")
+				return
+			default:
+				_ = text.PrintfLine("502 Not implemented")
+			}
+		}
+	}()
+
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	return port, messages
+}
+
+func setUpSMTP(port string) *mail.SMTPService {
+	return mail.NewSMTPService(&configuration.Mail{
+		From:     "no_reply@frameshot.com.br",
+		FromName: "Frameshot Notification",
+		SmtpHost: "127.0.0.1",
+		SmtpPort: port,
+		Timeout:  5 * time.Second,
+	}, metrics.Nop{})
+}
+
+func TestSMTPService_Send(t *testing.T) {
+	// Given
+	port, messages := startSMTPStub(t, "250 OK")
+	service := setUpSMTP(port)
+	notification := entity.Notification{
+		Subject: "Your request #22 is ready",
+		Text:    "The frames of your request #22 were extracted",
+		Html:    "<p>The frames of your request #22 were extracted</p>",
+		Request: &entity.Request{ID: 22},
+	}
+
+	// When
+	response, err := service.Send(context.Background(), "user@example.com", notification)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, "250 accepted by 127.0.0.1:"+port, response)
+
+	message := <-messages
+	assert.Contains(t, message, "To: <user@example.com>")
+	assert.Contains(t, message, `From: "Frameshot Notification" <no_reply@frameshot.com.br>`)
+	assert.Contains(t, message, "Subject: Your request #22 is ready")
+	assert.Contains(t, message, "Content-Type: multipart/alternative")
+	assert.Contains(t, message, "The frames of your request #22 were extracted")
+	assert.Contains(t, message, "<p>The frames of your request #22 were extracted</p>")
+}
+
+func TestSMTPService_RecipientRejected(t *testing.T) {
+	// Given
+	port, _ := startSMTPStub(t, "550 No such user")
+	service := setUpSMTP(port)
+
+	// When
+	response, err := service.Send(context.Background(), "unknown@example.com", entity.Notification{Request: &entity.Request{ID: 22}})
+
+	// Then
+	assert.ErrorContains(t, err, "No such user")
+	assert.Equal(t, "550 No such user", response)
+}
+
+func TestSMTPService_InvalidRecipient(t *testing.T) {
+	// When
+	_, err := setUpSMTP("25").Send(context.Background(), "xxxxxxx.com", entity.Notification{Request: &entity.Request{ID: 22}})
+
+	// Then
+	assert.ErrorContains(t, err, "invalid recipient address")
+}
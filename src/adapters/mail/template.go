@@ -0,0 +1,110 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"example/web-service-gin/src/core/entity"
+	"fmt"
+	htmltemplate "html/template"
+	"path"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates
+var templatesFS embed.FS
+
+// TemplateRenderer implements port.NotificationRenderer interface and writes the notifications
+// from the templates embedded on the binary. Each locale has, for each kind of notification,
+// <kind>.txt defining the "subject" and the "text" and <kind>.html defining the "subject"
+// and the "content" of layout.html
+type TemplateRenderer struct {
+	templates map[string]notificationTemplates
+}
+
+type notificationTemplates struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+var templateFuncs = map[string]any{
+	"bytes": formatBytes,
+	"date": func(value time.Time, layout string) string {
+		return value.UTC().Format(layout)
+	},
+}
+
+// NewTemplateRenderer parses the templates of all the kinds on all the locales, so a missing
+// or invalid template fails on the startup instead of on the delivery
+func NewTemplateRenderer() (*TemplateRenderer, error) {
+	renderer := &TemplateRenderer{map[string]notificationTemplates{}}
+
+	for _, locale := range entity.Locales {
+		for _, kind := range entity.NotificationKinds {
+			name := path.Join("templates", locale, string(kind))
+
+			text, err := texttemplate.New(string(kind)).Funcs(templateFuncs).ParseFS(templatesFS, name+".txt")
+			if err != nil {
+				return nil, fmt.Errorf("error parsing template %s.txt: %w", name, err)
+			}
+
+			html, err := htmltemplate.New(string(kind)).Funcs(templateFuncs).ParseFS(templatesFS, "templates/layout.html", name+".html")
+			if err != nil {
+				return nil, fmt.Errorf("error parsing template %s.html: %w", name, err)
+			}
+
+			renderer.templates[templateKey(locale, kind)] = notificationTemplates{text, html}
+		}
+	}
+
+	return renderer, nil
+}
+
+func (renderer *TemplateRenderer) Render(notification *entity.Notification) error {
+	templates, exists := renderer.templates[templateKey(notification.Locale, notification.Kind)]
+	if !exists {
+		return fmt.Errorf("no template of %s notifications on locale %q", notification.Kind, notification.Locale)
+	}
+
+	var subject, text, html bytes.Buffer
+
+	if err := templates.text.ExecuteTemplate(&subject, "subject", notification); err != nil {
+		return err
+	}
+
+	if err := templates.text.ExecuteTemplate(&text, "text", notification); err != nil {
+		return err
+	}
+
+	if err := templates.html.ExecuteTemplate(&html, "layout", notification); err != nil {
+		return err
+	}
+
+	notification.Subject = strings.TrimSpace(subject.String())
+	notification.Text = strings.TrimSpace(text.String())
+	notification.Html = html.String()
+	return nil
+}
+
+func templateKey(locale string, kind entity.NotificationKind) string {
+	return locale + "/" + string(kind)
+}
+
+// formatBytes writes the size with the biggest unit keeping at least 1, e.g. 1.5 GB
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	value := float64(size)
+	units := []string{"KB", "MB", "GB", "TB"}
+	index := -1
+	for value >= unit && index < len(units)-1 {
+		value /= unit
+		index++
+	}
+
+	return strings.TrimSuffix(fmt.Sprintf("%.1f", value), ".0") + " " + units[index]
+}
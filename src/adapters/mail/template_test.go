@@ -0,0 +1,136 @@
+package mail_test
+
+import (
+	"example/web-service-gin/src/adapters/mail"
+	"example/web-service-gin/src/core/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateRenderer_AllKindsAndLocales(t *testing.T) {
+	renderer, err := mail.NewTemplateRenderer()
+	require.NoError(t, err)
+
+	for _, locale := range entity.Locales {
+		for _, kind := range entity.NotificationKinds {
+			t.Run(locale+"/"+string(kind), func(t *testing.T) {
+				notification := &entity.Notification{
+					Kind:    kind,
+					Locale:  locale,
+					Request: &entity.Request{ID: 22, ZipOutputUrl: "https://example.com/frames.zip"},
+					Quota:   &entity.QuotaWarning{Limit: "bytes_per_month", Used: 17 << 30, Max: 20 << 30},
+					Digest:  &entity.Digest{Mode: entity.DigestDaily, Completed: 1, Requests: []entity.Request{{ID: 22, Status: entity.Completed}}},
+				}
+
+				// When
+				err := renderer.Render(notification)
+
+				// Then
+				require.NoError(t, err)
+				assert.NotEmpty(t, notification.Subject)
+				assert.NotEmpty(t, notification.Text)
+				assert.Contains(t, notification.Html, `<html lang="`+locale+`">`)
+			})
+		}
+	}
+}
+
+func TestTemplateRenderer_Completed(t *testing.T) {
+	renderer, _ := mail.NewTemplateRenderer()
+	notification := &entity.Notification{
+		Kind:    entity.NotificationRequestCompleted,
+		Locale:  "pt-BR",
+		Request: &entity.Request{ID: 22, ZipOutputUrl: "https://example.com/frames.zip?a=1&b=2"},
+	}
+
+	// When
+	err := renderer.Render(notification)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, "Sua solicitação #22 está pronta", notification.Subject)
+	assert.Contains(t, notification.Text, "https://example.com/frames.zip?a=1&b=2")
+	assert.Contains(t, notification.Html, `href="https://example.com/frames.zip?a=1&amp;b=2"`)
+}
+
+func TestTemplateRenderer_EscapesHtml(t *testing.T) {
+	renderer, _ := mail.NewTemplateRenderer()
+	notification := &entity.Notification{
+		Kind:    entity.NotificationRequestFailed,
+		Locale:  "en",
+		Request: &entity.Request{ID: 22, FailureReason: "<script>alert(1)</script>"},
+	}
+
+	// When
+	err := renderer.Render(notification)
+
+	// Then
+	require.NoError(t, err)
+	assert.Contains(t, notification.Text, "Reason: <script>alert(1)</script>")
+	assert.NotContains(t, notification.Html, "<script>")
+}
+
+func TestTemplateRenderer_QuotaWarning(t *testing.T) {
+	renderer, _ := mail.NewTemplateRenderer()
+	notification := &entity.Notification{
+		Kind:   entity.NotificationQuotaWarning,
+		Locale: "es",
+		Quota: &entity.QuotaWarning{
+			Limit:   "requests_per_day",
+			Used:    40,
+			Max:     50,
+			ResetAt: time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	// When
+	err := renderer.Render(notification)
+
+	// Then
+	require.NoError(t, err)
+	assert.Contains(t, notification.Text, "Creaste 40 de las 50 solicitudes")
+	assert.Contains(t, notification.Text, "16/01/2024 a las 00:00 UTC")
+}
+
+func TestTemplateRenderer_Digest(t *testing.T) {
+	renderer, _ := mail.NewTemplateRenderer()
+	notification := &entity.Notification{
+		Kind:   entity.NotificationDigest,
+		Locale: "en",
+		Digest: &entity.Digest{
+			Mode:        entity.DigestWeekly,
+			PeriodStart: time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC),
+			PeriodEnd:   time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+			Completed:   1,
+			Failed:      1,
+			Requests: []entity.Request{
+				{ID: 21, Status: entity.Completed, ZipOutputUrl: "https://example.com/frames.zip"},
+				{ID: 22, Status: entity.Failed, FailureReason: "the video could not be decoded"},
+			},
+		},
+	}
+
+	// When
+	err := renderer.Render(notification)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, "Your weekly summary: 1 completed, 1 failed", notification.Subject)
+	assert.Contains(t, notification.Text, "From Jan 8 to Jan 14, 2024")
+	assert.Contains(t, notification.Text, "- Request #21: ready, download the frames at https://example.com/frames.zip")
+	assert.Contains(t, notification.Text, "- Request #22: failed (the video could not be decoded)")
+	assert.Contains(t, notification.Html, `href="https://example.com/frames.zip"`)
+}
+
+func TestTemplateRenderer_UnknownLocale(t *testing.T) {
+	renderer, _ := mail.NewTemplateRenderer()
+
+	// When
+	err := renderer.Render(&entity.Notification{Kind: entity.NotificationRequestCompleted, Locale: "fr"})
+
+	// Then
+	assert.Error(t, err)
+}
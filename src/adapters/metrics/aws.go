@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"context"
+	"example/web-service-gin/src/core/port"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// InstrumentAWS records the latency and the errors of every call of the clients created from the
+// config, like S3, SQS and SNS. The latency of a call includes its retries
+func InstrumentAWS(config *aws.Config, metrics port.Metrics) {
+	config.APIOptions = append(config.APIOptions, func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("Metrics",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				start := time.Now()
+				out, metadata, err := next.HandleInitialize(ctx, in)
+
+				service := strings.ToLower(awsmiddleware.GetServiceID(ctx))
+				metrics.ObserveCall(service, awsmiddleware.GetOperationName(ctx), time.Since(start), err)
+
+				return out, metadata, err
+			}), middleware.After)
+	})
+}
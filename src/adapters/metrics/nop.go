@@ -0,0 +1,21 @@
+package metrics
+
+import (
+	"example/web-service-gin/src/core/entity"
+	"time"
+)
+
+// Nop implements port.Metrics discarding the measurements, it is used when the metrics are disabled
+type Nop struct{}
+
+func (Nop) ObserveHTTPRequest(method string, route string, status int, duration time.Duration) {}
+
+func (Nop) ObserveUpload(bytes int64, duration time.Duration, err error) {}
+
+func (Nop) ObserveCall(service string, operation string, duration time.Duration, err error) {}
+
+func (Nop) ObserveMessagesReceived(queue string, count int) {}
+
+func (Nop) ObserveMessage(queue string, duration time.Duration, err error) {}
+
+func (Nop) ObserveProcessing(status entity.RequestStatus, duration time.Duration) {}
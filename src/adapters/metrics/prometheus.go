@@ -0,0 +1,240 @@
+package metrics
+
+import (
+	"context"
+	"example/web-service-gin/src/core/entity"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes the names of all the metrics of the application
+const namespace = "frameshot"
+
+// countTimeout limits the query counting the requests of each status on a scrape
+const countTimeout = 5 * time.Second
+
+// countInterval is how long the requests of each status counted on a scrape are reused by the next ones
+const countInterval = 15 * time.Second
+
+// StatusCounter counts the requests of each status, it is implemented by port.RequestRepository
+type StatusCounter interface {
+	CountByStatus(ctx context.Context) (map[entity.RequestStatus]int, error)
+}
+
+// PrometheusMetrics implements port.Metrics on a Prometheus registry served by Handler
+type PrometheusMetrics struct {
+	registry          *prometheus.Registry
+	httpRequests      *prometheus.CounterVec
+	httpDuration      *prometheus.HistogramVec
+	uploadBytes       *prometheus.HistogramVec
+	uploadDuration    *prometheus.HistogramVec
+	callDuration      *prometheus.HistogramVec
+	callErrors        *prometheus.CounterVec
+	messagesReceived  *prometheus.CounterVec
+	messagesProcessed *prometheus.CounterVec
+	messagesFailed    *prometheus.CounterVec
+	messageDuration   *prometheus.HistogramVec
+	processing        *prometheus.HistogramVec
+}
+
+// NewPrometheusMetrics creates the metrics of the application, with the ones of the Go runtime
+// and of the process. The requests of each status are counted with the counter on the scrapes,
+// at most once each countInterval
+func NewPrometheusMetrics(counter StatusCounter) *PrometheusMetrics {
+	metrics := &PrometheusMetrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Requests served by the API.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of the requests served by the API.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		uploadBytes: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "upload_bytes",
+			Help:      "Size of the videos written to the storage.",
+			Buckets:   prometheus.ExponentialBuckets(1<<20, 4, 8),
+		}, []string{"result"}),
+		uploadDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "upload_duration_seconds",
+			Help:      "Time writing the videos to the storage.",
+			Buckets:   prometheus.ExponentialBuckets(0.1, 2, 12),
+		}, []string{"result"}),
+		callDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "external_call_duration_seconds",
+			Help:      "Latency of the calls to the external services.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"service", "operation"}),
+		callErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "external_call_errors_total",
+			Help:      "Failed calls to the external services.",
+		}, []string{"service", "operation"}),
+		messagesReceived: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "queue_messages_received_total",
+			Help:      "Messages read from the queues.",
+		}, []string{"queue"}),
+		messagesProcessed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "queue_messages_processed_total",
+			Help:      "Messages of the queues processed with success.",
+		}, []string{"queue"}),
+		messagesFailed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "queue_messages_failed_total",
+			Help:      "Messages of the queues whose processing failed.",
+		}, []string{"queue"}),
+		messageDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "queue_message_duration_seconds",
+			Help:      "Time processing the messages of the queues.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"queue"}),
+		processing: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_processing_duration_seconds",
+			Help:      "Time from the creation of the requests until their result.",
+			Buckets:   []float64{30, 60, 120, 300, 600, 900, 1800, 3600, 7200, 14400},
+		}, []string{"status"}),
+	}
+
+	metrics.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		metrics.httpRequests,
+		metrics.httpDuration,
+		metrics.uploadBytes,
+		metrics.uploadDuration,
+		metrics.callDuration,
+		metrics.callErrors,
+		metrics.messagesReceived,
+		metrics.messagesProcessed,
+		metrics.messagesFailed,
+		metrics.messageDuration,
+		metrics.processing,
+		newStatusCollector(counter),
+	)
+
+	return metrics
+}
+
+// Handler serves the metrics on the Prometheus text format
+func (metrics *PrometheusMetrics) Handler() http.Handler {
+	return promhttp.HandlerFor(metrics.registry, promhttp.HandlerOpts{})
+}
+
+func (metrics *PrometheusMetrics) ObserveHTTPRequest(method string, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	metrics.httpRequests.WithLabelValues(method, route, code).Inc()
+	metrics.httpDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+func (metrics *PrometheusMetrics) ObserveUpload(bytes int64, duration time.Duration, err error) {
+	metrics.uploadBytes.WithLabelValues(result(err)).Observe(float64(bytes))
+	metrics.uploadDuration.WithLabelValues(result(err)).Observe(duration.Seconds())
+}
+
+func (metrics *PrometheusMetrics) ObserveCall(service string, operation string, duration time.Duration, err error) {
+	metrics.callDuration.WithLabelValues(service, operation).Observe(duration.Seconds())
+
+	if err != nil {
+		metrics.callErrors.WithLabelValues(service, operation).Inc()
+	}
+}
+
+func (metrics *PrometheusMetrics) ObserveMessagesReceived(queue string, count int) {
+	metrics.messagesReceived.WithLabelValues(queue).Add(float64(count))
+}
+
+func (metrics *PrometheusMetrics) ObserveMessage(queue string, duration time.Duration, err error) {
+	metrics.messageDuration.WithLabelValues(queue).Observe(duration.Seconds())
+
+	if err != nil {
+		metrics.messagesFailed.WithLabelValues(queue).Inc()
+	} else {
+		metrics.messagesProcessed.WithLabelValues(queue).Inc()
+	}
+}
+
+func (metrics *PrometheusMetrics) ObserveProcessing(status entity.RequestStatus, duration time.Duration) {
+	metrics.processing.WithLabelValues(string(status)).Observe(duration.Seconds())
+}
+
+// statusCollector reports the requests of each status, counted on the database so the gauge is the
+// same on all the instances. The counts are reused for countInterval, so frequent scrapes or several
+// scrapers don't run the query each time
+type statusCollector struct {
+	counter   StatusCounter
+	desc      *prometheus.Desc
+	mutex     sync.Mutex
+	counts    map[entity.RequestStatus]int
+	countedAt time.Time
+}
+
+func newStatusCollector(counter StatusCounter) *statusCollector {
+	return &statusCollector{
+		counter: counter,
+		desc:    prometheus.NewDesc(namespace+"_requests", "Requests of each status.", []string{"status"}, nil),
+	}
+}
+
+func (collector *statusCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- collector.desc
+}
+
+// Collect skips the gauge when the requests can't be counted, the other metrics are still served
+func (collector *statusCollector) Collect(metrics chan<- prometheus.Metric) {
+	counts, err := collector.count()
+	if err != nil {
+		slog.Error("Error counting requests by status", "error", err)
+		return
+	}
+
+	for _, status := range []entity.RequestStatus{entity.Pending, entity.InProgress, entity.Completed, entity.Failed} {
+		metrics <- prometheus.MustNewConstMetric(collector.desc, prometheus.GaugeValue, float64(counts[status]), string(status))
+	}
+}
+
+// count returns the last counts while they are recent, otherwise counts the requests again
+func (collector *statusCollector) count() (map[entity.RequestStatus]int, error) {
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+
+	if collector.counts != nil && time.Since(collector.countedAt) < countInterval {
+		return collector.counts, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), countTimeout)
+	defer cancel()
+
+	counts, err := collector.counter.CountByStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	collector.counts = counts
+	collector.countedAt = time.Now()
+	return counts, nil
+}
+
+func result(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
@@ -0,0 +1,89 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"example/web-service-gin/src/adapters/metrics"
+	"example/web-service-gin/src/core/entity"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type statusCounter struct {
+	counts map[entity.RequestStatus]int
+	err    error
+	calls  int
+}
+
+func (counter *statusCounter) CountByStatus(ctx context.Context) (map[entity.RequestStatus]int, error) {
+	counter.calls++
+	return counter.counts, counter.err
+}
+
+func scrape(prometheusMetrics *metrics.PrometheusMetrics) string {
+	w := httptest.NewRecorder()
+	prometheusMetrics.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	body, _ := io.ReadAll(w.Body)
+	return string(body)
+}
+
+func TestPrometheusMetrics(t *testing.T) {
+	prometheusMetrics := metrics.NewPrometheusMetrics(&statusCounter{counts: map[entity.RequestStatus]int{entity.Completed: 7, entity.InProgress: 2}})
+
+	// When
+	prometheusMetrics.ObserveHTTPRequest("GET", "/requests/:id", 404, 20*time.Millisecond)
+	prometheusMetrics.ObserveUpload(50<<20, 3*time.Second, nil)
+	prometheusMetrics.ObserveCall("sendgrid", "send", 300*time.Millisecond, errors.New("timeout"))
+	prometheusMetrics.ObserveCall("sqs", "ReceiveMessage", 5*time.Second, nil)
+	prometheusMetrics.ObserveMessagesReceived("video-output", 3)
+	prometheusMetrics.ObserveMessage("video-output", time.Millisecond, nil)
+	prometheusMetrics.ObserveMessage("video-output", time.Millisecond, errors.New("invalid body"))
+	prometheusMetrics.ObserveProcessing(entity.Completed, 4*time.Minute)
+	body := scrape(prometheusMetrics)
+
+	// Then
+	assert.Contains(t, body, `frameshot_http_requests_total{method="GET",route="/requests/:id",status="404"} 1`)
+	assert.Contains(t, body, `frameshot_http_request_duration_seconds_count{method="GET",route="/requests/:id",status="404"} 1`)
+	assert.Contains(t, body, `frameshot_upload_bytes_sum{result="success"} 5.24288e+07`)
+	assert.Contains(t, body, `frameshot_upload_duration_seconds_sum{result="success"} 3`)
+	assert.Contains(t, body, `frameshot_external_call_errors_total{operation="send",service="sendgrid"} 1`)
+	assert.Contains(t, body, `frameshot_external_call_duration_seconds_count{operation="ReceiveMessage",service="sqs"} 1`)
+	assert.NotContains(t, body, `frameshot_external_call_errors_total{operation="ReceiveMessage"`)
+	assert.Contains(t, body, `frameshot_queue_messages_received_total{queue="video-output"} 3`)
+	assert.Contains(t, body, `frameshot_queue_messages_processed_total{queue="video-output"} 1`)
+	assert.Contains(t, body, `frameshot_queue_messages_failed_total{queue="video-output"} 1`)
+	assert.Contains(t, body, `frameshot_request_processing_duration_seconds_sum{status="COMPLETED"} 240`)
+	assert.Contains(t, body, `frameshot_requests{status="COMPLETED"} 7`)
+	assert.Contains(t, body, `frameshot_requests{status="IN_PROGRESS"} 2`)
+	assert.Contains(t, body, `frameshot_requests{status="PENDING"} 0`)
+}
+
+func TestPrometheusMetrics_CountError(t *testing.T) {
+	prometheusMetrics := metrics.NewPrometheusMetrics(&statusCounter{err: errors.New("connection refused")})
+
+	// When
+	prometheusMetrics.ObserveMessagesReceived("video-output", 1)
+	body := scrape(prometheusMetrics)
+
+	// Then
+	assert.NotContains(t, body, "frameshot_requests{")
+	assert.Contains(t, body, `frameshot_queue_messages_received_total{queue="video-output"} 1`)
+}
+
+func TestPrometheusMetrics_CountsReusedBetweenScrapes(t *testing.T) {
+	counter := &statusCounter{counts: map[entity.RequestStatus]int{entity.Pending: 3}}
+	prometheusMetrics := metrics.NewPrometheusMetrics(counter)
+
+	// When
+	scrape(prometheusMetrics)
+	body := scrape(prometheusMetrics)
+
+	// Then
+	assert.Contains(t, body, `frameshot_requests{status="PENDING"} 3`)
+	assert.Equal(t, 1, counter.calls)
+}
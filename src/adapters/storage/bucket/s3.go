@@ -2,58 +2,261 @@ package bucket
 
 import (
 	"context"
+	"errors"
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/port"
 	"example/web-service-gin/src/infra/configuration"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/golang-migrate/migrate/v4/source/file"
-	"mime/multipart"
-	"strings"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 )
 
+// S3Client contains the operations of the AWS S3 client used by the storage
+type S3Client interface {
+	PutObject(ctx context.Context, input *s3.PutObjectInput, opts ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	GetObject(ctx context.Context, input *s3.GetObjectInput, opts ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	HeadObject(ctx context.Context, input *s3.HeadObjectInput, opts ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+	DeleteObject(ctx context.Context, input *s3.DeleteObjectInput, opts ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+	ListObjectsV2(ctx context.Context, input *s3.ListObjectsV2Input, opts ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+}
+
 // S3Storage implements port.StoragePort
 type S3Storage struct {
 	config     *configuration.Aws
 	bucketName string
-	s3Client   *s3.Client
-	ctx        context.Context
+	s3Client   S3Client
+	metrics    port.Metrics
 }
 
-func NewS3Bucket(configs *configuration.Aws, ctx context.Context) *S3Storage {
-	s3Client := s3.NewFromConfig(configs.Config)
+func NewS3Bucket(configs *configuration.Aws, metrics port.Metrics) *S3Storage {
+	s3Client := s3.NewFromConfig(configs.Config, func(options *s3.Options) {
+		if endpoint := configs.S3Endpoint(); endpoint != "" {
+			options.BaseEndpoint = aws.String(endpoint)
+		}
+		options.UsePathStyle = configs.S3UsePathStyle
+	})
+	return NewS3BucketWithClient(configs, s3Client, metrics)
+}
+
+// NewS3BucketWithClient creates the storage with an already configured S3 client
+func NewS3BucketWithClient(configs *configuration.Aws, client S3Client, metrics port.Metrics) *S3Storage {
 	return &S3Storage{
 		configs,
 		configs.BucketName,
-		s3Client,
-		ctx}
+		client,
+		metrics}
 }
 
-func (handler *S3Storage) UploadFile(file *multipart.FileHeader, fileKey string) (string, error) {
-
-	fileData, _ := file.Open()
+// UploadFile waits for the upload to finish, so the request is only created for the videos stored
+func (handler *S3Storage) UploadFile(ctx context.Context, content io.ReadSeeker, size int64, contentType string, fileKey string) (string, error) {
 
 	// Upload input parameters
 	upParams := &s3.PutObjectInput{
+		Bucket:        aws.String(handler.bucketName),
+		Key:           aws.String(fileKey),
+		Body:          content,
+		ContentLength: aws.Int64(size),
+		ContentType:   aws.String(contentType),
+	}
+
+	start := time.Now()
+	_, err := handler.s3Client.PutObject(ctx, upParams)
+	handler.metrics.ObserveUpload(size, time.Since(start), err)
+
+	if err != nil {
+		return "", fmt.Errorf("error uploading file %s: %w", fileKey, err)
+	}
+
+	return fileKey, nil
+}
+
+func (handler *S3Storage) DownloadFile(ctx context.Context, fileKey string) (io.ReadCloser, *entity.FileInfo, error) {
+	return handler.DownloadFileRange(ctx, fileKey, 0, 0)
+}
+
+func (handler *S3Storage) DownloadFileRange(ctx context.Context, fileKey string, offset int64, length int64) (io.ReadCloser, *entity.FileInfo, error) {
+	input := &s3.GetObjectInput{
 		Bucket: aws.String(handler.bucketName),
 		Key:    aws.String(fileKey),
-		Body:   fileData,
 	}
 
-	// This will start an async running, and it will not wait to end
-	go handler.s3Client.PutObject(handler.ctx, upParams)
+	if offset < 0 {
+		return nil, nil, fmt.Errorf("negative offset %d: %w", offset, core.ErrInvalidRange)
+	}
 
-	return fileKey, nil
+	if offset > 0 || length > 0 {
+		input.Range = aws.String(formatRange(offset, length))
+	}
+
+	output, err := handler.s3Client.GetObject(ctx, input)
+
+	if err != nil {
+		return nil, nil, mapS3Error(err, fileKey)
+	}
+
+	info := &entity.FileInfo{
+		Key:          fileKey,
+		Size:         aws.ToInt64(output.ContentLength),
+		ContentType:  aws.ToString(output.ContentType),
+		ETag:         aws.ToString(output.ETag),
+		LastModified: aws.ToTime(output.LastModified),
+	}
+
+	// On ranged reads the whole file size comes after the slash: "bytes 0-99/1234"
+	if total, ok := parseContentRangeSize(aws.ToString(output.ContentRange)); ok {
+		info.Size = total
+	}
+
+	return output.Body, info, nil
+}
+
+func (handler *S3Storage) StatFile(ctx context.Context, fileKey string) (*entity.FileInfo, error) {
+	output, err := handler.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(handler.bucketName),
+		Key:    aws.String(fileKey),
+	})
+
+	if err != nil {
+		return nil, mapS3Error(err, fileKey)
+	}
+
+	return &entity.FileInfo{
+		Key:          fileKey,
+		Size:         aws.ToInt64(output.ContentLength),
+		ContentType:  aws.ToString(output.ContentType),
+		ETag:         aws.ToString(output.ETag),
+		LastModified: aws.ToTime(output.LastModified),
+	}, nil
+}
+
+func (handler *S3Storage) FileExists(ctx context.Context, fileKey string) (bool, error) {
+	_, err := handler.StatFile(ctx, fileKey)
+
+	if errors.Is(err, core.ErrDataNotFound) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (handler *S3Storage) DeleteFile(ctx context.Context, fileKey string) error {
+	_, err := handler.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(handler.bucketName),
+		Key:    aws.String(fileKey),
+	})
+
+	if err != nil {
+		return mapS3Error(err, fileKey)
+	}
+
+	return nil
 }
 
-func (handler *S3Storage) DownloadFile(fileKey string) (*file.File, error) {
-	return nil, nil
+func (handler *S3Storage) ListFiles(ctx context.Context, prefix string) ([]entity.FileInfo, error) {
+	var files []entity.FileInfo
+
+	paginator := s3.NewListObjectsV2Paginator(handler.s3Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(handler.bucketName),
+		Prefix: aws.String(prefix),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing files with prefix %s: %w", prefix, err)
+		}
+
+		for _, object := range page.Contents {
+			files = append(files, entity.FileInfo{
+				Key:          aws.ToString(object.Key),
+				Size:         aws.ToInt64(object.Size),
+				ETag:         aws.ToString(object.ETag),
+				LastModified: aws.ToTime(object.LastModified),
+			})
+		}
+	}
+
+	return files, nil
 }
 
+// GetFileUrl returns the object URL on AWS or on the custom endpoint, using
+// path-style (endpoint/bucket/key) or virtual-hosted (bucket.endpoint/key) addressing
 func (handler *S3Storage) GetFileUrl(fileKey string) string {
 
-	template := "https://<bucket-name>.s3.<region>.amazonaws.com/<object-key>"
-	template = strings.ReplaceAll(template, "<bucket-name>", handler.config.BucketName)
-	template = strings.ReplaceAll(template, "<region>", handler.config.Config.Region)
-	template = strings.ReplaceAll(template, "<object-key>", fileKey)
+	endpoint := handler.config.S3PublicEndpoint()
+
+	if endpoint == "" {
+		endpoint = "https://s3.<region>.amazonaws.com"
+		endpoint = strings.ReplaceAll(endpoint, "<region>", handler.config.Config.Region)
+	}
+
+	endpointUrl, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+	if err != nil || endpointUrl.Host == "" {
+		slog.Error("Invalid S3 endpoint", "endpoint", endpoint)
+		return ""
+	}
+
+	if handler.config.S3UsePathStyle {
+		endpointUrl.Path += "/" + handler.bucketName + "/" + fileKey
+	} else {
+		endpointUrl.Host = handler.bucketName + "." + endpointUrl.Host
+		endpointUrl.Path += "/" + fileKey
+	}
+
+	return endpointUrl.String()
+}
+
+// formatRange builds the HTTP Range header value for the S3 request
+func formatRange(offset int64, length int64) string {
+	if length <= 0 {
+		return fmt.Sprintf("bytes=%d-", offset)
+	}
+	return fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)
+}
+
+// parseContentRangeSize reads the complete size from a Content-Range header
+func parseContentRangeSize(contentRange string) (int64, bool) {
+	index := strings.LastIndex(contentRange, "/")
+	if index < 0 {
+		return 0, false
+	}
+
+	size, err := strconv.ParseInt(contentRange[index+1:], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return size, true
+}
+
+// mapS3Error converts the S3 missing object errors to core.ErrDataNotFound,
+// the invalid ranges to core.ErrInvalidRange and any other failure to core.ErrStorageUnavailable
+func mapS3Error(err error, fileKey string) error {
+	var noSuchKey *types.NoSuchKey
+	var notFound *types.NotFound
+	var apiError smithy.APIError
+
+	if errors.As(err, &noSuchKey) || errors.As(err, &notFound) {
+		return fmt.Errorf("file %s: %w", fileKey, core.ErrDataNotFound)
+	}
+
+	if errors.As(err, &apiError) && apiError.ErrorCode() == "InvalidRange" {
+		return fmt.Errorf("file %s: %w", fileKey, core.ErrInvalidRange)
+	}
 
-	return template
+	return fmt.Errorf("error accessing file %s: %w: %w", fileKey, core.ErrStorageUnavailable, err)
 }
@@ -2,21 +2,67 @@ package bucket_test
 
 import (
 	"context"
+	"errors"
+	"example/web-service-gin/src/adapters/metrics"
 	"example/web-service-gin/src/adapters/storage/bucket"
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
 	"example/web-service-gin/src/infra/configuration"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/stretchr/testify/assert"
-	"testing"
+	"github.com/stretchr/testify/mock"
 )
 
-func setUp() *bucket.S3Storage {
-	ctx := context.Background()
-	config := configuration.Aws{
+type MockS3Client struct {
+	mock.Mock
+}
+
+func (m *MockS3Client) PutObject(ctx context.Context, input *s3.PutObjectInput, opts ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*s3.PutObjectOutput), args.Error(1)
+}
+
+func (m *MockS3Client) GetObject(ctx context.Context, input *s3.GetObjectInput, opts ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*s3.GetObjectOutput), args.Error(1)
+}
+
+func (m *MockS3Client) HeadObject(ctx context.Context, input *s3.HeadObjectInput, opts ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*s3.HeadObjectOutput), args.Error(1)
+}
+
+func (m *MockS3Client) DeleteObject(ctx context.Context, input *s3.DeleteObjectInput, opts ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*s3.DeleteObjectOutput), args.Error(1)
+}
+
+func (m *MockS3Client) ListObjectsV2(ctx context.Context, input *s3.ListObjectsV2Input, opts ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*s3.ListObjectsV2Output), args.Error(1)
+}
+
+func getConfig() *configuration.Aws {
+	return &configuration.Aws{
 		Config:     aws.Config{Region: "us-east-1"},
 		BucketName: "bucket-name",
 	}
+}
+
+func setUp() *bucket.S3Storage {
+	return bucket.NewS3Bucket(getConfig(), metrics.Nop{})
+}
 
-	return bucket.NewS3Bucket(&config, ctx)
+func setUpWithMock() (*bucket.S3Storage, *MockS3Client) {
+	client := new(MockS3Client)
+	return bucket.NewS3BucketWithClient(getConfig(), client, metrics.Nop{}), client
 }
 
 func TestNewS3Bucket(t *testing.T) {
@@ -24,11 +70,157 @@ func TestNewS3Bucket(t *testing.T) {
 	assert.NotNil(t, storage)
 }
 
+func TestUploadFile(t *testing.T) {
+	storage, client := setUpWithMock()
+	file := strings.NewReader("video content")
+	client.On("PutObject", mock.Anything, mock.MatchedBy(func(input *s3.PutObjectInput) bool {
+		content, _ := io.ReadAll(input.Body)
+		return aws.ToString(input.Key) == "videos_input/video.mp4" && aws.ToInt64(input.ContentLength) == 13 && string(content) == "video content"
+	})).Return(&s3.PutObjectOutput{}, nil)
+
+	key, err := storage.UploadFile(context.Background(), file, file.Size(), "video/mp4", "videos_input/video.mp4")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "videos_input/video.mp4", key)
+	client.AssertNumberOfCalls(t, "PutObject", 1)
+}
+
+func TestUploadFile_Error(t *testing.T) {
+	storage, client := setUpWithMock()
+	file := strings.NewReader("video content")
+	client.On("PutObject", mock.Anything, mock.Anything).
+		Return((*s3.PutObjectOutput)(nil), errors.New("mock error"))
+
+	key, err := storage.UploadFile(context.Background(), file, file.Size(), "video/mp4", "videos_input/video.mp4")
+
+	assert.Error(t, err)
+	assert.Empty(t, key)
+}
+
 func TestDownloadFile(t *testing.T) {
-	storage := setUp()
-	file, err := storage.DownloadFile("/path/to/file")
+	storage, client := setUpWithMock()
+	client.On("GetObject", mock.Anything, mock.MatchedBy(func(input *s3.GetObjectInput) bool {
+		return input.Range == nil
+	})).Return(&s3.GetObjectOutput{
+		Body:          io.NopCloser(strings.NewReader("content")),
+		ContentLength: aws.Int64(7),
+		ContentType:   aws.String("video/mp4"),
+		ETag:          aws.String(`"etag"`),
+	}, nil)
+
+	file, info, err := storage.DownloadFile(context.Background(), "path/to/file")
+
+	assert.NoError(t, err)
+	content, _ := io.ReadAll(file)
+	assert.Equal(t, "content", string(content))
+	assert.Equal(t, int64(7), info.Size)
+	assert.Equal(t, "video/mp4", info.ContentType)
+	assert.Equal(t, `"etag"`, info.ETag)
+}
+
+func TestDownloadFileRange(t *testing.T) {
+	storage, client := setUpWithMock()
+	client.On("GetObject", mock.Anything, mock.MatchedBy(func(input *s3.GetObjectInput) bool {
+		return aws.ToString(input.Range) == "bytes=10-19"
+	})).Return(&s3.GetObjectOutput{
+		Body:          io.NopCloser(strings.NewReader("0123456789")),
+		ContentLength: aws.Int64(10),
+		ContentRange:  aws.String("bytes 10-19/1234"),
+	}, nil)
+
+	file, info, err := storage.DownloadFileRange(context.Background(), "path/to/file", 10, 10)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, file)
+	assert.Equal(t, int64(1234), info.Size)
+}
+
+func TestDownloadFileRange_NegativeOffset(t *testing.T) {
+	storage, client := setUpWithMock()
+
+	_, _, err := storage.DownloadFileRange(context.Background(), "path/to/file", -1, 10)
+
+	assert.ErrorIs(t, err, core.ErrInvalidRange)
+	client.AssertNotCalled(t, "GetObject", mock.Anything, mock.Anything)
+}
+
+func TestDownloadFile_NotFound(t *testing.T) {
+	storage, client := setUpWithMock()
+	client.On("GetObject", mock.Anything, mock.Anything).
+		Return((*s3.GetObjectOutput)(nil), &types.NoSuchKey{})
+
+	file, info, err := storage.DownloadFile(context.Background(), "path/to/file")
+
 	assert.Nil(t, file)
-	assert.Nil(t, err)
+	assert.Nil(t, info)
+	assert.ErrorIs(t, err, core.ErrDataNotFound)
+}
+
+func TestStatFile(t *testing.T) {
+	storage, client := setUpWithMock()
+	modified := time.Date(2025, 1, 23, 20, 38, 8, 0, time.UTC)
+	client.On("HeadObject", mock.Anything, mock.Anything).Return(&s3.HeadObjectOutput{
+		ContentLength: aws.Int64(2048),
+		ContentType:   aws.String("application/zip"),
+		ETag:          aws.String(`"etag"`),
+		LastModified:  aws.Time(modified),
+	}, nil)
+
+	info, err := storage.StatFile(context.Background(), "zip_output/file.zip")
+
+	assert.NoError(t, err)
+	assert.Equal(t, &entity.FileInfo{
+		Key:          "zip_output/file.zip",
+		Size:         2048,
+		ContentType:  "application/zip",
+		ETag:         `"etag"`,
+		LastModified: modified,
+	}, info)
+}
+
+func TestFileExists(t *testing.T) {
+	storage, client := setUpWithMock()
+	client.On("HeadObject", mock.Anything, mock.MatchedBy(func(input *s3.HeadObjectInput) bool {
+		return aws.ToString(input.Key) == "exists.zip"
+	})).Return(&s3.HeadObjectOutput{}, nil)
+	client.On("HeadObject", mock.Anything, mock.Anything).
+		Return((*s3.HeadObjectOutput)(nil), &types.NotFound{})
+
+	exists, err := storage.FileExists(context.Background(), "exists.zip")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = storage.FileExists(context.Background(), "missing.zip")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestDeleteFile_Error(t *testing.T) {
+	storage, client := setUpWithMock()
+	client.On("DeleteObject", mock.Anything, mock.Anything).
+		Return((*s3.DeleteObjectOutput)(nil), errors.New("mock error"))
+
+	err := storage.DeleteFile(context.Background(), "path/to/file")
+
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, core.ErrDataNotFound)
+}
+
+func TestListFiles(t *testing.T) {
+	storage, client := setUpWithMock()
+	client.On("ListObjectsV2", mock.Anything, mock.Anything).Return(&s3.ListObjectsV2Output{
+		Contents: []types.Object{
+			{Key: aws.String("videos_input/a.mp4"), Size: aws.Int64(10)},
+			{Key: aws.String("videos_input/b.mp4"), Size: aws.Int64(20)},
+		},
+	}, nil)
+
+	files, err := storage.ListFiles(context.Background(), "videos_input/")
+
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+	assert.Equal(t, "videos_input/a.mp4", files[0].Key)
+	assert.Equal(t, int64(20), files[1].Size)
 }
 
 func TestGetFileUrl(t *testing.T) {
@@ -38,3 +230,46 @@ func TestGetFileUrl(t *testing.T) {
 	assert.NotNil(t, url)
 	assert.Equal(t, expectedUrl, url)
 }
+
+func TestGetFileUrl_CustomEndpointPathStyle(t *testing.T) {
+	config := getConfig()
+	config.EndpointUrl = "http://localhost:9000"
+	config.S3UsePathStyle = true
+	storage := bucket.NewS3Bucket(config, metrics.Nop{})
+
+	url := storage.GetFileUrl("zip_output/file.zip")
+
+	assert.Equal(t, "http://localhost:9000/bucket-name/zip_output/file.zip", url)
+}
+
+func TestGetFileUrl_CustomEndpointVirtualHosted(t *testing.T) {
+	config := getConfig()
+	config.S3EndpointUrl = "https://storage.customer.local/"
+	storage := bucket.NewS3Bucket(config, metrics.Nop{})
+
+	url := storage.GetFileUrl("zip_output/file.zip")
+
+	assert.Equal(t, "https://bucket-name.storage.customer.local/zip_output/file.zip", url)
+}
+
+func TestGetFileUrl_PublicUrlOverridesEndpoint(t *testing.T) {
+	config := getConfig()
+	config.S3EndpointUrl = "http://minio:9000"
+	config.S3PublicUrl = "http://localhost:9000"
+	config.S3UsePathStyle = true
+	storage := bucket.NewS3Bucket(config, metrics.Nop{})
+
+	url := storage.GetFileUrl("zip_output/file.zip")
+
+	assert.Equal(t, "http://localhost:9000/bucket-name/zip_output/file.zip", url)
+}
+
+func TestGetFileUrl_AwsPathStyle(t *testing.T) {
+	config := getConfig()
+	config.S3UsePathStyle = true
+	storage := bucket.NewS3Bucket(config, metrics.Nop{})
+
+	url := storage.GetFileUrl("file.zip")
+
+	assert.Equal(t, "https://s3.us-east-1.amazonaws.com/bucket-name/file.zip", url)
+}
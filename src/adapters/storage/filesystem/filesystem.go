@@ -0,0 +1,343 @@
+package filesystem
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"example/web-service-gin/src/adapters/storage/bucket"
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/port"
+	"example/web-service-gin/src/infra/configuration"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileSystemStorage implements port.SignedStoragePort on the local disk, it is
+// meant for local development and integration tests without AWS credentials
+type FileSystemStorage struct {
+	config   *configuration.Storage
+	producer port.MessageProducer
+	queueUrl string
+	metrics  port.Metrics
+}
+
+// NewFileSystemStorage creates a new storage on the configured directory. Each upload
+// sends an S3-style ObjectCreated event to queueUrl, like the S3 bucket notifications
+func NewFileSystemStorage(conf *configuration.Storage, producer port.MessageProducer, queueUrl string, metrics port.Metrics) (*FileSystemStorage, error) {
+	err := os.MkdirAll(conf.Path, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("error creating storage directory: %w", err)
+	}
+
+	return &FileSystemStorage{
+		config:   conf,
+		producer: producer,
+		queueUrl: queueUrl,
+		metrics:  metrics,
+	}, nil
+}
+
+func (storage *FileSystemStorage) UploadFile(ctx context.Context, content io.ReadSeeker, size int64, contentType string, fileKey string) (string, error) {
+	start := time.Now()
+	err := storage.writeFile(content, fileKey)
+	storage.metrics.ObserveUpload(size, time.Since(start), err)
+
+	if err != nil {
+		return "", err
+	}
+
+	return fileKey, nil
+}
+
+// writeFile writes the uploaded content on the storage and announces it
+func (storage *FileSystemStorage) writeFile(source io.Reader, fileKey string) error {
+	filePath, err := storage.filePath(fileKey)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(filePath), 0o755)
+	if err != nil {
+		return fmt.Errorf("error creating file directory: %w", err)
+	}
+
+	// Writes on a temporary file first, so a partial upload is never visible
+	destination, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("error creating file: %w", err)
+	}
+	defer os.Remove(destination.Name())
+
+	hash := md5.New()
+	size, err := io.Copy(io.MultiWriter(destination, hash), source)
+	closeErr := destination.Close()
+
+	if err != nil || closeErr != nil {
+		return fmt.Errorf("error writing file %s: %w", fileKey, errors.Join(err, closeErr))
+	}
+
+	err = os.Rename(destination.Name(), filePath)
+	if err != nil {
+		return fmt.Errorf("error writing file %s: %w", fileKey, err)
+	}
+
+	storage.notifyObjectCreated(fileKey, size, hex.EncodeToString(hash.Sum(nil)))
+
+	return nil
+}
+
+func (storage *FileSystemStorage) DownloadFile(ctx context.Context, fileKey string) (io.ReadCloser, *entity.FileInfo, error) {
+	return storage.DownloadFileRange(ctx, fileKey, 0, 0)
+}
+
+func (storage *FileSystemStorage) DownloadFileRange(ctx context.Context, fileKey string, offset int64, length int64) (io.ReadCloser, *entity.FileInfo, error) {
+	filePath, err := storage.filePath(fileKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, nil, mapFileError(err, fileKey)
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, mapFileError(err, fileKey)
+	}
+
+	if offset < 0 || (offset > 0 && offset >= stat.Size()) {
+		file.Close()
+		return nil, nil, fmt.Errorf("offset %d of file %s: %w", offset, fileKey, core.ErrInvalidRange)
+	}
+
+	_, err = file.Seek(offset, io.SeekStart)
+	if err != nil {
+		file.Close()
+		return nil, nil, mapFileError(err, fileKey)
+	}
+
+	info := toFileInfo(fileKey, stat)
+
+	if length <= 0 {
+		return file, info, nil
+	}
+
+	return &limitedFile{Reader: io.LimitReader(file, length), Closer: file}, info, nil
+}
+
+func (storage *FileSystemStorage) StatFile(ctx context.Context, fileKey string) (*entity.FileInfo, error) {
+	filePath, err := storage.filePath(fileKey)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := os.Stat(filePath)
+	if err != nil {
+		return nil, mapFileError(err, fileKey)
+	}
+
+	return toFileInfo(fileKey, stat), nil
+}
+
+func (storage *FileSystemStorage) FileExists(ctx context.Context, fileKey string) (bool, error) {
+	_, err := storage.StatFile(ctx, fileKey)
+
+	if errors.Is(err, core.ErrDataNotFound) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (storage *FileSystemStorage) DeleteFile(ctx context.Context, fileKey string) error {
+	filePath, err := storage.filePath(fileKey)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(filePath)
+	if err != nil {
+		return mapFileError(err, fileKey)
+	}
+
+	return nil
+}
+
+func (storage *FileSystemStorage) ListFiles(ctx context.Context, prefix string) ([]entity.FileInfo, error) {
+	var files []entity.FileInfo
+
+	err := filepath.WalkDir(storage.config.Path, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".upload-") {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(storage.config.Path, filePath)
+		if err != nil {
+			return err
+		}
+
+		key := filepath.ToSlash(relativePath)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		stat, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		files = append(files, *toFileInfo(key, stat))
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing files with prefix %s: %w", prefix, err)
+	}
+
+	return files, nil
+}
+
+// GetFileUrl returns a URL of the /files route signed with the storage key and valid for the configured TTL
+func (storage *FileSystemStorage) GetFileUrl(fileKey string) string {
+	expires := strconv.FormatInt(time.Now().Add(storage.config.UrlTTL).Unix(), 10)
+
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", storage.sign(fileKey, expires))
+
+	return strings.TrimSuffix(storage.config.PublicUrl, "/") + "/files/" + fileKey + "?" + query.Encode()
+}
+
+// VerifyFileUrl validates the expiration and signature of a URL created by GetFileUrl
+func (storage *FileSystemStorage) VerifyFileUrl(fileKey string, expires string, signature string) error {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid url expiration: %w", core.ErrForbidden)
+	}
+
+	if time.Now().Unix() > expiresAt {
+		return fmt.Errorf("url expired: %w", core.ErrForbidden)
+	}
+
+	expected := storage.sign(fileKey, expires)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return fmt.Errorf("invalid url signature: %w", core.ErrForbidden)
+	}
+
+	return nil
+}
+
+func (storage *FileSystemStorage) sign(fileKey string, expires string) string {
+	mac := hmac.New(sha256.New, []byte(storage.config.SigningKey))
+	mac.Write([]byte(fileKey + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// filePath resolves the key inside the storage directory, rejecting keys that escape it
+func (storage *FileSystemStorage) filePath(fileKey string) (string, error) {
+	cleanKey := path.Clean("/" + fileKey)
+
+	if fileKey == "" || cleanKey == "/" || cleanKey != "/"+fileKey {
+		return "", fmt.Errorf("invalid file key %q: %w", fileKey, core.ErrForbidden)
+	}
+
+	return filepath.Join(storage.config.Path, filepath.FromSlash(cleanKey)), nil
+}
+
+// notifyObjectCreated emits the same event S3 sends to the upload notifications queue
+func (storage *FileSystemStorage) notifyObjectCreated(fileKey string, size int64, eTag string) {
+	if storage.producer == nil {
+		return
+	}
+
+	now := time.Now().UTC()
+	event := bucket.S3Event{
+		Records: []bucket.Record{
+			{
+				EventVersion: "2.1",
+				EventSource:  "frameshot:filesystem",
+				EventTime:    now.Format("2006-01-02T15:04:05.000Z"),
+				EventName:    "ObjectCreated:Put",
+				S3: bucket.S3{
+					SchemaVersion: "1.0",
+					Bucket:        bucket.Bucket{Name: storage.config.BucketName},
+					Object: bucket.S3Object{
+						Key:       fileKey,
+						Size:      size,
+						ETag:      eTag,
+						Sequencer: strconv.FormatInt(now.UnixNano(), 16),
+					},
+				},
+			},
+		},
+	}
+
+	jsonData, err := json.Marshal(event)
+	if err != nil {
+		slog.Error("Error trying to convert upload event to JSON", "error", err)
+		return
+	}
+
+	err = storage.producer.SendMessage(storage.queueUrl, string(jsonData))
+	if err != nil {
+		slog.Error("Error trying to send upload event", "destination", storage.queueUrl, "error", err)
+	}
+}
+
+// limitedFile closes the file behind a ranged read
+type limitedFile struct {
+	io.Reader
+	io.Closer
+}
+
+// toFileInfo maps the file system metadata, the ETag is derived from the
+// modification time and size so the content doesn't need to be hashed
+func toFileInfo(fileKey string, stat fs.FileInfo) *entity.FileInfo {
+	contentType := mime.TypeByExtension(path.Ext(fileKey))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	return &entity.FileInfo{
+		Key:          fileKey,
+		Size:         stat.Size(),
+		ContentType:  contentType,
+		ETag:         fmt.Sprintf(`"%x-%x"`, stat.ModTime().UnixNano(), stat.Size()),
+		LastModified: stat.ModTime(),
+	}
+}
+
+// mapFileError converts the missing file errors to core.ErrDataNotFound
+// and any other failure to core.ErrStorageUnavailable
+func mapFileError(err error, fileKey string) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("file %s: %w", fileKey, core.ErrDataNotFound)
+	}
+
+	return fmt.Errorf("error accessing file %s: %w: %w", fileKey, core.ErrStorageUnavailable, err)
+}
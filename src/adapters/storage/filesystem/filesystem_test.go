@@ -0,0 +1,169 @@
+package filesystem_test
+
+import (
+	"context"
+	"encoding/json"
+	"example/web-service-gin/src/adapters/handler/queue"
+	"example/web-service-gin/src/adapters/metrics"
+	"example/web-service-gin/src/adapters/storage/bucket"
+	"example/web-service-gin/src/adapters/storage/filesystem"
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/infra/configuration"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setUp(t *testing.T) (*filesystem.FileSystemStorage, *queue.MemoryQueue) {
+	config := &configuration.Storage{
+		Path:       t.TempDir(),
+		BucketName: "frameshot",
+		PublicUrl:  "http://localhost:8080/",
+		SigningKey: "signing-key",
+		UrlTTL:     time.Hour,
+	}
+
+	events := queue.NewMemoryQueue(time.Minute)
+	storage, err := filesystem.NewFileSystemStorage(config, events, "s3-events", metrics.Nop{})
+	assert.NoError(t, err)
+
+	return storage, events
+}
+
+func TestUploadAndDownloadFile(t *testing.T) {
+	storage, _ := setUp(t)
+	file := strings.NewReader("video content")
+
+	key, err := storage.UploadFile(context.Background(), file, file.Size(), "video/mp4", "videos_input/user_video.mp4")
+	assert.NoError(t, err)
+	assert.Equal(t, "videos_input/user_video.mp4", key)
+
+	reader, info, err := storage.DownloadFile(context.Background(), key)
+	assert.NoError(t, err)
+	defer reader.Close()
+
+	content, _ := io.ReadAll(reader)
+	assert.Equal(t, "video content", string(content))
+	assert.Equal(t, int64(13), info.Size)
+	assert.Equal(t, "video/mp4", info.ContentType)
+	assert.NotEmpty(t, info.ETag)
+}
+
+func TestDownloadFileRange(t *testing.T) {
+	storage, _ := setUp(t)
+	file := strings.NewReader("video content")
+	_, _ = storage.UploadFile(context.Background(), file, file.Size(), "video/mp4", "videos_input/user_video.mp4")
+
+	reader, info, err := storage.DownloadFileRange(context.Background(), "videos_input/user_video.mp4", 6, 3)
+	assert.NoError(t, err)
+	defer reader.Close()
+
+	content, _ := io.ReadAll(reader)
+	assert.Equal(t, "con", string(content))
+	assert.Equal(t, int64(13), info.Size)
+
+	_, _, err = storage.DownloadFileRange(context.Background(), "videos_input/user_video.mp4", 13, 0)
+	assert.ErrorIs(t, err, core.ErrInvalidRange)
+}
+
+func TestStatFileAndFileExists(t *testing.T) {
+	storage, _ := setUp(t)
+	file := strings.NewReader("video content")
+	_, _ = storage.UploadFile(context.Background(), file, file.Size(), "video/mp4", "videos_input/user_video.mp4")
+
+	info, err := storage.StatFile(context.Background(), "videos_input/user_video.mp4")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(13), info.Size)
+
+	exists, err := storage.FileExists(context.Background(), "videos_input/user_video.mp4")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = storage.FileExists(context.Background(), "videos_input/missing.mp4")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestUploadFile_EmitsObjectCreatedEvent(t *testing.T) {
+	storage, events := setUp(t)
+	file := strings.NewReader("video content")
+
+	_, err := storage.UploadFile(context.Background(), file, file.Size(), "video/mp4", "videos_input/user_video.mp4")
+	assert.NoError(t, err)
+
+	messages, _ := events.ReceiveMessages("s3-events", 1, 0)
+	assert.Len(t, messages, 1)
+
+	var event bucket.S3Event
+	assert.NoError(t, json.Unmarshal([]byte(messages[0].Body), &event))
+	assert.Equal(t, "ObjectCreated:Put", event.Records[0].EventName)
+	assert.Equal(t, "frameshot", event.Records[0].S3.Bucket.Name)
+	assert.Equal(t, "videos_input/user_video.mp4", event.Records[0].S3.Object.Key)
+	assert.Equal(t, int64(13), event.Records[0].S3.Object.Size)
+}
+
+func TestUploadFile_RejectsPathTraversal(t *testing.T) {
+	storage, _ := setUp(t)
+	file := strings.NewReader("video content")
+
+	_, err := storage.UploadFile(context.Background(), file, file.Size(), "video/mp4", "../outside.mp4")
+
+	assert.ErrorIs(t, err, core.ErrForbidden)
+}
+
+func TestDownloadFile_NotFound(t *testing.T) {
+	storage, _ := setUp(t)
+
+	reader, info, err := storage.DownloadFile(context.Background(), "zip_output/missing.zip")
+
+	assert.Nil(t, reader)
+	assert.Nil(t, info)
+	assert.ErrorIs(t, err, core.ErrDataNotFound)
+}
+
+func TestDeleteAndListFiles(t *testing.T) {
+	storage, _ := setUp(t)
+	ctx := context.Background()
+	_, _ = storage.UploadFile(ctx, strings.NewReader("a"), 1, "video/mp4", "videos_input/a.mp4")
+	_, _ = storage.UploadFile(ctx, strings.NewReader("b"), 1, "video/mp4", "videos_input/b.mp4")
+	_, _ = storage.UploadFile(ctx, strings.NewReader("c"), 1, "application/zip", "zip_output/c.zip")
+
+	files, err := storage.ListFiles(ctx, "videos_input/")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"videos_input/a.mp4", "videos_input/b.mp4"}, fileKeys(files))
+
+	assert.NoError(t, storage.DeleteFile(ctx, "videos_input/a.mp4"))
+	assert.ErrorIs(t, storage.DeleteFile(ctx, "videos_input/a.mp4"), core.ErrDataNotFound)
+
+	files, _ = storage.ListFiles(ctx, "videos_input/")
+	assert.Equal(t, []string{"videos_input/b.mp4"}, fileKeys(files))
+}
+
+func fileKeys(files []entity.FileInfo) []string {
+	var keys []string
+	for _, file := range files {
+		keys = append(keys, file.Key)
+	}
+	return keys
+}
+
+func TestGetFileUrl_IsSigned(t *testing.T) {
+	storage, _ := setUp(t)
+
+	fileUrl := storage.GetFileUrl("zip_output/file.zip")
+	parsed, err := url.Parse(fileUrl)
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(fileUrl, "http://localhost:8080/files/zip_output/file.zip?"))
+
+	expires := parsed.Query().Get("expires")
+	signature := parsed.Query().Get("signature")
+	assert.NoError(t, storage.VerifyFileUrl("zip_output/file.zip", expires, signature))
+	assert.ErrorIs(t, storage.VerifyFileUrl("zip_output/other.zip", expires, signature), core.ErrForbidden)
+	assert.ErrorIs(t, storage.VerifyFileUrl("zip_output/file.zip", "1", signature), core.ErrForbidden)
+}
@@ -70,7 +70,9 @@ func (db *DB) Migrate() error {
 // ErrorCode returns the error code of the given error
 func (db *DB) ErrorCode(err error) string {
 	var pgErr *pgconn.PgError
-	errors.As(err, &pgErr)
+	if !errors.As(err, &pgErr) {
+		return ""
+	}
 	return pgErr.Code
 }
 
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS "requests_status_started_at_idx";
+
+ALTER TABLE "requests"
+    DROP COLUMN IF EXISTS "started_at",
+    DROP COLUMN IF EXISTS "attempts",
+    DROP COLUMN IF EXISTS "failure_reason";
\ No newline at end of file
@@ -0,0 +1,6 @@
+ALTER TABLE "requests"
+    ADD COLUMN "started_at" timestamp,
+    ADD COLUMN "attempts" int NOT NULL DEFAULT 0,
+    ADD COLUMN "failure_reason" varchar;
+
+CREATE INDEX "requests_status_started_at_idx" ON "requests" ("status", "started_at");
@@ -0,0 +1,10 @@
+CREATE TABLE "queue_messages" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "queue" varchar NOT NULL,
+    "body" text NOT NULL,
+    "receive_count" int NOT NULL DEFAULT 0,
+    "visible_at" timestamp NOT NULL DEFAULT (now()),
+    "created_at" timestamp NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX "queue_messages_queue_visible_at_idx" ON "queue_messages" ("queue", "visible_at");
@@ -0,0 +1,6 @@
+ALTER TABLE "requests"
+    DROP COLUMN IF EXISTS "video_format",
+    DROP COLUMN IF EXISTS "video_duration_ms",
+    DROP COLUMN IF EXISTS "video_width",
+    DROP COLUMN IF EXISTS "video_height",
+    DROP COLUMN IF EXISTS "video_codec";
\ No newline at end of file
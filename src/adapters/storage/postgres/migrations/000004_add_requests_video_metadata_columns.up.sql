@@ -0,0 +1,6 @@
+ALTER TABLE "requests"
+    ADD COLUMN "video_format" varchar,
+    ADD COLUMN "video_duration_ms" bigint,
+    ADD COLUMN "video_width" int,
+    ADD COLUMN "video_height" int,
+    ADD COLUMN "video_codec" varchar;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS "requests_content_hash_status_idx";
+
+ALTER TABLE "requests"
+    DROP COLUMN IF EXISTS "organization_id",
+    DROP COLUMN IF EXISTS "content_hash",
+    DROP COLUMN IF EXISTS "frame_interval_ms",
+    DROP COLUMN IF EXISTS "duplicate_of";
\ No newline at end of file
@@ -0,0 +1,7 @@
+ALTER TABLE "requests"
+    ADD COLUMN "organization_id" varchar,
+    ADD COLUMN "content_hash" varchar(64),
+    ADD COLUMN "frame_interval_ms" int NOT NULL DEFAULT 1000,
+    ADD COLUMN "duplicate_of" bigint REFERENCES "requests" ("id");
+
+CREATE INDEX "requests_content_hash_status_idx" ON "requests" ("content_hash", "status");
@@ -0,0 +1,15 @@
+CREATE TABLE "api_keys" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "user_id" varchar NOT NULL,
+    "user_email" varchar NOT NULL,
+    "name" varchar NOT NULL,
+    "prefix" varchar NOT NULL,
+    "key_hash" varchar(64) NOT NULL UNIQUE,
+    "scopes" text[] NOT NULL,
+    "created_at" timestamp NOT NULL DEFAULT (now()),
+    "last_used_at" timestamp,
+    "expires_at" timestamp,
+    "revoked_at" timestamp
+);
+
+CREATE INDEX "api_keys_user_id_idx" ON "api_keys" ("user_id");
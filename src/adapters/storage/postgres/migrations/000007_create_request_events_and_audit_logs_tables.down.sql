@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "requests_status_created_at_idx";
+
+DROP TABLE IF EXISTS "audit_logs";
+
+DROP TABLE IF EXISTS "request_events";
\ No newline at end of file
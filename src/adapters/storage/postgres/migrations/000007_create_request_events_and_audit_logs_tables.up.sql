@@ -0,0 +1,24 @@
+CREATE TABLE "request_events" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "request_id" bigint NOT NULL REFERENCES "requests" ("id") ON DELETE CASCADE,
+    "status" varchar NOT NULL,
+    "message" varchar NOT NULL,
+    "actor" varchar NOT NULL,
+    "created_at" timestamp NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX "request_events_request_id_idx" ON "request_events" ("request_id", "created_at");
+
+CREATE TABLE "audit_logs" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "actor_id" varchar NOT NULL,
+    "actor_email" varchar NOT NULL,
+    "action" varchar NOT NULL,
+    "target_id" varchar,
+    "details" jsonb NOT NULL DEFAULT '{}',
+    "created_at" timestamp NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX "audit_logs_created_at_idx" ON "audit_logs" ("created_at");
+
+CREATE INDEX "requests_status_created_at_idx" ON "requests" ("status", "created_at");
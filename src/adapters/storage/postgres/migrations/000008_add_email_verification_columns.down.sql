@@ -0,0 +1,3 @@
+ALTER TABLE "requests"
+    DROP COLUMN IF EXISTS "user_email_verified",
+    DROP COLUMN IF EXISTS "notification_status";
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- The requests created before the policy keep receiving their notifications,
+-- and whether their notification was delivered is unknown (NULL)
+ALTER TABLE "requests"
+    ADD COLUMN "user_email_verified" boolean NOT NULL DEFAULT true,
+    ADD COLUMN "notification_status" varchar;
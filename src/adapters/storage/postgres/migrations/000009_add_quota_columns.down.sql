@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "requests_user_id_created_at_idx";
+
+ALTER TABLE "requests"
+    DROP COLUMN IF EXISTS "plan";
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- The plan the user had when the request was created, NULL is the default plan
+ALTER TABLE "requests"
+    ADD COLUMN "plan" varchar;
+
+-- The usage of the quotas is counted over the recent requests of the user
+CREATE INDEX "requests_user_id_created_at_idx" ON "requests" ("user_id", "created_at");
@@ -0,0 +1,8 @@
+-- The buckets only matter for a few minutes, so they are not written to the WAL
+-- and a crash resetting the limits is acceptable
+CREATE UNLOGGED TABLE "rate_limit_buckets" (
+    "key" varchar PRIMARY KEY,
+    "full_at" timestamptz NOT NULL
+);
+
+CREATE INDEX "rate_limit_buckets_full_at_idx" ON "rate_limit_buckets" ("full_at");
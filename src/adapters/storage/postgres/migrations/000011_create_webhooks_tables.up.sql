@@ -0,0 +1,29 @@
+CREATE TABLE "webhooks" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "user_id" varchar NOT NULL,
+    "url" varchar NOT NULL,
+    "secret" varchar NOT NULL,
+    "events" text[] NOT NULL,
+    "active" boolean NOT NULL DEFAULT true,
+    "created_at" timestamp NOT NULL DEFAULT (now()),
+    "updated_at" timestamp NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX "webhooks_user_id_idx" ON "webhooks" ("user_id");
+
+CREATE TABLE "webhook_deliveries" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "webhook_id" bigint NOT NULL REFERENCES "webhooks" ("id") ON DELETE CASCADE,
+    "event" varchar NOT NULL,
+    "payload" jsonb NOT NULL,
+    "status" varchar NOT NULL,
+    "attempts" integer NOT NULL DEFAULT 0,
+    "response_code" integer,
+    "error" varchar,
+    "next_attempt_at" timestamp NOT NULL DEFAULT (now()),
+    "last_attempt_at" timestamp,
+    "created_at" timestamp NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX "webhook_deliveries_webhook_id_idx" ON "webhook_deliveries" ("webhook_id", "created_at");
+CREATE INDEX "webhook_deliveries_status_next_attempt_at_idx" ON "webhook_deliveries" ("status", "next_attempt_at");
@@ -0,0 +1,5 @@
+ALTER TABLE "requests"
+    DROP COLUMN IF EXISTS "progress_updated_at",
+    DROP COLUMN IF EXISTS "progress_eta",
+    DROP COLUMN IF EXISTS "frames_extracted",
+    DROP COLUMN IF EXISTS "progress_percent";
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- The last progress reported by the worker, NULL until the first report
+ALTER TABLE "requests"
+    ADD COLUMN "progress_percent" real,
+    ADD COLUMN "frames_extracted" integer,
+    ADD COLUMN "progress_eta" timestamp,
+    ADD COLUMN "progress_updated_at" timestamp;
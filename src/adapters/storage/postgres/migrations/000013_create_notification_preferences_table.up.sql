@@ -0,0 +1,7 @@
+CREATE TABLE "notification_preferences" (
+    "user_id" varchar PRIMARY KEY,
+    "channels" text[] NOT NULL DEFAULT '{email}',
+    "slack_webhook_url" varchar,
+    "teams_webhook_url" varchar,
+    "updated_at" timestamp NOT NULL DEFAULT (now())
+);
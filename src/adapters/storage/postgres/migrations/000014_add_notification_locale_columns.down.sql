@@ -0,0 +1,5 @@
+ALTER TABLE "notification_preferences"
+    DROP COLUMN IF EXISTS "locale";
+
+ALTER TABLE "requests"
+    DROP COLUMN IF EXISTS "user_locale";
\ No newline at end of file
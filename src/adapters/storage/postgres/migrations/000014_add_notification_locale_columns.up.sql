@@ -0,0 +1,6 @@
+-- The locale of the token of the user when the request was created, NULL when not informed
+ALTER TABLE "requests"
+    ADD COLUMN "user_locale" varchar;
+
+ALTER TABLE "notification_preferences"
+    ADD COLUMN "locale" varchar;
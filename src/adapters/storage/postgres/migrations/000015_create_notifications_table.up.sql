@@ -0,0 +1,22 @@
+CREATE TABLE "notifications" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "request_id" bigint NOT NULL REFERENCES "requests" ("id") ON DELETE CASCADE,
+    "user_id" varchar NOT NULL,
+    "kind" varchar NOT NULL,
+    "channel" varchar NOT NULL,
+    "recipient" varchar NOT NULL,
+    "locale" varchar NOT NULL,
+    "subject" varchar NOT NULL,
+    "text" text NOT NULL,
+    "html" text NOT NULL,
+    "status" varchar NOT NULL,
+    "attempts" integer NOT NULL DEFAULT 0,
+    "provider_response" varchar,
+    "error" varchar,
+    "next_attempt_at" timestamp NOT NULL DEFAULT (now()),
+    "last_attempt_at" timestamp,
+    "created_at" timestamp NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX "notifications_request_id_idx" ON "notifications" ("request_id");
+CREATE INDEX "notifications_status_next_attempt_at_idx" ON "notifications" ("status", "next_attempt_at");
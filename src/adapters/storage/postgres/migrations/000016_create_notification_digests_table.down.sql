@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "notification_digests";
+
+ALTER TABLE "notification_preferences"
+    DROP COLUMN IF EXISTS "digest";
\ No newline at end of file
@@ -0,0 +1,14 @@
+-- How often the user is notified of the results of the requests: immediate, daily or weekly
+ALTER TABLE "notification_preferences"
+    ADD COLUMN "digest" varchar NOT NULL DEFAULT 'immediate';
+
+-- The digests claimed by the scheduler, so each period is sent once even across restarts
+CREATE TABLE "notification_digests" (
+    "user_id" varchar NOT NULL,
+    "mode" varchar NOT NULL,
+    "period_start" timestamp NOT NULL,
+    "period_end" timestamp NOT NULL,
+    "requests" integer NOT NULL,
+    "created_at" timestamp NOT NULL DEFAULT (now()),
+    PRIMARY KEY ("user_id", "mode", "period_start")
+);
@@ -0,0 +1,15 @@
+-- The users as last seen on their tokens, the API keys follow the email, the organization and the plan of their owner
+CREATE TABLE "users" (
+    "id" varchar PRIMARY KEY,
+    "email" varchar NOT NULL,
+    "email_verified" boolean NOT NULL,
+    "organization_id" varchar,
+    "plan" varchar,
+    "updated_at" timestamp NOT NULL DEFAULT (now())
+);
+
+-- The owners of the keys created before the policy keep using them until they authenticate with a token again
+INSERT INTO "users" ("id", "email", "email_verified")
+SELECT DISTINCT ON ("user_id") "user_id", "user_email", true
+FROM "api_keys"
+ORDER BY "user_id", "created_at" DESC;
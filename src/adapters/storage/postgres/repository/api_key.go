@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"example/web-service-gin/src/adapters/storage/postgres"
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+// PGApiKeyRepository implements port.ApiKeyRepository interface
+// and provides access to the postgres database
+type PGApiKeyRepository struct {
+	db *postgres.DB
+}
+
+// NewPGApiKeyRepository creates a new API key storage instance for postgres
+func NewPGApiKeyRepository(db *postgres.DB) *PGApiKeyRepository {
+	return &PGApiKeyRepository{
+		db,
+	}
+}
+
+// CreateApiKey creates a new API key register in the database
+func (repository *PGApiKeyRepository) CreateApiKey(ctx context.Context, key *entity.ApiKey) (*entity.ApiKey, error) {
+	query := repository.db.QueryBuilder.Insert("api_keys").
+		Columns("user_id", "user_email", "name", "prefix", "key_hash", "scopes", "created_at", "expires_at").
+		Values(key.UserId, key.UserEmail, key.Name, key.Prefix, key.KeyHash, key.Scopes, key.CreatedAt, nullableTime(key.ExpiresAt)).
+		Suffix(ReturnSuffix)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	row := repository.db.QueryRow(ctx, sql, args...)
+	key, err = mapRowToApiKey(row)
+
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
+
+	return key, nil
+}
+
+// GetByKeyHash returns the key that is not revoked with the informed hash and the current email, organization
+// and plan of its owner. The owners never seen on a token keep the email of the key, not verified, on the default plan
+func (repository *PGApiKeyRepository) GetByKeyHash(ctx context.Context, keyHash string) (*entity.ApiKey, error) {
+	query := repository.db.QueryBuilder.Select("api_keys.*", "coalesce(users.email, api_keys.user_email)", "coalesce(users.email_verified, false)",
+		"users.organization_id", "users.plan").
+		From("api_keys").
+		LeftJoin("users ON users.id = api_keys.user_id").
+		Where(sq.Eq{"key_hash": keyHash, "revoked_at": nil}).
+		Limit(1)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var model ApiKeyModel
+	err = repository.db.QueryRow(ctx, sql, args...).Scan(append(apiKeyFields(&model), &model.OwnerEmail, &model.EmailVerified, &model.OrganizationId, &model.Plan)...)
+
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
+
+	key := modelToApiKey(model)
+	key.UserEmail = model.OwnerEmail
+	return key, nil
+}
+
+// SaveOwner records the email with its verification, the organization and the plan informed by the token of the user
+func (repository *PGApiKeyRepository) SaveOwner(ctx context.Context, user *entity.User) error {
+	query := repository.db.QueryBuilder.Insert("users").
+		Columns("id", "email", "email_verified", "organization_id", "plan", "updated_at").
+		Values(user.Id, user.Email, user.EmailVerified, nullableString(user.OrganizationId), nullableString(user.Plan), sq.Expr("now()")).
+		Suffix("ON CONFLICT (id) DO UPDATE SET email = excluded.email, email_verified = excluded.email_verified, " +
+			"organization_id = excluded.organization_id, plan = excluded.plan, updated_at = excluded.updated_at")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = repository.db.Exec(ctx, sql, args...)
+	return mapError(repository.db, err)
+}
+
+// GetAllUserApiKeys returns the keys of the user that are not revoked
+func (repository *PGApiKeyRepository) GetAllUserApiKeys(ctx context.Context, userId string) ([]entity.ApiKey, error) {
+	query := repository.db.QueryBuilder.Select("*").
+		From("api_keys").
+		Where(sq.Eq{"user_id": userId, "revoked_at": nil}).
+		OrderBy("created_at")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repository.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
+
+	defer rows.Close()
+
+	var keys []entity.ApiKey
+	for rows.Next() {
+		key, err := mapRowToApiKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+
+	return keys, rows.Err()
+}
+
+// RevokeApiKey revokes a key of the user, the revoked keys are kept to inform when they were used
+func (repository *PGApiKeyRepository) RevokeApiKey(ctx context.Context, id uint64, userId string) error {
+	query := repository.db.QueryBuilder.Update("api_keys").
+		Set("revoked_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": id, "user_id": userId, "revoked_at": nil})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := repository.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return mapError(repository.db, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return core.ErrDataNotFound
+	}
+
+	return nil
+}
+
+// UpdateLastUsed registers the moment the key was used
+func (repository *PGApiKeyRepository) UpdateLastUsed(ctx context.Context, id uint64, usedAt time.Time) error {
+	query := repository.db.QueryBuilder.Update("api_keys").
+		Set("last_used_at", usedAt).
+		Where(sq.Eq{"id": id})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = repository.db.Exec(ctx, sql, args...)
+	return mapError(repository.db, err)
+}
+
+// Map a row of database data to domain entity ApiKey model
+func mapRowToApiKey(row pgx.Row) (*entity.ApiKey, error) {
+	var model ApiKeyModel
+
+	err := row.Scan(apiKeyFields(&model)...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return modelToApiKey(model), nil
+}
+
+// apiKeyFields returns the destinations of the columns of the api_keys table
+func apiKeyFields(model *ApiKeyModel) []any {
+	return []any{
+		&model.ID,
+		&model.UserId,
+		&model.UserEmail,
+		&model.Name,
+		&model.Prefix,
+		&model.KeyHash,
+		&model.Scopes,
+		&model.CreatedAt,
+		&model.LastUsedAt,
+		&model.ExpiresAt,
+		&model.RevokedAt,
+	}
+}
+
+func modelToApiKey(model ApiKeyModel) *entity.ApiKey {
+	return &entity.ApiKey{
+		ID:             model.ID,
+		UserId:         model.UserId,
+		UserEmail:      model.UserEmail,
+		EmailVerified:  model.EmailVerified,
+		OrganizationId: model.OrganizationId.String,
+		Plan:           model.Plan.String,
+		Name:           model.Name,
+		Prefix:         model.Prefix,
+		KeyHash:        model.KeyHash,
+		Scopes:         model.Scopes,
+		CreatedAt:      model.CreatedAt,
+		LastUsedAt:     model.LastUsedAt.Time,
+		ExpiresAt:      model.ExpiresAt.Time,
+		RevokedAt:      model.RevokedAt.Time,
+	}
+}
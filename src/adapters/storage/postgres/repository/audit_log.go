@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"example/web-service-gin/src/adapters/storage/postgres"
+	"example/web-service-gin/src/core/entity"
+)
+
+// PGAuditLogRepository implements port.AuditLogRepository interface
+// and provides access to the postgres database
+type PGAuditLogRepository struct {
+	db *postgres.DB
+}
+
+// NewPGAuditLogRepository creates a new audit log storage instance for postgres
+func NewPGAuditLogRepository(db *postgres.DB) *PGAuditLogRepository {
+	return &PGAuditLogRepository{
+		db,
+	}
+}
+
+// AddAuditLog records an action of an administrator
+func (repository *PGAuditLogRepository) AddAuditLog(ctx context.Context, log *entity.AuditLog) error {
+	details, err := json.Marshal(log.Details)
+	if err != nil {
+		return err
+	}
+
+	query := repository.db.QueryBuilder.Insert("audit_logs").
+		Columns("actor_id", "actor_email", "action", "target_id", "details", "created_at").
+		Values(log.ActorId, log.ActorEmail, log.Action, nullableString(log.TargetId), details, log.CreatedAt)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = repository.db.Exec(ctx, sql, args...)
+	return mapError(repository.db, err)
+}
+
+// GetAuditLogs returns the recorded actions, the newest first
+func (repository *PGAuditLogRepository) GetAuditLogs(ctx context.Context, limit uint64, offset uint64) ([]entity.AuditLog, error) {
+	query := repository.db.QueryBuilder.Select("*").
+		From("audit_logs").
+		OrderBy("created_at DESC", "id DESC").
+		Limit(limit).
+		Offset(offset)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repository.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
+
+	defer rows.Close()
+
+	var logs []entity.AuditLog
+	for rows.Next() {
+		var log entity.AuditLog
+		var targetId *string
+
+		err := rows.Scan(&log.ID, &log.ActorId, &log.ActorEmail, &log.Action, &targetId, &log.Details, &log.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if targetId != nil {
+			log.TargetId = *targetId
+		}
+		logs = append(logs, log)
+	}
+
+	return logs, rows.Err()
+}
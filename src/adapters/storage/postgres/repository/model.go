@@ -6,13 +6,116 @@ import (
 )
 
 type RequestModel struct {
-	ID           uint64
-	UserId       string
-	UserEmail    string
-	VideoSize    int64
-	VideoKey     string
-	ZipOutputKey sql.NullString
-	Status       string
-	CreatedAt    time.Time
-	FinishedAt   sql.NullTime
+	ID                 uint64
+	UserId             string
+	UserEmail          string
+	VideoSize          int64
+	VideoKey           string
+	ZipOutputKey       sql.NullString
+	Status             string
+	CreatedAt          time.Time
+	FinishedAt         sql.NullTime
+	StartedAt          sql.NullTime
+	Attempts           int
+	FailureReason      sql.NullString
+	VideoFormat        sql.NullString
+	VideoDuration      sql.NullInt64
+	VideoWidth         sql.NullInt32
+	VideoHeight        sql.NullInt32
+	VideoCodec         sql.NullString
+	OrganizationId     sql.NullString
+	ContentHash        sql.NullString
+	FrameInterval      int64
+	DuplicateOf        sql.NullInt64
+	UserEmailVerified  bool
+	NotificationStatus sql.NullString
+	Plan               sql.NullString
+	ProgressPercent    sql.NullFloat64
+	FramesExtracted    sql.NullInt32
+	ProgressEta        sql.NullTime
+	ProgressUpdatedAt  sql.NullTime
+	UserLocale         sql.NullString
+}
+
+// nullableTime maps a zero time to a NULL column value
+func nullableTime(value time.Time) sql.NullTime {
+	return sql.NullTime{Time: value, Valid: !value.IsZero()}
+}
+
+// nullableString maps an empty string to a NULL column value
+func nullableString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+// nullableFloat maps a zero number to a NULL column value
+func nullableFloat(value float64) sql.NullFloat64 {
+	return sql.NullFloat64{Float64: value, Valid: value != 0}
+}
+
+// nullableInt maps a zero number to a NULL column value
+func nullableInt(value int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: value, Valid: value != 0}
+}
+
+type ApiKeyModel struct {
+	ID         uint64
+	UserId     string
+	UserEmail  string
+	Name       string
+	Prefix     string
+	KeyHash    string
+	Scopes     []string
+	CreatedAt  time.Time
+	LastUsedAt sql.NullTime
+	ExpiresAt  sql.NullTime
+	RevokedAt  sql.NullTime
+	// The owner is read from the users table
+	OwnerEmail     string
+	EmailVerified  bool
+	OrganizationId sql.NullString
+	Plan           sql.NullString
+}
+
+type WebhookDeliveryModel struct {
+	ID            uint64
+	WebhookId     uint64
+	Event         string
+	Payload       []byte
+	Status        string
+	Attempts      int
+	ResponseCode  sql.NullInt32
+	Error         sql.NullString
+	NextAttemptAt time.Time
+	LastAttemptAt sql.NullTime
+	CreatedAt     time.Time
+}
+
+type NotificationPreferencesModel struct {
+	UserId          string
+	Channels        []string
+	SlackWebhookUrl sql.NullString
+	TeamsWebhookUrl sql.NullString
+	UpdatedAt       time.Time
+	Locale          sql.NullString
+	Digest          string
+}
+
+type NotificationDeliveryModel struct {
+	ID               uint64
+	RequestId        uint64
+	UserId           string
+	Kind             string
+	Channel          string
+	Recipient        string
+	Locale           string
+	Subject          string
+	Text             string
+	Html             string
+	Status           string
+	Attempts         int
+	ProviderResponse sql.NullString
+	Error            sql.NullString
+	NextAttemptAt    time.Time
+	LastAttemptAt    sql.NullTime
+	CreatedAt        time.Time
 }
@@ -0,0 +1,330 @@
+package repository
+
+import (
+	"context"
+	"example/web-service-gin/src/adapters/storage/postgres"
+	"example/web-service-gin/src/core/entity"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+// upsertPreferencesSuffix replaces the preferences when the user already has them
+const upsertPreferencesSuffix = `ON CONFLICT (user_id) DO UPDATE SET
+    channels = EXCLUDED.channels,
+    slack_webhook_url = EXCLUDED.slack_webhook_url,
+    teams_webhook_url = EXCLUDED.teams_webhook_url,
+    locale = EXCLUDED.locale,
+    digest = EXCLUDED.digest,
+    updated_at = EXCLUDED.updated_at
+` + ReturnSuffix
+
+// claimNotificationsQuery locks the due pending deliveries, skipping the ones already locked
+// by other instances, and postpones them by the lease while they are sent
+const claimNotificationsQuery = `
+UPDATE notifications
+SET next_attempt_at = now() + make_interval(secs => $1)
+WHERE id IN (
+    SELECT id FROM notifications
+    WHERE status = $2 AND next_attempt_at <= now()
+    ORDER BY next_attempt_at
+    LIMIT $3
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *`
+
+// PGNotificationRepository implements port.NotificationRepository interface
+// and provides access to the postgres database
+type PGNotificationRepository struct {
+	db *postgres.DB
+}
+
+// NewPGNotificationRepository creates a new notification storage instance for postgres
+func NewPGNotificationRepository(db *postgres.DB) *PGNotificationRepository {
+	return &PGNotificationRepository{
+		db,
+	}
+}
+
+// GetPreferences returns the notification preferences of the user
+func (repository *PGNotificationRepository) GetPreferences(ctx context.Context, userId string) (*entity.NotificationPreferences, error) {
+	query := repository.db.QueryBuilder.Select("*").
+		From("notification_preferences").
+		Where(sq.Eq{"user_id": userId}).
+		Limit(1)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	preferences, err := mapRowToPreferences(repository.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
+
+	return preferences, nil
+}
+
+// SavePreferences creates or replaces the notification preferences of the user
+func (repository *PGNotificationRepository) SavePreferences(ctx context.Context, preferences *entity.NotificationPreferences) (*entity.NotificationPreferences, error) {
+	channels := make([]string, len(preferences.Channels))
+	for i, channel := range preferences.Channels {
+		channels[i] = string(channel)
+	}
+
+	query := repository.db.QueryBuilder.Insert("notification_preferences").
+		Columns("user_id", "channels", "slack_webhook_url", "teams_webhook_url", "updated_at", "locale", "digest").
+		Values(preferences.UserId, channels, nullableString(preferences.SlackWebhookUrl),
+			nullableString(preferences.TeamsWebhookUrl), preferences.UpdatedAt, nullableString(preferences.Locale),
+			preferences.Digest).
+		Suffix(upsertPreferencesSuffix)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	preferences, err = mapRowToPreferences(repository.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
+
+	return preferences, nil
+}
+
+// CreateDelivery creates a new notification delivery register in the database
+func (repository *PGNotificationRepository) CreateDelivery(ctx context.Context, delivery *entity.NotificationDelivery) (*entity.NotificationDelivery, error) {
+	query := repository.db.QueryBuilder.Insert("notifications").
+		Columns("request_id", "user_id", "kind", "channel", "recipient", "locale", "subject", "text", "html",
+			"status", "next_attempt_at", "created_at").
+		Values(delivery.RequestId, delivery.UserId, delivery.Kind, delivery.Channel, delivery.Recipient, delivery.Locale,
+			delivery.Subject, delivery.Text, delivery.Html, delivery.Status, delivery.NextAttemptAt, delivery.CreatedAt).
+		Suffix(ReturnSuffix)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	delivery, err = mapRowToNotificationDelivery(repository.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
+
+	return delivery, nil
+}
+
+// GetDelivery returns a notification delivery
+func (repository *PGNotificationRepository) GetDelivery(ctx context.Context, id uint64) (*entity.NotificationDelivery, error) {
+	query := repository.db.QueryBuilder.Select("*").
+		From("notifications").
+		Where(sq.Eq{"id": id}).
+		Limit(1)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	delivery, err := mapRowToNotificationDelivery(repository.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
+
+	return delivery, nil
+}
+
+// SearchDeliveries returns a page of the notification deliveries matching the filter, the newest first
+func (repository *PGNotificationRepository) SearchDeliveries(ctx context.Context, filter entity.NotificationFilter) ([]entity.NotificationDelivery, error) {
+	query := repository.db.QueryBuilder.Select("*").
+		From("notifications").
+		OrderBy("created_at DESC", "id DESC").
+		Limit(filter.Limit).
+		Offset(filter.Offset)
+
+	if filter.RequestId != 0 {
+		query = query.Where(sq.Eq{"request_id": filter.RequestId})
+	}
+
+	if filter.Status != "" {
+		query = query.Where(sq.Eq{"status": filter.Status})
+	}
+
+	return repository.getDeliveries(ctx, query)
+}
+
+// GetRequestDeliveries returns all the notification deliveries of the request, the oldest first
+func (repository *PGNotificationRepository) GetRequestDeliveries(ctx context.Context, requestId uint64) ([]entity.NotificationDelivery, error) {
+	query := repository.db.QueryBuilder.Select("*").
+		From("notifications").
+		Where(sq.Eq{"request_id": requestId}).
+		OrderBy("created_at", "id")
+
+	return repository.getDeliveries(ctx, query)
+}
+
+func (repository *PGNotificationRepository) getDeliveries(ctx context.Context, query sq.SelectBuilder) ([]entity.NotificationDelivery, error) {
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repository.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
+
+	defer rows.Close()
+	return mapRowListToNotificationDeliveries(rows)
+}
+
+// ClaimDueDeliveries returns the pending notification deliveries whose attempt is due, postponing them by the lease
+func (repository *PGNotificationRepository) ClaimDueDeliveries(ctx context.Context, limit uint64, lease time.Duration) ([]entity.NotificationDelivery, error) {
+	rows, err := repository.db.Query(ctx, claimNotificationsQuery, lease.Seconds(), entity.DeliveryPending, limit)
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
+
+	defer rows.Close()
+	return mapRowListToNotificationDeliveries(rows)
+}
+
+// UpdateDelivery records the result of an attempt of the notification delivery
+func (repository *PGNotificationRepository) UpdateDelivery(ctx context.Context, delivery *entity.NotificationDelivery) error {
+	query := repository.db.QueryBuilder.Update("notifications").
+		SetMap(map[string]interface{}{
+			"status":            delivery.Status,
+			"attempts":          delivery.Attempts,
+			"provider_response": nullableString(delivery.ProviderResponse),
+			"error":             nullableString(delivery.Error),
+			"next_attempt_at":   delivery.NextAttemptAt,
+			"last_attempt_at":   nullableTime(delivery.LastAttemptAt),
+		}).
+		Where(sq.Eq{"id": delivery.ID})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = repository.db.Exec(ctx, sql, args...)
+	return mapError(repository.db, err)
+}
+
+// ClaimDigest records the digest of the user for its period, the ones already recorded are left untouched
+func (repository *PGNotificationRepository) ClaimDigest(ctx context.Context, digest *entity.Digest) (bool, error) {
+	query := repository.db.QueryBuilder.Insert("notification_digests").
+		Columns("user_id", "mode", "period_start", "period_end", "requests", "created_at").
+		Values(digest.UserId, digest.Mode, digest.PeriodStart, digest.PeriodEnd, len(digest.Requests), time.Now()).
+		Suffix("ON CONFLICT DO NOTHING")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := repository.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, mapError(repository.db, err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// Map a row of database data to domain entity NotificationPreferences model
+func mapRowToPreferences(row pgx.Row) (*entity.NotificationPreferences, error) {
+	var model NotificationPreferencesModel
+
+	err := row.Scan(
+		&model.UserId,
+		&model.Channels,
+		&model.SlackWebhookUrl,
+		&model.TeamsWebhookUrl,
+		&model.UpdatedAt,
+		&model.Locale,
+		&model.Digest,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	channels := make([]entity.NotificationChannel, len(model.Channels))
+	for i, channel := range model.Channels {
+		channels[i] = entity.NotificationChannel(channel)
+	}
+
+	return &entity.NotificationPreferences{
+		UserId:          model.UserId,
+		Channels:        channels,
+		SlackWebhookUrl: model.SlackWebhookUrl.String,
+		TeamsWebhookUrl: model.TeamsWebhookUrl.String,
+		Locale:          model.Locale.String,
+		Digest:          entity.DigestMode(model.Digest),
+		UpdatedAt:       model.UpdatedAt,
+	}, nil
+}
+
+// Map a row of database data to domain entity NotificationDelivery model
+func mapRowToNotificationDelivery(row pgx.Row) (*entity.NotificationDelivery, error) {
+	var model NotificationDeliveryModel
+
+	err := row.Scan(
+		&model.ID,
+		&model.RequestId,
+		&model.UserId,
+		&model.Kind,
+		&model.Channel,
+		&model.Recipient,
+		&model.Locale,
+		&model.Subject,
+		&model.Text,
+		&model.Html,
+		&model.Status,
+		&model.Attempts,
+		&model.ProviderResponse,
+		&model.Error,
+		&model.NextAttemptAt,
+		&model.LastAttemptAt,
+		&model.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.NotificationDelivery{
+		ID:               model.ID,
+		RequestId:        model.RequestId,
+		UserId:           model.UserId,
+		Kind:             entity.NotificationKind(model.Kind),
+		Channel:          entity.NotificationChannel(model.Channel),
+		Recipient:        model.Recipient,
+		Locale:           model.Locale,
+		Subject:          model.Subject,
+		Text:             model.Text,
+		Html:             model.Html,
+		Status:           entity.DeliveryStatus(model.Status),
+		Attempts:         model.Attempts,
+		ProviderResponse: model.ProviderResponse.String,
+		Error:            model.Error.String,
+		NextAttemptAt:    model.NextAttemptAt,
+		LastAttemptAt:    model.LastAttemptAt.Time,
+		CreatedAt:        model.CreatedAt,
+	}, nil
+}
+
+// Map the rows (list) to domain entity NotificationDelivery model
+func mapRowListToNotificationDeliveries(rows pgx.Rows) ([]entity.NotificationDelivery, error) {
+	var deliveries []entity.NotificationDelivery
+	for rows.Next() {
+		delivery, err := mapRowToNotificationDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+
+	return deliveries, rows.Err()
+}
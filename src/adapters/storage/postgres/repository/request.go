@@ -2,11 +2,13 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"example/web-service-gin/src/adapters/storage/postgres"
 	"example/web-service-gin/src/core"
 	"example/web-service-gin/src/core/entity"
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
+	"time"
 )
 
 const ReturnSuffix = "RETURNING *"
@@ -24,12 +26,66 @@ func NewPGRequestRepository(db *postgres.DB) *PGRequestRepository {
 	}
 }
 
+// queryRower runs the queries on the pool or on a transaction
+type queryRower interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
 // CreateRequest creates a new request register in the database
 func (repository *PGRequestRepository) CreateRequest(ctx context.Context, request *entity.Request) (*entity.Request, error) {
+	return repository.createRequest(ctx, repository.db, request)
+}
+
+// CreateRequestWithinQuota creates the request on a transaction holding a lock of the user, so the
+// usage informed to check counts the requests created concurrently by any instance. The error of
+// check is returned without creating the request
+func (repository *PGRequestRepository) CreateRequestWithinQuota(ctx context.Context, request *entity.Request, dayStart time.Time, monthStart time.Time, check func(usage *entity.Usage) error) (*entity.Request, error) {
+	tx, err := repository.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", request.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := repository.getUserUsage(ctx, tx, request.UserId, dayStart, monthStart)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = check(usage); err != nil {
+		return nil, err
+	}
 
+	request, err = repository.createRequest(ctx, tx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+func (repository *PGRequestRepository) createRequest(ctx context.Context, db queryRower, request *entity.Request) (*entity.Request, error) {
+	metadata := request.VideoMetadata
 	query := repository.db.QueryBuilder.Insert("requests").
-		Columns("user_id", "user_email", "video_size", "video_key", "zip_output_key", "status", "created_at").
-		Values(request.UserId, request.UserEmail, request.VideoSize, request.VideoKey, request.ZipOutputKey, request.Status, request.CreatedAt).
+		Columns("user_id", "user_email", "video_size", "video_key", "zip_output_key", "status", "created_at", "finished_at",
+			"video_format", "video_duration_ms", "video_width", "video_height", "video_codec",
+			"organization_id", "content_hash", "frame_interval_ms", "duplicate_of", "user_email_verified", "notification_status",
+			"plan", "user_locale").
+		Values(request.UserId, request.UserEmail, request.VideoSize, request.VideoKey, request.ZipOutputKey, request.Status, request.CreatedAt,
+			nullableTime(request.FinishedAt), nullableString(metadata.Format), nullableInt(metadata.Duration.Milliseconds()),
+			nullableInt(int64(metadata.Width)), nullableInt(int64(metadata.Height)), nullableString(metadata.Codec),
+			nullableString(request.OrganizationId), nullableString(request.ContentHash),
+			request.Options.FrameInterval.Milliseconds(), nullableInt(int64(request.DuplicateOf)),
+			request.UserEmailVerified, nullableString(string(request.NotificationStatus)), nullableString(request.Plan),
+			nullableString(request.UserLocale)).
 		Suffix(ReturnSuffix)
 
 	sql, args, err := query.ToSql()
@@ -37,14 +93,11 @@ func (repository *PGRequestRepository) CreateRequest(ctx context.Context, reques
 		return nil, err
 	}
 
-	row := repository.db.QueryRow(ctx, sql, args...)
+	row := db.QueryRow(ctx, sql, args...)
 	request, err = mapRowToRequest(row)
 
 	if err != nil {
-		if errCode := repository.db.ErrorCode(err); errCode == "23505" {
-			return nil, core.ErrConflictingData
-		}
-		return nil, err
+		return nil, mapError(repository.db, err)
 	}
 
 	return request, nil
@@ -63,7 +116,33 @@ func (repository *PGRequestRepository) GetById(ctx context.Context, id uint64) (
 	}
 
 	row := repository.db.QueryRow(ctx, sql, args...)
-	request, _ := mapRowToRequest(row)
+	request, err := mapRowToRequest(row)
+
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
+
+	return request, nil
+}
+
+// GetByVideoKey returns the request that uploaded the video, the duplicates only share it
+func (repository *PGRequestRepository) GetByVideoKey(ctx context.Context, videoKey string) (*entity.Request, error) {
+	query := repository.db.QueryBuilder.Select("*").
+		From("requests").
+		Where(sq.Eq{"video_key": videoKey, "duplicate_of": nil}).
+		Limit(1)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	row := repository.db.QueryRow(ctx, sql, args...)
+	request, err := mapRowToRequest(row)
+
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
 
 	return request, nil
 }
@@ -87,24 +166,45 @@ func (repository *PGRequestRepository) GetAllUserRequests(ctx context.Context, u
 	rows, err := repository.db.Query(ctx, sql, args...)
 
 	if err != nil {
-		return nil, err
+		return nil, mapError(repository.db, err)
 	}
 
 	defer rows.Close()
-	userRequests, _ = mapRowListToRequest(rows)
+	userRequests, err = mapRowListToRequest(rows)
+
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
 
 	return userRequests, nil
 }
 
 func (repository *PGRequestRepository) UpdateRequest(ctx context.Context, request *entity.Request) (*entity.Request, error) {
-	condition := sq.Eq{"id": request.ID}
+	return repository.updateRequest(ctx, request, sq.Eq{"id": request.ID})
+}
+
+// UpdateStuckRequest updates the request only while it is on the informed status and attempt, so a
+// result received or a new attempt started by another instance since it was read is not overwritten
+func (repository *PGRequestRepository) UpdateStuckRequest(ctx context.Context, request *entity.Request, status entity.RequestStatus, attempt int) (*entity.Request, error) {
+	return repository.updateRequest(ctx, request, sq.Eq{"id": request.ID, "status": status, "attempts": attempt})
+}
+
+func (repository *PGRequestRepository) updateRequest(ctx context.Context, request *entity.Request, condition sq.Eq) (*entity.Request, error) {
 	updatedData := map[string]interface{}{
 		"user_email":     request.UserEmail,
 		"video_size":     request.VideoSize,
 		"video_key":      request.VideoKey,
 		"zip_output_key": request.ZipOutputKey,
 		"status":         request.Status,
-		"finished_at":    request.FinishedAt,
+		"attempts":       request.Attempts,
+		"failure_reason": nullableString(request.FailureReason),
+		"started_at":     nullableTime(request.StartedAt),
+		"finished_at":    nullableTime(request.FinishedAt),
+		// The progress is written to reset it on a new attempt
+		"progress_percent":    nullableFloat(request.Progress.Percent),
+		"frames_extracted":    nullableInt(int64(request.Progress.FramesExtracted)),
+		"progress_eta":        nullableTime(request.Progress.Eta),
+		"progress_updated_at": nullableTime(request.Progress.UpdatedAt),
 	}
 
 	query := repository.db.QueryBuilder.Update("requests").
@@ -121,18 +221,58 @@ func (repository *PGRequestRepository) UpdateRequest(ctx context.Context, reques
 	updatedRequest, err := mapRowToRequest(row)
 
 	if err != nil {
-		return nil, err
+		return nil, mapError(repository.db, err)
 	}
 
 	return updatedRequest, nil
 }
 
+// UpdateProgress records the progress reported by the worker while the request is IN_PROGRESS. The queue
+// doesn't keep the order of the messages, so a report behind the recorded one is ignored
+func (repository *PGRequestRepository) UpdateProgress(ctx context.Context, id uint64, progress entity.Progress) error {
+	query := repository.db.QueryBuilder.Update("requests").
+		SetMap(map[string]interface{}{
+			"progress_percent":    progress.Percent,
+			"frames_extracted":    progress.FramesExtracted,
+			"progress_eta":        nullableTime(progress.Eta),
+			"progress_updated_at": progress.UpdatedAt,
+		}).
+		Where(sq.Eq{"id": id, "status": entity.InProgress}).
+		Where(sq.Expr("coalesce(frames_extracted, 0) <= ?", progress.FramesExtracted)).
+		Where(sq.Expr("coalesce(progress_percent, 0) <= ?", progress.Percent))
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := repository.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return mapError(repository.db, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return core.ErrDataNotFound
+	}
+
+	return nil
+}
+
 func (repository *PGRequestRepository) UpdateStatusByVideoKey(ctx context.Context, status string, videoKey string) (*entity.Request, error) {
-	condition := sq.Eq{"video_key": videoKey}
+	// The duplicates share the video of the original request, but are never processed
+	condition := sq.Eq{"video_key": videoKey, "duplicate_of": nil}
 	updatedData := map[string]interface{}{
 		"status": status,
 	}
 
+	// Each time a request goes to processing a new attempt starts, only from PENDING so a redelivered
+	// or late upload event doesn't start again a request already started by the watchdog or finished
+	if status == string(entity.InProgress) {
+		condition["status"] = entity.Pending
+		updatedData["started_at"] = sq.Expr("now()")
+		updatedData["attempts"] = sq.Expr("attempts + 1")
+	}
+
 	query := repository.db.QueryBuilder.Update("requests").
 		SetMap(updatedData).
 		Where(condition).
@@ -147,12 +287,350 @@ func (repository *PGRequestRepository) UpdateStatusByVideoKey(ctx context.Contex
 	updatedRequest, err := mapRowToRequest(row)
 
 	if err != nil {
-		return nil, err
+		return nil, mapError(repository.db, err)
 	}
 
 	return updatedRequest, nil
 }
 
+// GetStuckRequests returns the requests IN_PROGRESS that started before the informed moment
+func (repository *PGRequestRepository) GetStuckRequests(ctx context.Context, startedBefore time.Time) ([]entity.Request, error) {
+	query := repository.db.QueryBuilder.Select("*").
+		From("requests").
+		Where(sq.Eq{"status": entity.InProgress}).
+		Where(sq.Lt{"started_at": startedBefore}).
+		OrderBy("started_at")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repository.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
+
+	defer rows.Close()
+	return mapRowListToRequest(rows)
+}
+
+// GetStalePendingRequests returns the requests PENDING that were created before the informed moment
+func (repository *PGRequestRepository) GetStalePendingRequests(ctx context.Context, createdBefore time.Time) ([]entity.Request, error) {
+	query := repository.db.QueryBuilder.Select("*").
+		From("requests").
+		Where(sq.Eq{"status": entity.Pending}).
+		Where(sq.Lt{"created_at": createdBefore}).
+		OrderBy("created_at")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repository.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
+
+	defer rows.Close()
+	return mapRowListToRequest(rows)
+}
+
+// GetDigestUserIds returns the users with requests held for the digest that finished before the informed moment
+func (repository *PGRequestRepository) GetDigestUserIds(ctx context.Context, finishedBefore time.Time) ([]string, error) {
+	query := repository.db.QueryBuilder.Select("DISTINCT user_id").
+		From("requests").
+		Where(sq.Eq{"notification_status": entity.NotificationDigested}).
+		Where(sq.Lt{"finished_at": finishedBefore}).
+		OrderBy("user_id")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repository.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
+
+	defer rows.Close()
+
+	var userIds []string
+	for rows.Next() {
+		var userId string
+		if err := rows.Scan(&userId); err != nil {
+			return nil, err
+		}
+		userIds = append(userIds, userId)
+	}
+
+	return userIds, rows.Err()
+}
+
+// GetDigestRequests returns the requests of the user held for the digest that finished before the informed moment, the oldest first
+func (repository *PGRequestRepository) GetDigestRequests(ctx context.Context, userId string, finishedBefore time.Time) ([]entity.Request, error) {
+	query := repository.db.QueryBuilder.Select("*").
+		From("requests").
+		Where(sq.Eq{"user_id": userId, "notification_status": entity.NotificationDigested}).
+		Where(sq.Lt{"finished_at": finishedBefore}).
+		OrderBy("finished_at", "id")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repository.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
+
+	defer rows.Close()
+	return mapRowListToRequest(rows)
+}
+
+// GetCompletedByContentHash returns the last original COMPLETED request of the same video and options,
+// searching the requests of the organization when it is informed or else the requests of the user
+func (repository *PGRequestRepository) GetCompletedByContentHash(ctx context.Context, contentHash string, options entity.ExtractionOptions, userId string, organizationId string) (*entity.Request, error) {
+	owner := sq.Eq{"user_id": userId}
+	if organizationId != "" {
+		owner = sq.Eq{"organization_id": organizationId}
+	}
+
+	query := repository.db.QueryBuilder.Select("*").
+		From("requests").
+		Where(sq.Eq{
+			"content_hash":      contentHash,
+			"frame_interval_ms": options.FrameInterval.Milliseconds(),
+			"status":            entity.Completed,
+			"duplicate_of":      nil,
+		}).
+		Where(owner).
+		OrderBy("finished_at DESC").
+		Limit(1)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	row := repository.db.QueryRow(ctx, sql, args...)
+	request, err := mapRowToRequest(row)
+
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
+
+	return request, nil
+}
+
+// UpdateNotificationStatus registers whether the result email of the request was delivered
+func (repository *PGRequestRepository) UpdateNotificationStatus(ctx context.Context, id uint64, status entity.NotificationStatus) error {
+	query := repository.db.QueryBuilder.Update("requests").
+		Set("notification_status", status).
+		Where(sq.Eq{"id": id})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := repository.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return mapError(repository.db, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return core.ErrDataNotFound
+	}
+
+	return nil
+}
+
+// SearchRequests returns the requests of all users matching the filter, the newest first
+func (repository *PGRequestRepository) SearchRequests(ctx context.Context, filter entity.RequestFilter) ([]entity.Request, error) {
+	query := repository.db.QueryBuilder.Select("*").
+		From("requests").
+		OrderBy("created_at DESC", "id DESC").
+		Limit(filter.Limit).
+		Offset(filter.Offset)
+
+	if filter.UserId != "" {
+		query = query.Where(sq.Eq{"user_id": filter.UserId})
+	}
+
+	if filter.Status != "" {
+		query = query.Where(sq.Eq{"status": filter.Status})
+	}
+
+	if !filter.CreatedFrom.IsZero() {
+		query = query.Where(sq.GtOrEq{"created_at": filter.CreatedFrom})
+	}
+
+	if !filter.CreatedTo.IsZero() {
+		query = query.Where(sq.Lt{"created_at": filter.CreatedTo})
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repository.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
+
+	defer rows.Close()
+	return mapRowListToRequest(rows)
+}
+
+// CountByStatus returns the amount of requests of each status
+func (repository *PGRequestRepository) CountByStatus(ctx context.Context) (map[entity.RequestStatus]int, error) {
+	query := repository.db.QueryBuilder.Select("status", "count(*)").
+		From("requests").
+		GroupBy("status")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repository.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
+
+	defer rows.Close()
+
+	counts := map[entity.RequestStatus]int{}
+	for rows.Next() {
+		var status string
+		var count int
+
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[entity.RequestStatus(status)] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// GetUserUsage returns the active jobs of the user, the requests created since dayStart
+// and the bytes uploaded since monthStart, the duplicates don't count as uploaded bytes
+func (repository *PGRequestRepository) GetUserUsage(ctx context.Context, userId string, dayStart time.Time, monthStart time.Time) (*entity.Usage, error) {
+	return repository.getUserUsage(ctx, repository.db, userId, dayStart, monthStart)
+}
+
+func (repository *PGRequestRepository) getUserUsage(ctx context.Context, db queryRower, userId string, dayStart time.Time, monthStart time.Time) (*entity.Usage, error) {
+	query := repository.db.QueryBuilder.Select().
+		Column(sq.Expr("count(*) FILTER (WHERE status IN (?, ?))", entity.Pending, entity.InProgress)).
+		Column(sq.Expr("count(*) FILTER (WHERE created_at >= ?)", dayStart)).
+		Column(sq.Expr("coalesce(sum(video_size) FILTER (WHERE created_at >= ? AND duplicate_of IS NULL), 0)::bigint", monthStart)).
+		From("requests").
+		Where(sq.Eq{"user_id": userId})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &entity.Usage{DayStart: dayStart, MonthStart: monthStart}
+	err = db.QueryRow(ctx, sql, args...).Scan(&usage.ActiveJobs, &usage.RequestsToday, &usage.BytesThisMonth)
+
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
+
+	return usage, nil
+}
+
+// AddEvent appends an event to the history of the request and sets its id
+func (repository *PGRequestRepository) AddEvent(ctx context.Context, event *entity.RequestEvent) error {
+	query := repository.db.QueryBuilder.Insert("request_events").
+		Columns("request_id", "status", "message", "actor", "created_at").
+		Values(event.RequestId, event.Status, event.Message, event.Actor, event.CreatedAt).
+		Suffix("RETURNING id")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	err = repository.db.QueryRow(ctx, sql, args...).Scan(&event.ID)
+	return mapError(repository.db, err)
+}
+
+// GetRequestEvents returns the history of the request, the oldest first
+func (repository *PGRequestRepository) GetRequestEvents(ctx context.Context, requestId uint64) ([]entity.RequestEvent, error) {
+	query := repository.db.QueryBuilder.Select("id", "request_id", "status", "message", "actor", "created_at").
+		From("request_events").
+		Where(sq.Eq{"request_id": requestId}).
+		OrderBy("created_at", "id")
+
+	return repository.getEvents(ctx, query)
+}
+
+// GetUserEventsAfter returns the events of the requests of the user recorded after the event afterId, the oldest first
+func (repository *PGRequestRepository) GetUserEventsAfter(ctx context.Context, userId string, afterId uint64, limit uint64) ([]entity.RequestEvent, error) {
+	query := repository.db.QueryBuilder.Select("e.id", "e.request_id", "e.status", "e.message", "e.actor", "e.created_at").
+		From("request_events e").
+		Join("requests r ON r.id = e.request_id").
+		Where(sq.Eq{"r.user_id": userId}).
+		Where(sq.Gt{"e.id": afterId}).
+		OrderBy("e.id").
+		Limit(limit)
+
+	return repository.getEvents(ctx, query)
+}
+
+func (repository *PGRequestRepository) getEvents(ctx context.Context, query sq.SelectBuilder) ([]entity.RequestEvent, error) {
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repository.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
+
+	defer rows.Close()
+
+	var events []entity.RequestEvent
+	for rows.Next() {
+		var event entity.RequestEvent
+		var status string
+
+		err := rows.Scan(&event.ID, &event.RequestId, &status, &event.Message, &event.Actor, &event.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		event.Status = entity.RequestStatus(status)
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// mapError converts the missing rows to core.ErrDataNotFound and the
+// unique violations to core.ErrConflictingData
+func mapError(db *postgres.DB, err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return core.ErrDataNotFound
+	}
+
+	if errCode := db.ErrorCode(err); errCode == "23505" {
+		return core.ErrConflictingData
+	}
+
+	return err
+}
+
 // Map a row of database data to domain entity Request model
 func mapRowToRequest(row pgx.Row) (*entity.Request, error) {
 	var request RequestModel
@@ -167,6 +645,26 @@ func mapRowToRequest(row pgx.Row) (*entity.Request, error) {
 		&request.Status,
 		&request.CreatedAt,
 		&request.FinishedAt,
+		&request.StartedAt,
+		&request.Attempts,
+		&request.FailureReason,
+		&request.VideoFormat,
+		&request.VideoDuration,
+		&request.VideoWidth,
+		&request.VideoHeight,
+		&request.VideoCodec,
+		&request.OrganizationId,
+		&request.ContentHash,
+		&request.FrameInterval,
+		&request.DuplicateOf,
+		&request.UserEmailVerified,
+		&request.NotificationStatus,
+		&request.Plan,
+		&request.ProgressPercent,
+		&request.FramesExtracted,
+		&request.ProgressEta,
+		&request.ProgressUpdatedAt,
+		&request.UserLocale,
 	)
 
 	if err != nil {
@@ -187,7 +685,7 @@ func mapRowListToRequest(rows pgx.Rows) ([]entity.Request, error) {
 		requests = append(requests, *request)
 	}
 
-	return requests, nil
+	return requests, rows.Err()
 }
 
 func modelToEntity(model RequestModel) *entity.Request {
@@ -199,6 +697,7 @@ func modelToEntity(model RequestModel) *entity.Request {
 		VideoSize: model.VideoSize,
 		VideoKey:  model.VideoKey,
 		Status:    entity.RequestStatus(model.Status),
+		Attempts:  model.Attempts,
 		CreatedAt: model.CreatedAt,
 	}
 
@@ -210,5 +709,36 @@ func modelToEntity(model RequestModel) *entity.Request {
 		data.FinishedAt = model.FinishedAt.Time
 	}
 
+	if model.StartedAt.Valid {
+		data.StartedAt = model.StartedAt.Time
+	}
+
+	if model.FailureReason.Valid {
+		data.FailureReason = model.FailureReason.String
+	}
+
+	data.VideoMetadata = entity.VideoMetadata{
+		Format:   model.VideoFormat.String,
+		Duration: time.Duration(model.VideoDuration.Int64) * time.Millisecond,
+		Width:    int(model.VideoWidth.Int32),
+		Height:   int(model.VideoHeight.Int32),
+		Codec:    model.VideoCodec.String,
+	}
+
+	data.OrganizationId = model.OrganizationId.String
+	data.ContentHash = model.ContentHash.String
+	data.Options.FrameInterval = time.Duration(model.FrameInterval) * time.Millisecond
+	data.DuplicateOf = uint64(model.DuplicateOf.Int64)
+	data.UserEmailVerified = model.UserEmailVerified
+	data.NotificationStatus = entity.NotificationStatus(model.NotificationStatus.String)
+	data.Plan = model.Plan.String
+	data.UserLocale = model.UserLocale.String
+	data.Progress = entity.Progress{
+		Percent:         model.ProgressPercent.Float64,
+		FramesExtracted: int(model.FramesExtracted.Int32),
+		Eta:             model.ProgressEta.Time,
+		UpdatedAt:       model.ProgressUpdatedAt.Time,
+	}
+
 	return &data
 }
@@ -0,0 +1,340 @@
+package repository
+
+import (
+	"context"
+	"example/web-service-gin/src/adapters/storage/postgres"
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+// claimDeliveriesQuery locks the due pending deliveries, skipping the ones already locked
+// by other instances, and postpones them by the lease while they are sent
+const claimDeliveriesQuery = `
+UPDATE webhook_deliveries
+SET next_attempt_at = now() + make_interval(secs => $1)
+WHERE id IN (
+    SELECT id FROM webhook_deliveries
+    WHERE status = $2 AND next_attempt_at <= now()
+    ORDER BY next_attempt_at
+    LIMIT $3
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *`
+
+// PGWebhookRepository implements port.WebhookRepository interface
+// and provides access to the postgres database
+type PGWebhookRepository struct {
+	db *postgres.DB
+}
+
+// NewPGWebhookRepository creates a new webhook storage instance for postgres
+func NewPGWebhookRepository(db *postgres.DB) *PGWebhookRepository {
+	return &PGWebhookRepository{
+		db,
+	}
+}
+
+// CreateWebhook creates a new webhook register in the database
+func (repository *PGWebhookRepository) CreateWebhook(ctx context.Context, webhook *entity.Webhook) (*entity.Webhook, error) {
+	query := repository.db.QueryBuilder.Insert("webhooks").
+		Columns("user_id", "url", "secret", "events", "active", "created_at", "updated_at").
+		Values(webhook.UserId, webhook.Url, webhook.Secret, webhook.Events, webhook.Active, webhook.CreatedAt, webhook.UpdatedAt).
+		Suffix(ReturnSuffix)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	webhook, err = mapRowToWebhook(repository.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
+
+	return webhook, nil
+}
+
+// GetWebhook returns a webhook of the user, or of any user when userId is empty
+func (repository *PGWebhookRepository) GetWebhook(ctx context.Context, id uint64, userId string) (*entity.Webhook, error) {
+	query := repository.db.QueryBuilder.Select("*").
+		From("webhooks").
+		Where(sq.Eq{"id": id}).
+		Limit(1)
+
+	if userId != "" {
+		query = query.Where(sq.Eq{"user_id": userId})
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	webhook, err := mapRowToWebhook(repository.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
+
+	return webhook, nil
+}
+
+// GetUserWebhooks returns all the webhooks of the user
+func (repository *PGWebhookRepository) GetUserWebhooks(ctx context.Context, userId string) ([]entity.Webhook, error) {
+	return repository.getWebhooks(ctx, sq.Eq{"user_id": userId})
+}
+
+// GetSubscribedWebhooks returns the active webhooks of the user that receive the event
+func (repository *PGWebhookRepository) GetSubscribedWebhooks(ctx context.Context, userId string, event string) ([]entity.Webhook, error) {
+	return repository.getWebhooks(ctx, sq.And{
+		sq.Eq{"user_id": userId, "active": true},
+		sq.Expr("? = ANY(events)", event),
+	})
+}
+
+func (repository *PGWebhookRepository) getWebhooks(ctx context.Context, condition sq.Sqlizer) ([]entity.Webhook, error) {
+	query := repository.db.QueryBuilder.Select("*").
+		From("webhooks").
+		Where(condition).
+		OrderBy("created_at", "id")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repository.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
+
+	defer rows.Close()
+
+	var webhooks []entity.Webhook
+	for rows.Next() {
+		webhook, err := mapRowToWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, *webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// UpdateWebhook updates the url, events and active flag of the webhook
+func (repository *PGWebhookRepository) UpdateWebhook(ctx context.Context, webhook *entity.Webhook) (*entity.Webhook, error) {
+	query := repository.db.QueryBuilder.Update("webhooks").
+		SetMap(map[string]interface{}{
+			"url":        webhook.Url,
+			"events":     webhook.Events,
+			"active":     webhook.Active,
+			"updated_at": webhook.UpdatedAt,
+		}).
+		Where(sq.Eq{"id": webhook.ID, "user_id": webhook.UserId}).
+		Suffix(ReturnSuffix)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	webhook, err = mapRowToWebhook(repository.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
+
+	return webhook, nil
+}
+
+// DeleteWebhook deletes a webhook of the user, the deliveries are deleted in cascade
+func (repository *PGWebhookRepository) DeleteWebhook(ctx context.Context, id uint64, userId string) error {
+	query := repository.db.QueryBuilder.Delete("webhooks").
+		Where(sq.Eq{"id": id, "user_id": userId})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tag, err := repository.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return mapError(repository.db, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return core.ErrDataNotFound
+	}
+
+	return nil
+}
+
+// CreateDelivery creates a new delivery register in the database
+func (repository *PGWebhookRepository) CreateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) (*entity.WebhookDelivery, error) {
+	query := repository.db.QueryBuilder.Insert("webhook_deliveries").
+		Columns("webhook_id", "event", "payload", "status", "next_attempt_at", "created_at").
+		Values(delivery.WebhookId, delivery.Event, delivery.Payload, delivery.Status, delivery.NextAttemptAt, delivery.CreatedAt).
+		Suffix(ReturnSuffix)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	delivery, err = mapRowToDelivery(repository.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
+
+	return delivery, nil
+}
+
+// GetDelivery returns a delivery of the webhook
+func (repository *PGWebhookRepository) GetDelivery(ctx context.Context, id uint64, webhookId uint64) (*entity.WebhookDelivery, error) {
+	query := repository.db.QueryBuilder.Select("*").
+		From("webhook_deliveries").
+		Where(sq.Eq{"id": id, "webhook_id": webhookId}).
+		Limit(1)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	delivery, err := mapRowToDelivery(repository.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
+
+	return delivery, nil
+}
+
+// GetWebhookDeliveries returns the last deliveries of the webhook, the newest first
+func (repository *PGWebhookRepository) GetWebhookDeliveries(ctx context.Context, webhookId uint64, limit uint64) ([]entity.WebhookDelivery, error) {
+	query := repository.db.QueryBuilder.Select("*").
+		From("webhook_deliveries").
+		Where(sq.Eq{"webhook_id": webhookId}).
+		OrderBy("created_at DESC", "id DESC").
+		Limit(limit)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repository.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
+
+	defer rows.Close()
+	return mapRowListToDeliveries(rows)
+}
+
+// ClaimDueDeliveries returns the pending deliveries whose attempt is due, postponing them by the lease
+func (repository *PGWebhookRepository) ClaimDueDeliveries(ctx context.Context, limit uint64, lease time.Duration) ([]entity.WebhookDelivery, error) {
+	rows, err := repository.db.Query(ctx, claimDeliveriesQuery, lease.Seconds(), entity.DeliveryPending, limit)
+	if err != nil {
+		return nil, mapError(repository.db, err)
+	}
+
+	defer rows.Close()
+	return mapRowListToDeliveries(rows)
+}
+
+// UpdateDelivery records the result of an attempt of the delivery
+func (repository *PGWebhookRepository) UpdateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	query := repository.db.QueryBuilder.Update("webhook_deliveries").
+		SetMap(map[string]interface{}{
+			"status":          delivery.Status,
+			"attempts":        delivery.Attempts,
+			"response_code":   nullableInt(int64(delivery.ResponseCode)),
+			"error":           nullableString(delivery.Error),
+			"next_attempt_at": delivery.NextAttemptAt,
+			"last_attempt_at": nullableTime(delivery.LastAttemptAt),
+		}).
+		Where(sq.Eq{"id": delivery.ID})
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = repository.db.Exec(ctx, sql, args...)
+	return mapError(repository.db, err)
+}
+
+// Map a row of database data to domain entity Webhook model
+func mapRowToWebhook(row pgx.Row) (*entity.Webhook, error) {
+	var webhook entity.Webhook
+
+	err := row.Scan(
+		&webhook.ID,
+		&webhook.UserId,
+		&webhook.Url,
+		&webhook.Secret,
+		&webhook.Events,
+		&webhook.Active,
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+// Map a row of database data to domain entity WebhookDelivery model
+func mapRowToDelivery(row pgx.Row) (*entity.WebhookDelivery, error) {
+	var model WebhookDeliveryModel
+
+	err := row.Scan(
+		&model.ID,
+		&model.WebhookId,
+		&model.Event,
+		&model.Payload,
+		&model.Status,
+		&model.Attempts,
+		&model.ResponseCode,
+		&model.Error,
+		&model.NextAttemptAt,
+		&model.LastAttemptAt,
+		&model.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.WebhookDelivery{
+		ID:            model.ID,
+		WebhookId:     model.WebhookId,
+		Event:         model.Event,
+		Payload:       model.Payload,
+		Status:        entity.DeliveryStatus(model.Status),
+		Attempts:      model.Attempts,
+		ResponseCode:  int(model.ResponseCode.Int32),
+		Error:         model.Error.String,
+		NextAttemptAt: model.NextAttemptAt,
+		LastAttemptAt: model.LastAttemptAt.Time,
+		CreatedAt:     model.CreatedAt,
+	}, nil
+}
+
+// Map the rows (list) to domain entity WebhookDelivery model
+func mapRowListToDeliveries(rows pgx.Rows) ([]entity.WebhookDelivery, error) {
+	var deliveries []entity.WebhookDelivery
+	for rows.Next() {
+		delivery, err := mapRowToDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+
+	return deliveries, rows.Err()
+}
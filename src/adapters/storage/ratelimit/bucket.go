@@ -0,0 +1,25 @@
+package ratelimit
+
+import (
+	"example/web-service-gin/src/core/entity"
+	"time"
+)
+
+// The buckets are kept as their theoretical arrival time (GCRA), the moment the bucket
+// is full again. Each request moves it one interval forward, and a request is allowed
+// while it stays within one period from now, so a single value is stored per key
+
+// newResult builds the result from the time left until the bucket is full,
+// after the request when it was allowed or else as it was
+func newResult(limit entity.RateLimit, allowed bool, untilFull time.Duration) *entity.RateLimitResult {
+	untilFull = max(untilFull, 0)
+	result := &entity.RateLimitResult{Allowed: allowed, ResetAfter: untilFull}
+
+	if allowed {
+		result.Remaining = int((limit.Period - untilFull) / limit.Interval())
+	} else {
+		result.RetryAfter = max(untilFull+limit.Interval()-limit.Period, 0)
+	}
+
+	return result
+}
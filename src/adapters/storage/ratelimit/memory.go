@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"context"
+	"example/web-service-gin/src/core/entity"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often the full buckets are removed from memory
+const sweepInterval = time.Minute
+
+// MemoryStore implements port.RateLimitStore in memory, each instance
+// of the application limits its own requests
+type MemoryStore struct {
+	mutex     sync.Mutex
+	buckets   map[string]time.Time
+	lastSweep time.Time
+}
+
+// NewMemoryStore creates a new in-memory rate limit store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:   make(map[string]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+// Take consumes a token of the bucket of the key
+func (s *MemoryStore) Take(ctx context.Context, key string, limit entity.RateLimit) (*entity.RateLimitResult, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	current := s.buckets[key]
+	if current.Before(now) {
+		current = now
+	}
+
+	fullAt := current.Add(limit.Interval())
+
+	if fullAt.Sub(now) > limit.Period {
+		return newResult(limit, false, current.Sub(now)), nil
+	}
+
+	s.buckets[key] = fullAt
+	return newResult(limit, true, fullAt.Sub(now)), nil
+}
+
+// sweep removes the full buckets, which behave as the missing ones
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+
+	for key, fullAt := range s.buckets {
+		if fullAt.Before(now) {
+			delete(s.buckets, key)
+		}
+	}
+
+	s.lastSweep = now
+}
@@ -0,0 +1,60 @@
+package ratelimit_test
+
+import (
+	"context"
+	"example/web-service-gin/src/adapters/storage/ratelimit"
+	"example/web-service-gin/src/core/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore_AllowsTheBucket(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	limit := entity.RateLimit{Requests: 3, Period: time.Minute}
+
+	for remaining := 2; remaining >= 0; remaining-- {
+		result, err := store.Take(context.Background(), "user:1", limit)
+
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, remaining, result.Remaining)
+	}
+
+	result, err := store.Take(context.Background(), "user:1", limit)
+
+	assert.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.InDelta(t, 20*time.Second, result.RetryAfter, float64(time.Second))
+	assert.InDelta(t, time.Minute, result.ResetAfter, float64(time.Second))
+}
+
+func TestMemoryStore_SeparatesTheKeys(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	limit := entity.RateLimit{Requests: 1, Period: time.Minute}
+
+	first, _ := store.Take(context.Background(), "user:1", limit)
+	second, _ := store.Take(context.Background(), "user:2", limit)
+	again, _ := store.Take(context.Background(), "user:1", limit)
+
+	assert.True(t, first.Allowed)
+	assert.True(t, second.Allowed)
+	assert.False(t, again.Allowed)
+}
+
+func TestMemoryStore_RefillsOverTime(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	limit := entity.RateLimit{Requests: 2, Period: 100 * time.Millisecond}
+
+	_, _ = store.Take(context.Background(), "ip:10.0.0.1", limit)
+	_, _ = store.Take(context.Background(), "ip:10.0.0.1", limit)
+	rejected, _ := store.Take(context.Background(), "ip:10.0.0.1", limit)
+
+	time.Sleep(60 * time.Millisecond)
+	allowed, _ := store.Take(context.Background(), "ip:10.0.0.1", limit)
+
+	assert.False(t, rejected.Allowed)
+	assert.True(t, allowed.Allowed)
+}
@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"example/web-service-gin/src/adapters/storage/postgres"
+	"example/web-service-gin/src/core/entity"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// takeQuery moves the bucket one interval forward when it stays within one period from now,
+// returning the seconds until it is full. No row is returned when the request is rejected
+const takeQuery = `
+INSERT INTO rate_limit_buckets AS bucket (key, full_at)
+VALUES ($1, now() + make_interval(secs => $2))
+ON CONFLICT (key) DO UPDATE
+SET full_at = greatest(bucket.full_at, now()) + make_interval(secs => $2)
+WHERE greatest(bucket.full_at, now()) + make_interval(secs => $2) <= now() + make_interval(secs => $3)
+RETURNING extract(epoch FROM full_at - now())::float8`
+
+const untilFullQuery = `SELECT extract(epoch FROM full_at - now())::float8 FROM rate_limit_buckets WHERE key = $1`
+
+// PGStore implements port.RateLimitStore on the application database, so the
+// limits are shared by all the instances. The database clock is used by all of them
+type PGStore struct {
+	db *postgres.DB
+}
+
+// NewPGStore creates a new postgres rate limit store
+func NewPGStore(db *postgres.DB) *PGStore {
+	return &PGStore{db}
+}
+
+// Take consumes a token of the bucket of the key in a single statement
+func (s *PGStore) Take(ctx context.Context, key string, limit entity.RateLimit) (*entity.RateLimitResult, error) {
+	var seconds float64
+
+	err := s.db.QueryRow(ctx, takeQuery, key, limit.Interval().Seconds(), limit.Period.Seconds()).Scan(&seconds)
+	if err == nil {
+		return newResult(limit, true, toDuration(seconds)), nil
+	}
+
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	err = s.db.QueryRow(ctx, untilFullQuery, key).Scan(&seconds)
+	if err != nil {
+		return nil, err
+	}
+
+	return newResult(limit, false, toDuration(seconds)), nil
+}
+
+// DeleteFullBuckets removes the full buckets, which behave as the missing ones
+func (s *PGStore) DeleteFullBuckets(ctx context.Context) {
+	query := s.db.QueryBuilder.Delete("rate_limit_buckets").
+		Where("full_at < now()")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		slog.Error("Error building rate limit cleanup query", "error", err)
+		return
+	}
+
+	tag, err := s.db.Exec(ctx, sql, args...)
+	if err != nil {
+		slog.Error("Error deleting full rate limit buckets", "error", err)
+		return
+	}
+
+	slog.Debug("Deleted full rate limit buckets", "count", tag.RowsAffected())
+}
+
+func toDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
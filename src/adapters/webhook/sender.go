@@ -0,0 +1,50 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"example/web-service-gin/src/utils/network"
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxResponseSize is the amount of the response body read, so the connection can be reused
+const maxResponseSize = 64 << 10
+
+// HTTPSender implements port.WebhookSender interface and POSTs the deliveries to the URLs of the webhooks
+type HTTPSender struct {
+	client *http.Client
+}
+
+// NewHTTPSender creates a new sender whose requests are canceled after the timeout. The redirects
+// are not followed, so a delivery never reaches an URL not informed by the user, and the private
+// networks are only reached when allowPrivateNetworks
+func NewHTTPSender(timeout time.Duration, allowPrivateNetworks bool) *HTTPSender {
+	return &HTTPSender{
+		client: network.NewClient(timeout, allowPrivateNetworks),
+	}
+}
+
+// Send posts the body to the URL and returns the status code of the response
+func (sender *HTTPSender) Send(ctx context.Context, url string, headers map[string]string, body []byte) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	request.Header.Set("User-Agent", "Frameshot-Webhooks/1.0")
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := sender.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, maxResponseSize))
+
+	return response.StatusCode, nil
+}
@@ -0,0 +1,74 @@
+package webhook_test
+
+import (
+	"context"
+	"example/web-service-gin/src/adapters/webhook"
+	"example/web-service-gin/src/utils/network"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPSender_Send(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	sender := webhook.NewHTTPSender(time.Second, true)
+	statusCode, err := sender.Send(context.Background(), server.URL, map[string]string{"X-Webhook-Event": "request.created"}, []byte(`{"id":"evt_1"}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, statusCode)
+	assert.Equal(t, http.MethodPost, received.Method)
+	assert.Equal(t, "request.created", received.Header.Get("X-Webhook-Event"))
+	assert.Equal(t, `{"id":"evt_1"}`, string(body))
+}
+
+func TestHTTPSender_SendDoesNotFollowRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/", http.StatusFound)
+	}))
+	defer server.Close()
+
+	sender := webhook.NewHTTPSender(time.Second, true)
+	statusCode, err := sender.Send(context.Background(), server.URL, nil, []byte(`{}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusFound, statusCode)
+}
+
+func TestHTTPSender_SendError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	sender := webhook.NewHTTPSender(time.Second, true)
+	statusCode, err := sender.Send(context.Background(), server.URL, nil, []byte(`{}`))
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, statusCode)
+}
+
+func TestHTTPSender_SendRefusesPrivateNetworks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the private network was reached")
+	}))
+	defer server.Close()
+
+	sender := webhook.NewHTTPSender(time.Second, false)
+
+	for _, url := range []string{server.URL, "http://169.254.169.254/latest/meta-data/"} {
+		statusCode, err := sender.Send(context.Background(), url, nil, []byte(`{}`))
+
+		assert.ErrorIs(t, err, network.ErrNonPublicAddress)
+		assert.Equal(t, 0, statusCode)
+	}
+}
@@ -0,0 +1,47 @@
+package entity
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	// ScopeRequestsRead allows listing the requests of the user
+	ScopeRequestsRead = "requests:read"
+	// ScopeRequestsWrite allows submitting new videos
+	ScopeRequestsWrite = "requests:write"
+)
+
+// ApiKeyScopes are the scopes that can be granted to an API key
+var ApiKeyScopes = []string{ScopeRequestsRead, ScopeRequestsWrite}
+
+// ApiKey authenticates the machine clients of a user. Only the SHA-256 of the
+// key is stored, Prefix is kept so the user can tell the keys apart. UserEmail, EmailVerified,
+// OrganizationId and Plan are the ones of the owner on their last token, resolved when the
+// key authenticates, so the key follows the changes of its owner
+type ApiKey struct {
+	ID             uint64
+	UserId         string
+	UserEmail      string
+	EmailVerified  bool
+	OrganizationId string
+	Plan           string
+	Name           string
+	Prefix         string
+	KeyHash        string
+	Scopes         []string
+	CreatedAt      time.Time
+	LastUsedAt     time.Time
+	ExpiresAt      time.Time
+	RevokedAt      time.Time
+}
+
+// IsExpired tells if the key has an expiration and it has passed
+func (k *ApiKey) IsExpired(now time.Time) bool {
+	return !k.ExpiresAt.IsZero() && now.After(k.ExpiresAt)
+}
+
+// HasScope tells if the scope was granted to the key
+func (k *ApiKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
@@ -0,0 +1,38 @@
+package entity
+
+import "time"
+
+// Types of the domain events of the lifecycle of the requests, also sent to the webhooks
+const (
+	EventRequestCreated   = "request.created"
+	EventRequestStarted   = "request.started"
+	EventRequestCompleted = "request.completed"
+	EventRequestFailed    = "request.failed"
+	// EventRequestCancelled is reserved for the cancellation of the requests, which isn't supported yet
+	EventRequestCancelled = "request.cancelled"
+)
+
+// EventTypeOf returns the event of a request reaching the status, each
+// processing attempt is a new request.started
+func EventTypeOf(status RequestStatus) string {
+	switch status {
+	case Pending:
+		return EventRequestCreated
+	case InProgress:
+		return EventRequestStarted
+	case Completed:
+		return EventRequestCompleted
+	case Failed:
+		return EventRequestFailed
+	}
+	return ""
+}
+
+// DomainEvent is a transition of a request published to the other services. The ID is
+// unique to each event, so the consumers can ignore the ones delivered more than once
+type DomainEvent struct {
+	ID         string
+	Type       string
+	OccurredAt time.Time
+	Request    Request
+}
@@ -0,0 +1,45 @@
+package entity
+
+import "time"
+
+// SystemActor is the actor of the events caused by the application itself, like the watchdog
+const SystemActor = "system"
+
+// RequestEvent is an entry of the history of a request, recorded each time its status changes
+type RequestEvent struct {
+	ID        uint64
+	RequestId uint64
+	Status    RequestStatus
+	Message   string
+	Actor     string
+	CreatedAt time.Time
+}
+
+// RequestEventStream are the events of the requests of a user, the Missed ones recorded before the
+// subscription, to resume a stream, followed by the live Events. Close ends the subscription
+type RequestEventStream struct {
+	Missed []RequestEvent
+	Events <-chan RequestEvent
+	Close  func()
+}
+
+// AuditLog records an action of an administrator
+type AuditLog struct {
+	ID         uint64
+	ActorId    string
+	ActorEmail string
+	Action     string
+	TargetId   string
+	Details    map[string]string
+	CreatedAt  time.Time
+}
+
+// RequestFilter are the optional criteria to search the requests of all users, the zero values are ignored
+type RequestFilter struct {
+	UserId      string
+	Status      RequestStatus
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	Limit       uint64
+	Offset      uint64
+}
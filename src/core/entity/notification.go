@@ -1,8 +1,150 @@
 package entity
 
+import "time"
+
 type EventMessage struct {
-	MessageID string
-	Source    string
-	Body      string
-	Date      string
+	MessageID     string
+	ReceiptHandle string
+	Source        string
+	Body          string
+	Date          string
+}
+
+// NotificationChannel is a way of notifying the users when their requests finish
+type NotificationChannel string
+
+const (
+	ChannelEmail NotificationChannel = "email"
+	ChannelSlack NotificationChannel = "slack"
+	ChannelTeams NotificationChannel = "teams"
+)
+
+// NotificationChannels are the channels the users can choose
+var NotificationChannels = []NotificationChannel{ChannelEmail, ChannelSlack, ChannelTeams}
+
+// Locales are the languages the notifications are written in
+var Locales = []string{"pt-BR", "en", "es"}
+
+// DigestMode is how often a user is notified of the results of their requests
+type DigestMode string
+
+const (
+	// DigestImmediate notifies each result as soon as the request finishes
+	DigestImmediate DigestMode = "immediate"
+	DigestDaily     DigestMode = "daily"
+	DigestWeekly    DigestMode = "weekly"
+)
+
+// DigestModes are the modes the users can choose
+var DigestModes = []DigestMode{DigestImmediate, DigestDaily, DigestWeekly}
+
+// NotificationPreferences are the channels a user is notified on, the chat channels post to the
+// incoming webhook URL of the user. An empty Locale uses the locale of the token of the user
+type NotificationPreferences struct {
+	UserId          string
+	Channels        []NotificationChannel
+	SlackWebhookUrl string
+	TeamsWebhookUrl string
+	Locale          string
+	Digest          DigestMode
+	UpdatedAt       time.Time
+}
+
+// DefaultNotificationPreferences are the preferences of the users who never changed them
+func DefaultNotificationPreferences(userId string) *NotificationPreferences {
+	return &NotificationPreferences{UserId: userId, Channels: []NotificationChannel{ChannelEmail}, Digest: DigestImmediate}
+}
+
+// NotificationKind is the reason of a notification, each one has its own templates
+type NotificationKind string
+
+const (
+	NotificationRequestCompleted NotificationKind = "completed"
+	NotificationRequestFailed    NotificationKind = "failed"
+	// NotificationRequestExpired is sent when the request didn't finish in time and was given up
+	NotificationRequestExpired NotificationKind = "expired"
+	NotificationQuotaWarning   NotificationKind = "quota_warning"
+	// NotificationDigest summarizes the results of the requests of the users who chose a digest
+	NotificationDigest NotificationKind = "digest"
+)
+
+// NotificationKinds are all the kinds of notifications
+var NotificationKinds = []NotificationKind{NotificationRequestCompleted, NotificationRequestFailed, NotificationRequestExpired,
+	NotificationQuotaWarning, NotificationDigest}
+
+// IsRequestResult tells whether the notification is the result of its request, the other
+// kinds don't change the notification status of the request
+func (kind NotificationKind) IsRequestResult() bool {
+	return kind == NotificationRequestCompleted || kind == NotificationRequestFailed || kind == NotificationRequestExpired
+}
+
+// Notification is the message sent to a user, the subject and the contents are rendered from the
+// templates of the kind on the locale. Quota is only informed on the quota warnings and
+// Digest on the digests, whose Request is the last one of the digest
+type Notification struct {
+	Kind    NotificationKind
+	Locale  string
+	Subject string
+	Text    string
+	Html    string
+	Request *Request
+	Quota   *QuotaWarning
+	Digest  *Digest
+}
+
+// Digest summarizes the requests of a user finished on the period, from PeriodStart until
+// before PeriodEnd, whose results were held for it
+type Digest struct {
+	UserId      string
+	Mode        DigestMode
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Completed   int
+	Failed      int
+	Requests    []Request
+}
+
+// LastDay is the last day included on the period of the digest
+func (digest Digest) LastDay() time.Time {
+	return digest.PeriodEnd.AddDate(0, 0, -1)
+}
+
+// QuotaWarning tells the user that a limit of the plan is almost reached, Limit is
+// "requests_per_day" or "bytes_per_month"
+type QuotaWarning struct {
+	Limit   string
+	Used    int64
+	Max     int64
+	ResetAt time.Time
+}
+
+// NotificationDelivery is a notification sent to a recipient on a channel, logged on the notifications
+// table. The pending deliveries are retried on NextAttemptAt with the rendered contents, so a retry
+// sends the same message. ProviderResponse and Error are the result of the last attempt
+type NotificationDelivery struct {
+	ID               uint64
+	RequestId        uint64
+	UserId           string
+	Kind             NotificationKind
+	Channel          NotificationChannel
+	Recipient        string
+	Locale           string
+	Subject          string
+	Text             string
+	Html             string
+	Status           DeliveryStatus
+	Attempts         int
+	ProviderResponse string
+	Error            string
+	NextAttemptAt    time.Time
+	LastAttemptAt    time.Time
+	CreatedAt        time.Time
+}
+
+// NotificationFilter are the criteria of the deliveries listed to the administrators, the empty ones are ignored
+type NotificationFilter struct {
+	RequestId uint64
+	Status    DeliveryStatus
+	Limit     uint64
+	Offset    uint64
 }
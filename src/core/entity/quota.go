@@ -0,0 +1,32 @@
+package entity
+
+import "time"
+
+// Plan defines the usage limits of the users, a zero value disables the limit
+type Plan struct {
+	Name              string
+	MaxConcurrentJobs int
+	MaxRequestsPerDay int
+	MaxBytesPerMonth  int64
+	MaxFileSize       int64
+}
+
+// Usage is the consumption of a user on the current quota periods. The active jobs are
+// the requests PENDING or IN_PROGRESS, the days and months are counted in UTC
+type Usage struct {
+	ActiveJobs     int
+	RequestsToday  int
+	BytesThisMonth int64
+	DayStart       time.Time
+	MonthStart     time.Time
+}
+
+// DailyResetAt is when the requests of the day are reset
+func (u Usage) DailyResetAt() time.Time {
+	return u.DayStart.AddDate(0, 0, 1)
+}
+
+// MonthlyResetAt is when the bytes of the month are reset
+func (u Usage) MonthlyResetAt() time.Time {
+	return u.MonthStart.AddDate(0, 1, 0)
+}
@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// RateLimit allows Requests per Period to each client, as a token bucket of Requests
+// tokens refilled one each Period/Requests, so the client can burst the whole bucket
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Interval is the time to refill one token of the bucket
+func (l RateLimit) Interval() time.Duration {
+	return l.Period / time.Duration(l.Requests)
+}
+
+// RateLimitResult tells whether a request was allowed, how many requests are left, how
+// long until the bucket is full again and, for rejected requests, when to try again
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int
+	ResetAfter time.Duration
+	RetryAfter time.Duration
+}
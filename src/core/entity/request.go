@@ -11,14 +11,76 @@ const (
 	Failed     RequestStatus = "FAILED"
 )
 
+// NotificationStatus tells whether the result email of a request was delivered
+type NotificationStatus string
+
+const (
+	// NotificationPending is the status of the requests that didn't finish yet
+	NotificationPending NotificationStatus = "PENDING"
+	NotificationSent    NotificationStatus = "SENT"
+	// NotificationSkipped is the status of the requests of unverified email addresses
+	NotificationSkipped NotificationStatus = "SKIPPED"
+	NotificationFailed  NotificationStatus = "FAILED"
+	// NotificationDigested is the status of the requests whose result is sent on the digest of the user
+	NotificationDigested NotificationStatus = "DIGEST"
+)
+
+// DefaultFrameInterval is the extraction interval when the user doesn't inform one
+const DefaultFrameInterval = time.Second
+
+// Request is a video sent to have its frames extracted. ZipOutputKey is the stored key of the output
+// and ZipOutputUrl is where it is downloaded, signed when the request is read
 type Request struct {
-	ID           uint64
-	UserId       string
-	UserEmail    string
-	VideoSize    int64
-	VideoKey     string
-	ZipOutputKey string
-	Status       RequestStatus
-	CreatedAt    time.Time
-	FinishedAt   time.Time
+	ID                 uint64
+	UserId             string
+	UserEmail          string
+	UserEmailVerified  bool
+	UserLocale         string
+	OrganizationId     string
+	Plan               string
+	VideoSize          int64
+	VideoKey           string
+	VideoMetadata      VideoMetadata
+	ContentHash        string
+	Options            ExtractionOptions
+	DuplicateOf        uint64
+	ZipOutputKey       string
+	ZipOutputUrl       string
+	Status             RequestStatus
+	Attempts           int
+	FailureReason      string
+	NotificationStatus NotificationStatus
+	Progress           Progress
+	CreatedAt          time.Time
+	StartedAt          time.Time
+	FinishedAt         time.Time
+}
+
+// Progress is the last progress reported by the worker while processing the request,
+// it is reset on each new attempt. Eta is the estimated moment the processing finishes
+type Progress struct {
+	Percent         float64
+	FramesExtracted int
+	Eta             time.Time
+	UpdatedAt       time.Time
+}
+
+// IsZero tells whether the worker reported no progress
+func (progress Progress) IsZero() bool {
+	return progress.UpdatedAt.IsZero()
+}
+
+// VideoMetadata is the information read from the video container when it is uploaded
+type VideoMetadata struct {
+	Format   string
+	Duration time.Duration
+	Width    int
+	Height   int
+	Codec    string
+}
+
+// ExtractionOptions are the settings the worker uses to extract the frames,
+// two requests of the same video only have the same output with equal options
+type ExtractionOptions struct {
+	FrameInterval time.Duration
 }
@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+// FileInfo contains the metadata of an object kept on the storage
+type FileInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	ETag         string
+	LastModified time.Time
+}
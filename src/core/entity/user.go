@@ -1,7 +1,34 @@
 package entity
 
+import "slices"
+
 type User struct {
-	Id            string `json:"id"`
-	Email         string `json:"email"`
-	EmailVerified bool   `json:"email_verified"`
+	Id             string   `json:"id"`
+	Email          string   `json:"email"`
+	EmailVerified  bool     `json:"email_verified"`
+	OrganizationId string   `json:"organization_id"`
+	Groups         []string `json:"groups"`
+	// Plan is the name of the quota plan of the user, empty for the default plan
+	Plan string `json:"plan"`
+	// Locale is the language of the user, e.g. "pt-BR", empty when the token doesn't inform it
+	Locale string `json:"locale"`
+
+	// ApiKey is the key used to authenticate, nil when the user logged in with a token
+	ApiKey *ApiKey `json:"-"`
+}
+
+// HasScope tells if the user can access the scope, the users authenticated
+// with a token have all the scopes and the API keys only the ones granted to them
+func (u *User) HasScope(scope string) bool {
+	if u.ApiKey == nil {
+		return true
+	}
+	return u.ApiKey.HasScope(scope)
+}
+
+// InAnyGroup tells if the user belongs to one of the groups
+func (u *User) InAnyGroup(groups []string) bool {
+	return slices.ContainsFunc(u.Groups, func(group string) bool {
+		return slices.Contains(groups, group)
+	})
 }
@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"slices"
+	"time"
+)
+
+// WebhookEvents are the events a webhook can subscribe to
+var WebhookEvents = []string{EventRequestCreated, EventRequestStarted, EventRequestCompleted, EventRequestFailed}
+
+// Webhook is an URL of a user called on the events of the requests. The Secret
+// signs the deliveries, so it is kept as informed to the user
+type Webhook struct {
+	ID        uint64
+	UserId    string
+	Url       string
+	Secret    string
+	Events    []string
+	Active    bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Subscribed tells if the webhook is active and receives the event
+func (w *Webhook) Subscribed(event string) bool {
+	return w.Active && slices.Contains(w.Events, event)
+}
+
+// WebhookChanges are the fields of a webhook updated by the user, nil fields are kept
+type WebhookChanges struct {
+	Url    *string
+	Events []string
+	Active *bool
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "PENDING"
+	DeliverySucceeded DeliveryStatus = "SUCCEEDED"
+	// DeliveryFailed is the status of the deliveries that used all their attempts
+	DeliveryFailed DeliveryStatus = "FAILED"
+)
+
+// WebhookDelivery is an event sent to a webhook, the pending deliveries are retried on
+// NextAttemptAt. ResponseCode and Error are the result of the last attempt
+type WebhookDelivery struct {
+	ID            uint64
+	WebhookId     uint64
+	Event         string
+	Payload       []byte
+	Status        DeliveryStatus
+	Attempts      int
+	ResponseCode  int
+	Error         string
+	NextAttemptAt time.Time
+	LastAttemptAt time.Time
+	CreatedAt     time.Time
+}
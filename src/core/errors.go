@@ -1,6 +1,10 @@
 package core
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 var (
 	// ErrInternal is an error for when an internal service fails to process the request
@@ -15,4 +19,91 @@ var (
 	ErrUnauthorized = errors.New("user is unauthorized to access the resource")
 	// ErrForbidden is an error for when the user is forbidden to access the resource
 	ErrForbidden = errors.New("user is forbidden to access the resource")
+	// ErrInvalidRange is an error for when a read starts outside the stored data
+	ErrInvalidRange = errors.New("requested range is not satisfiable")
+	// ErrInvalidFile is an error for when the uploaded file is missing, empty or unreadable
+	ErrInvalidFile = errors.New("invalid file")
+	// ErrFileTooLarge is an error for when the uploaded file exceeds the size limit
+	ErrFileTooLarge = errors.New("file is too large")
+	// ErrUnsupportedMediaType is an error for when the uploaded file is not an allowed video
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	// ErrInvalidInput is an error for when a required field is missing or has an invalid value
+	ErrInvalidInput = errors.New("invalid input")
+	// ErrStorageUnavailable is an error for when the file storage can't be reached
+	ErrStorageUnavailable = errors.New("file storage is unavailable")
+	// ErrTooManyRequests is an error for when the user must wait before trying again
+	ErrTooManyRequests = errors.New("too many requests")
+	// ErrQuotaExceeded is an error for when the user used the whole quota of the period
+	ErrQuotaExceeded = errors.New("quota exceeded")
+)
+
+// Variants of ErrUnauthorized telling why a token or API key was rejected, errors.Is(err, ErrUnauthorized) is true for all of them
+var (
+	// ErrInvalidToken is an error for when the token is malformed or its signature is invalid
+	ErrInvalidToken = fmt.Errorf("invalid token: %w", ErrUnauthorized)
+	// ErrTokenExpired is an error for when the token is expired or not valid yet
+	ErrTokenExpired = fmt.Errorf("token expired or not valid yet: %w", ErrUnauthorized)
+	// ErrInvalidIssuer is an error for when the token was issued by another identity provider
+	ErrInvalidIssuer = fmt.Errorf("invalid token issuer: %w", ErrUnauthorized)
+	// ErrInvalidAudience is an error for when the token was issued to another client
+	ErrInvalidAudience = fmt.Errorf("invalid token audience: %w", ErrUnauthorized)
+	// ErrInvalidTokenUse is an error for when an access token is used instead of an id token, or the opposite
+	ErrInvalidTokenUse = fmt.Errorf("invalid token use: %w", ErrUnauthorized)
+	// ErrMissingClaim is an error for when a required claim is missing or has an unexpected type
+	ErrMissingClaim = fmt.Errorf("missing or invalid token claim: %w", ErrUnauthorized)
+	// ErrInvalidApiKey is an error for when the API key doesn't exist, was revoked or is expired
+	ErrInvalidApiKey = fmt.Errorf("invalid API key: %w", ErrUnauthorized)
 )
+
+// ErrEmailNotVerified is an error for when the user must verify the email address before the action,
+// errors.Is(err, ErrForbidden) is true for it
+var ErrEmailNotVerified = fmt.Errorf("email address is not verified: %w", ErrForbidden)
+
+// ValidationError describes why an input was rejected, Kind is one of the
+// errors above and can be checked with errors.Is
+type ValidationError struct {
+	Kind    error
+	Message string
+}
+
+// NewValidationError creates a new validation error of the informed kind
+func NewValidationError(kind error, message string) *ValidationError {
+	return &ValidationError{Kind: kind, Message: message}
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Kind
+}
+
+// QuotaError describes the quota that rejected an action. Kind is ErrTooManyRequests
+// when the user can try again soon, ErrQuotaExceeded when the period is used up or
+// ErrFileTooLarge for the file size. A zero ResetAt means the quota is not reset by time
+type QuotaError struct {
+	Kind    error
+	Quota   string
+	Limit   int64
+	Used    int64
+	ResetAt time.Time
+}
+
+// NewQuotaError creates a new quota error of the informed kind
+func NewQuotaError(kind error, quota string, limit int64, used int64, resetAt time.Time) *QuotaError {
+	return &QuotaError{Kind: kind, Quota: quota, Limit: limit, Used: used, ResetAt: resetAt}
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("the %s quota of %d was exceeded", e.Quota, e.Limit)
+}
+
+func (e *QuotaError) Unwrap() error {
+	return e.Kind
+}
+
+// Remaining is how much of the quota is still available
+func (e *QuotaError) Remaining() int64 {
+	return max(e.Limit-e.Used, 0)
+}
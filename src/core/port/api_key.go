@@ -0,0 +1,44 @@
+package port
+
+import (
+	"context"
+	"example/web-service-gin/src/core/entity"
+	"time"
+)
+
+type ApiKeyRepository interface {
+	// CreateApiKey creates a new API key on database and return it
+	CreateApiKey(ctx context.Context, key *entity.ApiKey) (*entity.ApiKey, error)
+
+	// GetByKeyHash returns the key that is not revoked with the informed hash and the current email,
+	// with its verification, the organization and the plan of its owner, or core.ErrDataNotFound
+	GetByKeyHash(ctx context.Context, keyHash string) (*entity.ApiKey, error)
+
+	// GetAllUserApiKeys returns the keys of the user that are not revoked
+	GetAllUserApiKeys(ctx context.Context, userId string) ([]entity.ApiKey, error)
+
+	// RevokeApiKey revokes a key of the user, or returns core.ErrDataNotFound
+	RevokeApiKey(ctx context.Context, id uint64, userId string) error
+
+	// UpdateLastUsed registers the moment the key was used
+	UpdateLastUsed(ctx context.Context, id uint64, usedAt time.Time) error
+
+	// SaveOwner records the email with its verification, the organization and the plan informed by the token of the user
+	SaveOwner(ctx context.Context, user *entity.User) error
+}
+
+type ApiKeyService interface {
+	// Create creates a key for the user and returns it with the plain key, that is never shown again
+	Create(ctx context.Context, user *entity.User, name string, scopes []string, expiresAt time.Time) (*entity.ApiKey, string, error)
+	List(ctx context.Context, userId string) ([]entity.ApiKey, error)
+	Revoke(ctx context.Context, id uint64, userId string) error
+}
+
+// ApiKeyAuthenticator resolves the user of an API key, like JwtService does for the tokens
+type ApiKeyAuthenticator interface {
+	GetUser(ctx context.Context, key string) (*entity.User, error)
+
+	// RecordOwner keeps the email, the organization and the plan of the user authenticated by a token,
+	// which are followed by the API keys of the user
+	RecordOwner(ctx context.Context, user *entity.User)
+}
@@ -0,0 +1,11 @@
+package port
+
+import (
+	"context"
+	"example/web-service-gin/src/core/entity"
+)
+
+type EventPublisher interface {
+	// Publish sends the domain event to the services subscribed to the events of the requests
+	Publish(ctx context.Context, event entity.DomainEvent) error
+}
@@ -0,0 +1,28 @@
+package port
+
+import (
+	"example/web-service-gin/src/core/entity"
+	"time"
+)
+
+// Metrics receives the measurements of the adapters, so they don't depend on the monitoring
+// system. The implementations must be safe for concurrent use
+type Metrics interface {
+	// ObserveHTTPRequest records a request served by the API, route is the pattern that matched it
+	ObserveHTTPRequest(method string, route string, status int, duration time.Duration)
+
+	// ObserveUpload records a video written to the storage, err is the failure to write it
+	ObserveUpload(bytes int64, duration time.Duration, err error)
+
+	// ObserveCall records a call to an external service like s3, sqs or sendgrid
+	ObserveCall(service string, operation string, duration time.Duration, err error)
+
+	// ObserveMessagesReceived records the messages read from the queue
+	ObserveMessagesReceived(queue string, count int)
+
+	// ObserveMessage records the processing of a message of the queue, err is the failure to process it
+	ObserveMessage(queue string, duration time.Duration, err error)
+
+	// ObserveProcessing records how long a request took from its creation until its result
+	ObserveProcessing(status entity.RequestStatus, duration time.Duration)
+}
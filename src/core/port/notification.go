@@ -0,0 +1,71 @@
+package port
+
+import (
+	"context"
+	"example/web-service-gin/src/core/entity"
+	"time"
+)
+
+// NotificationChannel delivers the notifications through a channel, the recipient is the email
+// address of the user on the email channels and the incoming webhook URL on the chat channels
+type NotificationChannel interface {
+	//Send delivers the notification to the recipient, returning the response of the provider
+	//to be logged, which is also informed when the provider rejects the notification
+	Send(ctx context.Context, recipient string, notification entity.Notification) (string, error)
+}
+
+// NotificationRenderer writes the notifications from the templates of their kind and locale
+type NotificationRenderer interface {
+	//Render fills the subject, the text and the html of the notification
+	Render(notification *entity.Notification) error
+}
+
+type NotificationRepository interface {
+	//GetPreferences returns the notification preferences of the user
+	GetPreferences(ctx context.Context, userId string) (*entity.NotificationPreferences, error)
+
+	//SavePreferences creates or replaces the notification preferences of the user
+	SavePreferences(ctx context.Context, preferences *entity.NotificationPreferences) (*entity.NotificationPreferences, error)
+
+	//CreateDelivery logs a new delivery of a notification
+	CreateDelivery(ctx context.Context, delivery *entity.NotificationDelivery) (*entity.NotificationDelivery, error)
+
+	//GetDelivery returns a delivery of a notification
+	GetDelivery(ctx context.Context, id uint64) (*entity.NotificationDelivery, error)
+
+	//SearchDeliveries returns a page of the deliveries matching the filter, the newest first
+	SearchDeliveries(ctx context.Context, filter entity.NotificationFilter) ([]entity.NotificationDelivery, error)
+
+	//GetRequestDeliveries returns all the deliveries of the notifications of the request, the oldest first
+	GetRequestDeliveries(ctx context.Context, requestId uint64) ([]entity.NotificationDelivery, error)
+
+	//ClaimDueDeliveries returns the pending deliveries whose attempt is due, postponing them by the lease
+	//so the other instances don't send them at the same time
+	ClaimDueDeliveries(ctx context.Context, limit uint64, lease time.Duration) ([]entity.NotificationDelivery, error)
+
+	//UpdateDelivery records the result of an attempt of the delivery
+	UpdateDelivery(ctx context.Context, delivery *entity.NotificationDelivery) error
+
+	//ClaimDigest records the digest of the user for its period, returning false when it was already claimed
+	ClaimDigest(ctx context.Context, digest *entity.Digest) (bool, error)
+}
+
+// NotificationLog keeps the deliveries of the notifications, so the failed ones can be inspected and sent again
+type NotificationLog interface {
+	//SearchDeliveries returns a page of the deliveries matching the filter, the newest first
+	SearchDeliveries(ctx context.Context, filter entity.NotificationFilter) ([]entity.NotificationDelivery, error)
+
+	//Resend sends the notification of a finished delivery again, as a new delivery
+	Resend(ctx context.Context, id uint64) (*entity.NotificationDelivery, error)
+}
+
+type NotificationService interface {
+	//GetPreferences returns the notification preferences of the user, the default ones when never changed
+	GetPreferences(ctx context.Context, userId string) (*entity.NotificationPreferences, error)
+
+	//UpdatePreferences validates and replaces the notification preferences of the user
+	UpdatePreferences(ctx context.Context, preferences *entity.NotificationPreferences) (*entity.NotificationPreferences, error)
+
+	//Preview renders a notification of the kind on the locale with sample data
+	Preview(kind entity.NotificationKind, locale string) (*entity.Notification, error)
+}
@@ -0,0 +1,16 @@
+package port
+
+import "example/web-service-gin/src/core/entity"
+
+type MessageConsumer interface {
+	// ReceiveMessages reads up to maxMessages from the queue, waiting at most waitTime seconds for them
+	ReceiveMessages(queueURL string, maxMessages int32, waitTime int32) ([]entity.EventMessage, error)
+
+	// DeleteMessage removes a processed message from the queue using its receipt handle
+	DeleteMessage(queueURL, receiptHandle string) error
+}
+
+type MessageProducer interface {
+	// SendMessage sends a message to the informed queue
+	SendMessage(queueURL, messageBody string) error
+}
@@ -0,0 +1,11 @@
+package port
+
+import (
+	"context"
+	"example/web-service-gin/src/core/entity"
+)
+
+type RateLimitStore interface {
+	// Take consumes a token of the bucket of the key, the buckets that don't exist start full
+	Take(ctx context.Context, key string, limit entity.RateLimit) (*entity.RateLimitResult, error)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"example/web-service-gin/src/core/entity"
 	"mime/multipart"
+	"time"
 )
 
 type RequestRepository interface {
@@ -11,17 +12,115 @@ type RequestRepository interface {
 	// CreateRequest creates a new Request on database and return it
 	CreateRequest(ctx context.Context, request *entity.Request) (*entity.Request, error)
 
+	// CreateRequestWithinQuota creates the request when check accepts the usage of the user, counted like
+	// GetUserUsage while the creations of the user are serialized, otherwise returns the error of check
+	CreateRequestWithinQuota(ctx context.Context, request *entity.Request, dayStart time.Time, monthStart time.Time, check func(usage *entity.Usage) error) (*entity.Request, error)
+
 	// GetById searchs for a request with informed ID
 	GetById(ctx context.Context, id uint64) (*entity.Request, error)
 
+	//GetByVideoKey returns the request that uploaded the video, not its duplicates, or core.ErrDataNotFound
+	GetByVideoKey(ctx context.Context, videoKey string) (*entity.Request, error)
+
 	//GetAllUserRequests returns a list of all user requests
 	GetAllUserRequests(ctx context.Context, userId string) ([]entity.Request, error)
 
 	//UpdateRequest updates the role request entity
 	UpdateRequest(ctx context.Context, request *entity.Request) (*entity.Request, error)
 
-	//UpdateStatusByVideoKey updates the status of a request in database looking for the file key name
+	//UpdateStuckRequest updates the request only while it is still on the informed status and attempt,
+	//or returns core.ErrDataNotFound when it finished or another attempt started meanwhile
+	UpdateStuckRequest(ctx context.Context, request *entity.Request, status entity.RequestStatus, attempt int) (*entity.Request, error)
+
+	//UpdateStatusByVideoKey updates the status of a request in database looking for the file key name, it moves
+	//to IN_PROGRESS only a PENDING request, otherwise returns core.ErrDataNotFound
 	UpdateStatusByVideoKey(ctx context.Context, status string, videoKey string) (*entity.Request, error)
+
+	//UpdateProgress records the progress of a request IN_PROGRESS, or returns core.ErrDataNotFound
+	//when the request isn't IN_PROGRESS or the recorded progress is ahead of the informed one
+	UpdateProgress(ctx context.Context, id uint64, progress entity.Progress) error
+
+	//GetStuckRequests returns the requests IN_PROGRESS that started before the informed moment
+	GetStuckRequests(ctx context.Context, startedBefore time.Time) ([]entity.Request, error)
+
+	//GetStalePendingRequests returns the requests PENDING that were created before the informed moment
+	GetStalePendingRequests(ctx context.Context, createdBefore time.Time) ([]entity.Request, error)
+
+	//GetCompletedByContentHash returns the last original COMPLETED request of the same video and extraction options,
+	//owned by the organization when it is informed or else by the user, or core.ErrDataNotFound
+	GetCompletedByContentHash(ctx context.Context, contentHash string, options entity.ExtractionOptions, userId string, organizationId string) (*entity.Request, error)
+
+	//UpdateNotificationStatus registers whether the result email of the request was delivered
+	UpdateNotificationStatus(ctx context.Context, id uint64, status entity.NotificationStatus) error
+
+	//SearchRequests returns the requests of all users matching the filter, the newest first
+	SearchRequests(ctx context.Context, filter entity.RequestFilter) ([]entity.Request, error)
+
+	//CountByStatus returns the amount of requests of each status
+	CountByStatus(ctx context.Context) (map[entity.RequestStatus]int, error)
+
+	//GetUserUsage returns the active jobs of the user, the requests created since dayStart
+	//and the bytes uploaded since monthStart, the duplicates don't count as uploaded bytes
+	GetUserUsage(ctx context.Context, userId string, dayStart time.Time, monthStart time.Time) (*entity.Usage, error)
+
+	//GetDigestUserIds returns the users with requests whose result was held for the digest and
+	//finished before the informed moment
+	GetDigestUserIds(ctx context.Context, finishedBefore time.Time) ([]string, error)
+
+	//GetDigestRequests returns the requests of the user whose result was held for the digest and
+	//finished before the informed moment, the oldest first
+	GetDigestRequests(ctx context.Context, userId string, finishedBefore time.Time) ([]entity.Request, error)
+}
+
+type RequestEventRepository interface {
+	//AddEvent appends an event to the history of the request and sets its id
+	AddEvent(ctx context.Context, event *entity.RequestEvent) error
+
+	//GetRequestEvents returns the history of the request, the oldest first
+	GetRequestEvents(ctx context.Context, requestId uint64) ([]entity.RequestEvent, error)
+
+	//GetUserEventsAfter returns the events of the requests of the user recorded after the event afterId, the oldest first
+	GetUserEventsAfter(ctx context.Context, userId string, afterId uint64, limit uint64) ([]entity.RequestEvent, error)
+}
+
+// RequestEventBroker fans the recorded events out to the streams of the users, across all the instances
+type RequestEventBroker interface {
+	//Publish sends the event to the subscribers of the user
+	Publish(ctx context.Context, userId string, event entity.RequestEvent) error
+
+	//Subscribe returns the events of the user published from now on and a function that ends the
+	//subscription. The channel is closed when the subscriber falls behind, so it can resume from the history
+	Subscribe(userId string) (<-chan entity.RequestEvent, func())
+}
+
+// EventStreamService streams the status changes of the requests of the user
+type EventStreamService interface {
+	//Subscribe returns the events recorded after lastEventId, when it is informed, and the live events
+	Subscribe(ctx context.Context, userId string, lastEventId uint64) (*entity.RequestEventStream, error)
+}
+
+type AuditLogRepository interface {
+	//AddAuditLog records an action of an administrator
+	AddAuditLog(ctx context.Context, log *entity.AuditLog) error
+
+	//GetAuditLogs returns the recorded actions, the newest first
+	GetAuditLogs(ctx context.Context, limit uint64, offset uint64) ([]entity.AuditLog, error)
+}
+
+type AdminService interface {
+	SearchRequests(ctx context.Context, admin *entity.User, filter entity.RequestFilter) ([]entity.Request, error)
+	GetRequest(ctx context.Context, admin *entity.User, id uint64) (*entity.Request, []entity.RequestEvent, error)
+	RetryRequest(ctx context.Context, admin *entity.User, id uint64) (*entity.Request, error)
+	FailRequest(ctx context.Context, admin *entity.User, id uint64, reason string) (*entity.Request, error)
+	CountByStatus(ctx context.Context, admin *entity.User) (map[entity.RequestStatus]int, error)
+	ListAuditLogs(ctx context.Context, admin *entity.User, limit uint64, offset uint64) ([]entity.AuditLog, error)
+	ListNotifications(ctx context.Context, admin *entity.User, filter entity.NotificationFilter) ([]entity.NotificationDelivery, error)
+	ResendNotification(ctx context.Context, admin *entity.User, id uint64) (*entity.NotificationDelivery, error)
+}
+
+type QuotaService interface {
+	//GetUsage returns the plan of the user and how much of it was used
+	GetUsage(ctx context.Context, user *entity.User) (*entity.Plan, *entity.Usage, error)
 }
 
 type RequestService interface {
@@ -29,8 +128,8 @@ type RequestService interface {
 	Update(ctx context.Context, request *entity.Request) (*entity.Request, error)
 	List(ctx context.Context, userId string) ([]entity.Request, error)
 	Get(ctx context.Context, id uint64) (*entity.Request, error)
-	HandleUploadNotification(ctx context.Context, msg entity.EventMessage)
-	HandleVideoOutputNotification(ctx context.Context, msg entity.EventMessage)
+	HandleUploadNotification(ctx context.Context, msg entity.EventMessage) error
+	HandleVideoOutputNotification(ctx context.Context, msg entity.EventMessage) error
 }
 
 type QueuePort interface {
@@ -40,6 +139,9 @@ type QueuePort interface {
 
 type MailServicePort interface {
 	// NotifyRequestStatus notify when a video request is converted with
-	//success by the service Or with any error
-	NotifyRequestStatus(request *entity.Request, status string) error
+	//success by the service Or with any error, on the channels chosen by the user
+	NotifyRequestStatus(request *entity.Request, kind entity.NotificationKind) error
+
+	// NotifyQuotaWarning notify the user who created the request that a limit of the plan is almost reached
+	NotifyQuotaWarning(request *entity.Request, warning entity.QuotaWarning) error
 }
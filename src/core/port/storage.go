@@ -1,12 +1,45 @@
 package port
 
 import (
-	"github.com/golang-migrate/migrate/v4/source/file"
-	"mime/multipart"
+	"context"
+	"example/web-service-gin/src/core/entity"
+	"io"
 )
 
 type StoragePort interface {
-	UploadFile(file *multipart.FileHeader, fileKey string) (string, error)
-	DownloadFile(fileKey string) (*file.File, error)
+	// UploadFile stores the size bytes of the content with the informed key and returns the key,
+	// the storage may seek the content back to its start to read it again
+	UploadFile(ctx context.Context, content io.ReadSeeker, size int64, contentType string, fileKey string) (string, error)
+
+	// DownloadFile returns the file content and its metadata, the caller must close the content
+	DownloadFile(ctx context.Context, fileKey string) (io.ReadCloser, *entity.FileInfo, error)
+
+	// DownloadFileRange returns up to length bytes of the file starting at offset,
+	// a length lower or equal to zero reads until the end of the file.
+	// The metadata size is always the size of the whole file
+	DownloadFileRange(ctx context.Context, fileKey string, offset int64, length int64) (io.ReadCloser, *entity.FileInfo, error)
+
+	// StatFile returns the file metadata without downloading it
+	StatFile(ctx context.Context, fileKey string) (*entity.FileInfo, error)
+
+	// FileExists checks if there is a file with the informed key
+	FileExists(ctx context.Context, fileKey string) (bool, error)
+
+	// DeleteFile removes the file from the storage
+	DeleteFile(ctx context.Context, fileKey string) error
+
+	// ListFiles returns the metadata of all files with keys starting with the prefix
+	ListFiles(ctx context.Context, prefix string) ([]entity.FileInfo, error)
+
+	// GetFileUrl returns the URL where the file can be downloaded
 	GetFileUrl(fileKey string) string
 }
+
+// SignedStoragePort is implemented by the storages that serve their own files
+// through signed URLs, instead of delegating the download to a cloud provider
+type SignedStoragePort interface {
+	StoragePort
+
+	// VerifyFileUrl validates the expiration and signature of a URL created by GetFileUrl
+	VerifyFileUrl(fileKey string, expires string, signature string) error
+}
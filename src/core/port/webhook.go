@@ -0,0 +1,63 @@
+package port
+
+import (
+	"context"
+	"example/web-service-gin/src/core/entity"
+	"time"
+)
+
+type WebhookRepository interface {
+	//CreateWebhook creates a new webhook and returns it
+	CreateWebhook(ctx context.Context, webhook *entity.Webhook) (*entity.Webhook, error)
+
+	//GetWebhook returns a webhook of the user, or of any user when userId is empty
+	GetWebhook(ctx context.Context, id uint64, userId string) (*entity.Webhook, error)
+
+	//GetUserWebhooks returns all the webhooks of the user
+	GetUserWebhooks(ctx context.Context, userId string) ([]entity.Webhook, error)
+
+	//GetSubscribedWebhooks returns the active webhooks of the user that receive the event
+	GetSubscribedWebhooks(ctx context.Context, userId string, event string) ([]entity.Webhook, error)
+
+	//UpdateWebhook updates the url, events and active flag of the webhook
+	UpdateWebhook(ctx context.Context, webhook *entity.Webhook) (*entity.Webhook, error)
+
+	//DeleteWebhook deletes a webhook of the user with its deliveries
+	DeleteWebhook(ctx context.Context, id uint64, userId string) error
+
+	//CreateDelivery creates a new pending delivery and returns it
+	CreateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) (*entity.WebhookDelivery, error)
+
+	//GetDelivery returns a delivery of the webhook
+	GetDelivery(ctx context.Context, id uint64, webhookId uint64) (*entity.WebhookDelivery, error)
+
+	//GetWebhookDeliveries returns the last deliveries of the webhook, the newest first
+	GetWebhookDeliveries(ctx context.Context, webhookId uint64, limit uint64) ([]entity.WebhookDelivery, error)
+
+	//ClaimDueDeliveries returns pending deliveries whose attempt is due, postponing them by the lease
+	//so other instances don't send them at the same time
+	ClaimDueDeliveries(ctx context.Context, limit uint64, lease time.Duration) ([]entity.WebhookDelivery, error)
+
+	//UpdateDelivery records the result of an attempt of the delivery
+	UpdateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error
+}
+
+type WebhookSender interface {
+	//Send posts the body to the url, returning the status code of the response
+	Send(ctx context.Context, url string, headers map[string]string, body []byte) (int, error)
+}
+
+type WebhookPublisher interface {
+	//Publish queues the event of the request to the webhooks of its user
+	Publish(ctx context.Context, request *entity.Request, event string)
+}
+
+type WebhookService interface {
+	Create(ctx context.Context, userId string, url string, events []string) (*entity.Webhook, error)
+	List(ctx context.Context, userId string) ([]entity.Webhook, error)
+	Get(ctx context.Context, id uint64, userId string) (*entity.Webhook, error)
+	Update(ctx context.Context, id uint64, userId string, changes entity.WebhookChanges) (*entity.Webhook, error)
+	Delete(ctx context.Context, id uint64, userId string) error
+	ListDeliveries(ctx context.Context, id uint64, userId string) ([]entity.WebhookDelivery, error)
+	Redeliver(ctx context.Context, id uint64, deliveryId uint64, userId string) (*entity.WebhookDelivery, error)
+}
@@ -0,0 +1,290 @@
+package usecase
+
+import (
+	"context"
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/port"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultPageSize is the amount of items listed when the limit is not informed
+	DefaultPageSize = 50
+	// MaxPageSize is the biggest amount of items listed at once
+	MaxPageSize = 200
+)
+
+// Actions recorded on the audit log
+const (
+	auditSearchRequests     = "requests.search"
+	auditViewRequest        = "requests.view"
+	auditRetryRequest       = "requests.retry"
+	auditFailRequest        = "requests.fail"
+	auditCountRequests      = "requests.count"
+	auditListAuditLogs      = "audit_logs.list"
+	auditListNotifications  = "notifications.list"
+	auditResendNotification = "notifications.resend"
+)
+
+type AdminUseCase struct {
+	repository    port.RequestRepository
+	events        port.RequestEventRepository
+	history       *RequestHistory
+	storage       port.StoragePort
+	audit         port.AuditLogRepository
+	queue         port.QueuePort
+	mail          port.MailServicePort
+	notifications port.NotificationLog
+}
+
+// NewAdminUseCase creates a new instance of the support staff operations over the requests of all users
+func NewAdminUseCase(repo port.RequestRepository, events port.RequestEventRepository, history *RequestHistory, storage port.StoragePort, audit port.AuditLogRepository, queue port.QueuePort, notif port.MailServicePort, notifications port.NotificationLog) *AdminUseCase {
+	return &AdminUseCase{repo, events, history, storage, audit, queue, notif, notifications}
+}
+
+// SearchRequests returns a page of the requests of all users matching the filter
+func (usecase *AdminUseCase) SearchRequests(ctx context.Context, admin *entity.User, filter entity.RequestFilter) ([]entity.Request, error) {
+	filter.Limit = pageSize(filter.Limit)
+
+	requests, err := usecase.repository.SearchRequests(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	usecase.record(ctx, admin, auditSearchRequests, "", map[string]string{
+		"user_id":      filter.UserId,
+		"status":       string(filter.Status),
+		"created_from": formatTime(filter.CreatedFrom),
+		"created_to":   formatTime(filter.CreatedTo),
+		"limit":        strconv.FormatUint(filter.Limit, 10),
+		"offset":       strconv.FormatUint(filter.Offset, 10),
+	})
+
+	if requests == nil {
+		return []entity.Request{}, nil
+	}
+
+	signOutputs(usecase.storage, requests)
+	return requests, nil
+}
+
+// GetRequest returns any request with its history
+func (usecase *AdminUseCase) GetRequest(ctx context.Context, admin *entity.User, id uint64) (*entity.Request, []entity.RequestEvent, error) {
+	request, err := usecase.repository.GetById(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	events, err := usecase.events.GetRequestEvents(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	usecase.record(ctx, admin, auditViewRequest, formatId(id), nil)
+	signOutput(usecase.storage, request)
+	return request, events, nil
+}
+
+// RetryRequest starts a new processing attempt for the request, whatever its status. The
+// duplicates are rejected as they are never processed, and so are the requests whose
+// upload was not received yet
+func (usecase *AdminUseCase) RetryRequest(ctx context.Context, admin *entity.User, id uint64) (*entity.Request, error) {
+	request, err := usecase.repository.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.DuplicateOf != 0 {
+		return nil, core.NewValidationError(core.ErrConflictingData,
+			fmt.Sprintf("request reuses the output of request %d, retry it instead", request.DuplicateOf))
+	}
+
+	if request.Status == entity.Pending {
+		return nil, core.NewValidationError(core.ErrConflictingData, "the video upload of the request was not received yet")
+	}
+
+	previousStatus := request.Status
+	request.Status = entity.InProgress
+	request.Attempts++
+	request.StartedAt = time.Now()
+	request.FinishedAt = time.Time{}
+	request.FailureReason = ""
+	request.ZipOutputKey = ""
+	request.Progress = entity.Progress{}
+
+	request, err = usecase.repository.UpdateRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	usecase.history.record(ctx, request, admin.Id, fmt.Sprintf("retried by an administrator, attempt %d", request.Attempts))
+	usecase.record(ctx, admin, auditRetryRequest, formatId(id), map[string]string{"previous_status": string(previousStatus)})
+
+	// The request is IN_PROGRESS, so the watchdog enqueues it again if this fails
+	if err = usecase.queue.SendVideoProccessToQueue(request); err != nil {
+		return nil, fmt.Errorf("error enqueuing retried request: %w", err)
+	}
+
+	return request, nil
+}
+
+// FailRequest marks a request that is not finished as FAILED and notifies the user
+func (usecase *AdminUseCase) FailRequest(ctx context.Context, admin *entity.User, id uint64, reason string) (*entity.Request, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, core.NewValidationError(core.ErrInvalidInput, "reason is required")
+	}
+
+	request, err := usecase.repository.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Status == entity.Completed || request.Status == entity.Failed {
+		return nil, core.NewValidationError(core.ErrConflictingData, fmt.Sprintf("request is already %s", request.Status))
+	}
+
+	previousStatus := request.Status
+	request.Status = entity.Failed
+	request.FinishedAt = time.Now()
+	request.FailureReason = reason
+
+	request, err = usecase.repository.UpdateRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	usecase.history.record(ctx, request, admin.Id, "failed by an administrator: "+reason)
+	usecase.record(ctx, admin, auditFailRequest, formatId(id), map[string]string{
+		"previous_status": string(previousStatus),
+		"reason":          reason,
+	})
+
+	if err = usecase.mail.NotifyRequestStatus(request, entity.NotificationRequestFailed); err != nil {
+		slog.Error("Error notifying failed request", "id", request.ID, "error", err)
+	}
+
+	return request, nil
+}
+
+// CountByStatus returns the amount of requests of each status, including the ones without requests
+func (usecase *AdminUseCase) CountByStatus(ctx context.Context, admin *entity.User) (map[entity.RequestStatus]int, error) {
+	counts, err := usecase.repository.CountByStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, status := range []entity.RequestStatus{entity.Pending, entity.InProgress, entity.Completed, entity.Failed} {
+		if _, exists := counts[status]; !exists {
+			counts[status] = 0
+		}
+	}
+
+	usecase.record(ctx, admin, auditCountRequests, "", nil)
+	return counts, nil
+}
+
+// ListAuditLogs returns a page of the actions of the administrators, the newest first
+func (usecase *AdminUseCase) ListAuditLogs(ctx context.Context, admin *entity.User, limit uint64, offset uint64) ([]entity.AuditLog, error) {
+	logs, err := usecase.audit.GetAuditLogs(ctx, pageSize(limit), offset)
+	if err != nil {
+		return nil, err
+	}
+
+	usecase.record(ctx, admin, auditListAuditLogs, "", nil)
+
+	if logs == nil {
+		return []entity.AuditLog{}, nil
+	}
+
+	return logs, nil
+}
+
+// ListNotifications returns a page of the logged notifications of all users matching the filter, the newest first
+func (usecase *AdminUseCase) ListNotifications(ctx context.Context, admin *entity.User, filter entity.NotificationFilter) ([]entity.NotificationDelivery, error) {
+	filter.Limit = pageSize(filter.Limit)
+
+	deliveries, err := usecase.notifications.SearchDeliveries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	usecase.record(ctx, admin, auditListNotifications, "", map[string]string{
+		"request_id": formatOptionalId(filter.RequestId),
+		"status":     string(filter.Status),
+		"limit":      strconv.FormatUint(filter.Limit, 10),
+		"offset":     strconv.FormatUint(filter.Offset, 10),
+	})
+
+	if deliveries == nil {
+		return []entity.NotificationDelivery{}, nil
+	}
+
+	return deliveries, nil
+}
+
+// ResendNotification sends a logged notification again to its recipient, returning the new delivery
+func (usecase *AdminUseCase) ResendNotification(ctx context.Context, admin *entity.User, id uint64) (*entity.NotificationDelivery, error) {
+	delivery, err := usecase.notifications.Resend(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	usecase.record(ctx, admin, auditResendNotification, formatId(id), map[string]string{
+		"delivery_id": formatId(delivery.ID),
+		"status":      string(delivery.Status),
+	})
+
+	return delivery, nil
+}
+
+// record adds the action to the audit log, the failures are logged as the action was already done
+func (usecase *AdminUseCase) record(ctx context.Context, admin *entity.User, action string, targetId string, details map[string]string) {
+	log := &entity.AuditLog{
+		ActorId:    admin.Id,
+		ActorEmail: admin.Email,
+		Action:     action,
+		TargetId:   targetId,
+		Details:    details,
+		CreatedAt:  time.Now(),
+	}
+
+	if log.Details == nil {
+		log.Details = map[string]string{}
+	}
+
+	if err := usecase.audit.AddAuditLog(ctx, log); err != nil {
+		slog.Error("Error recording audit log", "action", action, "actor", admin.Id, "target", targetId, "error", err)
+	}
+}
+
+// pageSize applies the default and the maximum to the informed limit
+func pageSize(limit uint64) uint64 {
+	if limit == 0 {
+		return DefaultPageSize
+	}
+	return min(limit, MaxPageSize)
+}
+
+func formatId(id uint64) string {
+	return strconv.FormatUint(id, 10)
+}
+
+func formatOptionalId(id uint64) string {
+	if id == 0 {
+		return ""
+	}
+	return formatId(id)
+}
+
+func formatTime(value time.Time) string {
+	if value.IsZero() {
+		return ""
+	}
+	return value.Format(time.RFC3339)
+}
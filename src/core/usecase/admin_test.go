@@ -0,0 +1,356 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"example/web-service-gin/src/adapters/handler/notification"
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/usecase"
+	"example/web-service-gin/src/utils/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRequestEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockRequestEventRepository) AddEvent(ctx context.Context, event *entity.RequestEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockRequestEventRepository) GetRequestEvents(ctx context.Context, requestId uint64) ([]entity.RequestEvent, error) {
+	args := m.Called(ctx, requestId)
+	return args.Get(0).([]entity.RequestEvent), args.Error(1)
+}
+
+func (m *MockRequestEventRepository) GetUserEventsAfter(ctx context.Context, userId string, afterId uint64, limit uint64) ([]entity.RequestEvent, error) {
+	args := m.Called(ctx, userId, afterId, limit)
+	return args.Get(0).([]entity.RequestEvent), args.Error(1)
+}
+
+type MockAuditLogRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditLogRepository) AddAuditLog(ctx context.Context, log *entity.AuditLog) error {
+	args := m.Called(ctx, log)
+	return args.Error(0)
+}
+
+func (m *MockAuditLogRepository) GetAuditLogs(ctx context.Context, limit uint64, offset uint64) ([]entity.AuditLog, error) {
+	args := m.Called(ctx, limit, offset)
+	return args.Get(0).([]entity.AuditLog), args.Error(1)
+}
+
+type MockNotificationLog struct {
+	mock.Mock
+}
+
+func (m *MockNotificationLog) SearchDeliveries(ctx context.Context, filter entity.NotificationFilter) ([]entity.NotificationDelivery, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]entity.NotificationDelivery), args.Error(1)
+}
+
+func (m *MockNotificationLog) Resend(ctx context.Context, id uint64) (*entity.NotificationDelivery, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.NotificationDelivery), args.Error(1)
+}
+
+type adminMocks struct {
+	repo          *MockRequestRepository
+	events        *MockRequestEventRepository
+	audit         *MockAuditLogRepository
+	queue         *MockRequestNotifications
+	mail          *MockMailService
+	notifications *MockNotificationLog
+}
+
+func adminUser() *entity.User {
+	return &entity.User{Id: "admin-1", Email: "support@example.com", Groups: []string{"admin"}}
+}
+
+func setUpAdmin() (*adminMocks, *usecase.AdminUseCase) {
+	m := &adminMocks{
+		repo:          new(MockRequestRepository),
+		events:        new(MockRequestEventRepository),
+		audit:         new(MockAuditLogRepository),
+		queue:         new(MockRequestNotifications),
+		mail:          new(MockMailService),
+		notifications: new(MockNotificationLog),
+	}
+
+	m.events.On("AddEvent", mock.Anything, mock.Anything).Return(nil)
+	m.audit.On("AddAuditLog", mock.Anything, mock.Anything).Return(nil)
+
+	return m, usecase.NewAdminUseCase(m.repo, m.events, usecase.NewRequestHistory(m.events, nil, nil, nil), new(MockStoragePort), m.audit, m.queue, m.mail, m.notifications)
+}
+
+// auditedAction matches the audit log of the action done by the admin
+func auditedAction(action string, targetId string) interface{} {
+	return mock.MatchedBy(func(log *entity.AuditLog) bool {
+		return log.Action == action && log.TargetId == targetId && log.ActorId == "admin-1" && log.ActorEmail == "support@example.com"
+	})
+}
+
+func TestAdminSearchRequests(t *testing.T) {
+	m, admin := setUpAdmin()
+	ctx := context.Background()
+
+	// When
+	m.repo.On("SearchRequests", ctx, entity.RequestFilter{Status: entity.Failed, Limit: usecase.DefaultPageSize}).
+		Return([]entity.Request{mocks.MockGetRequest()}, nil)
+	requests, err := admin.SearchRequests(ctx, adminUser(), entity.RequestFilter{Status: entity.Failed})
+
+	// Then
+	assert.NoError(t, err)
+	assert.Len(t, requests, 1)
+	m.audit.AssertCalled(t, "AddAuditLog", ctx, mock.MatchedBy(func(log *entity.AuditLog) bool {
+		return log.Action == "requests.search" && log.Details["status"] == "FAILED"
+	}))
+}
+
+func TestAdminSearchRequests_LimitsPageSize(t *testing.T) {
+	m, admin := setUpAdmin()
+	ctx := context.Background()
+
+	m.repo.On("SearchRequests", ctx, mock.MatchedBy(func(filter entity.RequestFilter) bool {
+		return filter.Limit == usecase.MaxPageSize
+	})).Return([]entity.Request(nil), nil)
+	requests, err := admin.SearchRequests(ctx, adminUser(), entity.RequestFilter{Limit: 10000})
+
+	assert.NoError(t, err)
+	assert.NotNil(t, requests)
+}
+
+func TestAdminGetRequest(t *testing.T) {
+	m, admin := setUpAdmin()
+	ctx := context.Background()
+	request := mocks.MockGetRequest()
+	history := []entity.RequestEvent{{RequestId: request.ID, Status: entity.Pending, Message: "request created"}}
+
+	// When
+	m.repo.On("GetById", ctx, request.ID).Return(&request, nil)
+	m.events.On("GetRequestEvents", ctx, request.ID).Return(history, nil)
+	found, events, err := admin.GetRequest(ctx, adminUser(), request.ID)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, request.ID, found.ID)
+	assert.Equal(t, history, events)
+	m.audit.AssertCalled(t, "AddAuditLog", ctx, auditedAction("requests.view", "1"))
+}
+
+func TestAdminGetRequest_NotFound(t *testing.T) {
+	m, admin := setUpAdmin()
+	ctx := context.Background()
+
+	m.repo.On("GetById", ctx, uint64(99)).Return((*entity.Request)(nil), core.ErrDataNotFound)
+	_, _, err := admin.GetRequest(ctx, adminUser(), 99)
+
+	assert.ErrorIs(t, err, core.ErrDataNotFound)
+	m.audit.AssertNotCalled(t, "AddAuditLog", mock.Anything, mock.Anything)
+}
+
+func TestAdminRetryRequest(t *testing.T) {
+	m, admin := setUpAdmin()
+	ctx := context.Background()
+	request := mocks.MockGetRequest()
+	request.Status = entity.Failed
+	request.FailureReason = "processing timed out"
+	request.Attempts = 3
+
+	// When
+	m.repo.On("GetById", ctx, request.ID).Return(&request, nil)
+	m.repo.On("UpdateRequest", ctx, mock.Anything).Return(&request, nil)
+	m.queue.On("SendVideoProccessToQueue", mock.Anything).Return(nil)
+	_, err := admin.RetryRequest(ctx, adminUser(), request.ID)
+
+	// Then
+	assert.NoError(t, err)
+	m.repo.AssertCalled(t, "UpdateRequest", ctx, mock.MatchedBy(func(r *entity.Request) bool {
+		return r.Status == entity.InProgress && r.Attempts == 4 && r.FailureReason == "" && r.FinishedAt.IsZero()
+	}))
+	m.queue.AssertCalled(t, "SendVideoProccessToQueue", mock.Anything)
+	m.events.AssertCalled(t, "AddEvent", ctx, mock.MatchedBy(func(e *entity.RequestEvent) bool {
+		return e.Actor == "admin-1" && e.Status == entity.InProgress
+	}))
+	m.audit.AssertCalled(t, "AddAuditLog", ctx, mock.MatchedBy(func(log *entity.AuditLog) bool {
+		return log.Action == "requests.retry" && log.Details["previous_status"] == "FAILED"
+	}))
+}
+
+func TestAdminRetryRequest_Rejected(t *testing.T) {
+	pending := mocks.MockGetRequest()
+	pending.Status = entity.Pending
+
+	duplicate := mocks.MockGetRequest()
+	duplicate.Status = entity.Completed
+	duplicate.DuplicateOf = 7
+
+	for name, request := range map[string]entity.Request{"pending": pending, "duplicate": duplicate} {
+		t.Run(name, func(t *testing.T) {
+			m, admin := setUpAdmin()
+			ctx := context.Background()
+
+			m.repo.On("GetById", ctx, request.ID).Return(&request, nil)
+			_, err := admin.RetryRequest(ctx, adminUser(), request.ID)
+
+			assert.ErrorIs(t, err, core.ErrConflictingData)
+			m.repo.AssertNotCalled(t, "UpdateRequest", mock.Anything, mock.Anything)
+			m.audit.AssertNotCalled(t, "AddAuditLog", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAdminFailRequest(t *testing.T) {
+	m, admin := setUpAdmin()
+	ctx := context.Background()
+	request := mocks.MockGetRequest()
+	request.Status = entity.InProgress
+
+	// When
+	m.repo.On("GetById", ctx, request.ID).Return(&request, nil)
+	m.repo.On("UpdateRequest", ctx, mock.Anything).Return(&request, nil)
+	m.mail.On("NotifyRequestStatus", mock.Anything, entity.NotificationRequestFailed).Return(nil)
+	_, err := admin.FailRequest(ctx, adminUser(), request.ID, "corrupted video")
+
+	// Then
+	assert.NoError(t, err)
+	m.repo.AssertCalled(t, "UpdateRequest", ctx, mock.MatchedBy(func(r *entity.Request) bool {
+		return r.Status == entity.Failed && r.FailureReason == "corrupted video" && !r.FinishedAt.IsZero()
+	}))
+	m.mail.AssertCalled(t, "NotifyRequestStatus", mock.Anything, entity.NotificationRequestFailed)
+	m.audit.AssertCalled(t, "AddAuditLog", ctx, mock.MatchedBy(func(log *entity.AuditLog) bool {
+		return log.Action == "requests.fail" && log.Details["reason"] == "corrupted video"
+	}))
+}
+
+func TestAdminFailRequest_PublishesDomainEvent(t *testing.T) {
+	m, _ := setUpAdmin()
+	publisher := notification.NewMemoryEventPublisher()
+	admin := usecase.NewAdminUseCase(m.repo, m.events, usecase.NewRequestHistory(m.events, publisher, nil, nil), new(MockStoragePort), m.audit, m.queue, m.mail, m.notifications)
+	ctx := context.Background()
+	request := mocks.MockGetRequest()
+	request.Status = entity.InProgress
+
+	// When
+	m.repo.On("GetById", ctx, request.ID).Return(&request, nil)
+	m.repo.On("UpdateRequest", ctx, mock.Anything).Return(&request, nil)
+	m.mail.On("NotifyRequestStatus", mock.Anything, entity.NotificationRequestFailed).Return(nil)
+	_, err := admin.FailRequest(ctx, adminUser(), request.ID, "corrupted video")
+
+	// Then the other services see the request failed by the admin
+	assert.NoError(t, err)
+	events := publisher.Events()
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, entity.EventRequestFailed, events[0].Type)
+		assert.Equal(t, request.ID, events[0].Request.ID)
+	}
+}
+
+func TestAdminFailRequest_Rejected(t *testing.T) {
+	m, admin := setUpAdmin()
+	ctx := context.Background()
+	request := mocks.MockGetRequest()
+	request.Status = entity.Completed
+
+	m.repo.On("GetById", ctx, request.ID).Return(&request, nil)
+
+	_, err := admin.FailRequest(ctx, adminUser(), request.ID, "corrupted video")
+	assert.ErrorIs(t, err, core.ErrConflictingData)
+
+	_, err = admin.FailRequest(ctx, adminUser(), request.ID, " ")
+	assert.ErrorIs(t, err, core.ErrInvalidInput)
+
+	m.repo.AssertNotCalled(t, "UpdateRequest", mock.Anything, mock.Anything)
+}
+
+func TestAdminCountByStatus(t *testing.T) {
+	m, admin := setUpAdmin()
+	ctx := context.Background()
+
+	// When
+	m.repo.On("CountByStatus", ctx).Return(map[entity.RequestStatus]int{entity.Completed: 10, entity.Failed: 2}, nil)
+	counts, err := admin.CountByStatus(ctx, adminUser())
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, map[entity.RequestStatus]int{
+		entity.Pending:    0,
+		entity.InProgress: 0,
+		entity.Completed:  10,
+		entity.Failed:     2,
+	}, counts)
+	m.audit.AssertCalled(t, "AddAuditLog", ctx, auditedAction("requests.count", ""))
+}
+
+func TestAdminListNotifications(t *testing.T) {
+	m, admin := setUpAdmin()
+	ctx := context.Background()
+
+	// When
+	m.notifications.On("SearchDeliveries", ctx, entity.NotificationFilter{Status: entity.DeliveryFailed, Limit: usecase.DefaultPageSize}).
+		Return([]entity.NotificationDelivery(nil), nil)
+	deliveries, err := admin.ListNotifications(ctx, adminUser(), entity.NotificationFilter{Status: entity.DeliveryFailed})
+
+	// Then
+	assert.NoError(t, err)
+	assert.NotNil(t, deliveries)
+	assert.Empty(t, deliveries)
+	m.audit.AssertCalled(t, "AddAuditLog", ctx, mock.MatchedBy(func(log *entity.AuditLog) bool {
+		return log.Action == "notifications.list" && log.Details["status"] == "FAILED" && log.Details["request_id"] == ""
+	}))
+}
+
+func TestAdminResendNotification(t *testing.T) {
+	m, admin := setUpAdmin()
+	ctx := context.Background()
+
+	// When
+	m.notifications.On("Resend", ctx, uint64(3)).Return(&entity.NotificationDelivery{ID: 10, Status: entity.DeliverySucceeded}, nil)
+	delivery, err := admin.ResendNotification(ctx, adminUser(), 3)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), delivery.ID)
+	m.audit.AssertCalled(t, "AddAuditLog", ctx, mock.MatchedBy(func(log *entity.AuditLog) bool {
+		return log.Action == "notifications.resend" && log.TargetId == "3" && log.Details["delivery_id"] == "10"
+	}))
+}
+
+func TestAdminResendNotification_NotFound(t *testing.T) {
+	m, admin := setUpAdmin()
+	ctx := context.Background()
+
+	// When
+	m.notifications.On("Resend", ctx, uint64(3)).Return(nil, core.ErrDataNotFound)
+	_, err := admin.ResendNotification(ctx, adminUser(), 3)
+
+	// Then
+	assert.ErrorIs(t, err, core.ErrDataNotFound)
+	m.audit.AssertNotCalled(t, "AddAuditLog", mock.Anything, mock.Anything)
+}
+
+func TestAdminActions_AuditFailureIsNotFatal(t *testing.T) {
+	m := &adminMocks{
+		repo:   new(MockRequestRepository),
+		events: new(MockRequestEventRepository),
+		audit:  new(MockAuditLogRepository),
+	}
+	admin := usecase.NewAdminUseCase(m.repo, m.events, usecase.NewRequestHistory(m.events, nil, nil, nil), new(MockStoragePort), m.audit, m.queue, m.mail, m.notifications)
+	ctx := context.Background()
+
+	m.audit.On("AddAuditLog", ctx, mock.Anything).Return(errors.New("connection refused"))
+	m.repo.On("CountByStatus", ctx).Return(map[entity.RequestStatus]int{}, nil)
+	_, err := admin.CountByStatus(ctx, adminUser())
+
+	assert.NoError(t, err)
+}
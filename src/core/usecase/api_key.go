@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/port"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ApiKeyPrefix starts all the keys, so they are easy to spot on leaked code or logs
+	ApiKeyPrefix = "vsk_"
+	// apiKeyDisplayLength is the amount of characters of the key kept to identify it
+	apiKeyDisplayLength = len(ApiKeyPrefix) + 8
+	maxApiKeyNameLength = 100
+)
+
+type ApiKeyUseCase struct {
+	repository port.ApiKeyRepository
+	// owners is the owner last recorded by this instance for each user
+	owners sync.Map
+}
+
+// NewApiKeyUseCase creates a new instance of the API keys use case
+func NewApiKeyUseCase(repo port.ApiKeyRepository) *ApiKeyUseCase {
+	return &ApiKeyUseCase{repository: repo}
+}
+
+// Create generates a new key for the user, the plain key is only returned
+// here as the database keeps its hash
+func (usecase *ApiKeyUseCase) Create(ctx context.Context, user *entity.User, name string, scopes []string, expiresAt time.Time) (*entity.ApiKey, string, error) {
+	err := validateApiKey(name, scopes, expiresAt)
+	if err != nil {
+		return nil, "", err
+	}
+
+	plainKey, err := generateApiKey()
+	if err != nil {
+		return nil, "", fmt.Errorf("error generating API key: %w", err)
+	}
+
+	key := &entity.ApiKey{
+		UserId:    user.Id,
+		UserEmail: user.Email,
+		Name:      name,
+		Prefix:    plainKey[:apiKeyDisplayLength],
+		KeyHash:   hashApiKey(plainKey),
+		Scopes:    scopes,
+		CreatedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	}
+
+	key, err = usecase.repository.CreateApiKey(ctx, key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return key, plainKey, nil
+}
+
+// List returns the keys of the user that are not revoked
+func (usecase *ApiKeyUseCase) List(ctx context.Context, userId string) ([]entity.ApiKey, error) {
+	return usecase.repository.GetAllUserApiKeys(ctx, userId)
+}
+
+// Revoke disables a key of the user, the next requests using it are rejected
+func (usecase *ApiKeyUseCase) Revoke(ctx context.Context, id uint64, userId string) error {
+	return usecase.repository.RevokeApiKey(ctx, id, userId)
+}
+
+// GetUser resolves the owner of the key, rejecting the unknown, revoked
+// and expired keys with core.ErrInvalidApiKey
+func (usecase *ApiKeyUseCase) GetUser(ctx context.Context, plainKey string) (*entity.User, error) {
+	if !strings.HasPrefix(plainKey, ApiKeyPrefix) {
+		return nil, core.ErrInvalidApiKey
+	}
+
+	key, err := usecase.repository.GetByKeyHash(ctx, hashApiKey(plainKey))
+
+	if errors.Is(err, core.ErrDataNotFound) {
+		return nil, core.ErrInvalidApiKey
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if key.IsExpired(now) {
+		return nil, fmt.Errorf("%w: expired at %s", core.ErrInvalidApiKey, key.ExpiresAt.Format(time.RFC3339))
+	}
+
+	// The usage is informational, a failure to register it doesn't reject the request
+	if err := usecase.repository.UpdateLastUsed(ctx, key.ID, now); err != nil {
+		slog.Error("Error updating API key last use", "key", key.ID, "error", err)
+	}
+
+	return &entity.User{
+		Id:             key.UserId,
+		Email:          key.UserEmail,
+		EmailVerified:  key.EmailVerified,
+		OrganizationId: key.OrganizationId,
+		Plan:           key.Plan,
+		ApiKey:         key,
+	}, nil
+}
+
+// RecordOwner saves the email with its verification, the organization and the plan informed by the token
+// of the user, which the API keys of the user follow. It is only written when it changed since this
+// instance last recorded it, and a failure is logged as the user is already authenticated
+func (usecase *ApiKeyUseCase) RecordOwner(ctx context.Context, user *entity.User) {
+	owner := apiKeyOwner{user.Email, user.EmailVerified, user.OrganizationId, user.Plan}
+
+	if recorded, exists := usecase.owners.Load(user.Id); exists && recorded == owner {
+		return
+	}
+
+	if err := usecase.repository.SaveOwner(ctx, user); err != nil {
+		slog.Error("Error recording API key owner", "user", user.Id, "error", err)
+		return
+	}
+
+	usecase.owners.Store(user.Id, owner)
+}
+
+// apiKeyOwner is what is recorded of an owner of API keys
+type apiKeyOwner struct {
+	email          string
+	verified       bool
+	organizationId string
+	plan           string
+}
+
+// validateApiKey checks the name, the scopes and the expiration informed by the user
+func validateApiKey(name string, scopes []string, expiresAt time.Time) error {
+	if strings.TrimSpace(name) == "" || len(name) > maxApiKeyNameLength {
+		return core.NewValidationError(core.ErrInvalidInput, fmt.Sprintf("name is required and must have at most %d characters", maxApiKeyNameLength))
+	}
+
+	if len(scopes) == 0 {
+		return core.NewValidationError(core.ErrInvalidInput, "at least one scope is required")
+	}
+
+	for _, scope := range scopes {
+		if !slices.Contains(entity.ApiKeyScopes, scope) {
+			return core.NewValidationError(core.ErrInvalidInput,
+				fmt.Sprintf("invalid scope %q, the allowed scopes are %s", scope, strings.Join(entity.ApiKeyScopes, ", ")))
+		}
+	}
+
+	if !expiresAt.IsZero() && !expiresAt.After(time.Now()) {
+		return core.NewValidationError(core.ErrInvalidInput, "expiration must be in the future")
+	}
+
+	return nil
+}
+
+// generateApiKey creates a random key with 256 bits of entropy
+func generateApiKey() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return ApiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// hashApiKey returns the SHA-256 of the key, a slow hash isn't needed as the keys are random
+func hashApiKey(plainKey string) string {
+	sum := sha256.Sum256([]byte(plainKey))
+	return hex.EncodeToString(sum[:])
+}
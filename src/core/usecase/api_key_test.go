@@ -0,0 +1,207 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/usecase"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockApiKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockApiKeyRepository) CreateApiKey(ctx context.Context, key *entity.ApiKey) (*entity.ApiKey, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ApiKey), args.Error(1)
+}
+
+func (m *MockApiKeyRepository) GetByKeyHash(ctx context.Context, keyHash string) (*entity.ApiKey, error) {
+	args := m.Called(ctx, keyHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ApiKey), args.Error(1)
+}
+
+func (m *MockApiKeyRepository) GetAllUserApiKeys(ctx context.Context, userId string) ([]entity.ApiKey, error) {
+	args := m.Called(ctx, userId)
+	return args.Get(0).([]entity.ApiKey), args.Error(1)
+}
+
+func (m *MockApiKeyRepository) RevokeApiKey(ctx context.Context, id uint64, userId string) error {
+	args := m.Called(ctx, id, userId)
+	return args.Error(0)
+}
+
+func (m *MockApiKeyRepository) UpdateLastUsed(ctx context.Context, id uint64, usedAt time.Time) error {
+	args := m.Called(ctx, id, usedAt)
+	return args.Error(0)
+}
+
+func (m *MockApiKeyRepository) SaveOwner(ctx context.Context, user *entity.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func setUpApiKeys() (*MockApiKeyRepository, *usecase.ApiKeyUseCase) {
+	mockRepo := new(MockApiKeyRepository)
+	return mockRepo, usecase.NewApiKeyUseCase(mockRepo)
+}
+
+func TestApiKeyCreate(t *testing.T) {
+	repo, apiKeys := setUpApiKeys()
+	ctx := context.Background()
+	user := &entity.User{Id: "123456", Email: "user@example.com", EmailVerified: true, OrganizationId: "org-1"}
+
+	// When
+	var key *entity.ApiKey
+	repo.On("CreateApiKey", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		key = args.Get(1).(*entity.ApiKey)
+	}).Return(&entity.ApiKey{ID: 1}, nil)
+	created, plainKey, err := apiKeys.Create(ctx, user, "CI pipeline", []string{entity.ScopeRequestsWrite}, time.Time{})
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), created.ID)
+	assert.True(t, strings.HasPrefix(plainKey, usecase.ApiKeyPrefix))
+	assert.True(t, strings.HasPrefix(plainKey, key.Prefix))
+	assert.NotContains(t, key.KeyHash, plainKey)
+	assert.Len(t, key.KeyHash, 64)
+	assert.Equal(t, "123456", key.UserId)
+	assert.Empty(t, key.OrganizationId)
+}
+
+func TestApiKeyCreate_InvalidInput(t *testing.T) {
+	cases := map[string]struct {
+		name      string
+		scopes    []string
+		expiresAt time.Time
+	}{
+		"empty name":        {"", []string{entity.ScopeRequestsRead}, time.Time{}},
+		"without scopes":    {"CI", nil, time.Time{}},
+		"unknown scope":     {"CI", []string{"admin"}, time.Time{}},
+		"already expired":   {"CI", []string{entity.ScopeRequestsRead}, time.Now().Add(-time.Hour)},
+		"name is too large": {strings.Repeat("a", 101), []string{entity.ScopeRequestsRead}, time.Time{}},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			repo, apiKeys := setUpApiKeys()
+
+			_, _, err := apiKeys.Create(context.Background(), &entity.User{Id: "123456"}, test.name, test.scopes, test.expiresAt)
+
+			assert.ErrorIs(t, err, core.ErrInvalidInput)
+			repo.AssertNotCalled(t, "CreateApiKey", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestApiKeyGetUser(t *testing.T) {
+	repo, apiKeys := setUpApiKeys()
+	ctx := context.Background()
+	key := &entity.ApiKey{ID: 1, UserId: "123456", UserEmail: "user@example.com", EmailVerified: true, OrganizationId: "org-1", Plan: "free",
+		Scopes: []string{entity.ScopeRequestsRead}}
+
+	// When
+	repo.On("GetByKeyHash", ctx, mock.Anything).Return(key, nil)
+	repo.On("UpdateLastUsed", ctx, uint64(1), mock.Anything).Return(nil)
+	user, err := apiKeys.GetUser(ctx, "vsk_secret")
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "123456", user.Id)
+	assert.Equal(t, "user@example.com", user.Email)
+	assert.True(t, user.EmailVerified)
+	assert.Equal(t, "org-1", user.OrganizationId)
+	assert.Equal(t, "free", user.Plan)
+	assert.True(t, user.HasScope(entity.ScopeRequestsRead))
+	assert.False(t, user.HasScope(entity.ScopeRequestsWrite))
+	repo.AssertCalled(t, "UpdateLastUsed", ctx, uint64(1), mock.Anything)
+}
+
+func TestApiKeyGetUser_Rejected(t *testing.T) {
+	cases := map[string]struct {
+		key   *entity.ApiKey
+		err   error
+		plain string
+	}{
+		"unknown key":    {nil, core.ErrDataNotFound, "vsk_unknown"},
+		"expired key":    {&entity.ApiKey{ID: 1, ExpiresAt: time.Now().Add(-time.Minute)}, nil, "vsk_expired"},
+		"without prefix": {nil, nil, "secret"},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			repo, apiKeys := setUpApiKeys()
+			ctx := context.Background()
+
+			repo.On("GetByKeyHash", ctx, mock.Anything).Return(test.key, test.err)
+			user, err := apiKeys.GetUser(ctx, test.plain)
+
+			assert.Nil(t, user)
+			assert.ErrorIs(t, err, core.ErrInvalidApiKey)
+			assert.ErrorIs(t, err, core.ErrUnauthorized)
+			repo.AssertNotCalled(t, "UpdateLastUsed", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestApiKeyGetUser_RepositoryError(t *testing.T) {
+	repo, apiKeys := setUpApiKeys()
+	ctx := context.Background()
+
+	repo.On("GetByKeyHash", ctx, mock.Anything).Return(nil, errors.New("connection refused"))
+	_, err := apiKeys.GetUser(ctx, "vsk_secret")
+
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, core.ErrUnauthorized)
+}
+
+func TestApiKeyRecordOwner(t *testing.T) {
+	repo, apiKeys := setUpApiKeys()
+	ctx := context.Background()
+	user := &entity.User{Id: "123456", Email: "user@example.com"}
+
+	// When
+	repo.On("SaveOwner", ctx, mock.Anything).Return(nil)
+	apiKeys.RecordOwner(ctx, user)
+	apiKeys.RecordOwner(ctx, user)
+	apiKeys.RecordOwner(ctx, &entity.User{Id: "123456", Email: "user@example.com", EmailVerified: true})
+	apiKeys.RecordOwner(ctx, &entity.User{Id: "123456", Email: "user@example.com", EmailVerified: true, Plan: "free"})
+
+	// Then
+	repo.AssertNumberOfCalls(t, "SaveOwner", 3)
+	repo.AssertCalled(t, "SaveOwner", ctx, mock.MatchedBy(func(owner *entity.User) bool {
+		return owner.EmailVerified
+	}))
+	repo.AssertCalled(t, "SaveOwner", ctx, mock.MatchedBy(func(owner *entity.User) bool {
+		return owner.Plan == "free"
+	}))
+}
+
+func TestApiKeyRecordOwner_RetriesFailures(t *testing.T) {
+	repo, apiKeys := setUpApiKeys()
+	ctx := context.Background()
+	user := &entity.User{Id: "123456", Email: "user@example.com", EmailVerified: true}
+
+	// When
+	repo.On("SaveOwner", ctx, user).Return(errors.New("connection refused")).Once()
+	repo.On("SaveOwner", ctx, user).Return(nil)
+	apiKeys.RecordOwner(ctx, user)
+	apiKeys.RecordOwner(ctx, user)
+	apiKeys.RecordOwner(ctx, user)
+
+	// Then
+	repo.AssertNumberOfCalls(t, "SaveOwner", 2)
+}
@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/port"
+)
+
+// maxMissedEvents is the amount of events replayed when a stream resumes
+const maxMissedEvents = 500
+
+type EventStreamUseCase struct {
+	repository port.RequestEventRepository
+	broker     port.RequestEventBroker
+}
+
+// NewEventStreamUseCase creates a new instance of the streams of the status changes
+func NewEventStreamUseCase(repo port.RequestEventRepository, broker port.RequestEventBroker) *EventStreamUseCase {
+	return &EventStreamUseCase{repo, broker}
+}
+
+// Subscribe returns the live events of the user and, when lastEventId is informed, the ones recorded
+// after it. The subscription starts before the history is read, so no event is lost between them,
+// and the receiver must skip the live events already replayed
+func (usecase *EventStreamUseCase) Subscribe(ctx context.Context, userId string, lastEventId uint64) (*entity.RequestEventStream, error) {
+	events, unsubscribe := usecase.broker.Subscribe(userId)
+	stream := &entity.RequestEventStream{Events: events, Close: unsubscribe}
+
+	if lastEventId == 0 {
+		return stream, nil
+	}
+
+	missed, err := usecase.repository.GetUserEventsAfter(ctx, userId, lastEventId, maxMissedEvents)
+	if err != nil {
+		unsubscribe()
+		return nil, err
+	}
+
+	stream.Missed = missed
+	return stream, nil
+}
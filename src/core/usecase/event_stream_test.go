@@ -0,0 +1,78 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/usecase"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRequestEventBroker struct {
+	mock.Mock
+}
+
+func (m *MockRequestEventBroker) Publish(ctx context.Context, userId string, event entity.RequestEvent) error {
+	args := m.Called(ctx, userId, event)
+	return args.Error(0)
+}
+
+func (m *MockRequestEventBroker) Subscribe(userId string) (<-chan entity.RequestEvent, func()) {
+	args := m.Called(userId)
+	return args.Get(0).(<-chan entity.RequestEvent), args.Get(1).(func())
+}
+
+func setUpEventStream() (*MockRequestEventRepository, *MockRequestEventBroker, *usecase.EventStreamUseCase) {
+	mockEvents := new(MockRequestEventRepository)
+	mockBroker := new(MockRequestEventBroker)
+	return mockEvents, mockBroker, usecase.NewEventStreamUseCase(mockEvents, mockBroker)
+}
+
+func TestEventStreamSubscribe_Live(t *testing.T) {
+	events, broker, streams := setUpEventStream()
+	ctx := context.Background()
+	live := make(<-chan entity.RequestEvent)
+
+	// When
+	broker.On("Subscribe", "123456").Return(live, func() {})
+	stream, err := streams.Subscribe(ctx, "123456", 0)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, live, stream.Events)
+	assert.Empty(t, stream.Missed)
+	events.AssertNotCalled(t, "GetUserEventsAfter", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEventStreamSubscribe_Resume(t *testing.T) {
+	events, broker, streams := setUpEventStream()
+	ctx := context.Background()
+	missed := []entity.RequestEvent{{ID: 11, Status: entity.InProgress}, {ID: 12, Status: entity.Completed}}
+
+	// When
+	broker.On("Subscribe", "123456").Return(make(<-chan entity.RequestEvent), func() {})
+	events.On("GetUserEventsAfter", ctx, "123456", uint64(10), mock.Anything).Return(missed, nil)
+	stream, err := streams.Subscribe(ctx, "123456", 10)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, missed, stream.Missed)
+}
+
+func TestEventStreamSubscribe_ResumeError(t *testing.T) {
+	events, broker, streams := setUpEventStream()
+	ctx := context.Background()
+	unsubscribed := false
+
+	// When
+	broker.On("Subscribe", "123456").Return(make(<-chan entity.RequestEvent), func() { unsubscribed = true })
+	events.On("GetUserEventsAfter", ctx, "123456", uint64(10), mock.Anything).Return([]entity.RequestEvent(nil), errors.New("connection refused"))
+	_, err := streams.Subscribe(ctx, "123456", 10)
+
+	// Then
+	assert.Error(t, err)
+	assert.True(t, unsubscribed)
+}
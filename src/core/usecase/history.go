@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/port"
+	"log/slog"
+	"time"
+)
+
+// RequestHistory records the status changes on the history of the requests and publishes
+// them as domain events, to the webhooks and to the event streams of the users. A nil
+// repository, publisher, webhooks or broker disables each part, and their failures are only
+// logged so the history never stops the processing. The streams resume from the history, so
+// they need the repository. The same history is shared by every use case changing the requests
+type RequestHistory struct {
+	repository port.RequestEventRepository
+	publisher  port.EventPublisher
+	webhooks   port.WebhookPublisher
+	broker     port.RequestEventBroker
+}
+
+// NewRequestHistory creates the history recording the status changes on the events repository and
+// publishing them to the publisher of domain events, the webhooks and the broker of the event streams
+func NewRequestHistory(events port.RequestEventRepository, publisher port.EventPublisher, webhooks port.WebhookPublisher, broker port.RequestEventBroker) *RequestHistory {
+	return &RequestHistory{events, publisher, webhooks, broker}
+}
+
+// record appends the current status of the request to its history, a nil history records nothing
+func (history *RequestHistory) record(ctx context.Context, request *entity.Request, actor string, message string) {
+	if history == nil {
+		return
+	}
+
+	if eventType := entity.EventTypeOf(request.Status); eventType != "" {
+		history.publish(ctx, request, eventType)
+
+		if history.webhooks != nil {
+			history.webhooks.Publish(ctx, request, eventType)
+		}
+	}
+
+	if history.repository == nil {
+		return
+	}
+
+	event := &entity.RequestEvent{
+		RequestId: request.ID,
+		Status:    request.Status,
+		Message:   message,
+		Actor:     actor,
+		CreatedAt: time.Now(),
+	}
+
+	if err := history.repository.AddEvent(ctx, event); err != nil {
+		slog.Error("Error recording request event", "id", request.ID, "status", request.Status, "error", err)
+		return
+	}
+
+	if history.broker == nil {
+		return
+	}
+
+	if err := history.broker.Publish(ctx, request.UserId, *event); err != nil {
+		slog.Error("Error publishing request event", "id", request.ID, "event", event.ID, "error", err)
+	}
+}
+
+// publish sends the domain event of the transition of the request
+func (history *RequestHistory) publish(ctx context.Context, request *entity.Request, eventType string) {
+	if history.publisher == nil {
+		return
+	}
+
+	id, err := newEventId()
+	if err != nil {
+		slog.Error("Error generating domain event id", "id", request.ID, "event", eventType, "error", err)
+		return
+	}
+
+	event := entity.DomainEvent{ID: id, Type: eventType, OccurredAt: time.Now().UTC(), Request: *request}
+	if err := history.publisher.Publish(ctx, event); err != nil {
+		slog.Error("Error publishing domain event", "id", request.ID, "event", eventType, "error", err)
+	}
+}
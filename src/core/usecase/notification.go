@@ -0,0 +1,615 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/port"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// notificationBatchSize is the amount of deliveries retried on each run of RetryPending
+const notificationBatchSize = 20
+
+// NotificationSettings are the policies of the notifications. DefaultLocale is used when
+// neither the preferences nor the token of the user inform a supported locale. The failed
+// deliveries are retried after RetryDelay, doubled on each attempt up to MaxRetryDelay
+type NotificationSettings struct {
+	RequireVerifiedEmail bool
+	DefaultLocale        string
+	MaxAttempts          int
+	RetryDelay           time.Duration
+	MaxRetryDelay        time.Duration
+}
+
+// NotificationUseCase dispatches the notifications of the requests to the channels chosen by
+// each user, the email when they never chose, written from the templates on the locale of the
+// user. When the verified email policy is enabled the unverified addresses are skipped, and
+// whether the result was delivered is recorded on the request. Every delivery is logged and
+// the failed ones are retried by RetryPending. The results of the users who chose a digest are
+// held and summarized by SendDigests. It implements port.MailServicePort, so the use cases
+// notifying the users don't need to know the channels nor the policies
+type NotificationUseCase struct {
+	channels      map[entity.NotificationChannel]port.NotificationChannel
+	renderer      port.NotificationRenderer
+	notifications port.NotificationRepository
+	repository    port.RequestRepository
+	storage       port.StoragePort
+	settings      NotificationSettings
+}
+
+// NewNotificationUseCase creates a new instance of the notifications delivery, the channels
+// missing on the map are not configured and skipped
+func NewNotificationUseCase(channels map[entity.NotificationChannel]port.NotificationChannel, renderer port.NotificationRenderer, notifications port.NotificationRepository, repo port.RequestRepository, storage port.StoragePort, settings NotificationSettings) *NotificationUseCase {
+	return &NotificationUseCase{channels, renderer, notifications, repo, storage, settings}
+}
+
+// NotifyRequestStatus sends the result of the request on each channel of the user, the delivery fails when any
+// channel fails. The result is held for the digest when the user chose one
+func (usecase *NotificationUseCase) NotifyRequestStatus(request *entity.Request, kind entity.NotificationKind) error {
+	preferences := usecase.loadPreferences(context.Background(), request.UserId)
+
+	if kind.IsRequestResult() && (preferences.Digest == entity.DigestDaily || preferences.Digest == entity.DigestWeekly) {
+		usecase.updateStatus(request, entity.NotificationDigested)
+		return nil
+	}
+
+	sent, err := usecase.notify(request, preferences, entity.Notification{Kind: kind, Request: request})
+
+	switch {
+	case err != nil:
+		usecase.updateStatus(request, entity.NotificationFailed)
+	case sent > 0:
+		usecase.updateStatus(request, entity.NotificationSent)
+	default:
+		usecase.updateStatus(request, entity.NotificationSkipped)
+	}
+
+	return err
+}
+
+// NotifyQuotaWarning warns the user who created the request, the status of the request is only about its result
+func (usecase *NotificationUseCase) NotifyQuotaWarning(request *entity.Request, warning entity.QuotaWarning) error {
+	preferences := usecase.loadPreferences(context.Background(), request.UserId)
+
+	_, err := usecase.notify(request, preferences, entity.Notification{Kind: entity.NotificationQuotaWarning, Request: request, Quota: &warning})
+	return err
+}
+
+// SendDigests summarizes the held results of every finished day or week, in UTC, whatever the
+// digest the user chooses now, so no result is held forever when the scheduler doesn't run or
+// the user changes the digest. The digests are claimed before they are sent, so a restart or
+// another instance never sends the same period twice
+func (usecase *NotificationUseCase) SendDigests(ctx context.Context) {
+	now := time.Now().UTC()
+	today, _ := digestPeriodOf(entity.DigestDaily, now)
+
+	userIds, err := usecase.repository.GetDigestUserIds(ctx, today)
+	if err != nil {
+		slog.Error("Error searching users of the digests", "error", err)
+		return
+	}
+
+	for _, userId := range userIds {
+		usecase.sendDigests(ctx, userId, now)
+	}
+}
+
+// sendDigests sends a digest for each finished period with results of the user held. The periods are
+// of the digest the user chooses now, the results held before the user chose to be notified
+// immediately are sent on daily digests
+func (usecase *NotificationUseCase) sendDigests(ctx context.Context, userId string, now time.Time) {
+	preferences := usecase.loadPreferences(ctx, userId)
+
+	mode := entity.DigestDaily
+	if preferences.Digest == entity.DigestWeekly {
+		mode = entity.DigestWeekly
+	}
+
+	currentStart, _ := digestPeriodOf(mode, now)
+	requests, err := usecase.repository.GetDigestRequests(ctx, userId, currentStart)
+	if err != nil {
+		slog.Error("Error searching requests of the digest", "user", userId, "mode", mode, "error", err)
+		return
+	}
+	signOutputs(usecase.storage, requests)
+
+	// The requests are the oldest first, so each period is a sequence of them
+	for len(requests) > 0 {
+		periodStart, periodEnd := digestPeriodOf(mode, requests[0].FinishedAt.UTC())
+
+		count := 1
+		for count < len(requests) && requests[count].FinishedAt.Before(periodEnd) {
+			count++
+		}
+
+		usecase.sendDigest(ctx, preferences, &entity.Digest{
+			UserId:      userId,
+			Mode:        mode,
+			PeriodStart: periodStart,
+			PeriodEnd:   periodEnd,
+			Requests:    requests[:count],
+		})
+		requests = requests[count:]
+	}
+}
+
+// sendDigest claims and sends the digest, recording on its requests whether it was delivered
+func (usecase *NotificationUseCase) sendDigest(ctx context.Context, preferences *entity.NotificationPreferences, digest *entity.Digest) {
+	for _, request := range digest.Requests {
+		if request.Status == entity.Completed {
+			digest.Completed++
+		} else {
+			digest.Failed++
+		}
+	}
+
+	claimed, err := usecase.notifications.ClaimDigest(ctx, digest)
+	if err != nil {
+		slog.Error("Error claiming digest", "user", digest.UserId, "mode", digest.Mode, "period", digest.PeriodStart, "error", err)
+		return
+	}
+
+	// The digest of the period was sent by another instance, or by a run that stopped before recording it
+	// on the requests, which are no longer held so they aren't read again on every run
+	if !claimed {
+		slog.Info("Digest already sent", "user", digest.UserId, "mode", digest.Mode, "period", digest.PeriodStart)
+		for i := range digest.Requests {
+			usecase.updateStatus(&digest.Requests[i], entity.NotificationSent)
+		}
+		return
+	}
+
+	// The last request informs the address, the locale of the token and where the deliveries are logged
+	latest := &digest.Requests[len(digest.Requests)-1]
+	sent, err := usecase.notify(latest, preferences, entity.Notification{Kind: entity.NotificationDigest, Request: latest, Digest: digest})
+
+	status := entity.NotificationSkipped
+	switch {
+	case err != nil:
+		slog.Error("Error sending digest", "user", digest.UserId, "mode", digest.Mode, "period", digest.PeriodStart, "error", err)
+		status = entity.NotificationFailed
+	case sent > 0:
+		status = entity.NotificationSent
+	}
+
+	for i := range digest.Requests {
+		usecase.updateStatus(&digest.Requests[i], status)
+	}
+}
+
+// digestPeriod returns the last day or week, starting on monday, finished before now
+func digestPeriod(mode entity.DigestMode, now time.Time) (time.Time, time.Time) {
+	currentStart, _ := digestPeriodOf(mode, now)
+	return digestPeriodOf(mode, currentStart.Add(-time.Nanosecond))
+}
+
+// digestPeriodOf returns the day or week, starting on monday, of the moment
+func digestPeriodOf(mode entity.DigestMode, moment time.Time) (time.Time, time.Time) {
+	day := time.Date(moment.Year(), moment.Month(), moment.Day(), 0, 0, 0, 0, time.UTC)
+
+	if mode == entity.DigestWeekly {
+		monday := day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+		return monday, monday.AddDate(0, 0, 7)
+	}
+
+	return day, day.AddDate(0, 0, 1)
+}
+
+// notify renders the notification on the locale of the user and sends it on each channel, returning how many delivered it
+func (usecase *NotificationUseCase) notify(request *entity.Request, preferences *entity.NotificationPreferences, notification entity.Notification) (int, error) {
+	ctx := context.Background()
+
+	notification.Locale = firstNotEmpty(matchLocale(preferences.Locale), matchLocale(request.UserLocale), usecase.settings.DefaultLocale)
+	if err := usecase.renderer.Render(&notification); err != nil {
+		return 0, fmt.Errorf("error rendering notification: %w", err)
+	}
+
+	sent := 0
+	var errs []error
+
+	for _, channel := range preferences.Channels {
+		if _, configured := usecase.channels[channel]; !configured {
+			slog.Warn("Skipping notification of channel not configured", "id", request.ID, "channel", channel)
+			continue
+		}
+
+		recipient := usecase.recipient(request, preferences, channel)
+		if recipient == "" {
+			continue
+		}
+
+		delivery := &entity.NotificationDelivery{
+			RequestId: request.ID,
+			UserId:    request.UserId,
+			Kind:      notification.Kind,
+			Channel:   channel,
+			Recipient: recipient,
+			Locale:    notification.Locale,
+			Subject:   notification.Subject,
+			Text:      notification.Text,
+			Html:      notification.Html,
+		}
+
+		// The notification is still sent when it can't be logged, but it is not retried
+		logged, err := usecase.createDelivery(ctx, delivery)
+		if err != nil {
+			slog.Error("Error logging notification", "id", request.ID, "channel", channel, "error", err)
+		} else {
+			delivery = logged
+		}
+
+		if err := usecase.deliver(ctx, delivery); err != nil {
+			errs = append(errs, fmt.Errorf("error notifying on %s: %w", channel, err))
+			continue
+		}
+
+		sent++
+	}
+
+	return sent, errors.Join(errs...)
+}
+
+// RetryPending sends again the failed deliveries whose attempt is due, with exponential backoff
+// until they use all the attempts
+func (usecase *NotificationUseCase) RetryPending(ctx context.Context) {
+	deliveries, err := usecase.notifications.ClaimDueDeliveries(ctx, notificationBatchSize, deliveryLease)
+	if err != nil {
+		slog.Error("Error searching pending notifications", "error", err)
+		return
+	}
+
+	for _, delivery := range deliveries {
+		if err := usecase.deliver(ctx, &delivery); err == nil {
+			usecase.refreshStatus(ctx, &delivery)
+		}
+	}
+}
+
+// SearchDeliveries returns a page of the logged deliveries matching the filter, the newest first
+func (usecase *NotificationUseCase) SearchDeliveries(ctx context.Context, filter entity.NotificationFilter) ([]entity.NotificationDelivery, error) {
+	return usecase.notifications.SearchDeliveries(ctx, filter)
+}
+
+// Resend sends the notification of a finished delivery again to the same recipient, as a new
+// delivery so the log keeps the original attempts. A failed attempt is retried like the others
+func (usecase *NotificationUseCase) Resend(ctx context.Context, id uint64) (*entity.NotificationDelivery, error) {
+	delivery, err := usecase.notifications.GetDelivery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if delivery.Status == entity.DeliveryPending {
+		return nil, core.NewValidationError(core.ErrConflictingData, "the notification is still being retried")
+	}
+
+	resent, err := usecase.createDelivery(ctx, &entity.NotificationDelivery{
+		RequestId: delivery.RequestId,
+		UserId:    delivery.UserId,
+		Kind:      delivery.Kind,
+		Channel:   delivery.Channel,
+		Recipient: delivery.Recipient,
+		Locale:    delivery.Locale,
+		Subject:   delivery.Subject,
+		Text:      delivery.Text,
+		Html:      delivery.Html,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := usecase.deliver(ctx, resent); err == nil {
+		usecase.refreshStatus(ctx, resent)
+	}
+
+	return resent, nil
+}
+
+// createDelivery logs a new pending delivery, postponed by the lease so RetryPending doesn't
+// send it while its first attempt is made
+func (usecase *NotificationUseCase) createDelivery(ctx context.Context, delivery *entity.NotificationDelivery) (*entity.NotificationDelivery, error) {
+	now := time.Now()
+	delivery.Status = entity.DeliveryPending
+	delivery.NextAttemptAt = now.Add(deliveryLease)
+	delivery.CreatedAt = now
+
+	return usecase.notifications.CreateDelivery(ctx, delivery)
+}
+
+// deliver makes an attempt of the delivery and records its result, returning the error of the channel
+func (usecase *NotificationUseCase) deliver(ctx context.Context, delivery *entity.NotificationDelivery) error {
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = now
+
+	var err error
+	if sender, configured := usecase.channels[delivery.Channel]; configured {
+		delivery.ProviderResponse, err = sender.Send(ctx, delivery.Recipient, entity.Notification{
+			Kind:    delivery.Kind,
+			Locale:  delivery.Locale,
+			Subject: delivery.Subject,
+			Text:    delivery.Text,
+			Html:    delivery.Html,
+		})
+	} else {
+		err = fmt.Errorf("channel %s is not configured", delivery.Channel)
+	}
+
+	switch {
+	case err == nil:
+		delivery.Status = entity.DeliverySucceeded
+		delivery.Error = ""
+	case delivery.Attempts >= usecase.settings.MaxAttempts:
+		delivery.Status = entity.DeliveryFailed
+		delivery.Error = err.Error()
+		slog.Warn("Notification delivery failed", "delivery", delivery.ID, "id", delivery.RequestId, "channel", delivery.Channel,
+			"attempts", delivery.Attempts, "error", err)
+	default:
+		delivery.Error = err.Error()
+		delivery.NextAttemptAt = now.Add(retryDelay(delivery.Attempts, usecase.settings.RetryDelay, usecase.settings.MaxRetryDelay))
+	}
+
+	// The deliveries that couldn't be logged have no id
+	if delivery.ID != 0 {
+		if updateErr := usecase.notifications.UpdateDelivery(ctx, delivery); updateErr != nil {
+			slog.Error("Error updating notification delivery", "delivery", delivery.ID, "status", delivery.Status, "error", updateErr)
+		}
+	}
+
+	return err
+}
+
+// refreshStatus records the request as notified once the last delivery of its result on each
+// channel succeeded, after a retry or a resend of a delivery that had failed
+func (usecase *NotificationUseCase) refreshStatus(ctx context.Context, delivery *entity.NotificationDelivery) {
+	if !delivery.Kind.IsRequestResult() {
+		return
+	}
+
+	deliveries, err := usecase.notifications.GetRequestDeliveries(ctx, delivery.RequestId)
+	if err != nil {
+		slog.Error("Error searching notifications of request", "id", delivery.RequestId, "error", err)
+		return
+	}
+
+	latest := map[entity.NotificationChannel]entity.DeliveryStatus{}
+	for _, logged := range deliveries {
+		if logged.Kind.IsRequestResult() {
+			latest[logged.Channel] = logged.Status
+		}
+	}
+
+	for _, status := range latest {
+		if status != entity.DeliverySucceeded {
+			return
+		}
+	}
+
+	if err := usecase.repository.UpdateNotificationStatus(ctx, delivery.RequestId, entity.NotificationSent); err != nil {
+		slog.Error("Error updating notification status", "id", delivery.RequestId, "status", entity.NotificationSent, "error", err)
+	}
+}
+
+// GetPreferences returns the notification preferences of the user, the default ones when never changed
+func (usecase *NotificationUseCase) GetPreferences(ctx context.Context, userId string) (*entity.NotificationPreferences, error) {
+	preferences, err := usecase.notifications.GetPreferences(ctx, userId)
+
+	if errors.Is(err, core.ErrDataNotFound) {
+		return entity.DefaultNotificationPreferences(userId), nil
+	}
+
+	return preferences, err
+}
+
+// UpdatePreferences replaces the notification preferences of the user, the chat channels
+// require their webhook URL. An empty list of channels disables the notifications, an empty
+// locale uses the one of the token and an empty digest notifies each result immediately
+func (usecase *NotificationUseCase) UpdatePreferences(ctx context.Context, preferences *entity.NotificationPreferences) (*entity.NotificationPreferences, error) {
+	channels := []entity.NotificationChannel{}
+
+	for _, channel := range preferences.Channels {
+		if !slices.Contains(entity.NotificationChannels, channel) {
+			return nil, core.NewValidationError(core.ErrInvalidInput,
+				fmt.Sprintf("invalid channel %q, the allowed channels are email, slack, teams", channel))
+		}
+
+		if !slices.Contains(channels, channel) {
+			channels = append(channels, channel)
+		}
+	}
+
+	if err := validateChatUrl("slack", preferences.SlackWebhookUrl, slices.Contains(channels, entity.ChannelSlack)); err != nil {
+		return nil, err
+	}
+
+	if err := validateChatUrl("teams", preferences.TeamsWebhookUrl, slices.Contains(channels, entity.ChannelTeams)); err != nil {
+		return nil, err
+	}
+
+	locale := matchLocale(preferences.Locale)
+	if preferences.Locale != "" && locale == "" {
+		return nil, core.NewValidationError(core.ErrInvalidInput, "locale must be one of "+strings.Join(entity.Locales, ", "))
+	}
+
+	if preferences.Digest == "" {
+		preferences.Digest = entity.DigestImmediate
+	}
+
+	if !slices.Contains(entity.DigestModes, preferences.Digest) {
+		return nil, core.NewValidationError(core.ErrInvalidInput, "digest must be one of immediate, daily, weekly")
+	}
+
+	preferences.Channels = channels
+	preferences.Locale = locale
+	preferences.UpdatedAt = time.Now()
+
+	return usecase.notifications.SavePreferences(ctx, preferences)
+}
+
+// loadPreferences returns the preferences of the user, a failure falls back to the default ones
+// so the user is still notified by email
+func (usecase *NotificationUseCase) loadPreferences(ctx context.Context, userId string) *entity.NotificationPreferences {
+	preferences, err := usecase.GetPreferences(ctx, userId)
+
+	if err != nil {
+		slog.Error("Error loading notification preferences", "user", userId, "error", err)
+		return entity.DefaultNotificationPreferences(userId)
+	}
+
+	return preferences
+}
+
+// recipient returns where the notification is sent on the channel, empty when it must be skipped
+func (usecase *NotificationUseCase) recipient(request *entity.Request, preferences *entity.NotificationPreferences, channel entity.NotificationChannel) string {
+	switch channel {
+	case entity.ChannelEmail:
+		if usecase.settings.RequireVerifiedEmail && !request.UserEmailVerified {
+			slog.Info("Skipping notification of unverified email address", "id", request.ID)
+			return ""
+		}
+		return request.UserEmail
+	case entity.ChannelSlack:
+		return preferences.SlackWebhookUrl
+	case entity.ChannelTeams:
+		return preferences.TeamsWebhookUrl
+	}
+
+	return ""
+}
+
+// updateStatus records the delivery on the request, the notification was already handled so a failure is only logged
+func (usecase *NotificationUseCase) updateStatus(request *entity.Request, status entity.NotificationStatus) {
+	request.NotificationStatus = status
+
+	if err := usecase.repository.UpdateNotificationStatus(context.Background(), request.ID, status); err != nil {
+		slog.Error("Error updating notification status", "id", request.ID, "status", status, "error", err)
+	}
+}
+
+// Preview renders a notification of the kind with sample data, on the default locale when it is empty
+func (usecase *NotificationUseCase) Preview(kind entity.NotificationKind, locale string) (*entity.Notification, error) {
+	if !slices.Contains(entity.NotificationKinds, kind) {
+		return nil, core.NewValidationError(core.ErrInvalidInput, fmt.Sprintf("invalid kind %q", kind))
+	}
+
+	if locale != "" && matchLocale(locale) == "" {
+		return nil, core.NewValidationError(core.ErrInvalidInput, "locale must be one of "+strings.Join(entity.Locales, ", "))
+	}
+
+	notification := &entity.Notification{Kind: kind, Locale: firstNotEmpty(matchLocale(locale), usecase.settings.DefaultLocale)}
+
+	now := time.Now()
+	notification.Request = &entity.Request{
+		ID:            42,
+		Status:        entity.Failed,
+		FailureReason: "the video could not be decoded",
+		CreatedAt:     now.Add(-time.Hour),
+		FinishedAt:    now,
+	}
+
+	switch kind {
+	case entity.NotificationRequestCompleted:
+		notification.Request.Status = entity.Completed
+		notification.Request.FailureReason = ""
+		notification.Request.ZipOutputUrl = "https://example.com/frames.zip"
+	case entity.NotificationRequestExpired:
+		notification.Request.FailureReason = "processing timed out after 3 attempts of 30m0s"
+	case entity.NotificationQuotaWarning:
+		notification.Request.Status = entity.Pending
+		notification.Quota = &entity.QuotaWarning{Limit: "requests_per_day", Used: 40, Max: 50, ResetAt: now.Truncate(24 * time.Hour).Add(24 * time.Hour)}
+	case entity.NotificationDigest:
+		completed := entity.Request{ID: 41, Status: entity.Completed, ZipOutputUrl: "https://example.com/frames.zip",
+			CreatedAt: now.Add(-2 * time.Hour), FinishedAt: now.Add(-time.Hour)}
+		periodStart, periodEnd := digestPeriod(entity.DigestDaily, now)
+		notification.Digest = &entity.Digest{Mode: entity.DigestDaily, PeriodStart: periodStart, PeriodEnd: periodEnd,
+			Completed: 1, Failed: 1, Requests: []entity.Request{completed, *notification.Request}}
+	}
+
+	if err := usecase.renderer.Render(notification); err != nil {
+		return nil, err
+	}
+
+	return notification, nil
+}
+
+// matchLocale returns the supported locale of a preference or a claim like "pt-BR", "pt_BR",
+// "es-419" or "EN", comparing only the language when there is no exact match. It is empty
+// when the locale is not supported
+func matchLocale(value string) string {
+	value = strings.ReplaceAll(strings.TrimSpace(value), "_", "-")
+	if value == "" {
+		return ""
+	}
+
+	for _, locale := range entity.Locales {
+		if strings.EqualFold(value, locale) {
+			return locale
+		}
+	}
+
+	language, _, _ := strings.Cut(value, "-")
+	for _, locale := range entity.Locales {
+		if localeLanguage, _, _ := strings.Cut(locale, "-"); strings.EqualFold(language, localeLanguage) {
+			return locale
+		}
+	}
+
+	return ""
+}
+
+// chatHosts are the hosts of the incoming webhooks of each chat, a leading dot accepts any subdomain
+var chatHosts = map[string][]string{
+	"slack": {"hooks.slack.com"},
+	"teams": {".webhook.office.com", ".logic.azure.com"},
+}
+
+// validateChatUrl checks the incoming webhook URL of a chat channel, required when the channel is chosen.
+// The URL must be on a host of the chat, so the notifications can't be posted anywhere else
+func validateChatUrl(channel string, webhookUrl string, required bool) error {
+	if webhookUrl == "" {
+		if required {
+			return core.NewValidationError(core.ErrInvalidInput, fmt.Sprintf("%s_webhook_url is required by the %s channel", channel, channel))
+		}
+		return nil
+	}
+
+	parsed, err := url.Parse(webhookUrl)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" || len(webhookUrl) > maxWebhookUrlLength {
+		return core.NewValidationError(core.ErrInvalidInput, fmt.Sprintf("%s_webhook_url must be an absolute https URL", channel))
+	}
+
+	if parsed.User != nil || parsed.Port() != "" || !isChatHost(channel, parsed.Hostname()) {
+		return core.NewValidationError(core.ErrInvalidInput,
+			fmt.Sprintf("%s_webhook_url must be on %s", channel, strings.Join(chatHosts[channel], ", ")))
+	}
+
+	return nil
+}
+
+// isChatHost tells whether the host is one of the hosts of the chat
+func isChatHost(channel string, host string) bool {
+	host = strings.ToLower(host)
+
+	for _, allowed := range chatHosts[channel] {
+		if host == allowed || (strings.HasPrefix(allowed, ".") && strings.HasSuffix(host, allowed)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func firstNotEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
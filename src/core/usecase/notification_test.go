@@ -0,0 +1,651 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/port"
+	"example/web-service-gin/src/core/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockNotificationChannel struct {
+	mock.Mock
+}
+
+func (m *MockNotificationChannel) Send(ctx context.Context, recipient string, notification entity.Notification) (string, error) {
+	args := m.Called(ctx, recipient, notification)
+	return args.String(0), args.Error(1)
+}
+
+type MockNotificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationRepository) GetPreferences(ctx context.Context, userId string) (*entity.NotificationPreferences, error) {
+	args := m.Called(ctx, userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.NotificationPreferences), args.Error(1)
+}
+
+func (m *MockNotificationRepository) SavePreferences(ctx context.Context, preferences *entity.NotificationPreferences) (*entity.NotificationPreferences, error) {
+	args := m.Called(ctx, preferences)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.NotificationPreferences), args.Error(1)
+}
+
+// CreateDelivery returns the informed delivery with an id, as the database would
+func (m *MockNotificationRepository) CreateDelivery(ctx context.Context, delivery *entity.NotificationDelivery) (*entity.NotificationDelivery, error) {
+	args := m.Called(ctx, delivery)
+	if args.Error(0) != nil {
+		return nil, args.Error(0)
+	}
+	created := *delivery
+	created.ID = 10
+	return &created, nil
+}
+
+func (m *MockNotificationRepository) GetDelivery(ctx context.Context, id uint64) (*entity.NotificationDelivery, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.NotificationDelivery), args.Error(1)
+}
+
+func (m *MockNotificationRepository) SearchDeliveries(ctx context.Context, filter entity.NotificationFilter) ([]entity.NotificationDelivery, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]entity.NotificationDelivery), args.Error(1)
+}
+
+func (m *MockNotificationRepository) GetRequestDeliveries(ctx context.Context, requestId uint64) ([]entity.NotificationDelivery, error) {
+	args := m.Called(ctx, requestId)
+	return args.Get(0).([]entity.NotificationDelivery), args.Error(1)
+}
+
+func (m *MockNotificationRepository) ClaimDueDeliveries(ctx context.Context, limit uint64, lease time.Duration) ([]entity.NotificationDelivery, error) {
+	args := m.Called(ctx, limit, lease)
+	return args.Get(0).([]entity.NotificationDelivery), args.Error(1)
+}
+
+func (m *MockNotificationRepository) UpdateDelivery(ctx context.Context, delivery *entity.NotificationDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) ClaimDigest(ctx context.Context, digest *entity.Digest) (bool, error) {
+	args := m.Called(ctx, digest)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockNotificationRenderer struct {
+	mock.Mock
+}
+
+func (m *MockNotificationRenderer) Render(notification *entity.Notification) error {
+	args := m.Called(notification)
+	return args.Error(0)
+}
+
+type notificationMocks struct {
+	email         *MockNotificationChannel
+	slack         *MockNotificationChannel
+	renderer      *MockNotificationRenderer
+	notifications *MockNotificationRepository
+	repo          *MockRequestRepository
+	storage       *MockStoragePort
+}
+
+func setUpNotification(requireVerified bool) (*notificationMocks, *usecase.NotificationUseCase) {
+	m := &notificationMocks{
+		email:         new(MockNotificationChannel),
+		slack:         new(MockNotificationChannel),
+		renderer:      new(MockNotificationRenderer),
+		notifications: new(MockNotificationRepository),
+		repo:          new(MockRequestRepository),
+		storage:       new(MockStoragePort),
+	}
+	m.repo.On("UpdateNotificationStatus", context.Background(), mock.Anything, mock.Anything).Return(nil)
+	m.renderer.On("Render", mock.Anything).Run(func(args mock.Arguments) {
+		notification := args.Get(0).(*entity.Notification)
+		notification.Subject = string(notification.Kind) + " on " + notification.Locale
+	}).Return(nil).Maybe()
+	m.notifications.On("CreateDelivery", mock.Anything, mock.Anything).Return(nil).Maybe()
+	m.notifications.On("UpdateDelivery", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	channels := map[entity.NotificationChannel]port.NotificationChannel{
+		entity.ChannelEmail: m.email,
+		entity.ChannelSlack: m.slack,
+	}
+
+	return m, usecase.NewNotificationUseCase(channels, m.renderer, m.notifications, m.repo, new(MockStoragePort), usecase.NotificationSettings{
+		RequireVerifiedEmail: requireVerified,
+		DefaultLocale:        "pt-BR",
+		MaxAttempts:          3,
+		RetryDelay:           time.Minute,
+		MaxRetryDelay:        time.Hour,
+	})
+}
+
+func TestNotifyRequestStatus_Sent(t *testing.T) {
+	m, notification := setUpNotification(true)
+	request := &entity.Request{ID: 1, UserId: "user-1", UserEmail: "user@example.com", UserEmailVerified: true, Status: entity.Completed}
+	m.notifications.On("GetPreferences", mock.Anything, "user-1").Return(nil, core.ErrDataNotFound)
+
+	// When
+	m.email.On("Send", mock.Anything, "user@example.com", mock.Anything).Return("202", nil)
+	err := notification.NotifyRequestStatus(request, entity.NotificationRequestCompleted)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, entity.NotificationSent, request.NotificationStatus)
+	m.repo.AssertCalled(t, "UpdateNotificationStatus", context.Background(), uint64(1), entity.NotificationSent)
+
+	sent := m.email.Calls[0].Arguments.Get(2).(entity.Notification)
+	assert.Equal(t, entity.NotificationRequestCompleted, sent.Kind)
+	assert.Equal(t, "completed on pt-BR", sent.Subject)
+	m.notifications.AssertCalled(t, "UpdateDelivery", mock.Anything, mock.MatchedBy(func(delivery *entity.NotificationDelivery) bool {
+		return delivery.ID == 10 && delivery.RequestId == 1 && delivery.Recipient == "user@example.com" &&
+			delivery.Status == entity.DeliverySucceeded && delivery.Attempts == 1 && delivery.ProviderResponse == "202"
+	}))
+}
+
+func TestNotifyRequestStatus_SkipsUnverified(t *testing.T) {
+	m, notification := setUpNotification(true)
+	request := &entity.Request{ID: 1, UserId: "user-1", UserEmail: "user@example.com"}
+	m.notifications.On("GetPreferences", mock.Anything, "user-1").Return(nil, core.ErrDataNotFound)
+
+	// When
+	err := notification.NotifyRequestStatus(request, entity.NotificationRequestCompleted)
+
+	// Then
+	assert.NoError(t, err)
+	m.email.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything)
+	m.repo.AssertCalled(t, "UpdateNotificationStatus", context.Background(), uint64(1), entity.NotificationSkipped)
+}
+
+func TestNotifyRequestStatus_PolicyDisabled(t *testing.T) {
+	m, notification := setUpNotification(false)
+	request := &entity.Request{ID: 1, UserId: "user-1", UserEmail: "user@example.com", Status: entity.Failed}
+	m.notifications.On("GetPreferences", mock.Anything, "user-1").Return(nil, core.ErrDataNotFound)
+
+	// When
+	m.email.On("Send", mock.Anything, "user@example.com", mock.Anything).Return("202", nil)
+	err := notification.NotifyRequestStatus(request, entity.NotificationRequestFailed)
+
+	// Then
+	assert.NoError(t, err)
+	m.email.AssertNumberOfCalls(t, "Send", 1)
+	m.repo.AssertCalled(t, "UpdateNotificationStatus", context.Background(), uint64(1), entity.NotificationSent)
+}
+
+func TestNotifyRequestStatus_DeliveryError(t *testing.T) {
+	m, notification := setUpNotification(true)
+	request := &entity.Request{ID: 1, UserId: "user-1", UserEmail: "user@example.com", UserEmailVerified: true}
+	m.notifications.On("GetPreferences", mock.Anything, "user-1").Return(nil, core.ErrDataNotFound)
+
+	// When
+	m.email.On("Send", mock.Anything, "user@example.com", mock.Anything).Return("", errors.New("sendgrid is down"))
+	err := notification.NotifyRequestStatus(request, entity.NotificationRequestCompleted)
+
+	// Then
+	assert.Error(t, err)
+	m.repo.AssertCalled(t, "UpdateNotificationStatus", context.Background(), uint64(1), entity.NotificationFailed)
+	m.notifications.AssertCalled(t, "UpdateDelivery", mock.Anything, mock.MatchedBy(func(delivery *entity.NotificationDelivery) bool {
+		return delivery.Status == entity.DeliveryPending && delivery.Attempts == 1 && delivery.Error == "sendgrid is down" &&
+			delivery.NextAttemptAt.After(time.Now().Add(50*time.Second))
+	}))
+}
+
+func TestNotifyRequestStatus_NotLoggedIsStillSent(t *testing.T) {
+	m := &notificationMocks{email: new(MockNotificationChannel), renderer: new(MockNotificationRenderer), notifications: new(MockNotificationRepository), repo: new(MockRequestRepository)}
+	m.repo.On("UpdateNotificationStatus", context.Background(), mock.Anything, mock.Anything).Return(nil)
+	m.renderer.On("Render", mock.Anything).Return(nil)
+	m.notifications.On("GetPreferences", mock.Anything, "user-1").Return(nil, core.ErrDataNotFound)
+	m.notifications.On("CreateDelivery", mock.Anything, mock.Anything).Return(errors.New("connection refused"))
+	notification := usecase.NewNotificationUseCase(map[entity.NotificationChannel]port.NotificationChannel{entity.ChannelEmail: m.email},
+		m.renderer, m.notifications, m.repo, new(MockStoragePort), usecase.NotificationSettings{DefaultLocale: "en", MaxAttempts: 3})
+	request := &entity.Request{ID: 1, UserId: "user-1", UserEmail: "user@example.com"}
+
+	// When
+	m.email.On("Send", mock.Anything, "user@example.com", mock.Anything).Return("202", nil)
+	err := notification.NotifyRequestStatus(request, entity.NotificationRequestCompleted)
+
+	// Then
+	assert.NoError(t, err)
+	m.email.AssertNumberOfCalls(t, "Send", 1)
+	m.notifications.AssertNotCalled(t, "UpdateDelivery", mock.Anything, mock.Anything)
+	m.repo.AssertCalled(t, "UpdateNotificationStatus", context.Background(), uint64(1), entity.NotificationSent)
+}
+
+func TestNotifyRequestStatus_ChosenChannels(t *testing.T) {
+	m, notification := setUpNotification(true)
+	request := &entity.Request{ID: 1, UserId: "user-1", UserEmail: "user@example.com", Status: entity.Failed, FailureReason: "corrupted video"}
+	m.notifications.On("GetPreferences", mock.Anything, "user-1").Return(&entity.NotificationPreferences{
+		UserId:          "user-1",
+		Channels:        []entity.NotificationChannel{entity.ChannelSlack, entity.ChannelTeams},
+		SlackWebhookUrl: "https://hooks.slack.com/services/T0/B0/X",
+	}, nil)
+
+	// When
+	m.slack.On("Send", mock.Anything, "https://hooks.slack.com/services/T0/B0/X", mock.Anything).Return("202", nil)
+	err := notification.NotifyRequestStatus(request, entity.NotificationRequestFailed)
+
+	// Then
+	assert.NoError(t, err)
+	m.email.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything)
+	m.repo.AssertCalled(t, "UpdateNotificationStatus", context.Background(), uint64(1), entity.NotificationSent)
+
+	sent := m.slack.Calls[0].Arguments.Get(2).(entity.Notification)
+	assert.Equal(t, "failed on pt-BR", sent.Subject)
+}
+
+func TestNotifyRequestStatus_Locale(t *testing.T) {
+	tests := map[string]struct {
+		preference string
+		claim      string
+		expected   string
+	}{
+		"preference first":      {preference: "es", claim: "en", expected: "es"},
+		"claim of the token":    {claim: "en-US", expected: "en"},
+		"unsupported claim":     {claim: "fr-FR", expected: "pt-BR"},
+		"claim with underscore": {claim: "pt_br", expected: "pt-BR"},
+		"default":               {expected: "pt-BR"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m, notification := setUpNotification(false)
+			request := &entity.Request{ID: 1, UserId: "user-1", UserEmail: "user@example.com", UserLocale: test.claim}
+			m.notifications.On("GetPreferences", mock.Anything, "user-1").Return(&entity.NotificationPreferences{
+				Channels: []entity.NotificationChannel{entity.ChannelEmail},
+				Locale:   test.preference,
+			}, nil)
+			m.email.On("Send", mock.Anything, "user@example.com", mock.Anything).Return("202", nil)
+
+			// When
+			err := notification.NotifyRequestStatus(request, entity.NotificationRequestCompleted)
+
+			// Then
+			assert.NoError(t, err)
+			sent := m.email.Calls[0].Arguments.Get(2).(entity.Notification)
+			assert.Equal(t, test.expected, sent.Locale)
+		})
+	}
+}
+
+func TestNotifyRequestStatus_RenderError(t *testing.T) {
+	m := &notificationMocks{email: new(MockNotificationChannel), renderer: new(MockNotificationRenderer), notifications: new(MockNotificationRepository), repo: new(MockRequestRepository)}
+	m.repo.On("UpdateNotificationStatus", context.Background(), mock.Anything, mock.Anything).Return(nil)
+	m.renderer.On("Render", mock.Anything).Return(errors.New("template: completed.txt: unexpected EOF"))
+	m.notifications.On("GetPreferences", mock.Anything, "user-1").Return(nil, core.ErrDataNotFound)
+	notification := usecase.NewNotificationUseCase(map[entity.NotificationChannel]port.NotificationChannel{entity.ChannelEmail: m.email},
+		m.renderer, m.notifications, m.repo, new(MockStoragePort), usecase.NotificationSettings{DefaultLocale: "en"})
+	request := &entity.Request{ID: 1, UserId: "user-1", UserEmail: "user@example.com"}
+
+	// When
+	err := notification.NotifyRequestStatus(request, entity.NotificationRequestCompleted)
+
+	// Then
+	assert.ErrorContains(t, err, "error rendering notification")
+	m.email.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything)
+	m.repo.AssertCalled(t, "UpdateNotificationStatus", context.Background(), uint64(1), entity.NotificationFailed)
+}
+
+func TestNotifyQuotaWarning(t *testing.T) {
+	m, notification := setUpNotification(false)
+	request := &entity.Request{ID: 1, UserId: "user-1", UserEmail: "user@example.com"}
+	warning := entity.QuotaWarning{Limit: "requests_per_day", Used: 40, Max: 50}
+	m.notifications.On("GetPreferences", mock.Anything, "user-1").Return(nil, core.ErrDataNotFound)
+
+	// When
+	m.email.On("Send", mock.Anything, "user@example.com", mock.Anything).Return("202", nil)
+	err := notification.NotifyQuotaWarning(request, warning)
+
+	// Then
+	assert.NoError(t, err)
+	m.repo.AssertNotCalled(t, "UpdateNotificationStatus", mock.Anything, mock.Anything, mock.Anything)
+
+	sent := m.email.Calls[0].Arguments.Get(2).(entity.Notification)
+	assert.Equal(t, entity.NotificationQuotaWarning, sent.Kind)
+	assert.Equal(t, "quota_warning on pt-BR", sent.Subject)
+	m.renderer.AssertCalled(t, "Render", mock.MatchedBy(func(notification *entity.Notification) bool {
+		return notification.Quota != nil && *notification.Quota == warning
+	}))
+}
+
+// failedDelivery is a delivery of the result of request 1 by email whose first attempt failed
+func failedDelivery() entity.NotificationDelivery {
+	return entity.NotificationDelivery{
+		ID:        3,
+		RequestId: 1,
+		UserId:    "user-1",
+		Kind:      entity.NotificationRequestCompleted,
+		Channel:   entity.ChannelEmail,
+		Recipient: "user@example.com",
+		Locale:    "en",
+		Subject:   "Your request #1 is ready",
+		Status:    entity.DeliveryPending,
+		Attempts:  1,
+		Error:     "sendgrid is down",
+	}
+}
+
+func TestNotifyRequestStatus_HeldForDigest(t *testing.T) {
+	m, notification := setUpNotification(true)
+	request := &entity.Request{ID: 1, UserId: "user-1", UserEmail: "user@example.com", UserEmailVerified: true, Status: entity.Completed}
+	m.notifications.On("GetPreferences", mock.Anything, "user-1").Return(&entity.NotificationPreferences{
+		UserId:   "user-1",
+		Channels: []entity.NotificationChannel{entity.ChannelEmail},
+		Digest:   entity.DigestWeekly,
+	}, nil)
+
+	// When
+	err := notification.NotifyRequestStatus(request, entity.NotificationRequestCompleted)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, entity.NotificationDigested, request.NotificationStatus)
+	m.repo.AssertCalled(t, "UpdateNotificationStatus", context.Background(), uint64(1), entity.NotificationDigested)
+	m.email.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything)
+	m.notifications.AssertNotCalled(t, "CreateDelivery", mock.Anything, mock.Anything)
+}
+
+func TestSendDigests(t *testing.T) {
+	m, notification := setUpNotification(true)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	periodEnd := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	periodStart := periodEnd.AddDate(0, 0, -1)
+
+	preferences := &entity.NotificationPreferences{UserId: "user-1", Channels: []entity.NotificationChannel{entity.ChannelEmail}, Digest: entity.DigestDaily}
+	m.repo.On("GetDigestUserIds", ctx, periodEnd).Return([]string{"user-1"}, nil)
+	m.notifications.On("GetPreferences", ctx, "user-1").Return(preferences, nil)
+	m.repo.On("GetDigestRequests", ctx, "user-1", periodEnd).Return([]entity.Request{
+		{ID: 1, UserId: "user-1", Status: entity.Completed, ZipOutputKey: "https://example.com/frames.zip", FinishedAt: periodStart.Add(time.Hour)},
+		{ID: 2, UserId: "user-1", UserEmail: "user@example.com", UserEmailVerified: true, UserLocale: "es", Status: entity.Failed, FinishedAt: periodStart.Add(2 * time.Hour)},
+	}, nil)
+	m.notifications.On("ClaimDigest", ctx, mock.Anything).Return(true, nil)
+
+	// When
+	m.email.On("Send", mock.Anything, "user@example.com", mock.Anything).Return("202", nil)
+	notification.SendDigests(ctx)
+
+	// Then
+	m.email.AssertNumberOfCalls(t, "Send", 1)
+	sent := m.email.Calls[0].Arguments.Get(2).(entity.Notification)
+	assert.Equal(t, entity.NotificationDigest, sent.Kind)
+	assert.Equal(t, "digest on es", sent.Subject)
+
+	m.notifications.AssertCalled(t, "ClaimDigest", ctx, mock.MatchedBy(func(digest *entity.Digest) bool {
+		return digest.UserId == "user-1" && digest.Mode == entity.DigestDaily && digest.PeriodStart.Equal(periodStart) &&
+			digest.PeriodEnd.Equal(periodEnd) && digest.Completed == 1 && digest.Failed == 1 && len(digest.Requests) == 2
+	}))
+	m.notifications.AssertCalled(t, "CreateDelivery", mock.Anything, mock.MatchedBy(func(delivery *entity.NotificationDelivery) bool {
+		return delivery.Kind == entity.NotificationDigest && delivery.RequestId == 2
+	}))
+	m.repo.AssertCalled(t, "UpdateNotificationStatus", context.Background(), uint64(1), entity.NotificationSent)
+	m.repo.AssertCalled(t, "UpdateNotificationStatus", context.Background(), uint64(2), entity.NotificationSent)
+}
+
+func TestSendDigests_MissedPeriodsAfterModeChange(t *testing.T) {
+	m, notification := setUpNotification(true)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	// Given results held on two past days by a user who chose to be notified immediately since
+	preferences := &entity.NotificationPreferences{UserId: "user-1", Channels: []entity.NotificationChannel{entity.ChannelEmail}, Digest: entity.DigestImmediate}
+	m.repo.On("GetDigestUserIds", ctx, today).Return([]string{"user-1"}, nil)
+	m.notifications.On("GetPreferences", ctx, "user-1").Return(preferences, nil)
+	m.repo.On("GetDigestRequests", ctx, "user-1", today).Return([]entity.Request{
+		{ID: 1, UserId: "user-1", UserEmail: "user@example.com", UserEmailVerified: true, Status: entity.Completed, FinishedAt: today.AddDate(0, 0, -5)},
+		{ID: 2, UserId: "user-1", UserEmail: "user@example.com", UserEmailVerified: true, Status: entity.Completed, FinishedAt: today.AddDate(0, 0, -5).Add(time.Hour)},
+		{ID: 3, UserId: "user-1", UserEmail: "user@example.com", UserEmailVerified: true, Status: entity.Failed, FinishedAt: today.AddDate(0, 0, -2)},
+	}, nil)
+	m.notifications.On("ClaimDigest", ctx, mock.Anything).Return(true, nil)
+
+	// When
+	m.email.On("Send", mock.Anything, "user@example.com", mock.Anything).Return("202", nil)
+	notification.SendDigests(ctx)
+
+	// Then each day is summarized on its own digest
+	m.email.AssertNumberOfCalls(t, "Send", 2)
+	m.notifications.AssertCalled(t, "ClaimDigest", ctx, mock.MatchedBy(func(digest *entity.Digest) bool {
+		return digest.Mode == entity.DigestDaily && digest.PeriodStart.Equal(today.AddDate(0, 0, -5)) && len(digest.Requests) == 2
+	}))
+	m.notifications.AssertCalled(t, "ClaimDigest", ctx, mock.MatchedBy(func(digest *entity.Digest) bool {
+		return digest.Mode == entity.DigestDaily && digest.PeriodStart.Equal(today.AddDate(0, 0, -2)) && len(digest.Requests) == 1
+	}))
+	for _, id := range []uint64{1, 2, 3} {
+		m.repo.AssertCalled(t, "UpdateNotificationStatus", context.Background(), id, entity.NotificationSent)
+	}
+}
+
+func TestSendDigests_AlreadyClaimed(t *testing.T) {
+	m, notification := setUpNotification(true)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monday := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+
+	preferences := &entity.NotificationPreferences{UserId: "user-1", Channels: []entity.NotificationChannel{entity.ChannelEmail}, Digest: entity.DigestWeekly}
+	m.repo.On("GetDigestUserIds", ctx, mock.Anything).Return([]string{"user-1"}, nil)
+	m.notifications.On("GetPreferences", ctx, "user-1").Return(preferences, nil)
+	m.repo.On("GetDigestRequests", ctx, "user-1", monday).Return([]entity.Request{
+		{ID: 2, UserId: "user-1", UserEmail: "user@example.com", UserEmailVerified: true, Status: entity.Completed, FinishedAt: monday.AddDate(0, 0, -3)},
+	}, nil)
+	m.notifications.On("ClaimDigest", ctx, mock.Anything).Return(false, nil)
+
+	// When
+	notification.SendDigests(ctx)
+
+	// Then
+	m.notifications.AssertCalled(t, "ClaimDigest", ctx, mock.MatchedBy(func(digest *entity.Digest) bool {
+		return digest.PeriodStart.Equal(monday.AddDate(0, 0, -7)) && digest.PeriodEnd.Equal(monday)
+	}))
+	m.email.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything)
+	m.repo.AssertCalled(t, "UpdateNotificationStatus", context.Background(), uint64(2), entity.NotificationSent)
+}
+
+func TestRetryPending(t *testing.T) {
+	m, notification := setUpNotification(true)
+	ctx := context.Background()
+	delivery := failedDelivery()
+	m.notifications.On("ClaimDueDeliveries", ctx, mock.Anything, mock.Anything).Return([]entity.NotificationDelivery{delivery}, nil)
+
+	succeeded := delivery
+	succeeded.Status = entity.DeliverySucceeded
+	quotaWarning := entity.NotificationDelivery{Kind: entity.NotificationQuotaWarning, Channel: entity.ChannelEmail, Status: entity.DeliveryFailed}
+	m.notifications.On("GetRequestDeliveries", ctx, uint64(1)).Return([]entity.NotificationDelivery{quotaWarning, succeeded}, nil)
+
+	// When
+	m.email.On("Send", ctx, "user@example.com", mock.Anything).Return("202", nil)
+	notification.RetryPending(ctx)
+
+	// Then
+	sent := m.email.Calls[0].Arguments.Get(2).(entity.Notification)
+	assert.Equal(t, "Your request #1 is ready", sent.Subject)
+	m.notifications.AssertCalled(t, "UpdateDelivery", ctx, mock.MatchedBy(func(delivery *entity.NotificationDelivery) bool {
+		return delivery.ID == 3 && delivery.Status == entity.DeliverySucceeded && delivery.Attempts == 2 && delivery.Error == ""
+	}))
+	m.repo.AssertCalled(t, "UpdateNotificationStatus", ctx, uint64(1), entity.NotificationSent)
+}
+
+func TestRetryPending_OtherChannelFailed(t *testing.T) {
+	m, notification := setUpNotification(true)
+	ctx := context.Background()
+	delivery := failedDelivery()
+	m.notifications.On("ClaimDueDeliveries", ctx, mock.Anything, mock.Anything).Return([]entity.NotificationDelivery{delivery}, nil)
+
+	succeeded := delivery
+	succeeded.Status = entity.DeliverySucceeded
+	slack := entity.NotificationDelivery{Kind: entity.NotificationRequestCompleted, Channel: entity.ChannelSlack, Status: entity.DeliveryFailed}
+	m.notifications.On("GetRequestDeliveries", ctx, uint64(1)).Return([]entity.NotificationDelivery{slack, succeeded}, nil)
+
+	// When
+	m.email.On("Send", ctx, "user@example.com", mock.Anything).Return("202", nil)
+	notification.RetryPending(ctx)
+
+	// Then
+	m.repo.AssertNotCalled(t, "UpdateNotificationStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRetryPending_LastAttempt(t *testing.T) {
+	m, notification := setUpNotification(true)
+	ctx := context.Background()
+	delivery := failedDelivery()
+	delivery.Attempts = 2
+	m.notifications.On("ClaimDueDeliveries", ctx, mock.Anything, mock.Anything).Return([]entity.NotificationDelivery{delivery}, nil)
+
+	// When
+	m.email.On("Send", ctx, "user@example.com", mock.Anything).Return("400 invalid address", errors.New("unexpected response status 400"))
+	notification.RetryPending(ctx)
+
+	// Then
+	m.notifications.AssertCalled(t, "UpdateDelivery", ctx, mock.MatchedBy(func(delivery *entity.NotificationDelivery) bool {
+		return delivery.Status == entity.DeliveryFailed && delivery.Attempts == 3 && delivery.ProviderResponse == "400 invalid address"
+	}))
+	m.notifications.AssertNotCalled(t, "GetRequestDeliveries", mock.Anything, mock.Anything)
+}
+
+func TestResend(t *testing.T) {
+	m, notification := setUpNotification(true)
+	ctx := context.Background()
+	delivery := failedDelivery()
+	delivery.Status = entity.DeliveryFailed
+	delivery.Attempts = 3
+	m.notifications.On("GetDelivery", ctx, uint64(3)).Return(&delivery, nil)
+	m.notifications.On("GetRequestDeliveries", ctx, uint64(1)).Return([]entity.NotificationDelivery{delivery}, nil)
+
+	// When
+	m.email.On("Send", ctx, "user@example.com", mock.Anything).Return("202", nil)
+	resent, err := notification.Resend(ctx, 3)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), resent.ID)
+	assert.Equal(t, entity.DeliverySucceeded, resent.Status)
+	assert.Equal(t, 1, resent.Attempts)
+	assert.Equal(t, "Your request #1 is ready", resent.Subject)
+	m.notifications.AssertCalled(t, "CreateDelivery", ctx, mock.MatchedBy(func(created *entity.NotificationDelivery) bool {
+		return created.Status == entity.DeliveryPending && created.Recipient == "user@example.com" && created.Attempts == 0
+	}))
+	// The original delivery is still the last failed one of the channel
+	m.repo.AssertNotCalled(t, "UpdateNotificationStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestResend_StillRetried(t *testing.T) {
+	m, notification := setUpNotification(true)
+	delivery := failedDelivery()
+	m.notifications.On("GetDelivery", mock.Anything, uint64(3)).Return(&delivery, nil)
+
+	// When
+	_, err := notification.Resend(context.Background(), 3)
+
+	// Then
+	assert.ErrorIs(t, err, core.ErrConflictingData)
+	m.email.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPreview(t *testing.T) {
+	_, notification := setUpNotification(true)
+
+	// When
+	preview, err := notification.Preview(entity.NotificationQuotaWarning, "es-AR")
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "quota_warning on es", preview.Subject)
+	assert.NotNil(t, preview.Quota)
+}
+
+func TestPreview_Invalid(t *testing.T) {
+	_, notification := setUpNotification(true)
+
+	// When
+	_, kindErr := notification.Preview("unknown", "")
+	_, localeErr := notification.Preview(entity.NotificationRequestCompleted, "fr")
+
+	// Then
+	assert.ErrorIs(t, kindErr, core.ErrInvalidInput)
+	assert.ErrorIs(t, localeErr, core.ErrInvalidInput)
+}
+
+func TestNotifyRequestStatus_PreferencesErrorFallsBackToEmail(t *testing.T) {
+	m, notification := setUpNotification(false)
+	request := &entity.Request{ID: 1, UserId: "user-1", UserEmail: "user@example.com"}
+	m.notifications.On("GetPreferences", mock.Anything, "user-1").Return(nil, errors.New("connection refused"))
+
+	// When
+	m.email.On("Send", mock.Anything, "user@example.com", mock.Anything).Return("202", nil)
+	err := notification.NotifyRequestStatus(request, entity.NotificationRequestFailed)
+
+	// Then
+	assert.NoError(t, err)
+	m.email.AssertNumberOfCalls(t, "Send", 1)
+}
+
+func TestUpdatePreferences(t *testing.T) {
+	m, notification := setUpNotification(true)
+	m.notifications.On("SavePreferences", mock.Anything, mock.Anything).Return(&entity.NotificationPreferences{}, nil)
+
+	// When
+	_, err := notification.UpdatePreferences(context.Background(), &entity.NotificationPreferences{
+		UserId:          "user-1",
+		Channels:        []entity.NotificationChannel{entity.ChannelTeams, entity.ChannelEmail, entity.ChannelTeams},
+		TeamsWebhookUrl: "https://example.webhook.office.com/webhookb2/1",
+		Locale:          "pt_br",
+	})
+
+	// Then
+	assert.NoError(t, err)
+
+	saved := m.notifications.Calls[0].Arguments.Get(1).(*entity.NotificationPreferences)
+	assert.Equal(t, []entity.NotificationChannel{entity.ChannelTeams, entity.ChannelEmail}, saved.Channels)
+	assert.Equal(t, "pt-BR", saved.Locale)
+	assert.Equal(t, entity.DigestImmediate, saved.Digest)
+	assert.False(t, saved.UpdatedAt.IsZero())
+}
+
+func TestUpdatePreferences_Invalid(t *testing.T) {
+	tests := map[string]*entity.NotificationPreferences{
+		"unknown channel":     {Channels: []entity.NotificationChannel{"sms"}},
+		"missing slack url":   {Channels: []entity.NotificationChannel{entity.ChannelSlack}},
+		"http teams url":      {Channels: []entity.NotificationChannel{entity.ChannelTeams}, TeamsWebhookUrl: "http://example.com/hook"},
+		"relative slack url":  {Channels: []entity.NotificationChannel{}, SlackWebhookUrl: "/services/T0"},
+		"missing teams url":   {Channels: []entity.NotificationChannel{entity.ChannelEmail, entity.ChannelTeams}},
+		"invalid unused url":  {Channels: []entity.NotificationChannel{entity.ChannelEmail}, TeamsWebhookUrl: "ftp://example.com"},
+		"missing url scheme":  {Channels: []entity.NotificationChannel{entity.ChannelSlack}, SlackWebhookUrl: "hooks.slack.com"},
+		"other slack host":    {Channels: []entity.NotificationChannel{entity.ChannelSlack}, SlackWebhookUrl: "https://169.254.169.254/services/T0"},
+		"slack lookalike":     {Channels: []entity.NotificationChannel{entity.ChannelSlack}, SlackWebhookUrl: "https://hooks.slack.com.example.com/T0"},
+		"slack with port":     {Channels: []entity.NotificationChannel{entity.ChannelSlack}, SlackWebhookUrl: "https://hooks.slack.com:8443/T0"},
+		"other teams host":    {Channels: []entity.NotificationChannel{entity.ChannelTeams}, TeamsWebhookUrl: "https://example.com/webhookb2/1"},
+		"teams suffix":        {Channels: []entity.NotificationChannel{entity.ChannelTeams}, TeamsWebhookUrl: "https://evilwebhook.office.com/1"},
+		"unknown empty value": {Channels: []entity.NotificationChannel{""}},
+		"unsupported locale":  {Channels: []entity.NotificationChannel{entity.ChannelEmail}, Locale: "fr"},
+		"unknown digest":      {Channels: []entity.NotificationChannel{entity.ChannelEmail}, Digest: "hourly"},
+	}
+
+	for name, preferences := range tests {
+		t.Run(name, func(t *testing.T) {
+			m, notification := setUpNotification(true)
+
+			// When
+			_, err := notification.UpdatePreferences(context.Background(), preferences)
+
+			// Then
+			assert.ErrorIs(t, err, core.ErrInvalidInput)
+			m.notifications.AssertNotCalled(t, "SavePreferences", mock.Anything, mock.Anything)
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"context"
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/port"
+	"log/slog"
+	"time"
+)
+
+// quotaWarningThreshold is the share of a limit that warns the user when it is reached
+const quotaWarningThreshold = 0.8
+
+// QuotaUseCase limits the usage of each user according to their plan. The usage
+// is counted on the requests table, so the limits are shared by all the instances
+type QuotaUseCase struct {
+	repository  port.RequestRepository
+	plans       map[string]entity.Plan
+	defaultPlan string
+	now         func() time.Time
+}
+
+// NewQuotaUseCase creates a new instance of the quotas, the users without plan
+// or with an unknown plan have the limits of the defaultPlan
+func NewQuotaUseCase(repo port.RequestRepository, plans map[string]entity.Plan, defaultPlan string) *QuotaUseCase {
+	return &QuotaUseCase{
+		repository:  repo,
+		plans:       plans,
+		defaultPlan: defaultPlan,
+		now:         time.Now,
+	}
+}
+
+// Plan returns the limits of the plan, or of the default plan when it is unknown
+func (usecase *QuotaUseCase) Plan(name string) entity.Plan {
+	if plan, exists := usecase.plans[name]; exists {
+		plan.Name = name
+		return plan
+	}
+
+	if name != "" {
+		slog.Warn("Unknown quota plan, using the default plan", "plan", name, "default", usecase.defaultPlan)
+	}
+
+	plan := usecase.plans[usecase.defaultPlan]
+	plan.Name = usecase.defaultPlan
+	return plan
+}
+
+// GetUsage returns the plan of the user and how much of it was used on the current periods
+func (usecase *QuotaUseCase) GetUsage(ctx context.Context, user *entity.User) (*entity.Plan, *entity.Usage, error) {
+	plan := usecase.Plan(user.Plan)
+
+	usage, err := usecase.getUsage(ctx, user.Id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &plan, usage, nil
+}
+
+// Check rejects a new request of fileSize bytes when it is over a limit of the plan, returning
+// a core.QuotaError. The plan used is recorded on the request, and the accepted request returns
+// the warning of the limit whose threshold it reaches, if any
+func (usecase *QuotaUseCase) Check(ctx context.Context, request *entity.Request, fileSize int64) (*entity.QuotaWarning, error) {
+	plan := usecase.Plan(request.Plan)
+	request.Plan = plan.Name
+
+	if plan.MaxFileSize > 0 && fileSize > plan.MaxFileSize {
+		return nil, core.NewQuotaError(core.ErrFileTooLarge, "file_size", plan.MaxFileSize, fileSize, time.Time{})
+	}
+
+	usage, err := usecase.getUsage(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return checkUsage(plan, usage, fileSize)
+}
+
+// CreateRequest creates the request checked by Check, checking the usage again while the creations
+// of the user are serialized, so the concurrent requests of a user can't go over the limits together
+func (usecase *QuotaUseCase) CreateRequest(ctx context.Context, request *entity.Request, fileSize int64) (*entity.Request, *entity.QuotaWarning, error) {
+	plan := usecase.Plan(request.Plan)
+	request.Plan = plan.Name
+
+	var warning *entity.QuotaWarning
+	dayStart, monthStart := usecase.periods()
+
+	request, err := usecase.repository.CreateRequestWithinQuota(ctx, request, dayStart, monthStart, func(usage *entity.Usage) error {
+		var err error
+		warning, err = checkUsage(plan, usage, fileSize)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return request, warning, nil
+}
+
+// checkUsage rejects a new request of fileSize bytes when the usage is at a limit of the plan
+func checkUsage(plan entity.Plan, usage *entity.Usage, fileSize int64) (*entity.QuotaWarning, error) {
+	if plan.MaxConcurrentJobs > 0 && usage.ActiveJobs >= plan.MaxConcurrentJobs {
+		return nil, core.NewQuotaError(core.ErrTooManyRequests, "concurrent_jobs",
+			int64(plan.MaxConcurrentJobs), int64(usage.ActiveJobs), time.Time{})
+	}
+
+	if plan.MaxRequestsPerDay > 0 && usage.RequestsToday >= plan.MaxRequestsPerDay {
+		return nil, core.NewQuotaError(core.ErrTooManyRequests, "requests_per_day",
+			int64(plan.MaxRequestsPerDay), int64(usage.RequestsToday), usage.DailyResetAt())
+	}
+
+	if plan.MaxBytesPerMonth > 0 && usage.BytesThisMonth+fileSize > plan.MaxBytesPerMonth {
+		return nil, core.NewQuotaError(core.ErrQuotaExceeded, "bytes_per_month",
+			plan.MaxBytesPerMonth, usage.BytesThisMonth, usage.MonthlyResetAt())
+	}
+
+	return quotaWarning(plan, usage, fileSize), nil
+}
+
+// getUsage counts the usage of the user since the start of the day and of the month
+func (usecase *QuotaUseCase) getUsage(ctx context.Context, userId string) (*entity.Usage, error) {
+	dayStart, monthStart := usecase.periods()
+	return usecase.repository.GetUserUsage(ctx, userId, dayStart, monthStart)
+}
+
+// periods returns the start of the current day and month, in UTC
+func (usecase *QuotaUseCase) periods() (time.Time, time.Time) {
+	now := usecase.now().UTC()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	return dayStart, monthStart
+}
+
+// quotaWarning returns the warning of the limit whose threshold the new request reaches, nil when none.
+// Only the request reaching the threshold warns, so the user is warned once on each period
+func quotaWarning(plan entity.Plan, usage *entity.Usage, fileSize int64) *entity.QuotaWarning {
+	requests := int64(usage.RequestsToday) + 1
+	if reachesThreshold(int64(usage.RequestsToday), requests, int64(plan.MaxRequestsPerDay)) {
+		return &entity.QuotaWarning{Limit: "requests_per_day", Used: requests, Max: int64(plan.MaxRequestsPerDay), ResetAt: usage.DailyResetAt()}
+	}
+
+	bytes := usage.BytesThisMonth + fileSize
+	if reachesThreshold(usage.BytesThisMonth, bytes, plan.MaxBytesPerMonth) {
+		return &entity.QuotaWarning{Limit: "bytes_per_month", Used: bytes, Max: plan.MaxBytesPerMonth, ResetAt: usage.MonthlyResetAt()}
+	}
+
+	return nil
+}
+
+// reachesThreshold tells whether the usage goes from below to over the warning threshold of the limit
+func reachesThreshold(before int64, after int64, limit int64) bool {
+	if limit <= 0 {
+		return false
+	}
+
+	threshold := float64(limit) * quotaWarningThreshold
+	return float64(before) < threshold && float64(after) >= threshold
+}
@@ -0,0 +1,241 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/usecase"
+	"example/web-service-gin/src/utils/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var testPlans = map[string]entity.Plan{
+	"free": {MaxConcurrentJobs: 2, MaxRequestsPerDay: 10, MaxBytesPerMonth: 1000, MaxFileSize: 500},
+	"pro":  {MaxConcurrentJobs: 10},
+}
+
+func setUpQuotas(usage *entity.Usage) (*MockRequestRepository, *usecase.QuotaUseCase) {
+	mockRepo := new(MockRequestRepository)
+	quotas := usecase.NewQuotaUseCase(mockRepo, testPlans, "free")
+
+	mockRepo.On("GetUserUsage", mock.Anything, "user123", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			usage.DayStart = args.Get(2).(time.Time)
+			usage.MonthStart = args.Get(3).(time.Time)
+		}).
+		Return(usage, nil)
+
+	return mockRepo, quotas
+}
+
+func TestQuotaPlan_UnknownUsesDefault(t *testing.T) {
+	_, quotas := setUpQuotas(&entity.Usage{})
+
+	assert.Equal(t, "free", quotas.Plan("").Name)
+	assert.Equal(t, "free", quotas.Plan("enterprise").Name)
+	assert.Equal(t, 10, quotas.Plan("pro").MaxConcurrentJobs)
+}
+
+func TestQuotaCheck_WithinLimits(t *testing.T) {
+	_, quotas := setUpQuotas(&entity.Usage{ActiveJobs: 1, RequestsToday: 9, BytesThisMonth: 500})
+	request := &entity.Request{UserId: "user123"}
+
+	_, err := quotas.Check(context.Background(), request, 500)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "free", request.Plan)
+}
+
+func TestQuotaCheck_Warning(t *testing.T) {
+	cases := map[string]struct {
+		usage    entity.Usage
+		fileSize int64
+		limit    string
+		used     int64
+	}{
+		"requests per day": {entity.Usage{RequestsToday: 7}, 100, "requests_per_day", 8},
+		"bytes per month":  {entity.Usage{BytesThisMonth: 700}, 150, "bytes_per_month", 850},
+	}
+
+	for name, testCase := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, quotas := setUpQuotas(&testCase.usage)
+
+			warning, err := quotas.Check(context.Background(), &entity.Request{UserId: "user123"}, testCase.fileSize)
+
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.limit, warning.Limit)
+			assert.Equal(t, testCase.used, warning.Used)
+			assert.True(t, warning.ResetAt.After(time.Now()))
+		})
+	}
+}
+
+func TestQuotaCheck_WarningOncePerPeriod(t *testing.T) {
+	_, quotas := setUpQuotas(&entity.Usage{RequestsToday: 8, BytesThisMonth: 100})
+
+	warning, err := quotas.Check(context.Background(), &entity.Request{UserId: "user123"}, 100)
+
+	assert.NoError(t, err)
+	assert.Nil(t, warning)
+}
+
+func TestQuotaCheck_ExceededLimits(t *testing.T) {
+	cases := map[string]struct {
+		usage    entity.Usage
+		fileSize int64
+		kind     error
+		quota    string
+		resets   bool
+	}{
+		"file size":        {entity.Usage{}, 501, core.ErrFileTooLarge, "file_size", false},
+		"concurrent jobs":  {entity.Usage{ActiveJobs: 2}, 100, core.ErrTooManyRequests, "concurrent_jobs", false},
+		"requests per day": {entity.Usage{RequestsToday: 10}, 100, core.ErrTooManyRequests, "requests_per_day", true},
+		"bytes per month":  {entity.Usage{BytesThisMonth: 901}, 100, core.ErrQuotaExceeded, "bytes_per_month", true},
+	}
+
+	for name, testCase := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, quotas := setUpQuotas(&testCase.usage)
+
+			_, err := quotas.Check(context.Background(), &entity.Request{UserId: "user123"}, testCase.fileSize)
+
+			var quotaError *core.QuotaError
+			assert.ErrorIs(t, err, testCase.kind)
+			assert.True(t, errors.As(err, &quotaError))
+			assert.Equal(t, testCase.quota, quotaError.Quota)
+			assert.Equal(t, testCase.resets, quotaError.ResetAt.After(time.Now()))
+		})
+	}
+}
+
+func TestQuotaCheck_UnlimitedPlan(t *testing.T) {
+	_, quotas := setUpQuotas(&entity.Usage{ActiveJobs: 2, RequestsToday: 100, BytesThisMonth: 1 << 40})
+
+	warning, err := quotas.Check(context.Background(), &entity.Request{UserId: "user123", Plan: "pro"}, 1<<30)
+
+	assert.NoError(t, err)
+	assert.Nil(t, warning)
+}
+
+func TestQuotaCheck_RepositoryError(t *testing.T) {
+	mockRepo := new(MockRequestRepository)
+	quotas := usecase.NewQuotaUseCase(mockRepo, testPlans, "free")
+	mockRepo.On("GetUserUsage", mock.Anything, "user123", mock.Anything, mock.Anything).
+		Return((*entity.Usage)(nil), errors.New("mock error"))
+
+	_, err := quotas.Check(context.Background(), &entity.Request{UserId: "user123"}, 100)
+
+	assert.EqualError(t, err, "mock error")
+}
+
+func TestQuotaGetUsage_CountsFromStartOfPeriods(t *testing.T) {
+	mockRepo, quotas := setUpQuotas(&entity.Usage{RequestsToday: 3})
+
+	plan, usage, err := quotas.GetUsage(context.Background(), &entity.User{Id: "user123"})
+
+	now := time.Now().UTC()
+	assert.NoError(t, err)
+	assert.Equal(t, "free", plan.Name)
+	assert.Equal(t, 3, usage.RequestsToday)
+	assert.Equal(t, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), usage.DayStart)
+	assert.Equal(t, time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), usage.MonthStart)
+	mockRepo.AssertNumberOfCalls(t, "GetUserUsage", 1)
+}
+
+func TestCreateRequest_QuotaExceeded(t *testing.T) {
+	mockRepo := new(MockRequestRepository)
+	mockStorage := new(MockStoragePort)
+	quotas := usecase.NewQuotaUseCase(mockRepo, testPlans, "free")
+	requestUsecase := usecase.NewRequestUseCase(mockRepo, mockStorage, new(MockRequestNotifications), new(MockMailService),
+		usecase.WithQuotas(quotas))
+
+	mockRepo.On("GetUserUsage", mock.Anything, "user123", mock.Anything, mock.Anything).
+		Return(&entity.Usage{ActiveJobs: 2}, nil)
+
+	videoFile := mocks.MockGetFileHeader("video.mp4", mocks.MockGetVideoContent("mp4"))
+
+	createdRequest, err := requestUsecase.Create(context.Background(), &entity.Request{UserId: "user123"}, videoFile)
+
+	assert.Nil(t, createdRequest)
+	assert.ErrorIs(t, err, core.ErrTooManyRequests)
+	mockStorage.AssertNotCalled(t, "UploadFile", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "CreateRequest", mock.Anything, mock.Anything)
+}
+
+func TestCreateRequest_QuotaWarning(t *testing.T) {
+	mockRepo := new(MockRequestRepository)
+	mockStorage := new(MockStoragePort)
+	mockMailService := new(MockMailService)
+	quotas := usecase.NewQuotaUseCase(mockRepo, testPlans, "free")
+	requestUsecase := usecase.NewRequestUseCase(mockRepo, mockStorage, new(MockRequestNotifications), mockMailService,
+		usecase.WithQuotas(quotas))
+
+	mockRepo.On("GetUserUsage", mock.Anything, "user123", mock.Anything, mock.Anything).
+		Return(&entity.Usage{RequestsToday: 7}, nil)
+	mockStorage.On("UploadFile", mock.Anything, mock.Anything).Return("videos_input/user123.mp4", nil)
+	mockRepo.On("CreateRequest", mock.Anything, mock.Anything).Return(&entity.Request{ID: 1, UserId: "user123"}, nil)
+	mockMailService.On("NotifyQuotaWarning", mock.Anything, mock.Anything).Return(errors.New("mock error"))
+
+	videoFile := mocks.MockGetFileHeader("video.mp4", mocks.MockGetVideoContent("mp4"))
+
+	createdRequest, err := requestUsecase.Create(context.Background(), &entity.Request{UserId: "user123"}, videoFile)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, createdRequest)
+	mockMailService.AssertCalled(t, "NotifyQuotaWarning", createdRequest, mock.MatchedBy(func(warning entity.QuotaWarning) bool {
+		return warning.Limit == "requests_per_day" && warning.Used == 8 && warning.Max == 10
+	}))
+}
+
+func TestCreateRequest_PlanAllowsLargerFiles(t *testing.T) {
+	mockRepo := new(MockRequestRepository)
+	plans := map[string]entity.Plan{"free": {MaxFileSize: 1 << 30, MaxConcurrentJobs: 1}}
+	quotas := usecase.NewQuotaUseCase(mockRepo, plans, "free")
+	requestUsecase := usecase.NewRequestUseCase(mockRepo, new(MockStoragePort), new(MockRequestNotifications), new(MockMailService),
+		usecase.WithQuotas(quotas))
+
+	mockRepo.On("GetUserUsage", mock.Anything, "user123", mock.Anything, mock.Anything).
+		Return(&entity.Usage{ActiveJobs: 1}, nil)
+
+	videoFile := mocks.MockGetFileHeader("video.mp4", mocks.MockGetVideoContent("mp4"))
+	videoFile.Size = 600 << 20
+
+	createdRequest, err := requestUsecase.Create(context.Background(), &entity.Request{UserId: "user123"}, videoFile)
+
+	// The file size is accepted and the request is only rejected by the other limits
+	assert.Nil(t, createdRequest)
+	assert.ErrorIs(t, err, core.ErrTooManyRequests)
+}
+
+func TestCreateRequest_QuotaReachedWhileUploading(t *testing.T) {
+	mockRepo := new(MockRequestRepository)
+	mockStorage := new(MockStoragePort)
+	quotas := usecase.NewQuotaUseCase(mockRepo, testPlans, "free")
+	requestUsecase := usecase.NewRequestUseCase(mockRepo, mockStorage, new(MockRequestNotifications), new(MockMailService),
+		usecase.WithQuotas(quotas))
+
+	videoFile := mocks.MockGetFileHeader("video.mp4", mocks.MockGetVideoContent("mp4"))
+
+	// Given another request of the user created while the video was uploaded
+	mockRepo.On("GetUserUsage", mock.Anything, "user123", mock.Anything, mock.Anything).
+		Return(&entity.Usage{ActiveJobs: 1}, nil).Once()
+	mockRepo.On("GetUserUsage", mock.Anything, "user123", mock.Anything, mock.Anything).
+		Return(&entity.Usage{ActiveJobs: 2}, nil).Once()
+	mockStorage.On("UploadFile", videoFile.Size, mock.Anything).Return("videos_input/user123.mp4", nil)
+	mockStorage.On("DeleteFile", "videos_input/user123.mp4").Return(nil)
+
+	// When
+	createdRequest, err := requestUsecase.Create(context.Background(), &entity.Request{UserId: "user123"}, videoFile)
+
+	// Then the limit is checked again when creating the request and the upload is removed
+	assert.Nil(t, createdRequest)
+	assert.ErrorIs(t, err, core.ErrTooManyRequests)
+	mockRepo.AssertNotCalled(t, "CreateRequest", mock.Anything, mock.Anything)
+	mockStorage.AssertCalled(t, "DeleteFile", "videos_input/user123.mp4")
+}
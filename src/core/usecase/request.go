@@ -2,14 +2,23 @@ package usecase
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"example/web-service-gin/src/adapters/handler/queue"
 	"example/web-service-gin/src/adapters/storage/bucket"
+	"example/web-service-gin/src/core"
 	"example/web-service-gin/src/core/entity"
 	"example/web-service-gin/src/core/port"
+	"example/web-service-gin/src/utils/video"
 	"fmt"
+	"hash"
+	"io"
+	"log/slog"
 	"mime/multipart"
+	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 )
@@ -19,45 +28,248 @@ type RequestUseCase struct {
 	storage    port.StoragePort
 	queue      port.QueuePort
 	mail       port.MailServicePort
+	limits     VideoLimits
+	dedupMode  DedupMode
+	history    *RequestHistory
+	quotas     *QuotaUseCase
+	metrics    port.Metrics
+}
+
+// DedupMode tells whose COMPLETED requests are searched for a video with the same content
+type DedupMode string
+
+const (
+	// DedupOff always processes the uploaded video
+	DedupOff DedupMode = "off"
+	// DedupUser reuses the results of the same user
+	DedupUser DedupMode = "user"
+	// DedupOrganization reuses the results of the whole organization of the user,
+	// users without organization only reuse their own results
+	DedupOrganization DedupMode = "organization"
+)
+
+// VideoLimits are the maximum duration and resolution of the uploaded videos,
+// a zero value disables the check. The resolution is compared regardless of
+// the orientation, so 1920x1080 also accepts portrait 1080x1920 videos
+type VideoLimits struct {
+	MaxDuration time.Duration
+	MaxWidth    int
+	MaxHeight   int
+}
+
+// RequestUseCaseOption configures the optional settings of the RequestUseCase
+type RequestUseCaseOption func(*RequestUseCase)
+
+// WithVideoLimits rejects the videos over the informed duration and resolution
+func WithVideoLimits(limits VideoLimits) RequestUseCaseOption {
+	return func(usecase *RequestUseCase) {
+		usecase.limits = limits
+	}
+}
+
+// WithDeduplication returns the output of an already COMPLETED request when the same
+// video is submitted again with the same extraction options, instead of processing it.
+// The duplicates point to the video and the output of the original request, so those
+// files must not be removed while the original has duplicates
+func WithDeduplication(mode DedupMode) RequestUseCaseOption {
+	return func(usecase *RequestUseCase) {
+		usecase.dedupMode = mode
+	}
+}
+
+// WithHistory records the status changes of the requests on the history, which publishes them to
+// the other services, the webhooks and the event streams. The duplicates are created COMPLETED,
+// so they only publish request.completed
+func WithHistory(history *RequestHistory) RequestUseCaseOption {
+	return func(usecase *RequestUseCase) {
+		usecase.history = history
+	}
+}
+
+// WithQuotas rejects the requests over the limits of the plan of the user, without them (nil) only
+// the default file size limits the requests
+func WithQuotas(quotas *QuotaUseCase) RequestUseCaseOption {
+	return func(usecase *RequestUseCase) {
+		usecase.quotas = quotas
+	}
+}
+
+// WithMetrics records how long the requests take from their creation until the result of the worker
+func WithMetrics(metrics port.Metrics) RequestUseCaseOption {
+	return func(usecase *RequestUseCase) {
+		usecase.metrics = metrics
+	}
 }
 
 // NewRequestUseCase creates a new user service instance
-func NewRequestUseCase(repo port.RequestRepository, storage port.StoragePort, queue port.QueuePort, notif port.MailServicePort) *RequestUseCase {
-	return &RequestUseCase{repo, storage, queue, notif}
+func NewRequestUseCase(repo port.RequestRepository, storage port.StoragePort, queue port.QueuePort, notif port.MailServicePort, options ...RequestUseCaseOption) *RequestUseCase {
+	usecase := &RequestUseCase{
+		repository: repo,
+		storage:    storage,
+		queue:      queue,
+		mail:       notif,
+		dedupMode:  DedupOff,
+	}
+
+	for _, option := range options {
+		option(usecase)
+	}
+
+	return usecase
 }
 
 func (usecase *RequestUseCase) Create(ctx context.Context, request *entity.Request, file *multipart.FileHeader) (*entity.Request, error) {
 
-	_, err := validateFileRules(file)
-	fileKeyName := generateFileKey(request.UserId, file)
+	metadata, err := validateFileRules(file, usecase.limits, usecase.maxFileSize())
 
 	// Is a Valid File
 	if err != nil {
 		return nil, err
 	}
 
+	// Rejects the request before uploading, the quotas are checked again when it is created
+	if usecase.quotas != nil {
+		if _, err = usecase.quotas.Check(ctx, request, file.Size); err != nil {
+			return nil, err
+		}
+	}
+
+	request.VideoMetadata = entity.VideoMetadata{
+		Format:   string(metadata.Format),
+		Duration: metadata.Duration,
+		Width:    metadata.Width,
+		Height:   metadata.Height,
+		Codec:    metadata.Codec,
+	}
+
+	if request.Options.FrameInterval == 0 {
+		request.Options.FrameInterval = entity.DefaultFrameInterval
+	}
+
 	request.Status = entity.Pending
+	request.NotificationStatus = entity.NotificationPending
 	request.CreatedAt = time.Now()
-	fileKey, err := usecase.storage.UploadFile(file, fileKeyName)
+	fileKey, err := usecase.upload(ctx, request, file, generateFileKey(request.UserId, file))
+	if err != nil {
+		return nil, err
+	}
 
-	// Storage Error
+	original, err := usecase.findProcessedVideo(ctx, request)
 	if err != nil {
 		return nil, err
 	}
 
+	if original != nil {
+		// The video stored by the original request is used instead
+		if err = usecase.storage.DeleteFile(ctx, fileKey); err != nil {
+			slog.Error("Error deleting the upload of a duplicate video", "key", fileKey, "error", err)
+		}
+
+		return usecase.createDuplicate(ctx, request, file, original)
+	}
+
 	request.VideoKey = fileKey
 	request.VideoSize = file.Size
-	request, err = usecase.repository.CreateRequest(ctx, request)
+	request, warning, err := usecase.createRequest(ctx, request, file.Size)
 
 	// Repository Error
 	if err != nil {
+		if deleteErr := usecase.storage.DeleteFile(ctx, fileKey); deleteErr != nil {
+			slog.Error("Error deleting the upload of a request not created", "key", fileKey, "error", deleteErr)
+		}
 		return nil, err
 	}
 
+	usecase.history.record(ctx, request, request.UserId, "request created")
+	usecase.warnQuota(request, warning)
 	return request, nil
 
 }
 
+// notify sends the result of the request to the user, the failed deliveries are logged and retried
+// by the notifications so the failure is only logged here
+func (usecase *RequestUseCase) notify(request *entity.Request, kind entity.NotificationKind) {
+	if err := usecase.mail.NotifyRequestStatus(request, kind); err != nil {
+		slog.Error("Error notifying request status", "id", request.ID, "kind", kind, "error", err)
+	}
+}
+
+// warnQuota notifies the user when the request reached the warning threshold of a limit, the
+// request was already created so a failure is only logged
+func (usecase *RequestUseCase) warnQuota(request *entity.Request, warning *entity.QuotaWarning) {
+	if warning == nil {
+		return
+	}
+
+	if err := usecase.mail.NotifyQuotaWarning(request, *warning); err != nil {
+		slog.Error("Error notifying quota warning", "id", request.ID, "limit", warning.Limit, "error", err)
+	}
+}
+
+// findProcessedVideo searches a COMPLETED request of the same content according to
+// the deduplication mode, returning nil when the video must be processed
+func (usecase *RequestUseCase) findProcessedVideo(ctx context.Context, request *entity.Request) (*entity.Request, error) {
+	var organizationId string
+
+	switch usecase.dedupMode {
+	case DedupUser:
+	case DedupOrganization:
+		organizationId = request.OrganizationId
+	default:
+		return nil, nil
+	}
+
+	original, err := usecase.repository.GetCompletedByContentHash(ctx, request.ContentHash, request.Options, request.UserId, organizationId)
+
+	if errors.Is(err, core.ErrDataNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return original, nil
+}
+
+// createDuplicate creates an already COMPLETED request linked to the original one, sharing
+// its video and output so nothing is sent to the worker. The duplicate doesn't own a copy
+// of the files, so they must be kept while any duplicate of the original exists
+func (usecase *RequestUseCase) createDuplicate(ctx context.Context, request *entity.Request, file *multipart.FileHeader, original *entity.Request) (*entity.Request, error) {
+	now := time.Now()
+
+	request.Status = entity.Completed
+	request.CreatedAt = now
+	request.FinishedAt = now
+	request.VideoKey = original.VideoKey
+	request.VideoSize = file.Size
+	request.ZipOutputKey = original.ZipOutputKey
+	request.DuplicateOf = original.ID
+	request.NotificationStatus = entity.NotificationPending
+
+	// The duplicate doesn't upload any bytes
+	request, warning, err := usecase.createRequest(ctx, request, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	signOutput(usecase.storage, request)
+	usecase.history.record(ctx, request, request.UserId, fmt.Sprintf("reused the output of request %d", original.ID))
+	usecase.notify(request, entity.NotificationRequestCompleted)
+	usecase.warnQuota(request, warning)
+	return request, nil
+}
+
+// createRequest creates the request, within the quotas of the user when they are enabled
+func (usecase *RequestUseCase) createRequest(ctx context.Context, request *entity.Request, fileSize int64) (*entity.Request, *entity.QuotaWarning, error) {
+	if usecase.quotas == nil {
+		request, err := usecase.repository.CreateRequest(ctx, request)
+		return request, nil, err
+	}
+
+	return usecase.quotas.CreateRequest(ctx, request, fileSize)
+}
+
 func (usecase *RequestUseCase) Update(ctx context.Context, request *entity.Request) (*entity.Request, error) {
 
 	updatedRequest, err := usecase.repository.UpdateRequest(ctx, request)
@@ -81,6 +293,7 @@ func (usecase *RequestUseCase) List(ctx context.Context, userId string) ([]entit
 		return []entity.Request{}, nil
 	}
 
+	signOutputs(usecase.storage, requestList)
 	return requestList, nil
 }
 
@@ -92,18 +305,41 @@ func (usecase *RequestUseCase) Get(ctx context.Context, id uint64) (*entity.Requ
 		return nil, err
 	}
 
+	signOutput(usecase.storage, request)
 	return request, nil
 }
 
-func (usecase *RequestUseCase) HandleUploadNotification(ctx context.Context, msg entity.EventMessage) {
+// signOutput sets the URL where the output of the request is downloaded. Only the key is stored and the
+// URL is signed on each read, so it never expires while stored. The requests finished before the key was
+// stored kept the URL itself
+func signOutput(storage port.StoragePort, request *entity.Request) {
+	switch {
+	case request.ZipOutputKey == "":
+		request.ZipOutputUrl = ""
+	case strings.Contains(request.ZipOutputKey, "://"):
+		request.ZipOutputUrl = request.ZipOutputKey
+	default:
+		request.ZipOutputUrl = storage.GetFileUrl(request.ZipOutputKey)
+	}
+}
+
+// signOutputs sets the download URL of the output of each request
+func signOutputs(storage port.StoragePort, requests []entity.Request) {
+	for i := range requests {
+		signOutput(storage, &requests[i])
+	}
+}
+
+// HandleUploadNotification sends the uploaded videos to processing, returning the records that failed
+func (usecase *RequestUseCase) HandleUploadNotification(ctx context.Context, msg entity.EventMessage) error {
 
 	var event bucket.S3Event
 	var bodyMessage string = msg.Body
+	var errs []error
 
 	err := json.Unmarshal([]byte(bodyMessage), &event)
 	if err != nil {
-		fmt.Println("Error converting body message: ", err)
-		return
+		return fmt.Errorf("error converting body message: %w", err)
 	}
 
 	// Loop for each record of the S3 Event
@@ -114,26 +350,74 @@ func (usecase *RequestUseCase) HandleUploadNotification(ctx context.Context, msg
 		fmt.Println("Size:", record.S3.Object.Size)
 
 		// Update status on Database
-		request, _ := usecase.repository.UpdateStatusByVideoKey(ctx, string(entity.InProgress), fileKey)
+		request, err := usecase.repository.UpdateStatusByVideoKey(ctx, string(entity.InProgress), fileKey)
+
+		if errors.Is(err, core.ErrDataNotFound) {
+			if err = usecase.checkUnstartedUpload(ctx, fileKey); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error updating request of the uploaded file %s: %w", fileKey, err))
+			continue
+		}
+
+		usecase.history.record(ctx, request, entity.SystemActor, "sent to processing")
 
 		// Sent Message to SQS to Start Upload
 		usecase.queue.SendVideoProccessToQueue(request)
 	}
 
+	return errors.Join(errs...)
+}
+
+// checkUnstartedUpload tells why no PENDING request of the uploaded file was found. The event was already
+// handled, the watchdog started the request meanwhile, or the upload was removed because it was a duplicate
+// or its request couldn't be created. Otherwise the upload finished before its request was created, and the
+// error keeps the event to be handled again
+func (usecase *RequestUseCase) checkUnstartedUpload(ctx context.Context, fileKey string) error {
+	request, err := usecase.repository.GetByVideoKey(ctx, fileKey)
+	if err == nil {
+		slog.Info("Request of the uploaded file already started", "id", request.ID, "key", fileKey, "status", request.Status)
+		return nil
+	}
+
+	if !errors.Is(err, core.ErrDataNotFound) {
+		return fmt.Errorf("error searching request of the uploaded file %s: %w", fileKey, err)
+	}
+
+	exists, err := usecase.storage.FileExists(ctx, fileKey)
+	if err != nil {
+		return fmt.Errorf("error checking the uploaded file %s: %w", fileKey, err)
+	}
+
+	if !exists {
+		slog.Info("Uploaded file removed before its request was created", "key", fileKey)
+		return nil
+	}
+
+	return fmt.Errorf("request of the uploaded file %s not created yet: %w", fileKey, core.ErrDataNotFound)
 }
 
-func (usecase *RequestUseCase) HandleVideoOutputNotification(ctx context.Context, msg entity.EventMessage) {
+// HandleVideoOutputNotification records the progress and the results reported by the worker
+func (usecase *RequestUseCase) HandleVideoOutputNotification(ctx context.Context, msg entity.EventMessage) error {
 
 	var notification queue.SnapVideoResponse
 	var bodyMessage string = msg.Body
 	var statusMessage string
+	var kind entity.NotificationKind
 
 	fmt.Println("Message from Queue: ", msg.Body)
 
 	err := json.Unmarshal([]byte(bodyMessage), &notification)
 	if err != nil {
-		fmt.Println("Error converting body message: ", err)
-		return
+		return fmt.Errorf("error converting body message: %w", err)
+	}
+
+	if notification.Type == queue.MessageTypeProgress {
+		return usecase.handleProgress(ctx, notification)
 	}
 
 	var isSuccess bool = notification.Status == "OK"
@@ -141,55 +425,262 @@ func (usecase *RequestUseCase) HandleVideoOutputNotification(ctx context.Context
 	videoRequest, getError := usecase.Get(ctx, notification.Id)
 
 	if getError != nil {
-		fmt.Println("Invalid request ID: ", err)
-		return
+		return fmt.Errorf("invalid request id %d: %w", notification.Id, getError)
 	}
 
 	videoRequest.FinishedAt = time.Now()
-	videoUrl := usecase.storage.GetFileUrl(notification.S3ZipFileKey)
 
 	if isSuccess {
 		videoRequest.Status = entity.Completed
-		videoRequest.ZipOutputKey = videoUrl
+		videoRequest.ZipOutputKey = notification.S3ZipFileKey
+		videoRequest.Progress.Percent = 100
+		videoRequest.Progress.Eta = time.Time{}
 		statusMessage = "sucesso"
+		kind = entity.NotificationRequestCompleted
 	} else {
 		videoRequest.Status = entity.Failed
 		statusMessage = "erro"
+		kind = entity.NotificationRequestFailed
 	}
 
 	_, err = usecase.repository.UpdateRequest(ctx, videoRequest)
 
 	if err != nil {
-		return
+		return fmt.Errorf("error updating request %d: %w", videoRequest.ID, err)
+	}
+
+	signOutput(usecase.storage, videoRequest)
+	usecase.history.record(ctx, videoRequest, entity.SystemActor, "processing finished with "+statusMessage)
+
+	if usecase.metrics != nil {
+		usecase.metrics.ObserveProcessing(videoRequest.Status, videoRequest.FinishedAt.Sub(videoRequest.CreatedAt))
 	}
 
 	fmt.Println("Sucesso: ", statusMessage)
-	_ = usecase.mail.NotifyRequestStatus(videoRequest, statusMessage)
+	usecase.notify(videoRequest, kind)
+	return nil
 }
 
-func validateFileRules(file *multipart.FileHeader) (bool, error) {
+// handleProgress records the progress reported by the worker, the reports of the requests
+// no longer IN_PROGRESS and the ones behind the recorded progress are ignored
+func (usecase *RequestUseCase) handleProgress(ctx context.Context, notification queue.SnapVideoResponse) error {
+	progress := entity.Progress{
+		Percent:         min(max(notification.Percent, 0), 100),
+		FramesExtracted: max(notification.FramesExtracted, 0),
+		UpdatedAt:       time.Now(),
+	}
+
+	if notification.EtaSeconds > 0 {
+		progress.Eta = progress.UpdatedAt.Add(time.Duration(notification.EtaSeconds) * time.Second)
+	}
 
-	var allowedExtensions = [...]string{"mp4", "mkv", "avi", "webm", "mov"}
-	var fileExtension = strings.Split(file.Filename, ".")[1]
-	var fileSize = (file.Size / 1024) / 1000 // Mbs
+	err := usecase.repository.UpdateProgress(ctx, notification.Id, progress)
 
-	for _, extension := range allowedExtensions {
-		if extension == fileExtension {
-			if fileSize <= 500 {
-				return true, nil
-			} else {
-				return false, errors.New("file size is greater than 500Mb")
-			}
+	if errors.Is(err, core.ErrDataNotFound) {
+		slog.Debug("Ignoring outdated progress", "id", notification.Id, "percent", progress.Percent)
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("error updating progress of request %d: %w", notification.Id, err)
+	}
+
+	return nil
+}
+
+// defaultMaxFileSize is the biggest video accepted without quotas, in bytes
+const defaultMaxFileSize = 500 * 1024 * 1024
+
+// maxFileSize returns the biggest video accepted, zero when the plans of the quotas limit it
+func (usecase *RequestUseCase) maxFileSize() int64 {
+	if usecase.quotas != nil {
+		return 0
+	}
+	return defaultMaxFileSize
+}
+
+// allowedFormats maps each accepted extension to the containers its content may have,
+// MP4 and MOV share the same box structure and players open both with either extension
+var allowedFormats = map[string][]video.Format{
+	"mp4":  {video.MP4, video.MOV},
+	"mov":  {video.MOV, video.MP4},
+	"mkv":  {video.Matroska, video.WebM},
+	"webm": {video.WebM},
+	"avi":  {video.AVI},
+}
+
+// validateFileRules checks the extension, the size and the content of the uploaded video, returning
+// the metadata read from its container when it is within the limits. A zero maxSize doesn't limit the size
+func validateFileRules(file *multipart.FileHeader, limits VideoLimits, maxSize int64) (*video.Metadata, error) {
+
+	if file == nil {
+		return nil, core.NewValidationError(core.ErrInvalidFile, "video file is required")
+	}
+
+	fileExtension := getFileExtension(file.Filename)
+	formats, allowed := allowedFormats[fileExtension]
+
+	if !allowed {
+		return nil, core.NewValidationError(core.ErrUnsupportedMediaType, "file extension not allowed")
+	}
+
+	if maxSize > 0 && file.Size > maxSize {
+		return nil, core.NewValidationError(core.ErrFileTooLarge, fmt.Sprintf("file size is greater than %dMb", maxSize/(1024*1024)))
+	}
+
+	if file.Size == 0 {
+		return nil, core.NewValidationError(core.ErrInvalidFile, "file is empty")
+	}
+
+	content, err := file.Open()
+	if err != nil {
+		return nil, core.NewValidationError(core.ErrInvalidFile, "file could not be read")
+	}
+	defer content.Close()
+
+	header, err := readFileHeader(content)
+	if err != nil {
+		return nil, core.NewValidationError(core.ErrInvalidFile, "file could not be read")
+	}
+
+	format := video.Detect(header)
+	if format == video.Unknown {
+		return nil, core.NewValidationError(core.ErrUnsupportedMediaType, "file content is not a supported video")
+	}
+
+	if !slices.Contains(formats, format) {
+		return nil, core.NewValidationError(core.ErrUnsupportedMediaType,
+			fmt.Sprintf("file content (%s) does not match the extension .%s", format, fileExtension))
+	}
+
+	metadata, err := video.Probe(content, file.Size)
+	if errors.Is(err, video.ErrNoVideoStream) {
+		return nil, core.NewValidationError(core.ErrInvalidFile, "file has no video stream")
+	}
+
+	if err != nil {
+		return nil, core.NewValidationError(core.ErrInvalidFile, "video metadata could not be read")
+	}
+
+	err = validateVideoLimits(metadata, limits)
+	if err != nil {
+		return nil, err
+	}
+
+	return metadata, nil
+}
+
+// validateVideoLimits checks the duration and resolution, a duration that
+// is not informed by the container (e.g. fragmented MP4) is accepted
+func validateVideoLimits(metadata *video.Metadata, limits VideoLimits) error {
+
+	if limits.MaxDuration > 0 && metadata.Duration > limits.MaxDuration {
+		return core.NewValidationError(core.ErrInvalidFile,
+			fmt.Sprintf("video duration is longer than %s", limits.MaxDuration))
+	}
+
+	if limits.MaxWidth > 0 && limits.MaxHeight > 0 {
+		longSide, shortSide := max(metadata.Width, metadata.Height), min(metadata.Width, metadata.Height)
+		maxLongSide, maxShortSide := max(limits.MaxWidth, limits.MaxHeight), min(limits.MaxWidth, limits.MaxHeight)
+
+		if longSide > maxLongSide || shortSide > maxShortSide {
+			return core.NewValidationError(core.ErrInvalidFile,
+				fmt.Sprintf("video resolution is greater than %dx%d", limits.MaxWidth, limits.MaxHeight))
 		}
 	}
 
-	return false, errors.New("file extension not allowed")
+	return nil
+}
+
+// readFileHeader reads the first bytes of the file used to detect its format
+func readFileHeader(content io.ReaderAt) ([]byte, error) {
+	header := make([]byte, video.SniffLength)
+	read, err := content.ReadAt(header, 0)
+
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	return header[:read], nil
+}
+
+// upload stores the video with the informed key, setting the content hash of the request
+// from the bytes read by the storage, so the video is read only once
+func (usecase *RequestUseCase) upload(ctx context.Context, request *entity.Request, file *multipart.FileHeader, fileKey string) (string, error) {
+	content, err := file.Open()
+	if err != nil {
+		return "", fmt.Errorf("error opening uploaded file: %w", err)
+	}
+	defer content.Close()
+
+	reader := newHashingReader(content)
+	fileKey, err = usecase.storage.UploadFile(ctx, reader, file.Size, file.Header.Get("Content-Type"), fileKey)
+
+	// Storage Error
+	if err != nil {
+		return "", fmt.Errorf("error uploading video: %w: %w", core.ErrStorageUnavailable, err)
+	}
+
+	request.ContentHash, err = reader.sum(file.Size)
+	if err != nil {
+		return "", err
+	}
+
+	return fileKey, nil
+}
+
+// hashingReader computes the SHA-256 of the content while it is read. The storages may seek back
+// and read it again (the S3 client reads the body to sign the request before sending it), so each
+// byte is only hashed the first time it is read
+type hashingReader struct {
+	content io.ReadSeeker
+	hash    hash.Hash
+	offset  int64
+	hashed  int64
+}
+
+func newHashingReader(content io.ReadSeeker) *hashingReader {
+	return &hashingReader{content: content, hash: sha256.New()}
+}
+
+func (reader *hashingReader) Read(buffer []byte) (int, error) {
+	read, err := reader.content.Read(buffer)
+	end := reader.offset + int64(read)
+
+	if reader.offset <= reader.hashed && end > reader.hashed {
+		reader.hash.Write(buffer[reader.hashed-reader.offset : read])
+		reader.hashed = end
+	}
+
+	reader.offset = end
+	return read, err
+}
+
+func (reader *hashingReader) Seek(offset int64, whence int) (int64, error) {
+	position, err := reader.content.Seek(offset, whence)
+	if err == nil {
+		reader.offset = position
+	}
+	return position, err
+}
+
+// sum returns the hex encoded SHA-256 of the content, once all its size bytes were read
+func (reader *hashingReader) sum(size int64) (string, error) {
+	if reader.hashed != size {
+		return "", fmt.Errorf("error hashing uploaded file: %d of %d bytes read", reader.hashed, size)
+	}
+
+	return hex.EncodeToString(reader.hash.Sum(nil)), nil
+}
 
+// getFileExtension returns the lowercase extension after the last dot, or empty when there is none
+func getFileExtension(filename string) string {
+	return strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))
 }
 
 func generateFileKey(userId string, file *multipart.FileHeader) string {
 	now := time.Now().UTC().Format("2006-01-02-15-04-05")
-	fileExtension := strings.Split(file.Filename, ".")[1]
+	fileExtension := getFileExtension(file.Filename)
 	fileKey := "videos_input/" + userId + "_" + now + "." + fileExtension
 
 	return fileKey
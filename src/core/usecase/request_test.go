@@ -2,15 +2,20 @@ package usecase_test
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"example/web-service-gin/src/adapters/handler/notification"
+	"example/web-service-gin/src/core"
 	"example/web-service-gin/src/core/entity"
 	"example/web-service-gin/src/core/usecase"
 	"example/web-service-gin/src/utils/mocks"
+	"io"
 	"mime/multipart"
+	"strings"
 	"testing"
 	"time"
 
-	"github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -36,6 +41,20 @@ func (m *MockRequestRepository) CreateRequest(ctx context.Context, request *enti
 	return args.Get(0).(*entity.Request), args.Error(1)
 }
 
+// CreateRequestWithinQuota checks the usage of the GetUserUsage expectation before the CreateRequest one
+func (m *MockRequestRepository) CreateRequestWithinQuota(ctx context.Context, request *entity.Request, dayStart time.Time, monthStart time.Time, check func(usage *entity.Usage) error) (*entity.Request, error) {
+	usage, err := m.GetUserUsage(ctx, request.UserId, dayStart, monthStart)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = check(usage); err != nil {
+		return nil, err
+	}
+
+	return m.CreateRequest(ctx, request)
+}
+
 func (m *MockRequestRepository) UpdateRequest(ctx context.Context, request *entity.Request) (*entity.Request, error) {
 	args := m.Called(ctx, request)
 	return args.Get(0).(*entity.Request), args.Error(1)
@@ -56,14 +75,143 @@ func (m *MockRequestRepository) GetById(ctx context.Context, id uint64) (*entity
 	return args.Get(0).(*entity.Request), args.Error(1)
 }
 
-func (m *MockStoragePort) UploadFile(file *multipart.FileHeader, fileKey string) (string, error) {
-	args := m.Called(file, fileKey)
+func (m *MockRequestRepository) GetByVideoKey(ctx context.Context, videoKey string) (*entity.Request, error) {
+	args := m.Called(ctx, videoKey)
+	return args.Get(0).(*entity.Request), args.Error(1)
+}
+
+func (m *MockRequestRepository) UpdateProgress(ctx context.Context, id uint64, progress entity.Progress) error {
+	args := m.Called(ctx, id, progress)
+	return args.Error(0)
+}
+
+func (m *MockRequestRepository) UpdateStuckRequest(ctx context.Context, request *entity.Request, status entity.RequestStatus, attempt int) (*entity.Request, error) {
+	args := m.Called(ctx, request, status, attempt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Request), args.Error(1)
+}
+
+func (m *MockRequestRepository) GetStuckRequests(ctx context.Context, startedBefore time.Time) ([]entity.Request, error) {
+	args := m.Called(ctx, startedBefore)
+	return args.Get(0).([]entity.Request), args.Error(1)
+}
+
+func (m *MockRequestRepository) GetStalePendingRequests(ctx context.Context, createdBefore time.Time) ([]entity.Request, error) {
+	args := m.Called(ctx, createdBefore)
+	return args.Get(0).([]entity.Request), args.Error(1)
+}
+
+func (m *MockRequestRepository) GetCompletedByContentHash(ctx context.Context, contentHash string, options entity.ExtractionOptions, userId string, organizationId string) (*entity.Request, error) {
+	args := m.Called(ctx, contentHash, options, userId, organizationId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Request), args.Error(1)
+}
+
+func (m *MockRequestRepository) UpdateNotificationStatus(ctx context.Context, id uint64, status entity.NotificationStatus) error {
+	args := m.Called(ctx, id, status)
+	return args.Error(0)
+}
+
+func (m *MockRequestRepository) SearchRequests(ctx context.Context, filter entity.RequestFilter) ([]entity.Request, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]entity.Request), args.Error(1)
+}
+
+func (m *MockRequestRepository) CountByStatus(ctx context.Context) (map[entity.RequestStatus]int, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(map[entity.RequestStatus]int), args.Error(1)
+}
+
+func (m *MockRequestRepository) GetUserUsage(ctx context.Context, userId string, dayStart time.Time, monthStart time.Time) (*entity.Usage, error) {
+	args := m.Called(ctx, userId, dayStart, monthStart)
+	return args.Get(0).(*entity.Usage), args.Error(1)
+}
+
+func (m *MockRequestRepository) GetDigestUserIds(ctx context.Context, finishedBefore time.Time) ([]string, error) {
+	args := m.Called(ctx, finishedBefore)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockRequestRepository) GetDigestRequests(ctx context.Context, userId string, finishedBefore time.Time) ([]entity.Request, error) {
+	args := m.Called(ctx, userId, finishedBefore)
+	return args.Get(0).([]entity.Request), args.Error(1)
+}
+
+type MockMetrics struct {
+	mock.Mock
+}
+
+func (m *MockMetrics) ObserveHTTPRequest(method string, route string, status int, duration time.Duration) {
+	m.Called(method, route, status, duration)
+}
+
+func (m *MockMetrics) ObserveUpload(bytes int64, duration time.Duration, err error) {
+	m.Called(bytes, duration, err)
+}
+
+func (m *MockMetrics) ObserveCall(service string, operation string, duration time.Duration, err error) {
+	m.Called(service, operation, duration, err)
+}
+
+func (m *MockMetrics) ObserveMessagesReceived(queue string, count int) {
+	m.Called(queue, count)
+}
+
+func (m *MockMetrics) ObserveMessage(queue string, duration time.Duration, err error) {
+	m.Called(queue, duration, err)
+}
+
+func (m *MockMetrics) ObserveProcessing(status entity.RequestStatus, duration time.Duration) {
+	m.Called(status, duration)
+}
+
+func (m *MockStoragePort) UploadFile(ctx context.Context, content io.ReadSeeker, size int64, contentType string, fileKey string) (string, error) {
+	// Reads the content twice, like the S3 client signing the request
+	_, _ = io.Copy(io.Discard, content)
+	_, _ = content.Seek(0, io.SeekStart)
+	_, _ = io.Copy(io.Discard, content)
+	args := m.Called(size, fileKey)
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockStoragePort) DownloadFile(fileKey string) (*file.File, error) {
+func (m *MockStoragePort) DownloadFile(ctx context.Context, fileKey string) (io.ReadCloser, *entity.FileInfo, error) {
 	args := m.Called(fileKey)
-	return args.Get(0).(*file.File), args.Error(1)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(io.ReadCloser), args.Get(1).(*entity.FileInfo), args.Error(2)
+}
+
+func (m *MockStoragePort) DownloadFileRange(ctx context.Context, fileKey string, offset int64, length int64) (io.ReadCloser, *entity.FileInfo, error) {
+	args := m.Called(fileKey, offset, length)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(io.ReadCloser), args.Get(1).(*entity.FileInfo), args.Error(2)
+}
+
+func (m *MockStoragePort) StatFile(ctx context.Context, fileKey string) (*entity.FileInfo, error) {
+	args := m.Called(fileKey)
+	return args.Get(0).(*entity.FileInfo), args.Error(1)
+}
+
+func (m *MockStoragePort) FileExists(ctx context.Context, fileKey string) (bool, error) {
+	args := m.Called(fileKey)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockStoragePort) DeleteFile(ctx context.Context, fileKey string) error {
+	args := m.Called(fileKey)
+	return args.Error(0)
+}
+
+func (m *MockStoragePort) ListFiles(ctx context.Context, prefix string) ([]entity.FileInfo, error) {
+	args := m.Called(prefix)
+	return args.Get(0).([]entity.FileInfo), args.Error(1)
 }
 
 func (m *MockStoragePort) GetFileUrl(fileKey string) string {
@@ -76,8 +224,13 @@ func (m *MockRequestNotifications) SendVideoProccessToQueue(request *entity.Requ
 	return args.Error(0)
 }
 
-func (m *MockMailService) NotifyRequestStatus(request *entity.Request, status string) error {
-	args := m.Called(request, status)
+func (m *MockMailService) NotifyRequestStatus(request *entity.Request, kind entity.NotificationKind) error {
+	args := m.Called(request, kind)
+	return args.Error(0)
+}
+
+func (m *MockMailService) NotifyQuotaWarning(request *entity.Request, warning entity.QuotaWarning) error {
+	args := m.Called(request, warning)
 	return args.Error(0)
 }
 
@@ -102,23 +255,32 @@ func TestCreateRequest_Success(t *testing.T) {
 	request := &entity.Request{
 		UserId: "user123",
 	}
-	videoFile := &multipart.FileHeader{
-		Filename: "video.mp4",
-		Size:     100 * 1024 * 1024, // 100 MB
-	}
+	videoFile := mocks.MockGetFileHeader("video.mp4", mocks.MockGetVideoContent("mp4"))
 
 	fileKey := "videos_input/user123_2023-01-01-10-00-00.mp4"
-	mockStorage.On("UploadFile", videoFile, mock.Anything).Return(fileKey, nil)
+	mockStorage.On("UploadFile", videoFile.Size, mock.Anything).Return(fileKey, nil)
 	mockRepo.On("CreateRequest", ctx, mock.Anything).Return(request, nil)
 
 	createdRequest, err := requestUsecase.Create(ctx, request, videoFile)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, createdRequest)
-	mockStorage.AssertCalled(t, "UploadFile", videoFile, mock.AnythingOfType("string"))
+	mockStorage.AssertCalled(t, "UploadFile", videoFile.Size, mock.AnythingOfType("string"))
 	mockRepo.AssertCalled(t, "CreateRequest", ctx, mock.Anything)
 }
 
+func TestCreateRequest_StorageError(t *testing.T) {
+	_, mockStorage, _, requestUsecase := setUp()
+
+	videoFile := mocks.MockGetFileHeader("video.mp4", mocks.MockGetVideoContent("mp4"))
+	mockStorage.On("UploadFile", videoFile.Size, mock.Anything).Return("", errors.New("connection reset"))
+
+	createdRequest, err := requestUsecase.Create(context.Background(), &entity.Request{UserId: "user123"}, videoFile)
+
+	assert.Nil(t, createdRequest)
+	assert.ErrorIs(t, err, core.ErrStorageUnavailable)
+}
+
 func TestCreateRequest_InvalidFileExtension(t *testing.T) {
 	_, _, _, requestUsecase := setUp()
 
@@ -136,6 +298,78 @@ func TestCreateRequest_InvalidFileExtension(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, createdRequest)
 	assert.EqualError(t, err, "file extension not allowed")
+	assert.ErrorIs(t, err, core.ErrUnsupportedMediaType)
+}
+
+func TestCreateRequest_FileWithoutExtension(t *testing.T) {
+	_, _, _, requestUsecase := setUp()
+
+	ctx := context.Background()
+	request := &entity.Request{UserId: "user123"}
+	videoFile := mocks.MockGetFileHeader("video", mocks.MockGetVideoContent("mp4"))
+
+	createdRequest, err := requestUsecase.Create(ctx, request, videoFile)
+
+	assert.Nil(t, createdRequest)
+	assert.ErrorIs(t, err, core.ErrUnsupportedMediaType)
+}
+
+func TestCreateRequest_MultipleDotsAndUppercaseExtension(t *testing.T) {
+	mockRepo, mockStorage, _, requestUsecase := setUp()
+
+	ctx := context.Background()
+	request := &entity.Request{UserId: "user123"}
+	videoFile := mocks.MockGetFileHeader("my.holiday.video.MKV", mocks.MockGetVideoContent("mkv"))
+
+	mockStorage.On("UploadFile", videoFile.Size, mock.MatchedBy(func(key string) bool {
+		return strings.HasSuffix(key, ".mkv")
+	})).Return("videos_input/user123.mkv", nil)
+	mockRepo.On("CreateRequest", ctx, mock.Anything).Return(request, nil)
+
+	createdRequest, err := requestUsecase.Create(ctx, request, videoFile)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, createdRequest)
+}
+
+func TestCreateRequest_ContentMismatch(t *testing.T) {
+	_, mockStorage, _, requestUsecase := setUp()
+
+	ctx := context.Background()
+	request := &entity.Request{UserId: "user123"}
+	videoFile := mocks.MockGetFileHeader("video.mp4", mocks.MockGetVideoContent("avi"))
+
+	createdRequest, err := requestUsecase.Create(ctx, request, videoFile)
+
+	assert.Nil(t, createdRequest)
+	assert.ErrorIs(t, err, core.ErrUnsupportedMediaType)
+	mockStorage.AssertNotCalled(t, "UploadFile", mock.Anything, mock.Anything)
+}
+
+func TestCreateRequest_RenamedExecutable(t *testing.T) {
+	_, _, _, requestUsecase := setUp()
+
+	ctx := context.Background()
+	request := &entity.Request{UserId: "user123"}
+	videoFile := mocks.MockGetFileHeader("video.mp4", []byte("MZ\x90\x00\x03\x00\x00\x00"))
+
+	createdRequest, err := requestUsecase.Create(ctx, request, videoFile)
+
+	assert.Nil(t, createdRequest)
+	assert.ErrorIs(t, err, core.ErrUnsupportedMediaType)
+}
+
+func TestCreateRequest_EmptyFile(t *testing.T) {
+	_, _, _, requestUsecase := setUp()
+
+	ctx := context.Background()
+	request := &entity.Request{UserId: "user123"}
+	videoFile := mocks.MockGetFileHeader("video.mp4", []byte{})
+
+	createdRequest, err := requestUsecase.Create(ctx, request, videoFile)
+
+	assert.Nil(t, createdRequest)
+	assert.ErrorIs(t, err, core.ErrInvalidFile)
 }
 
 func TestCreateRequest_InvalidFileSize(t *testing.T) {
@@ -155,6 +389,231 @@ func TestCreateRequest_InvalidFileSize(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, createdRequest)
 	assert.EqualError(t, err, "file size is greater than 500Mb")
+	assert.ErrorIs(t, err, core.ErrFileTooLarge)
+}
+
+func setUpWithLimits() (*MockRequestRepository, *MockStoragePort, *usecase.RequestUseCase) {
+	mockRepo := new(MockRequestRepository)
+	mockStorage := new(MockStoragePort)
+	requestUsecase := usecase.NewRequestUseCase(mockRepo, mockStorage, new(MockRequestNotifications), new(MockMailService),
+		usecase.WithVideoLimits(usecase.VideoLimits{MaxDuration: time.Hour, MaxWidth: 1920, MaxHeight: 1080}))
+
+	return mockRepo, mockStorage, requestUsecase
+}
+
+func TestCreateRequest_StoresVideoMetadata(t *testing.T) {
+	mockRepo, mockStorage, requestUsecase := setUpWithLimits()
+
+	ctx := context.Background()
+	request := &entity.Request{UserId: "user123"}
+	videoFile := mocks.MockGetFileHeader("video.webm", mocks.MockGetVideo("webm", 90*time.Second, 1920, 1080))
+
+	mockStorage.On("UploadFile", videoFile.Size, mock.Anything).Return("videos_input/user123.webm", nil)
+	mockRepo.On("CreateRequest", ctx, mock.Anything).Return(request, nil)
+
+	_, err := requestUsecase.Create(ctx, request, videoFile)
+
+	assert.NoError(t, err)
+	mockRepo.AssertCalled(t, "CreateRequest", ctx, mock.MatchedBy(func(request *entity.Request) bool {
+		return request.VideoMetadata == entity.VideoMetadata{
+			Format:   "webm",
+			Duration: 90 * time.Second,
+			Width:    1920,
+			Height:   1080,
+			Codec:    "vp9",
+		}
+	}))
+}
+
+func TestCreateRequest_PortraitVideoWithinLimits(t *testing.T) {
+	mockRepo, mockStorage, requestUsecase := setUpWithLimits()
+
+	ctx := context.Background()
+	request := &entity.Request{UserId: "user123"}
+	videoFile := mocks.MockGetFileHeader("video.mp4", mocks.MockGetVideo("mp4", time.Minute, 1080, 1920))
+
+	mockStorage.On("UploadFile", videoFile.Size, mock.Anything).Return("videos_input/user123.mp4", nil)
+	mockRepo.On("CreateRequest", ctx, mock.Anything).Return(request, nil)
+
+	_, err := requestUsecase.Create(ctx, request, videoFile)
+
+	assert.NoError(t, err)
+}
+
+func TestCreateRequest_VideoTooLong(t *testing.T) {
+	_, mockStorage, requestUsecase := setUpWithLimits()
+
+	request := &entity.Request{UserId: "user123"}
+	videoFile := mocks.MockGetFileHeader("video.mkv", mocks.MockGetVideo("mkv", 2*time.Hour, 1280, 720))
+
+	createdRequest, err := requestUsecase.Create(context.Background(), request, videoFile)
+
+	assert.Nil(t, createdRequest)
+	assert.ErrorIs(t, err, core.ErrInvalidFile)
+	assert.EqualError(t, err, "video duration is longer than 1h0m0s")
+	mockStorage.AssertNotCalled(t, "UploadFile", mock.Anything, mock.Anything)
+}
+
+func TestCreateRequest_VideoResolutionTooHigh(t *testing.T) {
+	_, mockStorage, requestUsecase := setUpWithLimits()
+
+	request := &entity.Request{UserId: "user123"}
+	videoFile := mocks.MockGetFileHeader("video.avi", mocks.MockGetVideo("avi", time.Minute, 3840, 2160))
+
+	createdRequest, err := requestUsecase.Create(context.Background(), request, videoFile)
+
+	assert.Nil(t, createdRequest)
+	assert.ErrorIs(t, err, core.ErrInvalidFile)
+	assert.EqualError(t, err, "video resolution is greater than 1920x1080")
+	mockStorage.AssertNotCalled(t, "UploadFile", mock.Anything, mock.Anything)
+}
+
+func TestCreateRequest_CorruptedVideo(t *testing.T) {
+	_, _, _, requestUsecase := setUp()
+
+	content := mocks.MockGetVideoContent("mp4")
+	videoFile := mocks.MockGetFileHeader("video.mp4", content[:len(content)/2])
+
+	createdRequest, err := requestUsecase.Create(context.Background(), &entity.Request{UserId: "user123"}, videoFile)
+
+	assert.Nil(t, createdRequest)
+	assert.ErrorIs(t, err, core.ErrInvalidFile)
+	assert.EqualError(t, err, "video metadata could not be read")
+}
+
+func setUpWithDeduplication(mode usecase.DedupMode) (*MockRequestRepository, *MockStoragePort, *MockRequestNotifications, *usecase.RequestUseCase) {
+	mockRepo := new(MockRequestRepository)
+	mockStorage := new(MockStoragePort)
+	mockNotification := new(MockRequestNotifications)
+	mockMailService := new(MockMailService)
+	requestUsecase := usecase.NewRequestUseCase(mockRepo, mockStorage, mockNotification, mockMailService,
+		usecase.WithDeduplication(mode))
+
+	mockMailService.On("NotifyRequestStatus", mock.Anything, mock.Anything).Return(nil)
+
+	return mockRepo, mockStorage, mockNotification, requestUsecase
+}
+
+func TestCreateRequest_StoresContentHash(t *testing.T) {
+	mockRepo, mockStorage, _, requestUsecase := setUp()
+
+	ctx := context.Background()
+	content := mocks.MockGetVideoContent("mp4")
+	videoFile := mocks.MockGetFileHeader("video.mp4", content)
+	expectedHash := sha256.Sum256(content)
+
+	mockStorage.On("UploadFile", videoFile.Size, mock.Anything).Return("videos_input/user123.mp4", nil)
+	mockRepo.On("CreateRequest", ctx, mock.Anything).Return(&entity.Request{}, nil)
+
+	_, err := requestUsecase.Create(ctx, &entity.Request{UserId: "user123"}, videoFile)
+
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "GetCompletedByContentHash", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertCalled(t, "CreateRequest", ctx, mock.MatchedBy(func(request *entity.Request) bool {
+		return request.ContentHash == hex.EncodeToString(expectedHash[:]) &&
+			request.Options.FrameInterval == entity.DefaultFrameInterval
+	}))
+}
+
+func TestCreateRequest_HashesWholeUpload(t *testing.T) {
+	_, mockStorage, _, requestUsecase := setUp()
+
+	videoFile := mocks.MockGetFileHeader("video.mp4", mocks.MockGetVideoContent("mp4"))
+	videoFile.Size++
+	mockStorage.On("UploadFile", videoFile.Size, mock.Anything).Return("videos_input/user123.mp4", nil)
+
+	createdRequest, err := requestUsecase.Create(context.Background(), &entity.Request{UserId: "user123"}, videoFile)
+
+	assert.Nil(t, createdRequest)
+	assert.ErrorContains(t, err, "bytes read")
+}
+
+func TestCreateRequest_DuplicateReusesOutput(t *testing.T) {
+	mockRepo, mockStorage, mockQueue, requestUsecase := setUpWithDeduplication(usecase.DedupUser)
+
+	ctx := context.Background()
+	videoFile := mocks.MockGetFileHeader("video.mp4", mocks.MockGetVideoContent("mp4"))
+	original := &entity.Request{
+		ID:           7,
+		UserId:       "user123",
+		VideoKey:     "videos_input/user123_original.mp4",
+		ZipOutputKey: "url-to-s3-file/zip_output/7.zip",
+		Status:       entity.Completed,
+	}
+
+	mockStorage.On("UploadFile", videoFile.Size, mock.Anything).Return("videos_input/user123_duplicate.mp4", nil)
+	mockStorage.On("DeleteFile", "videos_input/user123_duplicate.mp4").Return(nil)
+	mockRepo.On("GetCompletedByContentHash", ctx, mock.Anything, mock.Anything, "user123", "").Return(original, nil)
+	mockRepo.On("CreateRequest", ctx, mock.Anything).Return(&entity.Request{ID: 8, DuplicateOf: 7}, nil)
+
+	createdRequest, err := requestUsecase.Create(ctx, &entity.Request{UserId: "user123", OrganizationId: "team-1"}, videoFile)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), createdRequest.DuplicateOf)
+	mockRepo.AssertCalled(t, "CreateRequest", ctx, mock.MatchedBy(func(request *entity.Request) bool {
+		return request.DuplicateOf == 7 &&
+			request.Status == entity.Completed &&
+			request.ZipOutputKey == original.ZipOutputKey &&
+			request.VideoKey == original.VideoKey &&
+			!request.FinishedAt.IsZero()
+	}))
+	mockStorage.AssertCalled(t, "DeleteFile", "videos_input/user123_duplicate.mp4")
+	mockQueue.AssertNotCalled(t, "SendVideoProccessToQueue", mock.Anything)
+}
+
+func TestCreateRequest_DuplicateSearchesOrganization(t *testing.T) {
+	mockRepo, mockStorage, _, requestUsecase := setUpWithDeduplication(usecase.DedupOrganization)
+
+	ctx := context.Background()
+	videoFile := mocks.MockGetFileHeader("video.mp4", mocks.MockGetVideoContent("mp4"))
+	options := entity.ExtractionOptions{FrameInterval: 500 * time.Millisecond}
+
+	mockStorage.On("UploadFile", videoFile.Size, mock.Anything).Return("videos_input/user123.mp4", nil)
+	mockStorage.On("DeleteFile", mock.Anything).Return(nil)
+	mockRepo.On("GetCompletedByContentHash", ctx, mock.Anything, options, "user123", "team-1").
+		Return(&entity.Request{ID: 7, Status: entity.Completed}, nil)
+	mockRepo.On("CreateRequest", ctx, mock.Anything).Return(&entity.Request{ID: 8, DuplicateOf: 7}, nil)
+
+	_, err := requestUsecase.Create(ctx, &entity.Request{UserId: "user123", OrganizationId: "team-1", Options: options}, videoFile)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateRequest_NoDuplicateFound(t *testing.T) {
+	mockRepo, mockStorage, _, requestUsecase := setUpWithDeduplication(usecase.DedupUser)
+
+	ctx := context.Background()
+	videoFile := mocks.MockGetFileHeader("video.mp4", mocks.MockGetVideoContent("mp4"))
+
+	mockRepo.On("GetCompletedByContentHash", ctx, mock.Anything, mock.Anything, "user123", "").Return(nil, core.ErrDataNotFound)
+	mockStorage.On("UploadFile", videoFile.Size, mock.Anything).Return("videos_input/user123.mp4", nil)
+	mockRepo.On("CreateRequest", ctx, mock.Anything).Return(&entity.Request{ID: 8}, nil)
+
+	_, err := requestUsecase.Create(ctx, &entity.Request{UserId: "user123"}, videoFile)
+
+	assert.NoError(t, err)
+	mockStorage.AssertCalled(t, "UploadFile", videoFile.Size, mock.Anything)
+	mockRepo.AssertCalled(t, "CreateRequest", ctx, mock.MatchedBy(func(request *entity.Request) bool {
+		return request.DuplicateOf == 0 && request.Status == entity.Pending
+	}))
+}
+
+func TestCreateRequest_DuplicateSearchError(t *testing.T) {
+	mockRepo, mockStorage, _, requestUsecase := setUpWithDeduplication(usecase.DedupUser)
+
+	ctx := context.Background()
+	videoFile := mocks.MockGetFileHeader("video.mp4", mocks.MockGetVideoContent("mp4"))
+
+	mockStorage.On("UploadFile", videoFile.Size, mock.Anything).Return("videos_input/user123.mp4", nil)
+	mockRepo.On("GetCompletedByContentHash", ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.New("database error"))
+
+	createdRequest, err := requestUsecase.Create(ctx, &entity.Request{UserId: "user123"}, videoFile)
+
+	assert.Nil(t, createdRequest)
+	assert.Error(t, err)
+	mockRepo.AssertNotCalled(t, "CreateRequest", mock.Anything, mock.Anything)
 }
 
 func TestUpdateRequest_Success(t *testing.T) {
@@ -277,6 +736,82 @@ func TestHandleUploadNotification_Success(t *testing.T) {
 	notify.AssertCalled(t, "SendVideoProccessToQueue", updatedRequest)
 }
 
+func TestHandleUploadNotification_RecordsHistory(t *testing.T) {
+	repo := new(MockRequestRepository)
+	notify := new(MockRequestNotifications)
+	events := new(MockRequestEventRepository)
+	use := usecase.NewRequestUseCase(repo, new(MockStoragePort), notify, new(MockMailService), usecase.WithHistory(usecase.NewRequestHistory(events, nil, nil, nil)))
+	ctx := context.Background()
+
+	// Given
+	event := entity.EventMessage{Body: mocks.MockGetMockS3EventBody()}
+	updatedRequest := &entity.Request{ID: 1, Status: entity.InProgress}
+
+	// When
+	repo.On("UpdateStatusByVideoKey", ctx, string(entity.InProgress), "video_input/test.mp4").Return(updatedRequest, nil)
+	notify.On("SendVideoProccessToQueue", updatedRequest).Return(nil)
+	events.On("AddEvent", ctx, mock.Anything).Return(nil)
+	use.HandleUploadNotification(ctx, event)
+
+	// Then
+	events.AssertCalled(t, "AddEvent", ctx, mock.MatchedBy(func(e *entity.RequestEvent) bool {
+		return e.RequestId == 1 && e.Status == entity.InProgress && e.Actor == entity.SystemActor
+	}))
+}
+
+func TestHandleUploadNotification_AlreadyStarted(t *testing.T) {
+	repo, _, notify, use := setUp()
+	ctx := context.Background()
+
+	// Given
+	event := entity.EventMessage{Body: mocks.MockGetMockS3EventBody()}
+	repo.On("UpdateStatusByVideoKey", ctx, string(entity.InProgress), "video_input/test.mp4").Return((*entity.Request)(nil), core.ErrDataNotFound)
+	repo.On("GetByVideoKey", ctx, "video_input/test.mp4").Return(&entity.Request{ID: 1, Status: entity.InProgress}, nil)
+
+	// When
+	err := use.HandleUploadNotification(ctx, event)
+
+	// Then
+	assert.NoError(t, err)
+	notify.AssertNotCalled(t, "SendVideoProccessToQueue", mock.Anything)
+}
+
+func TestHandleUploadNotification_RequestNotCreatedYet(t *testing.T) {
+	repo, storage, notify, use := setUp()
+	ctx := context.Background()
+
+	// Given
+	event := entity.EventMessage{Body: mocks.MockGetMockS3EventBody()}
+	repo.On("UpdateStatusByVideoKey", ctx, string(entity.InProgress), "video_input/test.mp4").Return((*entity.Request)(nil), core.ErrDataNotFound)
+	repo.On("GetByVideoKey", ctx, "video_input/test.mp4").Return((*entity.Request)(nil), core.ErrDataNotFound)
+	storage.On("FileExists", "video_input/test.mp4").Return(true, nil)
+
+	// When
+	err := use.HandleUploadNotification(ctx, event)
+
+	// Then
+	assert.ErrorIs(t, err, core.ErrDataNotFound)
+	notify.AssertNotCalled(t, "SendVideoProccessToQueue", mock.Anything)
+}
+
+func TestHandleUploadNotification_UploadRemoved(t *testing.T) {
+	repo, storage, notify, use := setUp()
+	ctx := context.Background()
+
+	// Given
+	event := entity.EventMessage{Body: mocks.MockGetMockS3EventBody()}
+	repo.On("UpdateStatusByVideoKey", ctx, string(entity.InProgress), "video_input/test.mp4").Return((*entity.Request)(nil), core.ErrDataNotFound)
+	repo.On("GetByVideoKey", ctx, "video_input/test.mp4").Return((*entity.Request)(nil), core.ErrDataNotFound)
+	storage.On("FileExists", "video_input/test.mp4").Return(false, nil)
+
+	// When
+	err := use.HandleUploadNotification(ctx, event)
+
+	// Then
+	assert.NoError(t, err)
+	notify.AssertNotCalled(t, "SendVideoProccessToQueue", mock.Anything)
+}
+
 func TestHandleUploadNotification_InvalidBody(t *testing.T) {
 	repo, _, notify, use := setUp()
 	ctx := context.Background()
@@ -327,9 +862,10 @@ func TestHandleVideoOutputNotification_UpdateError(t *testing.T) {
 	// When
 	repo.On("GetById", ctx, id).Return(&request, nil)
 	repo.On("UpdateRequest", ctx, mock.Anything).Return((*entity.Request)(nil), errors.New("mock error"))
-	use.HandleVideoOutputNotification(ctx, message)
+	err := use.HandleVideoOutputNotification(ctx, message)
 
 	// Then
+	assert.Error(t, err)
 	repo.AssertCalled(t, "GetById", ctx, id)
 	repo.AssertNotCalled(t, "UpdateRequest")
 
@@ -344,9 +880,10 @@ func TestHandleVideoOutputNotification_InvalidBody(t *testing.T) {
 	message := entity.EventMessage{Body: notificationBody}
 
 	// When
-	use.HandleVideoOutputNotification(ctx, message)
+	err := use.HandleVideoOutputNotification(ctx, message)
 
 	// Then
+	assert.Error(t, err)
 	repo.AssertNotCalled(t, "GetById")
 	repo.AssertNotCalled(t, "UpdateRequest")
 }
@@ -373,3 +910,209 @@ func TestHandleVideoOutputNotification_Success(t *testing.T) {
 	repo.AssertNotCalled(t, "GetById")
 	repo.AssertNotCalled(t, "UpdateRequest")
 }
+
+func TestHandleUploadNotification_PublishesWebhook(t *testing.T) {
+	repo := new(MockRequestRepository)
+	notify := new(MockRequestNotifications)
+	webhooks := new(MockWebhookPublisher)
+	use := usecase.NewRequestUseCase(repo, new(MockStoragePort), notify, new(MockMailService), usecase.WithHistory(usecase.NewRequestHistory(nil, nil, webhooks, nil)))
+	ctx := context.Background()
+
+	// Given
+	event := entity.EventMessage{Body: mocks.MockGetMockS3EventBody()}
+	updatedRequest := &entity.Request{ID: 1, Status: entity.InProgress}
+
+	// When
+	repo.On("UpdateStatusByVideoKey", ctx, string(entity.InProgress), "video_input/test.mp4").Return(updatedRequest, nil)
+	notify.On("SendVideoProccessToQueue", updatedRequest).Return(nil)
+	webhooks.On("Publish", ctx, updatedRequest, entity.EventRequestStarted).Return()
+	use.HandleUploadNotification(ctx, event)
+
+	// Then
+	webhooks.AssertCalled(t, "Publish", ctx, updatedRequest, entity.EventRequestStarted)
+}
+
+func TestHandleUploadNotification_PublishesEvent(t *testing.T) {
+	repo := new(MockRequestRepository)
+	notify := new(MockRequestNotifications)
+	events := new(MockRequestEventRepository)
+	broker := new(MockRequestEventBroker)
+	use := usecase.NewRequestUseCase(repo, new(MockStoragePort), notify, new(MockMailService),
+		usecase.WithHistory(usecase.NewRequestHistory(events, nil, nil, broker)))
+	ctx := context.Background()
+
+	// Given
+	event := entity.EventMessage{Body: mocks.MockGetMockS3EventBody()}
+	updatedRequest := &entity.Request{ID: 1, UserId: "123456", Status: entity.InProgress}
+
+	// When
+	repo.On("UpdateStatusByVideoKey", ctx, string(entity.InProgress), "video_input/test.mp4").Return(updatedRequest, nil)
+	notify.On("SendVideoProccessToQueue", updatedRequest).Return(nil)
+	events.On("AddEvent", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(*entity.RequestEvent).ID = 42
+	}).Return(nil)
+	broker.On("Publish", ctx, "123456", mock.Anything).Return(nil)
+	use.HandleUploadNotification(ctx, event)
+
+	// Then
+	broker.AssertCalled(t, "Publish", ctx, "123456", mock.MatchedBy(func(e entity.RequestEvent) bool {
+		return e.ID == 42 && e.RequestId == 1 && e.Status == entity.InProgress
+	}))
+}
+
+func TestHandleVideoOutputNotification_Progress(t *testing.T) {
+	repo, _, _, use := setUp()
+	ctx := context.Background()
+
+	// Given
+	message := entity.EventMessage{Body: `{"id": 1, "id_user": "123", "type": "progress", "percent": 142.5, "frames_extracted": 120, "eta_seconds": 90}`}
+
+	// When
+	repo.On("UpdateProgress", ctx, uint64(1), mock.Anything).Return(nil)
+	use.HandleVideoOutputNotification(ctx, message)
+
+	// Then
+	repo.AssertCalled(t, "UpdateProgress", ctx, uint64(1), mock.MatchedBy(func(p entity.Progress) bool {
+		return p.Percent == 100 && p.FramesExtracted == 120 && p.Eta.Sub(p.UpdatedAt) == 90*time.Second
+	}))
+	repo.AssertNotCalled(t, "GetById", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "UpdateRequest", mock.Anything, mock.Anything)
+}
+
+func TestHandleVideoOutputNotification_OutdatedProgress(t *testing.T) {
+	repo, _, _, use := setUp()
+	ctx := context.Background()
+
+	// Given
+	message := entity.EventMessage{Body: `{"id": 1, "type": "progress", "percent": 10, "frames_extracted": 12}`}
+
+	// When
+	repo.On("UpdateProgress", ctx, uint64(1), mock.Anything).Return(core.ErrDataNotFound)
+	use.HandleVideoOutputNotification(ctx, message)
+
+	// Then
+	repo.AssertCalled(t, "UpdateProgress", ctx, uint64(1), mock.MatchedBy(func(p entity.Progress) bool {
+		return p.Eta.IsZero()
+	}))
+	repo.AssertNotCalled(t, "UpdateRequest", mock.Anything, mock.Anything)
+}
+
+func TestHandleVideoOutputNotification_ResultCompletesProgress(t *testing.T) {
+	repo, storage, _, use := setUp()
+	ctx := context.Background()
+
+	// Given
+	request := &entity.Request{ID: 1, Status: entity.InProgress, Progress: entity.Progress{
+		Percent: 90, FramesExtracted: 200, Eta: time.Now().Add(time.Minute), UpdatedAt: time.Now(),
+	}}
+	message := entity.EventMessage{Body: `{"id": 1, "type": "result", "status": "OK", "s3_zip_file_key": "zip_output/1.zip"}`}
+
+	// When
+	repo.On("GetById", ctx, uint64(1)).Return(request, nil)
+	repo.On("UpdateRequest", ctx, mock.Anything).Return(request, nil)
+	storage.On("GetFileUrl", "zip_output/1.zip").Return("url-to-s3-file/zip_output/1.zip")
+	use.HandleVideoOutputNotification(ctx, message)
+
+	// Then
+	repo.AssertCalled(t, "UpdateRequest", ctx, mock.MatchedBy(func(r *entity.Request) bool {
+		return r.Status == entity.Completed && r.Progress.Percent == 100 && r.Progress.FramesExtracted == 200 && r.Progress.Eta.IsZero()
+	}))
+}
+
+func TestHandleVideoOutputNotification_StoresOutputKey(t *testing.T) {
+	repo, storage, _, use := setUp()
+	ctx := context.Background()
+
+	// Given
+	request := &entity.Request{ID: 1, Status: entity.InProgress}
+	message := entity.EventMessage{Body: `{"id": 1, "type": "result", "status": "OK", "s3_zip_file_key": "zip_output/1.zip"}`}
+
+	// When
+	repo.On("GetById", ctx, uint64(1)).Return(request, nil)
+	repo.On("UpdateRequest", ctx, mock.Anything).Return(request, nil)
+	use.HandleVideoOutputNotification(ctx, message)
+
+	// Then
+	repo.AssertCalled(t, "UpdateRequest", ctx, mock.MatchedBy(func(r *entity.Request) bool {
+		return r.ZipOutputKey == "zip_output/1.zip"
+	}))
+	storage.AssertCalled(t, "GetFileUrl", "zip_output/1.zip")
+	assert.Equal(t, "url-to-s3-file/zip_output/file.zip", request.ZipOutputUrl)
+}
+
+func TestGetRequest_SignsOutput(t *testing.T) {
+	repo, storage, _, use := setUp()
+	ctx := context.Background()
+
+	// Given
+	repo.On("GetById", ctx, uint64(1)).Return(&entity.Request{ID: 1, ZipOutputKey: "zip_output/1.zip"}, nil)
+	repo.On("GetById", ctx, uint64(2)).Return(&entity.Request{ID: 2, ZipOutputKey: "https://bucket.s3.amazonaws.com/zip_output/2.zip"}, nil)
+
+	// When
+	signed, _ := use.Get(ctx, 1)
+	legacy, _ := use.Get(ctx, 2)
+
+	// Then
+	assert.Equal(t, "url-to-s3-file/zip_output/file.zip", signed.ZipOutputUrl)
+	assert.Equal(t, "https://bucket.s3.amazonaws.com/zip_output/2.zip", legacy.ZipOutputUrl)
+	storage.AssertNumberOfCalls(t, "GetFileUrl", 1)
+}
+
+func TestHandleVideoOutputNotification_ObservesProcessing(t *testing.T) {
+	repo := new(MockRequestRepository)
+	storage := new(MockStoragePort)
+	mail := new(MockMailService)
+	metrics := new(MockMetrics)
+	use := usecase.NewRequestUseCase(repo, storage, new(MockRequestNotifications), mail, usecase.WithMetrics(metrics))
+	ctx := context.Background()
+
+	// Given
+	request := &entity.Request{ID: 1, Status: entity.InProgress, CreatedAt: time.Now().Add(-10 * time.Minute)}
+	message := entity.EventMessage{Body: `{"id": 1, "type": "result", "status": "OK", "s3_zip_file_key": "zip_output/1.zip"}`}
+
+	// When
+	repo.On("GetById", ctx, uint64(1)).Return(request, nil)
+	repo.On("UpdateRequest", ctx, mock.Anything).Return(request, nil)
+	storage.On("GetFileUrl", "zip_output/1.zip").Return("url-to-s3-file/zip_output/1.zip")
+	mail.On("NotifyRequestStatus", mock.Anything, mock.Anything).Return(nil)
+	metrics.On("ObserveProcessing", mock.Anything, mock.Anything).Return()
+	err := use.HandleVideoOutputNotification(ctx, message)
+
+	// Then
+	assert.NoError(t, err)
+	metrics.AssertCalled(t, "ObserveProcessing", entity.Completed, mock.MatchedBy(func(duration time.Duration) bool {
+		return duration >= 10*time.Minute && duration < 11*time.Minute
+	}))
+}
+
+func TestRequestTransitions_PublishDomainEvents(t *testing.T) {
+	repo := new(MockRequestRepository)
+	storage := new(MockStoragePort)
+	notify := new(MockRequestNotifications)
+	mail := new(MockMailService)
+	publisher := notification.NewMemoryEventPublisher()
+	use := usecase.NewRequestUseCase(repo, storage, notify, mail, usecase.WithHistory(usecase.NewRequestHistory(nil, publisher, nil, nil)))
+	ctx := context.Background()
+
+	// Given
+	started := &entity.Request{ID: 1, UserId: "123456", Status: entity.InProgress}
+	finished := &entity.Request{ID: 1, UserId: "123456", Status: entity.InProgress}
+
+	// When
+	repo.On("UpdateStatusByVideoKey", ctx, string(entity.InProgress), "video_input/test.mp4").Return(started, nil)
+	notify.On("SendVideoProccessToQueue", started).Return(nil)
+	repo.On("GetById", ctx, uint64(1)).Return(finished, nil)
+	repo.On("UpdateRequest", ctx, mock.Anything).Return(finished, nil)
+	storage.On("GetFileUrl", mock.Anything).Return("url-to-s3-file/zip_output/1.zip")
+	mail.On("NotifyRequestStatus", mock.Anything, mock.Anything).Return(nil)
+	use.HandleUploadNotification(ctx, entity.EventMessage{Body: mocks.MockGetMockS3EventBody()})
+	use.HandleVideoOutputNotification(ctx, entity.EventMessage{Body: `{"id": 1, "status": "ERROR"}`})
+
+	// Then
+	events := publisher.Events()
+	assert.Len(t, events, 2)
+	assert.Equal(t, entity.EventRequestStarted, events[0].Type)
+	assert.Equal(t, entity.EventRequestFailed, events[1].Type)
+	assert.Equal(t, "123456", events[1].Request.UserId)
+	assert.NotEqual(t, events[0].ID, events[1].ID)
+}
@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/port"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+type WatchdogUseCase struct {
+	repository  port.RequestRepository
+	history     *RequestHistory
+	storage     port.StoragePort
+	queue       port.QueuePort
+	mail        port.MailServicePort
+	sla         time.Duration
+	maxAttempts int
+}
+
+// NewWatchdogUseCase creates a new watchdog instance for requests stuck in processing
+func NewWatchdogUseCase(repo port.RequestRepository, history *RequestHistory, storage port.StoragePort, queue port.QueuePort, notif port.MailServicePort, sla time.Duration, maxAttempts int) *WatchdogUseCase {
+	return &WatchdogUseCase{repo, history, storage, queue, notif, sla, maxAttempts}
+}
+
+// HandleStuckRequests looks for requests IN_PROGRESS longer than the SLA and
+// re-enqueues them while there are attempts left, otherwise marks them as FAILED.
+// The requests PENDING longer than the SLA are handled by handleStalePending
+func (usecase *WatchdogUseCase) HandleStuckRequests(ctx context.Context) {
+
+	before := time.Now().Add(-usecase.sla)
+	usecase.handleStalePending(ctx, before)
+
+	requests, err := usecase.repository.GetStuckRequests(ctx, before)
+
+	if err != nil {
+		slog.Error("Error searching for stuck requests", "error", err)
+		return
+	}
+
+	for _, request := range requests {
+		if request.Attempts < usecase.maxAttempts {
+			usecase.requeue(ctx, &request)
+		} else {
+			usecase.timeout(ctx, &request)
+		}
+	}
+}
+
+// handleStalePending sends to processing the requests PENDING since before the informed moment whose
+// upload notification was missed, and fails the ones whose video isn't stored, so they stop counting
+// as active jobs on the quotas
+func (usecase *WatchdogUseCase) handleStalePending(ctx context.Context, createdBefore time.Time) {
+	requests, err := usecase.repository.GetStalePendingRequests(ctx, createdBefore)
+
+	if err != nil {
+		slog.Error("Error searching for stale pending requests", "error", err)
+		return
+	}
+
+	for _, request := range requests {
+		exists, err := usecase.storage.FileExists(ctx, request.VideoKey)
+
+		if err != nil {
+			slog.Error("Error checking the video of a stale pending request", "id", request.ID, "error", err)
+			continue
+		}
+
+		if exists {
+			usecase.start(ctx, &request)
+		} else {
+			usecase.discard(ctx, &request)
+		}
+	}
+}
+
+// start sends to processing the PENDING request whose video was uploaded
+func (usecase *WatchdogUseCase) start(ctx context.Context, request *entity.Request) {
+	attempt := request.Attempts
+	request.Status = entity.InProgress
+	request.Attempts++
+	request.StartedAt = time.Now()
+
+	updatedRequest, err := usecase.repository.UpdateStuckRequest(ctx, request, entity.Pending, attempt)
+
+	if errors.Is(err, core.ErrDataNotFound) {
+		slog.Info("Stale pending request changed before being sent to processing", "id", request.ID)
+		return
+	}
+
+	if err != nil {
+		slog.Error("Error updating stale pending request", "id", request.ID, "error", err)
+		return
+	}
+
+	slog.Warn("Sending stale pending request to processing", "id", request.ID)
+	usecase.history.record(ctx, updatedRequest, entity.SystemActor, "sent to processing without the upload notification")
+
+	err = usecase.queue.SendVideoProccessToQueue(updatedRequest)
+
+	if err != nil {
+		slog.Error("Error enqueuing stale pending request", "id", request.ID, "error", err)
+	}
+}
+
+// discard marks the PENDING request without video as FAILED and notifies the user
+func (usecase *WatchdogUseCase) discard(ctx context.Context, request *entity.Request) {
+	request.Status = entity.Failed
+	request.FinishedAt = time.Now()
+	request.FailureReason = "the video upload was not received"
+
+	updatedRequest, err := usecase.repository.UpdateStuckRequest(ctx, request, entity.Pending, request.Attempts)
+
+	if errors.Is(err, core.ErrDataNotFound) {
+		slog.Info("Stale pending request changed before being failed", "id", request.ID)
+		return
+	}
+
+	if err != nil {
+		slog.Error("Error failing stale pending request", "id", request.ID, "error", err)
+		return
+	}
+
+	slog.Warn("Stale pending request marked as failed", "id", request.ID, "reason", request.FailureReason)
+	usecase.history.record(ctx, updatedRequest, entity.SystemActor, request.FailureReason)
+
+	err = usecase.mail.NotifyRequestStatus(updatedRequest, entity.NotificationRequestFailed)
+
+	if err != nil {
+		slog.Error("Error notifying failed request", "id", request.ID, "error", err)
+	}
+}
+
+// requeue starts a new processing attempt for the request
+func (usecase *WatchdogUseCase) requeue(ctx context.Context, request *entity.Request) {
+	attempt := request.Attempts
+	request.Attempts++
+	request.StartedAt = time.Now()
+	request.Progress = entity.Progress{}
+
+	updatedRequest, err := usecase.repository.UpdateStuckRequest(ctx, request, entity.InProgress, attempt)
+
+	if errors.Is(err, core.ErrDataNotFound) {
+		slog.Info("Stuck request changed before being re-enqueued", "id", request.ID)
+		return
+	}
+
+	if err != nil {
+		slog.Error("Error updating stuck request", "id", request.ID, "error", err)
+		return
+	}
+
+	slog.Warn("Re-enqueuing stuck request", "id", request.ID, "attempt", updatedRequest.Attempts)
+	usecase.history.record(ctx, updatedRequest, entity.SystemActor, fmt.Sprintf("re-enqueued stuck request, attempt %d", updatedRequest.Attempts))
+
+	err = usecase.queue.SendVideoProccessToQueue(updatedRequest)
+
+	if err != nil {
+		slog.Error("Error re-enqueuing stuck request", "id", request.ID, "error", err)
+	}
+}
+
+// timeout marks the request as FAILED and notifies the user
+func (usecase *WatchdogUseCase) timeout(ctx context.Context, request *entity.Request) {
+	request.Status = entity.Failed
+	request.FinishedAt = time.Now()
+	request.FailureReason = fmt.Sprintf("processing timed out after %d attempts of %s", request.Attempts, usecase.sla)
+
+	updatedRequest, err := usecase.repository.UpdateStuckRequest(ctx, request, entity.InProgress, request.Attempts)
+
+	if errors.Is(err, core.ErrDataNotFound) {
+		slog.Info("Stuck request changed before being failed", "id", request.ID)
+		return
+	}
+
+	if err != nil {
+		slog.Error("Error failing stuck request", "id", request.ID, "error", err)
+		return
+	}
+
+	slog.Warn("Stuck request marked as failed", "id", request.ID, "reason", request.FailureReason)
+	usecase.history.record(ctx, updatedRequest, entity.SystemActor, request.FailureReason)
+
+	err = usecase.mail.NotifyRequestStatus(updatedRequest, entity.NotificationRequestExpired)
+
+	if err != nil {
+		slog.Error("Error notifying failed request", "id", request.ID, "error", err)
+	}
+}
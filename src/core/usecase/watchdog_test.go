@@ -0,0 +1,229 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"example/web-service-gin/src/adapters/handler/notification"
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/usecase"
+	"example/web-service-gin/src/utils/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setUpWatchdog(pending ...entity.Request) (*MockRequestRepository, *MockRequestEventRepository, *MockRequestNotifications, *MockMailService, *usecase.WatchdogUseCase) {
+	mockRepo := new(MockRequestRepository)
+	mockEvents := new(MockRequestEventRepository)
+	mockQueue := new(MockRequestNotifications)
+	mockMail := new(MockMailService)
+	mockStorage := new(MockStoragePort)
+	watchdog := usecase.NewWatchdogUseCase(mockRepo, usecase.NewRequestHistory(mockEvents, nil, nil, nil), mockStorage, mockQueue, mockMail, 30*time.Minute, 3)
+
+	// The video of the stale pending requests is stored unless their key is empty
+	mockStorage.On("FileExists", "").Return(false, nil)
+	mockStorage.On("FileExists", mock.Anything).Return(true, nil)
+	mockEvents.On("AddEvent", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("GetStalePendingRequests", mock.Anything, mock.AnythingOfType("time.Time")).Return(append([]entity.Request{}, pending...), nil)
+	return mockRepo, mockEvents, mockQueue, mockMail, watchdog
+}
+
+func TestHandleStuckRequests_Requeue(t *testing.T) {
+	repo, events, queue, mail, watchdog := setUpWatchdog()
+	ctx := context.Background()
+
+	// Given
+	request := mocks.MockGetRequest()
+	request.Attempts = 1
+	stuckList := []entity.Request{request}
+
+	// When
+	repo.On("GetStuckRequests", ctx, mock.AnythingOfType("time.Time")).Return(stuckList, nil)
+	repo.On("UpdateStuckRequest", ctx, mock.Anything, entity.InProgress, 1).Return(&request, nil)
+	queue.On("SendVideoProccessToQueue", mock.Anything).Return(nil)
+	watchdog.HandleStuckRequests(ctx)
+
+	// Then
+	repo.AssertCalled(t, "UpdateStuckRequest", ctx, mock.MatchedBy(func(r *entity.Request) bool {
+		return r.Attempts == 2 && r.Status == entity.InProgress
+	}), entity.InProgress, 1)
+	queue.AssertCalled(t, "SendVideoProccessToQueue", mock.Anything)
+	mail.AssertNotCalled(t, "NotifyRequestStatus", mock.Anything, mock.Anything)
+	events.AssertCalled(t, "AddEvent", ctx, mock.MatchedBy(func(e *entity.RequestEvent) bool {
+		return e.Actor == entity.SystemActor && e.Status == entity.InProgress
+	}))
+}
+
+func TestHandleStuckRequests_Timeout(t *testing.T) {
+	repo, events, queue, mail, watchdog := setUpWatchdog()
+	ctx := context.Background()
+
+	// Given
+	request := mocks.MockGetRequest()
+	request.Attempts = 3
+	stuckList := []entity.Request{request}
+
+	// When
+	repo.On("GetStuckRequests", ctx, mock.AnythingOfType("time.Time")).Return(stuckList, nil)
+	repo.On("UpdateStuckRequest", ctx, mock.Anything, entity.InProgress, 3).Return(&request, nil)
+	mail.On("NotifyRequestStatus", mock.Anything, entity.NotificationRequestExpired).Return(nil)
+	watchdog.HandleStuckRequests(ctx)
+
+	// Then
+	repo.AssertCalled(t, "UpdateStuckRequest", ctx, mock.MatchedBy(func(r *entity.Request) bool {
+		return r.Status == entity.Failed && r.FailureReason != "" && !r.FinishedAt.IsZero()
+	}), entity.InProgress, 3)
+	queue.AssertNotCalled(t, "SendVideoProccessToQueue", mock.Anything)
+	mail.AssertCalled(t, "NotifyRequestStatus", mock.Anything, entity.NotificationRequestExpired)
+	events.AssertCalled(t, "AddEvent", ctx, mock.MatchedBy(func(e *entity.RequestEvent) bool {
+		return e.Actor == entity.SystemActor && strings.Contains(e.Message, "timed out")
+	}))
+}
+
+func TestHandleStuckRequests_RepositoryError(t *testing.T) {
+	repo, events, queue, mail, watchdog := setUpWatchdog()
+	defer events.AssertNotCalled(t, "AddEvent", mock.Anything, mock.Anything)
+	ctx := context.Background()
+
+	// When
+	repo.On("GetStuckRequests", ctx, mock.AnythingOfType("time.Time")).
+		Return(([]entity.Request)(nil), errors.New("mock error"))
+	watchdog.HandleStuckRequests(ctx)
+
+	// Then
+	repo.AssertNotCalled(t, "UpdateStuckRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	queue.AssertNotCalled(t, "SendVideoProccessToQueue", mock.Anything)
+	mail.AssertNotCalled(t, "NotifyRequestStatus", mock.Anything, mock.Anything)
+}
+
+func TestHandleStuckRequests_ChangedMeanwhile(t *testing.T) {
+	repo, events, queue, mail, watchdog := setUpWatchdog()
+	defer events.AssertNotCalled(t, "AddEvent", mock.Anything, mock.Anything)
+	ctx := context.Background()
+
+	// Given
+	requeued := mocks.MockGetRequest()
+	requeued.ID = 1
+	requeued.Attempts = 1
+	expired := mocks.MockGetRequest()
+	expired.ID = 2
+	expired.Attempts = 3
+	stuckList := []entity.Request{requeued, expired}
+
+	// When
+	repo.On("GetStuckRequests", ctx, mock.AnythingOfType("time.Time")).Return(stuckList, nil)
+	repo.On("UpdateStuckRequest", ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil, core.ErrDataNotFound)
+	watchdog.HandleStuckRequests(ctx)
+
+	// Then
+	repo.AssertNumberOfCalls(t, "UpdateStuckRequest", 2)
+	queue.AssertNotCalled(t, "SendVideoProccessToQueue", mock.Anything)
+	mail.AssertNotCalled(t, "NotifyRequestStatus", mock.Anything, mock.Anything)
+}
+
+func TestHandleStuckRequests_StartsStalePending(t *testing.T) {
+	// Given a request whose upload notification was missed
+	request := mocks.MockGetRequest()
+	request.Status = entity.Pending
+	request.VideoKey = "videos_input/user123.mp4"
+	repo, events, queue, mail, watchdog := setUpWatchdog(request)
+	ctx := context.Background()
+
+	// When
+	repo.On("GetStuckRequests", ctx, mock.AnythingOfType("time.Time")).Return([]entity.Request{}, nil)
+	repo.On("UpdateStuckRequest", ctx, mock.Anything, entity.Pending, 0).Return(&request, nil)
+	queue.On("SendVideoProccessToQueue", mock.Anything).Return(nil)
+	watchdog.HandleStuckRequests(ctx)
+
+	// Then
+	repo.AssertCalled(t, "UpdateStuckRequest", ctx, mock.MatchedBy(func(r *entity.Request) bool {
+		return r.Status == entity.InProgress && r.Attempts == 1 && !r.StartedAt.IsZero()
+	}), entity.Pending, 0)
+	queue.AssertCalled(t, "SendVideoProccessToQueue", mock.Anything)
+	mail.AssertNotCalled(t, "NotifyRequestStatus", mock.Anything, mock.Anything)
+	events.AssertCalled(t, "AddEvent", ctx, mock.Anything)
+}
+
+func TestHandleStuckRequests_FailsStalePendingWithoutVideo(t *testing.T) {
+	// Given a request whose video was never stored
+	request := mocks.MockGetRequest()
+	request.Status = entity.Pending
+	request.VideoKey = ""
+	repo, events, queue, mail, watchdog := setUpWatchdog(request)
+	ctx := context.Background()
+
+	// When
+	repo.On("GetStuckRequests", ctx, mock.AnythingOfType("time.Time")).Return([]entity.Request{}, nil)
+	repo.On("UpdateStuckRequest", ctx, mock.Anything, entity.Pending, 0).Return(&request, nil)
+	mail.On("NotifyRequestStatus", mock.Anything, entity.NotificationRequestFailed).Return(nil)
+	watchdog.HandleStuckRequests(ctx)
+
+	// Then the request stops counting as an active job
+	repo.AssertCalled(t, "UpdateStuckRequest", ctx, mock.MatchedBy(func(r *entity.Request) bool {
+		return r.Status == entity.Failed && r.FailureReason != "" && !r.FinishedAt.IsZero()
+	}), entity.Pending, 0)
+	queue.AssertNotCalled(t, "SendVideoProccessToQueue", mock.Anything)
+	mail.AssertCalled(t, "NotifyRequestStatus", mock.Anything, entity.NotificationRequestFailed)
+	events.AssertCalled(t, "AddEvent", ctx, mock.MatchedBy(func(e *entity.RequestEvent) bool {
+		return e.Actor == entity.SystemActor && strings.Contains(e.Message, "upload was not received")
+	}))
+}
+
+func TestHandleStuckRequests_PublishesToEventStreams(t *testing.T) {
+	repo := new(MockRequestRepository)
+	events := new(MockRequestEventRepository)
+	broker := new(MockRequestEventBroker)
+	queue := new(MockRequestNotifications)
+	watchdog := usecase.NewWatchdogUseCase(repo, usecase.NewRequestHistory(events, nil, nil, broker), new(MockStoragePort), queue, new(MockMailService), 30*time.Minute, 3)
+	ctx := context.Background()
+
+	// Given
+	request := mocks.MockGetRequest()
+	request.Attempts = 1
+
+	// When
+	repo.On("GetStalePendingRequests", ctx, mock.AnythingOfType("time.Time")).Return([]entity.Request{}, nil)
+	repo.On("GetStuckRequests", ctx, mock.AnythingOfType("time.Time")).Return([]entity.Request{request}, nil)
+	repo.On("UpdateStuckRequest", ctx, mock.Anything, entity.InProgress, 1).Return(&request, nil)
+	queue.On("SendVideoProccessToQueue", mock.Anything).Return(nil)
+	events.On("AddEvent", ctx, mock.Anything).Return(nil)
+	broker.On("Publish", ctx, request.UserId, mock.Anything).Return(nil)
+	watchdog.HandleStuckRequests(ctx)
+
+	// Then the users following their requests see the new attempt
+	broker.AssertCalled(t, "Publish", ctx, request.UserId, mock.MatchedBy(func(e entity.RequestEvent) bool {
+		return e.RequestId == request.ID && e.Actor == entity.SystemActor
+	}))
+}
+
+func TestHandleStuckRequests_PublishesDomainEvents(t *testing.T) {
+	repo := new(MockRequestRepository)
+	mail := new(MockMailService)
+	publisher := notification.NewMemoryEventPublisher()
+	watchdog := usecase.NewWatchdogUseCase(repo, usecase.NewRequestHistory(nil, publisher, nil, nil), new(MockStoragePort), new(MockRequestNotifications), mail, 30*time.Minute, 3)
+	ctx := context.Background()
+
+	// Given
+	request := mocks.MockGetRequest()
+	request.Attempts = 3
+	failed := request
+	failed.Status = entity.Failed
+
+	// When
+	repo.On("GetStalePendingRequests", ctx, mock.AnythingOfType("time.Time")).Return([]entity.Request{}, nil)
+	repo.On("GetStuckRequests", ctx, mock.AnythingOfType("time.Time")).Return([]entity.Request{request}, nil)
+	repo.On("UpdateStuckRequest", ctx, mock.Anything, entity.InProgress, 3).Return(&failed, nil)
+	mail.On("NotifyRequestStatus", mock.Anything, entity.NotificationRequestExpired).Return(nil)
+	watchdog.HandleStuckRequests(ctx)
+
+	// Then the other services see the request that timed out
+	events := publisher.Events()
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, entity.EventRequestFailed, events[0].Type)
+		assert.Equal(t, request.ID, events[0].Request.ID)
+	}
+}
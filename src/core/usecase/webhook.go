@@ -0,0 +1,415 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/port"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers of the webhook deliveries. The signature is the hex encoded HMAC-SHA256 of
+// "<timestamp>.<body>" with the secret of the webhook, prefixed by "sha256="
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+const (
+	// WebhookSecretPrefix starts all the secrets, like ApiKeyPrefix does for the keys
+	WebhookSecretPrefix = "whsec_"
+	maxWebhooksPerUser  = 10
+	maxWebhookUrlLength = 2048
+	// deliveryLogSize is the amount of deliveries listed to the user
+	deliveryLogSize = 50
+	// deliveryBatchSize is the amount of deliveries sent on each run of DeliverPending
+	deliveryBatchSize = 20
+	// deliveryLease postpones the claimed deliveries, it must be longer than the timeout of the sender
+	deliveryLease = time.Minute
+)
+
+// WebhookSettings are the retries of the deliveries, the delay before the second attempt is
+// RetryDelay and it doubles on each attempt up to MaxRetryDelay. AllowHttp accepts plain
+// http URLs and AllowPrivateNetworks accepts IP addresses as host, which should only be
+// used for local development
+type WebhookSettings struct {
+	MaxAttempts          int
+	RetryDelay           time.Duration
+	MaxRetryDelay        time.Duration
+	AllowHttp            bool
+	AllowPrivateNetworks bool
+}
+
+type WebhookUseCase struct {
+	repository port.WebhookRepository
+	sender     port.WebhookSender
+	settings   WebhookSettings
+}
+
+// NewWebhookUseCase creates a new instance of the webhooks of the users
+func NewWebhookUseCase(repo port.WebhookRepository, sender port.WebhookSender, settings WebhookSettings) *WebhookUseCase {
+	return &WebhookUseCase{repo, sender, settings}
+}
+
+// Create registers a new webhook of the user with a generated secret
+func (usecase *WebhookUseCase) Create(ctx context.Context, userId string, webhookUrl string, events []string) (*entity.Webhook, error) {
+	err := usecase.validateWebhook(webhookUrl, events)
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks, err := usecase.repository.GetUserWebhooks(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(webhooks) >= maxWebhooksPerUser {
+		return nil, core.NewValidationError(core.ErrConflictingData, fmt.Sprintf("a user can have at most %d webhooks", maxWebhooksPerUser))
+	}
+
+	secret, err := randomToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("error generating webhook secret: %w", err)
+	}
+
+	now := time.Now()
+	return usecase.repository.CreateWebhook(ctx, &entity.Webhook{
+		UserId:    userId,
+		Url:       webhookUrl,
+		Secret:    WebhookSecretPrefix + secret,
+		Events:    events,
+		Active:    true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+// List returns the webhooks of the user
+func (usecase *WebhookUseCase) List(ctx context.Context, userId string) ([]entity.Webhook, error) {
+	webhooks, err := usecase.repository.GetUserWebhooks(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if webhooks == nil {
+		return []entity.Webhook{}, nil
+	}
+
+	return webhooks, nil
+}
+
+// Get returns a webhook of the user
+func (usecase *WebhookUseCase) Get(ctx context.Context, id uint64, userId string) (*entity.Webhook, error) {
+	return usecase.repository.GetWebhook(ctx, id, userId)
+}
+
+// Update changes the informed fields of a webhook of the user
+func (usecase *WebhookUseCase) Update(ctx context.Context, id uint64, userId string, changes entity.WebhookChanges) (*entity.Webhook, error) {
+	webhook, err := usecase.repository.GetWebhook(ctx, id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if changes.Url != nil {
+		webhook.Url = *changes.Url
+	}
+
+	if changes.Events != nil {
+		webhook.Events = changes.Events
+	}
+
+	if changes.Active != nil {
+		webhook.Active = *changes.Active
+	}
+
+	err = usecase.validateWebhook(webhook.Url, webhook.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.UpdatedAt = time.Now()
+	return usecase.repository.UpdateWebhook(ctx, webhook)
+}
+
+// Delete removes a webhook of the user with its deliveries
+func (usecase *WebhookUseCase) Delete(ctx context.Context, id uint64, userId string) error {
+	return usecase.repository.DeleteWebhook(ctx, id, userId)
+}
+
+// ListDeliveries returns the last deliveries of a webhook of the user
+func (usecase *WebhookUseCase) ListDeliveries(ctx context.Context, id uint64, userId string) ([]entity.WebhookDelivery, error) {
+	_, err := usecase.repository.GetWebhook(ctx, id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, err := usecase.repository.GetWebhookDeliveries(ctx, id, deliveryLogSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if deliveries == nil {
+		return []entity.WebhookDelivery{}, nil
+	}
+
+	return deliveries, nil
+}
+
+// Redeliver sends the payload of a delivery again as a new delivery, so the log keeps the original attempts
+func (usecase *WebhookUseCase) Redeliver(ctx context.Context, id uint64, deliveryId uint64, userId string) (*entity.WebhookDelivery, error) {
+	_, err := usecase.repository.GetWebhook(ctx, id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery, err := usecase.repository.GetDelivery(ctx, deliveryId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return usecase.queueDelivery(ctx, id, delivery.Event, delivery.Payload)
+}
+
+// Publish queues the event of the request to the subscribed webhooks of its user, the deliveries
+// are sent by DeliverPending. Failures are only logged so the webhooks never stop the processing
+func (usecase *WebhookUseCase) Publish(ctx context.Context, request *entity.Request, event string) {
+	webhooks, err := usecase.repository.GetSubscribedWebhooks(ctx, request.UserId, event)
+	if err != nil {
+		slog.Error("Error searching webhooks", "id", request.ID, "event", event, "error", err)
+		return
+	}
+
+	if len(webhooks) == 0 {
+		return
+	}
+
+	payload, err := newWebhookPayload(request, event)
+	if err != nil {
+		slog.Error("Error building webhook payload", "id", request.ID, "event", event, "error", err)
+		return
+	}
+
+	for _, webhook := range webhooks {
+		if _, err := usecase.queueDelivery(ctx, webhook.ID, event, payload); err != nil {
+			slog.Error("Error queueing webhook delivery", "webhook", webhook.ID, "id", request.ID, "event", event, "error", err)
+		}
+	}
+}
+
+// DeliverPending sends the deliveries whose attempt is due, the failed ones are retried
+// with exponential backoff until they use all the attempts
+func (usecase *WebhookUseCase) DeliverPending(ctx context.Context) {
+	deliveries, err := usecase.repository.ClaimDueDeliveries(ctx, deliveryBatchSize, deliveryLease)
+	if err != nil {
+		slog.Error("Error searching pending webhook deliveries", "error", err)
+		return
+	}
+
+	for _, delivery := range deliveries {
+		usecase.deliver(ctx, &delivery)
+	}
+}
+
+// deliver makes an attempt of the delivery and records its result
+func (usecase *WebhookUseCase) deliver(ctx context.Context, delivery *entity.WebhookDelivery) {
+	webhook, err := usecase.repository.GetWebhook(ctx, delivery.WebhookId, "")
+	if err != nil {
+		slog.Error("Error searching webhook of delivery", "delivery", delivery.ID, "webhook", delivery.WebhookId, "error", err)
+		return
+	}
+
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = now
+
+	if !webhook.Active {
+		delivery.Status = entity.DeliveryFailed
+		delivery.Error = "webhook is disabled"
+		usecase.updateDelivery(ctx, delivery)
+		return
+	}
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	headers := map[string]string{
+		"Content-Type":         "application/json",
+		WebhookEventHeader:     delivery.Event,
+		WebhookDeliveryHeader:  strconv.FormatUint(delivery.ID, 10),
+		WebhookTimestampHeader: timestamp,
+		WebhookSignatureHeader: SignWebhookPayload(webhook.Secret, timestamp, delivery.Payload),
+	}
+
+	statusCode, err := usecase.sender.Send(ctx, webhook.Url, headers, delivery.Payload)
+	delivery.ResponseCode = statusCode
+
+	switch {
+	case err != nil:
+		delivery.Error = err.Error()
+	case statusCode < 200 || statusCode > 299:
+		delivery.Error = fmt.Sprintf("unexpected response status %d", statusCode)
+	default:
+		delivery.Status = entity.DeliverySucceeded
+		delivery.Error = ""
+		usecase.updateDelivery(ctx, delivery)
+		return
+	}
+
+	if delivery.Attempts >= usecase.settings.MaxAttempts {
+		delivery.Status = entity.DeliveryFailed
+		slog.Warn("Webhook delivery failed", "delivery", delivery.ID, "webhook", webhook.ID, "attempts", delivery.Attempts, "error", delivery.Error)
+	} else {
+		delivery.NextAttemptAt = now.Add(retryDelay(delivery.Attempts, usecase.settings.RetryDelay, usecase.settings.MaxRetryDelay))
+	}
+
+	usecase.updateDelivery(ctx, delivery)
+}
+
+func (usecase *WebhookUseCase) updateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) {
+	if err := usecase.repository.UpdateDelivery(ctx, delivery); err != nil {
+		slog.Error("Error updating webhook delivery", "delivery", delivery.ID, "status", delivery.Status, "error", err)
+	}
+}
+
+// retryDelay doubles the delay after each failed attempt, up to the maximum delay
+func retryDelay(attempts int, delay time.Duration, maxDelay time.Duration) time.Duration {
+	for i := 1; i < attempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxDelay)
+}
+
+func (usecase *WebhookUseCase) queueDelivery(ctx context.Context, webhookId uint64, event string, payload []byte) (*entity.WebhookDelivery, error) {
+	now := time.Now()
+
+	return usecase.repository.CreateDelivery(ctx, &entity.WebhookDelivery{
+		WebhookId:     webhookId,
+		Event:         event,
+		Payload:       payload,
+		Status:        entity.DeliveryPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	})
+}
+
+// validateWebhook checks the URL and the events informed by the user
+func (usecase *WebhookUseCase) validateWebhook(webhookUrl string, events []string) error {
+	parsed, err := url.Parse(webhookUrl)
+
+	validScheme := err == nil && (parsed.Scheme == "https" || (usecase.settings.AllowHttp && parsed.Scheme == "http"))
+	if !validScheme || parsed.Host == "" || len(webhookUrl) > maxWebhookUrlLength {
+		return core.NewValidationError(core.ErrInvalidInput, "url must be an absolute https URL")
+	}
+
+	// The names are checked when connecting, after they are resolved
+	if !usecase.settings.AllowPrivateNetworks && net.ParseIP(parsed.Hostname()) != nil {
+		return core.NewValidationError(core.ErrInvalidInput, "url must have a domain name as host, not an IP address")
+	}
+
+	if len(events) == 0 {
+		return core.NewValidationError(core.ErrInvalidInput, "at least one event is required")
+	}
+
+	for _, event := range events {
+		if !slices.Contains(entity.WebhookEvents, event) {
+			return core.NewValidationError(core.ErrInvalidInput,
+				fmt.Sprintf("invalid event %q, the allowed events are %s", event, strings.Join(entity.WebhookEvents, ", ")))
+		}
+	}
+
+	return nil
+}
+
+// SignWebhookPayload returns the signature header of the payload sent on the timestamp
+func SignWebhookPayload(secret string, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type webhookPayload struct {
+	ID        string             `json:"id"`
+	Type      string             `json:"type"`
+	CreatedAt time.Time          `json:"created_at"`
+	Data      webhookRequestData `json:"data"`
+}
+
+type webhookRequestData struct {
+	ID            uint64               `json:"id"`
+	Status        entity.RequestStatus `json:"status"`
+	VideoSize     int64                `json:"video_size"`
+	DuplicateOf   uint64               `json:"duplicate_of,omitempty"`
+	ZipOutputUrl  string               `json:"zip_output_key,omitempty"`
+	Attempts      int                  `json:"attempts"`
+	FailureReason string               `json:"failure_reason,omitempty"`
+	CreatedAt     time.Time            `json:"created_at"`
+	StartedAt     *time.Time           `json:"started_at,omitempty"`
+	FinishedAt    *time.Time           `json:"finished_at,omitempty"`
+}
+
+// newWebhookPayload builds the body of the event, its id is the same on all the webhooks and
+// redeliveries so the receivers can ignore the events they already handled
+func newWebhookPayload(request *entity.Request, event string) ([]byte, error) {
+	id, err := newEventId()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(webhookPayload{
+		ID:        id,
+		Type:      event,
+		CreatedAt: time.Now().UTC(),
+		Data: webhookRequestData{
+			ID:            request.ID,
+			Status:        request.Status,
+			VideoSize:     request.VideoSize,
+			DuplicateOf:   request.DuplicateOf,
+			ZipOutputUrl:  request.ZipOutputUrl,
+			Attempts:      request.Attempts,
+			FailureReason: request.FailureReason,
+			CreatedAt:     request.CreatedAt,
+			StartedAt:     optionalTime(request.StartedAt),
+			FinishedAt:    optionalTime(request.FinishedAt),
+		},
+	})
+}
+
+// newEventId returns a random id for an event
+func newEventId() (string, error) {
+	id, err := randomToken(16)
+	if err != nil {
+		return "", err
+	}
+	return "evt_" + id, nil
+}
+
+// randomToken returns size random bytes encoded as base64url
+func randomToken(size int) (string, error) {
+	token := make([]byte, size)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// optionalTime omits the zero time
+func optionalTime(value time.Time) *time.Time {
+	if value.IsZero() {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,370 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/usecase"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) CreateWebhook(ctx context.Context, webhook *entity.Webhook) (*entity.Webhook, error) {
+	args := m.Called(ctx, webhook)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetWebhook(ctx context.Context, id uint64, userId string) (*entity.Webhook, error) {
+	args := m.Called(ctx, id, userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetUserWebhooks(ctx context.Context, userId string) ([]entity.Webhook, error) {
+	args := m.Called(ctx, userId)
+	return args.Get(0).([]entity.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetSubscribedWebhooks(ctx context.Context, userId string, event string) ([]entity.Webhook, error) {
+	args := m.Called(ctx, userId, event)
+	return args.Get(0).([]entity.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) UpdateWebhook(ctx context.Context, webhook *entity.Webhook) (*entity.Webhook, error) {
+	args := m.Called(ctx, webhook)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) DeleteWebhook(ctx context.Context, id uint64, userId string) error {
+	args := m.Called(ctx, id, userId)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) CreateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) (*entity.WebhookDelivery, error) {
+	args := m.Called(ctx, delivery)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetDelivery(ctx context.Context, id uint64, webhookId uint64) (*entity.WebhookDelivery, error) {
+	args := m.Called(ctx, id, webhookId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetWebhookDeliveries(ctx context.Context, webhookId uint64, limit uint64) ([]entity.WebhookDelivery, error) {
+	args := m.Called(ctx, webhookId, limit)
+	return args.Get(0).([]entity.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ClaimDueDeliveries(ctx context.Context, limit uint64, lease time.Duration) ([]entity.WebhookDelivery, error) {
+	args := m.Called(ctx, limit, lease)
+	return args.Get(0).([]entity.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) UpdateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+type MockWebhookSender struct {
+	mock.Mock
+}
+
+func (m *MockWebhookSender) Send(ctx context.Context, url string, headers map[string]string, body []byte) (int, error) {
+	args := m.Called(ctx, url, headers, body)
+	return args.Int(0), args.Error(1)
+}
+
+type MockWebhookPublisher struct {
+	mock.Mock
+}
+
+func (m *MockWebhookPublisher) Publish(ctx context.Context, request *entity.Request, event string) {
+	m.Called(ctx, request, event)
+}
+
+var webhookSettings = usecase.WebhookSettings{MaxAttempts: 3, RetryDelay: time.Minute, MaxRetryDelay: time.Hour}
+
+func setUpWebhooks() (*MockWebhookRepository, *MockWebhookSender, *usecase.WebhookUseCase) {
+	mockRepo := new(MockWebhookRepository)
+	mockSender := new(MockWebhookSender)
+	return mockRepo, mockSender, usecase.NewWebhookUseCase(mockRepo, mockSender, webhookSettings)
+}
+
+func TestWebhookCreate(t *testing.T) {
+	repo, _, webhooks := setUpWebhooks()
+	ctx := context.Background()
+
+	// When
+	var webhook *entity.Webhook
+	repo.On("GetUserWebhooks", ctx, "123456").Return([]entity.Webhook{}, nil)
+	repo.On("CreateWebhook", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		webhook = args.Get(1).(*entity.Webhook)
+	}).Return(&entity.Webhook{ID: 1}, nil)
+	created, err := webhooks.Create(ctx, "123456", "https://example.com/hook", []string{entity.EventRequestCompleted})
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), created.ID)
+	assert.Equal(t, "123456", webhook.UserId)
+	assert.True(t, webhook.Active)
+	assert.True(t, strings.HasPrefix(webhook.Secret, usecase.WebhookSecretPrefix))
+	assert.Greater(t, len(webhook.Secret), len(usecase.WebhookSecretPrefix)+32)
+}
+
+func TestWebhookCreate_InvalidInput(t *testing.T) {
+	tests := map[string]struct {
+		url    string
+		events []string
+	}{
+		"plain http":     {"http://example.com/hook", []string{entity.EventRequestCompleted}},
+		"relative url":   {"/hook", []string{entity.EventRequestCompleted}},
+		"no events":      {"https://example.com/hook", []string{}},
+		"unknown event":  {"https://example.com/hook", []string{"request.deleted"}},
+		"invalid scheme": {"ftp://example.com/hook", []string{entity.EventRequestCompleted}},
+		"metadata ip":    {"https://169.254.169.254/latest/meta-data/", []string{entity.EventRequestCompleted}},
+		"loopback ip":    {"https://127.0.0.1:8080/hook", []string{entity.EventRequestCompleted}},
+		"ipv6":           {"https://[::1]/hook", []string{entity.EventRequestCompleted}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			repo, _, webhooks := setUpWebhooks()
+
+			_, err := webhooks.Create(context.Background(), "123456", test.url, test.events)
+
+			assert.ErrorIs(t, err, core.ErrInvalidInput)
+			repo.AssertNotCalled(t, "CreateWebhook", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestWebhookCreate_AllowHttp(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	webhooks := usecase.NewWebhookUseCase(repo, new(MockWebhookSender), usecase.WebhookSettings{AllowHttp: true, AllowPrivateNetworks: true})
+	ctx := context.Background()
+
+	// When
+	repo.On("GetUserWebhooks", ctx, "123456").Return([]entity.Webhook{}, nil)
+	repo.On("CreateWebhook", ctx, mock.Anything).Return(&entity.Webhook{ID: 1}, nil)
+	_, err := webhooks.Create(ctx, "123456", "http://127.0.0.1:3000/hook", []string{entity.EventRequestCompleted})
+
+	// Then
+	assert.NoError(t, err)
+}
+
+func TestWebhookCreate_TooManyWebhooks(t *testing.T) {
+	repo, _, webhooks := setUpWebhooks()
+	ctx := context.Background()
+
+	// When
+	repo.On("GetUserWebhooks", ctx, "123456").Return(make([]entity.Webhook, 10), nil)
+	_, err := webhooks.Create(ctx, "123456", "https://example.com/hook", []string{entity.EventRequestCompleted})
+
+	// Then
+	assert.ErrorIs(t, err, core.ErrConflictingData)
+	repo.AssertNotCalled(t, "CreateWebhook", mock.Anything, mock.Anything)
+}
+
+func TestWebhookUpdate(t *testing.T) {
+	repo, _, webhooks := setUpWebhooks()
+	ctx := context.Background()
+	active := false
+
+	// When
+	repo.On("GetWebhook", ctx, uint64(1), "123456").Return(&entity.Webhook{
+		ID: 1, UserId: "123456", Url: "https://example.com/hook", Events: []string{entity.EventRequestCompleted}, Active: true,
+	}, nil)
+	repo.On("UpdateWebhook", ctx, mock.Anything).Return(&entity.Webhook{ID: 1}, nil)
+	_, err := webhooks.Update(ctx, 1, "123456", entity.WebhookChanges{Active: &active})
+
+	// Then
+	assert.NoError(t, err)
+	repo.AssertCalled(t, "UpdateWebhook", ctx, mock.MatchedBy(func(w *entity.Webhook) bool {
+		return !w.Active && w.Url == "https://example.com/hook" && len(w.Events) == 1
+	}))
+}
+
+func TestWebhookUpdate_NotFound(t *testing.T) {
+	repo, _, webhooks := setUpWebhooks()
+	ctx := context.Background()
+
+	// When
+	repo.On("GetWebhook", ctx, uint64(1), "123456").Return(nil, core.ErrDataNotFound)
+	_, err := webhooks.Update(ctx, 1, "123456", entity.WebhookChanges{})
+
+	// Then
+	assert.ErrorIs(t, err, core.ErrDataNotFound)
+}
+
+func TestWebhookPublish(t *testing.T) {
+	repo, _, webhooks := setUpWebhooks()
+	ctx := context.Background()
+	request := &entity.Request{ID: 7, UserId: "123456", Status: entity.Completed, ZipOutputUrl: "https://cdn/out.zip"}
+
+	// When
+	repo.On("GetSubscribedWebhooks", ctx, "123456", entity.EventRequestCompleted).
+		Return([]entity.Webhook{{ID: 1}, {ID: 2}}, nil)
+	repo.On("CreateDelivery", ctx, mock.Anything).Return(&entity.WebhookDelivery{}, nil)
+	webhooks.Publish(ctx, request, entity.EventRequestCompleted)
+
+	// Then
+	repo.AssertNumberOfCalls(t, "CreateDelivery", 2)
+	repo.AssertCalled(t, "CreateDelivery", ctx, mock.MatchedBy(func(d *entity.WebhookDelivery) bool {
+		var payload map[string]any
+		json.Unmarshal(d.Payload, &payload)
+		data := payload["data"].(map[string]any)
+		return d.WebhookId == 2 && d.Status == entity.DeliveryPending && payload["type"] == entity.EventRequestCompleted &&
+			data["id"] == float64(7) && data["zip_output_key"] == "https://cdn/out.zip"
+	}))
+}
+
+func TestWebhookPublish_NoSubscribers(t *testing.T) {
+	repo, _, webhooks := setUpWebhooks()
+	ctx := context.Background()
+	request := &entity.Request{ID: 7, UserId: "123456", Status: entity.Pending}
+
+	// When
+	repo.On("GetSubscribedWebhooks", ctx, "123456", entity.EventRequestCreated).Return([]entity.Webhook{}, nil)
+	webhooks.Publish(ctx, request, entity.EventRequestCreated)
+
+	// Then
+	repo.AssertNotCalled(t, "CreateDelivery", mock.Anything, mock.Anything)
+}
+
+func TestWebhookDeliverPending_Success(t *testing.T) {
+	repo, sender, webhooks := setUpWebhooks()
+	ctx := context.Background()
+	payload := []byte(`{"id":"evt_1"}`)
+
+	// When
+	repo.On("ClaimDueDeliveries", ctx, mock.Anything, mock.Anything).
+		Return([]entity.WebhookDelivery{{ID: 5, WebhookId: 1, Event: entity.EventRequestStarted, Payload: payload, Status: entity.DeliveryPending}}, nil)
+	repo.On("GetWebhook", ctx, uint64(1), "").Return(&entity.Webhook{ID: 1, Url: "https://example.com/hook", Secret: "whsec_test", Active: true}, nil)
+	sender.On("Send", ctx, "https://example.com/hook", mock.Anything, payload).Return(204, nil)
+	repo.On("UpdateDelivery", ctx, mock.Anything).Return(nil)
+	webhooks.DeliverPending(ctx)
+
+	// Then
+	sender.AssertCalled(t, "Send", ctx, "https://example.com/hook", mock.MatchedBy(func(headers map[string]string) bool {
+		signature := usecase.SignWebhookPayload("whsec_test", headers[usecase.WebhookTimestampHeader], payload)
+		return headers[usecase.WebhookSignatureHeader] == signature &&
+			headers[usecase.WebhookEventHeader] == entity.EventRequestStarted &&
+			headers[usecase.WebhookDeliveryHeader] == "5"
+	}), payload)
+	repo.AssertCalled(t, "UpdateDelivery", ctx, mock.MatchedBy(func(d *entity.WebhookDelivery) bool {
+		return d.Status == entity.DeliverySucceeded && d.Attempts == 1 && d.ResponseCode == 204 && !d.LastAttemptAt.IsZero()
+	}))
+}
+
+func TestWebhookDeliverPending_RetriesWithBackoff(t *testing.T) {
+	repo, sender, webhooks := setUpWebhooks()
+	ctx := context.Background()
+
+	// When
+	repo.On("ClaimDueDeliveries", ctx, mock.Anything, mock.Anything).
+		Return([]entity.WebhookDelivery{{ID: 5, WebhookId: 1, Status: entity.DeliveryPending, Attempts: 1}}, nil)
+	repo.On("GetWebhook", ctx, uint64(1), "").Return(&entity.Webhook{ID: 1, Active: true}, nil)
+	sender.On("Send", ctx, mock.Anything, mock.Anything, mock.Anything).Return(500, nil)
+	repo.On("UpdateDelivery", ctx, mock.Anything).Return(nil)
+	webhooks.DeliverPending(ctx)
+
+	// Then
+	repo.AssertCalled(t, "UpdateDelivery", ctx, mock.MatchedBy(func(d *entity.WebhookDelivery) bool {
+		delay := d.NextAttemptAt.Sub(d.LastAttemptAt)
+		return d.Status == entity.DeliveryPending && d.Attempts == 2 && d.ResponseCode == 500 &&
+			delay == 2*time.Minute && d.Error == "unexpected response status 500"
+	}))
+}
+
+func TestWebhookDeliverPending_FailsAfterLastAttempt(t *testing.T) {
+	repo, sender, webhooks := setUpWebhooks()
+	ctx := context.Background()
+
+	// When
+	repo.On("ClaimDueDeliveries", ctx, mock.Anything, mock.Anything).
+		Return([]entity.WebhookDelivery{{ID: 5, WebhookId: 1, Status: entity.DeliveryPending, Attempts: 2}}, nil)
+	repo.On("GetWebhook", ctx, uint64(1), "").Return(&entity.Webhook{ID: 1, Active: true}, nil)
+	sender.On("Send", ctx, mock.Anything, mock.Anything, mock.Anything).Return(0, errors.New("connection refused"))
+	repo.On("UpdateDelivery", ctx, mock.Anything).Return(nil)
+	webhooks.DeliverPending(ctx)
+
+	// Then
+	repo.AssertCalled(t, "UpdateDelivery", ctx, mock.MatchedBy(func(d *entity.WebhookDelivery) bool {
+		return d.Status == entity.DeliveryFailed && d.Attempts == 3 && d.Error == "connection refused"
+	}))
+}
+
+func TestWebhookDeliverPending_DisabledWebhook(t *testing.T) {
+	repo, sender, webhooks := setUpWebhooks()
+	ctx := context.Background()
+
+	// When
+	repo.On("ClaimDueDeliveries", ctx, mock.Anything, mock.Anything).
+		Return([]entity.WebhookDelivery{{ID: 5, WebhookId: 1, Status: entity.DeliveryPending}}, nil)
+	repo.On("GetWebhook", ctx, uint64(1), "").Return(&entity.Webhook{ID: 1, Active: false}, nil)
+	repo.On("UpdateDelivery", ctx, mock.Anything).Return(nil)
+	webhooks.DeliverPending(ctx)
+
+	// Then
+	sender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	repo.AssertCalled(t, "UpdateDelivery", ctx, mock.MatchedBy(func(d *entity.WebhookDelivery) bool {
+		return d.Status == entity.DeliveryFailed
+	}))
+}
+
+func TestWebhookRedeliver(t *testing.T) {
+	repo, _, webhooks := setUpWebhooks()
+	ctx := context.Background()
+	payload := []byte(`{"id":"evt_1"}`)
+
+	// When
+	repo.On("GetWebhook", ctx, uint64(1), "123456").Return(&entity.Webhook{ID: 1}, nil)
+	repo.On("GetDelivery", ctx, uint64(5), uint64(1)).
+		Return(&entity.WebhookDelivery{ID: 5, WebhookId: 1, Event: entity.EventRequestFailed, Payload: payload, Status: entity.DeliveryFailed}, nil)
+	repo.On("CreateDelivery", ctx, mock.Anything).Return(&entity.WebhookDelivery{ID: 6}, nil)
+	delivery, err := webhooks.Redeliver(ctx, 1, 5, "123456")
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(6), delivery.ID)
+	repo.AssertCalled(t, "CreateDelivery", ctx, mock.MatchedBy(func(d *entity.WebhookDelivery) bool {
+		return d.WebhookId == 1 && d.Event == entity.EventRequestFailed && string(d.Payload) == string(payload) && d.Status == entity.DeliveryPending
+	}))
+}
+
+func TestWebhookListDeliveries_OtherUser(t *testing.T) {
+	repo, _, webhooks := setUpWebhooks()
+	ctx := context.Background()
+
+	// When
+	repo.On("GetWebhook", ctx, uint64(1), "654321").Return(nil, core.ErrDataNotFound)
+	_, err := webhooks.ListDeliveries(ctx, 1, "654321")
+
+	// Then
+	assert.ErrorIs(t, err, core.ErrDataNotFound)
+	repo.AssertNotCalled(t, "GetWebhookDeliveries", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -2,7 +2,12 @@ package configuration
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	awslib "github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -12,11 +17,22 @@ import (
 // Container contains environment variables for the application, database, cache, token, and http server
 type (
 	Container struct {
-		App  *App
-		DB   *Database
-		HTTP *HTTP
-		AWS  *Aws
-		Mail *Mail
+		App       *App
+		DB        *Database
+		HTTP      *HTTP
+		AWS       *Aws
+		Mail      *Mail
+		Watchdog  *Watchdog
+		Queue     *Queue
+		Storage   *Storage
+		Video     *Video
+		Dedup     *Dedup
+		Auth      *Auth
+		Quota     *Quota
+		RateLimit *RateLimit
+		Webhook   *Webhook
+		Events    *Events
+		Metrics   *Metrics
 	}
 	// App contains all the environment variables for the application
 	App struct {
@@ -33,29 +49,203 @@ type (
 		Password   string
 		Name       string
 	}
-	// HTTP contains all the environment variables for the http server
+	// HTTP contains all the environment variables for the http server. The client IP is only
+	// read from the X-Forwarded-For headers set by the TrustedProxies, none by default
 	HTTP struct {
 		Env            string
 		URL            string
 		Port           string
 		AllowedOrigins string
+		TrustedProxies []string
 	}
 
+	// Mail contains all the environment variables for the notifications. Driver is one of
+	// "sendgrid" or "smtp", the email transport. Timeout limits each delivery, by email or
+	// to the Slack and Teams webhooks of the users. DefaultLocale is the language of the
+	// users who don't inform one, on their preferences or token. The failed deliveries are
+	// retried every RetryInterval after RetryDelay, doubled on each attempt up to MaxRetryDelay.
+	// The daily and weekly digests are checked every DigestInterval
 	Mail struct {
-		Key        string
-		TemplateId string
+		Driver         string
+		Key            string
+		DefaultLocale  string
+		From           string
+		FromName       string
+		SmtpHost       string
+		SmtpPort       string
+		SmtpUsername   string
+		SmtpPassword   string
+		Timeout        time.Duration
+		RetryInterval  time.Duration
+		MaxAttempts    int
+		RetryDelay     time.Duration
+		MaxRetryDelay  time.Duration
+		DigestInterval time.Duration
 	}
 
+	// Aws contains all the environment variables for the AWS services. The endpoint
+	// URLs point the clients to S3-compatible services (MinIO, LocalStack), the
+	// service specific ones take precedence over EndpointUrl. The domain events are
+	// published to EventsTopicArn, they are disabled when it is empty
 	Aws struct {
 		Config              awslib.Config
 		BucketName          string
-		CognitoJwksUrl      string
 		S3QueueUrl          string
 		VideoInputQueueUrl  string
 		VideoOutputQueueUrl string
+		EventsTopicArn      string
+		EndpointUrl         string
+		S3EndpointUrl       string
+		S3PublicUrl         string
+		S3UsePathStyle      bool
+		SQSEndpointUrl      string
+		SNSEndpointUrl      string
+	}
+
+	// Queue contains all the environment variables for the message queues.
+	// Driver is one of "sqs", "postgres" or "memory"
+	Queue struct {
+		Driver         string
+		Visibility     time.Duration
+		SimulateWorker bool
+	}
+
+	// Storage contains all the environment variables for the file storage.
+	// Driver is one of "s3" or "filesystem"
+	Storage struct {
+		Driver     string
+		Path       string
+		BucketName string
+		PublicUrl  string
+		SigningKey string
+		UrlTTL     time.Duration
+	}
+
+	// Video contains the limits of the uploaded videos, zero disables the limit
+	Video struct {
+		MaxDuration time.Duration
+		MaxWidth    int
+		MaxHeight   int
+	}
+
+	// Dedup contains the environment variables of the deduplication of uploaded videos.
+	// Mode is one of "off", "user" or "organization"
+	Dedup struct {
+		Mode string
+	}
+
+	// Auth contains all the environment variables for the token validation. The JWKS
+	// is refreshed each RefreshInterval and, for unknown keys, at most once each RefreshRateLimit.
+	// An empty Issuer, ClientIds or TokenUse disables its check. The members of the
+	// AdminGroups (Cognito groups) can access the requests of all users. The plan and the
+	// organization are read from the groups named with PlanGroupPrefix and OrganizationGroupPrefix,
+	// e.g. "plan:pro", as only the administrators assign them. RequireVerifiedEmail
+	// blocks the uploads of unverified addresses and skips their notifications
+	Auth struct {
+		JwksUrl                 string
+		RefreshInterval         time.Duration
+		RefreshRateLimit        time.Duration
+		Issuer                  string
+		ClientIds               []string
+		TokenUse                []string
+		AllowedAlgorithms       []string
+		ClockSkew               time.Duration
+		AdminGroups             []string
+		PlanGroupPrefix         string
+		OrganizationGroupPrefix string
+		RequireVerifiedEmail    bool
+	}
+
+	// Quota contains the plans limiting the usage of each user. The plan is read from the
+	// plan group of the token, the users without plan or with an unknown plan have the
+	// DefaultPlan, whose limits come from the QUOTA_MAX_* variables. QUOTA_PLANS adds other
+	// plans as JSON, e.g. {"pro": {"max_concurrent_jobs": 10}}
+	Quota struct {
+		Enabled     bool
+		DefaultPlan string
+		Plans       map[string]QuotaPlan
+	}
+
+	// QuotaPlan contains the limits of a plan, zero disables the limit
+	QuotaPlan struct {
+		MaxConcurrentJobs int   `json:"max_concurrent_jobs"`
+		MaxRequestsPerDay int   `json:"max_requests_per_day"`
+		MaxBytesPerMonth  int64 `json:"max_bytes_per_month"`
+		MaxFileSize       int64 `json:"max_file_size"`
+	}
+
+	// RateLimit contains the limits of the requests of each user, or of each IP on the public
+	// routes and before the authentication, informed as "requests/period" (e.g. "10/1m"). Store
+	// is one of "memory" or "postgres", the postgres store shares the limits between the instances
+	RateLimit struct {
+		Enabled bool
+		Store   string
+		Uploads RateLimitRule
+		Api     RateLimitRule
+		Public  RateLimitRule
+		Auth    RateLimitRule
+	}
+
+	// RateLimitRule allows Requests per Period
+	RateLimitRule struct {
+		Requests int
+		Period   time.Duration
+	}
+
+	// Webhook contains the delivery settings of the webhooks of the users. The failed deliveries
+	// are retried after RetryDelay, doubled on each attempt up to MaxRetryDelay
+	Webhook struct {
+		Interval             time.Duration
+		Timeout              time.Duration
+		MaxAttempts          int
+		RetryDelay           time.Duration
+		MaxRetryDelay        time.Duration
+		AllowHttp            bool
+		AllowPrivateNetworks bool
+	}
+
+	// Events contains the settings of the event streams of the users. Broker is one of "memory"
+	// or "postgres", the postgres broker shares the events between the instances
+	Events struct {
+		Broker    string
+		Heartbeat time.Duration
+	}
+
+	// Metrics contains the settings of the Prometheus metrics served on /metrics of Address, apart
+	// from the API so only the scrapers reach them
+	Metrics struct {
+		Enabled bool
+		Address string
+	}
+
+	// Watchdog contains all the environment variables for the stuck requests watchdog
+	Watchdog struct {
+		Interval    time.Duration
+		SLA         time.Duration
+		MaxAttempts int
 	}
 )
 
+// S3Endpoint returns the custom S3 endpoint, empty when using AWS
+func (a *Aws) S3Endpoint() string {
+	return firstNotEmpty(a.S3EndpointUrl, a.EndpointUrl)
+}
+
+// S3PublicEndpoint returns the custom endpoint used on the file URLs given to users
+func (a *Aws) S3PublicEndpoint() string {
+	return firstNotEmpty(a.S3PublicUrl, a.S3Endpoint())
+}
+
+// SQSEndpoint returns the custom SQS endpoint, empty when using AWS
+func (a *Aws) SQSEndpoint() string {
+	return firstNotEmpty(a.SQSEndpointUrl, a.EndpointUrl)
+}
+
+// SNSEndpoint returns the custom SNS endpoint, empty when using AWS
+func (a *Aws) SNSEndpoint() string {
+	return firstNotEmpty(a.SNSEndpointUrl, a.EndpointUrl)
+}
+
 // New creates a new container instance
 func New() (*Container, error) {
 	if os.Getenv("APP_ENV") != "production" {
@@ -84,6 +274,7 @@ func New() (*Container, error) {
 		URL:            os.Getenv("HTTP_URL"),
 		Port:           os.Getenv("HTTP_PORT"),
 		AllowedOrigins: os.Getenv("HTTP_ALLOWED_ORIGINS"),
+		TrustedProxies: getEnvList("HTTP_TRUSTED_PROXIES", nil),
 	}
 
 	awsConfiguration, _ := config.LoadDefaultConfig(context.Background())
@@ -91,15 +282,124 @@ func New() (*Container, error) {
 	aws := &Aws{
 		Config:              awsConfiguration,
 		BucketName:          os.Getenv("AWS_BUCKET_NAME"),
-		CognitoJwksUrl:      os.Getenv("AWS_COGNITO_JWKS_URL"),
 		S3QueueUrl:          os.Getenv("AWS_S3_QUEUE_URL"),
 		VideoInputQueueUrl:  os.Getenv("AWS_VIDEO_INPUT_QUEUE_URL"),
 		VideoOutputQueueUrl: os.Getenv("AWS_VIDEO_OUTPUT_QUEUE_URL"),
+		EventsTopicArn:      os.Getenv("AWS_SNS_EVENTS_TOPIC_ARN"),
+		EndpointUrl:         os.Getenv("AWS_ENDPOINT_URL"),
+		S3EndpointUrl:       os.Getenv("AWS_ENDPOINT_URL_S3"),
+		S3PublicUrl:         os.Getenv("AWS_S3_PUBLIC_URL"),
+		S3UsePathStyle:      os.Getenv("AWS_S3_USE_PATH_STYLE") == "true",
+		SQSEndpointUrl:      os.Getenv("AWS_ENDPOINT_URL_SQS"),
+		SNSEndpointUrl:      os.Getenv("AWS_ENDPOINT_URL_SNS"),
 	}
 
 	mail := &Mail{
-		Key:        os.Getenv("SENDGRID_API_KEY"),
-		TemplateId: os.Getenv("SENDGRID_TEMPLATE_ID"),
+		Driver:         getEnv("MAIL_DRIVER", "sendgrid"),
+		Key:            os.Getenv("SENDGRID_API_KEY"),
+		DefaultLocale:  getEnv("MAIL_DEFAULT_LOCALE", "pt-BR"),
+		From:           getEnv("MAIL_FROM", "no_reply@frameshot.com.br"),
+		FromName:       getEnv("MAIL_FROM_NAME", "Frameshot Notification"),
+		SmtpHost:       getEnv("SMTP_HOST", "127.0.0.1"),
+		SmtpPort:       getEnv("SMTP_PORT", "587"),
+		SmtpUsername:   os.Getenv("SMTP_USERNAME"),
+		SmtpPassword:   os.Getenv("SMTP_PASSWORD"),
+		Timeout:        getEnvDuration("NOTIFICATION_TIMEOUT", 10*time.Second),
+		RetryInterval:  getEnvDuration("NOTIFICATION_RETRY_INTERVAL", 30*time.Second),
+		MaxAttempts:    getEnvInt("NOTIFICATION_MAX_ATTEMPTS", 5),
+		RetryDelay:     getEnvDuration("NOTIFICATION_RETRY_DELAY", time.Minute),
+		MaxRetryDelay:  getEnvDuration("NOTIFICATION_MAX_RETRY_DELAY", time.Hour),
+		DigestInterval: getEnvDuration("NOTIFICATION_DIGEST_INTERVAL", 15*time.Minute),
+	}
+
+	watchdog := &Watchdog{
+		Interval:    getEnvDuration("WATCHDOG_INTERVAL", time.Minute),
+		SLA:         getEnvDuration("WATCHDOG_SLA", 30*time.Minute),
+		MaxAttempts: getEnvInt("WATCHDOG_MAX_ATTEMPTS", 3),
+	}
+
+	queue := &Queue{
+		Driver:         getEnv("QUEUE_DRIVER", "sqs"),
+		Visibility:     getEnvDuration("QUEUE_VISIBILITY_TIMEOUT", 5*time.Minute),
+		SimulateWorker: os.Getenv("QUEUE_SIMULATE_WORKER") == "true",
+	}
+
+	storage := &Storage{
+		Driver:     getEnv("STORAGE_DRIVER", "s3"),
+		Path:       getEnv("STORAGE_PATH", "./storage"),
+		BucketName: os.Getenv("AWS_BUCKET_NAME"),
+		PublicUrl:  getEnv("STORAGE_PUBLIC_URL", "http://"+http.URL+":"+http.Port),
+		SigningKey: os.Getenv("STORAGE_SIGNING_KEY"),
+		UrlTTL:     getEnvDuration("STORAGE_URL_TTL", 7*24*time.Hour),
+	}
+
+	video := &Video{
+		MaxDuration: getEnvDuration("VIDEO_MAX_DURATION", 2*time.Hour),
+		MaxWidth:    getEnvInt("VIDEO_MAX_WIDTH", 3840),
+		MaxHeight:   getEnvInt("VIDEO_MAX_HEIGHT", 2160),
+	}
+
+	dedup := &Dedup{
+		Mode: getEnv("DEDUP_MODE", "off"),
+	}
+
+	// Cognito issues the tokens on the URL where it publishes the keys
+	jwksUrl := os.Getenv("AWS_COGNITO_JWKS_URL")
+	var defaultIssuer string
+	if issuer, found := strings.CutSuffix(jwksUrl, "/.well-known/jwks.json"); found {
+		defaultIssuer = issuer
+	}
+
+	auth := &Auth{
+		JwksUrl:                 jwksUrl,
+		RefreshInterval:         getEnvDuration("AUTH_JWKS_REFRESH_INTERVAL", time.Hour),
+		RefreshRateLimit:        getEnvDuration("AUTH_JWKS_REFRESH_RATE_LIMIT", 5*time.Minute),
+		Issuer:                  getEnv("AUTH_ISSUER", defaultIssuer),
+		ClientIds:               getEnvList("AUTH_CLIENT_IDS", nil),
+		TokenUse:                getEnvList("AUTH_TOKEN_USE", []string{"id"}),
+		AllowedAlgorithms:       getEnvList("AUTH_ALLOWED_ALGORITHMS", []string{"RS256"}),
+		ClockSkew:               getEnvDuration("AUTH_CLOCK_SKEW", time.Minute),
+		AdminGroups:             getEnvList("AUTH_ADMIN_GROUPS", []string{"admin"}),
+		PlanGroupPrefix:         getEnv("AUTH_PLAN_GROUP_PREFIX", "plan:"),
+		OrganizationGroupPrefix: getEnv("AUTH_ORGANIZATION_GROUP_PREFIX", "org:"),
+		// Enabled unless it is explicitly disabled
+		RequireVerifiedEmail: os.Getenv("AUTH_REQUIRE_VERIFIED_EMAIL") != "false",
+	}
+
+	quota, err := loadQuota()
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimit := &RateLimit{
+		// Enabled unless it is explicitly disabled
+		Enabled: os.Getenv("RATE_LIMIT_ENABLED") != "false",
+		Store:   getEnv("RATE_LIMIT_STORE", "memory"),
+		Uploads: getEnvRateLimit("RATE_LIMIT_UPLOADS", RateLimitRule{10, time.Minute}),
+		Api:     getEnvRateLimit("RATE_LIMIT_API", RateLimitRule{120, time.Minute}),
+		Public:  getEnvRateLimit("RATE_LIMIT_PUBLIC", RateLimitRule{60, time.Minute}),
+		Auth:    getEnvRateLimit("RATE_LIMIT_AUTH", RateLimitRule{300, time.Minute}),
+	}
+
+	webhook := &Webhook{
+		Interval:             getEnvDuration("WEBHOOK_DELIVERY_INTERVAL", 10*time.Second),
+		Timeout:              getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		MaxAttempts:          getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		RetryDelay:           getEnvDuration("WEBHOOK_RETRY_DELAY", 30*time.Second),
+		MaxRetryDelay:        getEnvDuration("WEBHOOK_MAX_RETRY_DELAY", 6*time.Hour),
+		AllowHttp:            os.Getenv("WEBHOOK_ALLOW_HTTP") == "true",
+		AllowPrivateNetworks: os.Getenv("WEBHOOK_ALLOW_PRIVATE_NETWORKS") == "true",
+	}
+
+	events := &Events{
+		Broker:    getEnv("EVENTS_BROKER", "memory"),
+		Heartbeat: getEnvDuration("EVENTS_HEARTBEAT_INTERVAL", 15*time.Second),
+	}
+
+	metrics := &Metrics{
+		// Enabled unless it is explicitly disabled
+		Enabled: os.Getenv("METRICS_ENABLED") != "false",
+		Address: getEnv("METRICS_ADDRESS", "0.0.0.0:9090"),
 	}
 
 	return &Container{
@@ -108,5 +408,125 @@ func New() (*Container, error) {
 		http,
 		aws,
 		mail,
+		watchdog,
+		queue,
+		storage,
+		video,
+		dedup,
+		auth,
+		quota,
+		rateLimit,
+		webhook,
+		events,
+		metrics,
 	}, nil
 }
+
+// loadQuota reads the default plan from the QUOTA_MAX_* variables and the other plans from QUOTA_PLANS
+func loadQuota() (*Quota, error) {
+	quota := &Quota{
+		// Enabled unless it is explicitly disabled
+		Enabled:     os.Getenv("QUOTA_ENABLED") != "false",
+		DefaultPlan: getEnv("QUOTA_DEFAULT_PLAN", "free"),
+		Plans:       map[string]QuotaPlan{},
+	}
+
+	quota.Plans[quota.DefaultPlan] = QuotaPlan{
+		MaxConcurrentJobs: getEnvInt("QUOTA_MAX_CONCURRENT_JOBS", 3),
+		MaxRequestsPerDay: getEnvInt("QUOTA_MAX_REQUESTS_PER_DAY", 50),
+		MaxBytesPerMonth:  getEnvInt64("QUOTA_MAX_BYTES_PER_MONTH", 20<<30),
+		MaxFileSize:       getEnvInt64("QUOTA_MAX_FILE_SIZE", 500<<20),
+	}
+
+	if plans := os.Getenv("QUOTA_PLANS"); plans != "" {
+		if err := json.Unmarshal([]byte(plans), &quota.Plans); err != nil {
+			return nil, fmt.Errorf("invalid QUOTA_PLANS: %w", err)
+		}
+	}
+
+	return quota, nil
+}
+
+func firstNotEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// getEnv reads a string from the environment or returns the fallback
+func getEnv(key string, fallback string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// getEnvList reads a comma separated list from the environment or returns the fallback
+func getEnvList(key string, fallback []string) []string {
+	var values []string
+
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+
+	if len(values) == 0 {
+		return fallback
+	}
+	return values
+}
+
+// getEnvDuration reads a duration (e.g. "30m") from the environment or returns the fallback
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// getEnvInt reads an integer from the environment or returns the fallback
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// getEnvInt64 reads a 64 bits integer (e.g. an amount of bytes) from the environment or returns the fallback
+func getEnvInt64(key string, fallback int64) int64 {
+	value, err := strconv.ParseInt(os.Getenv(key), 10, 64)
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// getEnvRateLimit reads a rate limit as "requests/period" (e.g. "10/1m") from the environment or returns the fallback
+func getEnvRateLimit(key string, fallback RateLimitRule) RateLimitRule {
+	requests, period, found := strings.Cut(os.Getenv(key), "/")
+	if !found {
+		return fallback
+	}
+
+	rule := RateLimitRule{}
+	var err error
+
+	rule.Requests, err = strconv.Atoi(strings.TrimSpace(requests))
+	if err != nil {
+		return fallback
+	}
+
+	rule.Period, err = time.ParseDuration(strings.TrimSpace(period))
+	if err != nil || rule.Period <= 0 {
+		return fallback
+	}
+
+	return rule
+}
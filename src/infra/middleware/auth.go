@@ -1,20 +1,137 @@
 package middleware
 
 import (
-	"example/web-service-gin/src/utils"
+	"errors"
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/port"
+	"fmt"
+
 	"github.com/gin-gonic/gin"
 )
 
-func JwtServiceMiddleware(jwksURL string) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		jwtService, err := utils.NewJwtService(jwksURL)
+// AuthUserKey is the key of the authenticated entity.User on the Gin context
+const AuthUserKey = "user"
+
+// ApiKeyHeader is the header used by the machine clients to inform their API key
+const ApiKeyHeader = "X-API-Key"
+
+// Authenticate validates the API key of the X-API-Key header or else the token of the Authorization
+// header with the services created at startup, rejecting the request with 401 or adding the user
+// to the Gin context. A nil apiKeyService only accepts tokens, otherwise the email verification of
+// the token users is recorded for their API keys. The failures to look the API key up
+// are server errors, so the clients don't discard valid keys during an outage
+func Authenticate(jwtService port.JwtService, apiKeyService port.ApiKeyAuthenticator) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		apiKey := ctx.GetHeader(ApiKeyHeader)
+		token := ctx.GetHeader("Authorization")
+
+		var user *entity.User
+		var err error
+
+		switch {
+		case apiKey != "" && apiKeyService != nil:
+			user, err = apiKeyService.GetUser(ctx, apiKey)
+
+			if err != nil && !errors.Is(err, core.ErrInvalidApiKey) {
+				ctx.Error(fmt.Errorf("error authenticating API key: %w", err))
+				ctx.Abort()
+				return
+			}
+		case token != "":
+			user, err = jwtService.GetUser(token)
+
+			if err == nil && apiKeyService != nil {
+				apiKeyService.RecordOwner(ctx, user)
+			}
+		default:
+			ctx.Error(fmt.Errorf("missing authorization header: %w", core.ErrUnauthorized))
+			ctx.Abort()
+			return
+		}
+
 		if err != nil {
-			c.AbortWithStatusJSON(500, gin.H{"error": "failed to initialize JwtService"})
+			ctx.Error(fmt.Errorf("%w: %v", core.ErrUnauthorized, err))
+			ctx.Abort()
+			return
+		}
+
+		ctx.Set(AuthUserKey, user)
+		ctx.Next()
+	}
+}
+
+// RequireScope rejects with 403 the users that can't access the scope,
+// it must run after Authenticate
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		user := GetAuthUser(ctx)
+
+		if user != nil && !user.HasScope(scope) {
+			ctx.Error(core.NewValidationError(core.ErrForbidden, fmt.Sprintf("the API key doesn't have the %s scope", scope)))
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// RequireToken rejects with 403 the users authenticated with an API key,
+// so a leaked key can't be used to create or revoke other keys
+func RequireToken() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		user := GetAuthUser(ctx)
+
+		if user != nil && user.ApiKey != nil {
+			ctx.Error(core.NewValidationError(core.ErrForbidden, "API keys can't manage API keys, log in to do it"))
+			ctx.Abort()
 			return
 		}
 
-		// Adds JwtService on Gin context
-		c.Set("jwtService", jwtService)
-		c.Next()
+		ctx.Next()
 	}
 }
+
+// RequireVerifiedEmail rejects with 403 the users that didn't verify their email address,
+// when the policy is enabled. The users of API keys inform the verification of the key owner
+func RequireVerifiedEmail(enabled bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		user := GetAuthUser(ctx)
+
+		if enabled && user != nil && !user.EmailVerified {
+			ctx.Error(core.NewValidationError(core.ErrEmailNotVerified, "verify your email address before submitting videos"))
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// RequireGroup rejects with 403 the users that don't belong to any of the Cognito
+// groups, the API keys are always rejected as they don't inform groups
+func RequireGroup(groups []string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		user := GetAuthUser(ctx)
+
+		if user == nil || user.ApiKey != nil || !user.InAnyGroup(groups) {
+			ctx.Error(core.ErrForbidden)
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// GetAuthUser returns the user added by Authenticate, or nil on public routes
+func GetAuthUser(ctx *gin.Context) *entity.User {
+	value, exists := ctx.Get(AuthUserKey)
+	if !exists {
+		return nil
+	}
+
+	user, _ := value.(*entity.User)
+	return user
+}
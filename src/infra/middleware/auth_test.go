@@ -0,0 +1,231 @@
+package middleware_test
+
+import (
+	"errors"
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/infra/middleware"
+	"example/web-service-gin/src/utils/mocks"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setUpAuth() (*gin.Engine, *mocks.MockJwtService, *mocks.MockApiKeyAuthenticator) {
+	jwtService := new(mocks.MockJwtService)
+	apiKeyService := new(mocks.MockApiKeyAuthenticator)
+	apiKeyService.On("RecordOwner", mock.Anything, mock.Anything)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.Authenticate(jwtService, apiKeyService))
+	router.GET("/me", func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, middleware.GetAuthUser(ctx))
+	})
+	router.POST("/requests", middleware.RequireScope(entity.ScopeRequestsWrite), func(ctx *gin.Context) {
+		ctx.Status(http.StatusCreated)
+	})
+
+	router.GET("/admin/stats", middleware.RequireGroup([]string{"admin", "support"}), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	router.GET("/me/api-keys", middleware.RequireToken(), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	return router, jwtService, apiKeyService
+}
+
+func TestAuthenticate_ValidToken(t *testing.T) {
+	router, jwtService, _ := setUpAuth()
+	jwtService.On("GetUser", "Bearer valid-token").Return(&entity.User{Id: "123456", Email: "user@example.com"}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":"123456"`)
+}
+
+func TestAuthenticate_TokenRecordsApiKeyOwner(t *testing.T) {
+	router, jwtService, apiKeyService := setUpAuth()
+	user := &entity.User{Id: "123456", Email: "user@example.com", EmailVerified: true}
+	jwtService.On("GetUser", "Bearer valid-token").Return(user, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	apiKeyService.AssertCalled(t, "RecordOwner", mock.Anything, user)
+}
+
+func TestAuthenticate_InvalidToken(t *testing.T) {
+	router, jwtService, _ := setUpAuth()
+	jwtService.On("GetUser", mock.Anything).Return((*entity.User)(nil), errors.New("token is expired"))
+
+	req, _ := http.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer expired-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, middleware.ProblemContentType, w.Header().Get("Content-Type"))
+	assert.NotContains(t, w.Body.String(), "expired")
+}
+
+func TestAuthenticate_MissingToken(t *testing.T) {
+	router, jwtService, _ := setUpAuth()
+
+	req, _ := http.NewRequest(http.MethodGet, "/me", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	jwtService.AssertNotCalled(t, "GetUser", mock.Anything)
+}
+
+func TestAuthenticate_ValidApiKey(t *testing.T) {
+	router, jwtService, apiKeyService := setUpAuth()
+	apiKeyService.On("GetUser", mock.Anything, "vsk_valid").Return(&entity.User{Id: "123456"}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set(middleware.ApiKeyHeader, "vsk_valid")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":"123456"`)
+	jwtService.AssertNotCalled(t, "GetUser", mock.Anything)
+}
+
+func TestAuthenticate_InvalidApiKey(t *testing.T) {
+	router, _, apiKeyService := setUpAuth()
+	apiKeyService.On("GetUser", mock.Anything, "vsk_revoked").Return(nil, core.ErrInvalidApiKey)
+
+	req, _ := http.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set(middleware.ApiKeyHeader, "vsk_revoked")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAuthenticate_ApiKeyLookupError(t *testing.T) {
+	router, _, apiKeyService := setUpAuth()
+	apiKeyService.On("GetUser", mock.Anything, "vsk_valid").Return(nil, errors.New("connection refused"))
+
+	req, _ := http.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set(middleware.ApiKeyHeader, "vsk_valid")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestRequireScope(t *testing.T) {
+	tests := map[string]struct {
+		user     *entity.User
+		expected int
+	}{
+		"token user":            {&entity.User{Id: "123456"}, http.StatusCreated},
+		"api key with scope":    {&entity.User{Id: "123456", ApiKey: &entity.ApiKey{Scopes: []string{entity.ScopeRequestsWrite}}}, http.StatusCreated},
+		"api key without scope": {&entity.User{Id: "123456", ApiKey: &entity.ApiKey{Scopes: []string{entity.ScopeRequestsRead}}}, http.StatusForbidden},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			router, _, apiKeyService := setUpAuth()
+			apiKeyService.On("GetUser", mock.Anything, "vsk_key").Return(test.user, nil)
+
+			req, _ := http.NewRequest(http.MethodPost, "/requests", nil)
+			req.Header.Set(middleware.ApiKeyHeader, "vsk_key")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expected, w.Code)
+		})
+	}
+}
+
+func TestRequireToken_RejectsApiKeys(t *testing.T) {
+	router, _, apiKeyService := setUpAuth()
+	apiKeyService.On("GetUser", mock.Anything, "vsk_key").Return(&entity.User{Id: "123456", ApiKey: &entity.ApiKey{}}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/me/api-keys", nil)
+	req.Header.Set(middleware.ApiKeyHeader, "vsk_key")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestRequireGroup(t *testing.T) {
+	tests := map[string]struct {
+		user     *entity.User
+		expected int
+	}{
+		"member of a group":   {&entity.User{Id: "123456", Groups: []string{"users", "support"}}, http.StatusOK},
+		"without groups":      {&entity.User{Id: "123456"}, http.StatusForbidden},
+		"member of other":     {&entity.User{Id: "123456", Groups: []string{"users"}}, http.StatusForbidden},
+		"api key of an admin": {&entity.User{Id: "123456", Groups: []string{"admin"}, ApiKey: &entity.ApiKey{}}, http.StatusForbidden},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			router, jwtService, _ := setUpAuth()
+			jwtService.On("GetUser", "valid-token").Return(test.user, nil)
+
+			req, _ := http.NewRequest(http.MethodGet, "/admin/stats", nil)
+			req.Header.Set("Authorization", "valid-token")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expected, w.Code)
+		})
+	}
+}
+
+func TestRequireVerifiedEmail(t *testing.T) {
+	tests := map[string]struct {
+		user     *entity.User
+		enabled  bool
+		expected int
+	}{
+		"verified":            {&entity.User{Id: "123456", EmailVerified: true}, true, http.StatusOK},
+		"unverified":          {&entity.User{Id: "123456"}, true, http.StatusForbidden},
+		"unverified disabled": {&entity.User{Id: "123456"}, false, http.StatusOK},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			jwtService := new(mocks.MockJwtService)
+			jwtService.On("GetUser", "valid-token").Return(test.user, nil)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(middleware.ErrorHandler())
+			router.POST("/requests", middleware.Authenticate(jwtService, nil), middleware.RequireVerifiedEmail(test.enabled),
+				func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+			req, _ := http.NewRequest(http.MethodPost, "/requests", nil)
+			req.Header.Set("Authorization", "valid-token")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expected, w.Code)
+			if test.expected == http.StatusForbidden {
+				assert.Contains(t, w.Body.String(), `"code":"email_not_verified"`)
+				assert.Contains(t, w.Body.String(), "verify your email address")
+			}
+		})
+	}
+}
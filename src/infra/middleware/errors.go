@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"errors"
+	"example/web-service-gin/src/core"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemContentType is the media type of the RFC 7807 error responses
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 error response. Code is a stable identifier of the
+// error that clients can rely on, unlike the human readable Detail
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+
+	// Quota describes the quota that rejected the request, when it was a quota error
+	Quota *ProblemQuota `json:"quota,omitempty"`
+}
+
+// ProblemQuota is the limit, usage and remaining amount of a quota, ResetAt
+// is omitted when the quota is not reset by time
+type ProblemQuota struct {
+	Name      string     `json:"name"`
+	Limit     int64      `json:"limit"`
+	Used      int64      `json:"used"`
+	Remaining int64      `json:"remaining"`
+	ResetAt   *time.Time `json:"reset_at,omitempty"`
+}
+
+// problemMapping relates a core error to its HTTP status and error code
+type problemMapping struct {
+	err    error
+	status int
+	code   string
+}
+
+// problemMappings is checked in order with errors.Is, so the most specific errors come first
+var problemMappings = []problemMapping{
+	{core.ErrFileTooLarge, http.StatusRequestEntityTooLarge, "file_too_large"},
+	{core.ErrUnsupportedMediaType, http.StatusUnsupportedMediaType, "unsupported_media_type"},
+	{core.ErrInvalidFile, http.StatusBadRequest, "invalid_file"},
+	{core.ErrInvalidInput, http.StatusBadRequest, "invalid_input"},
+	{core.ErrNoUpdatedData, http.StatusBadRequest, "no_updated_data"},
+	{core.ErrInvalidRange, http.StatusRequestedRangeNotSatisfiable, "invalid_range"},
+	{core.ErrUnauthorized, http.StatusUnauthorized, "unauthorized"},
+	{core.ErrEmailNotVerified, http.StatusForbidden, "email_not_verified"},
+	{core.ErrForbidden, http.StatusForbidden, "forbidden"},
+	{core.ErrDataNotFound, http.StatusNotFound, "not_found"},
+	{core.ErrConflictingData, http.StatusConflict, "conflict"},
+	{core.ErrStorageUnavailable, http.StatusServiceUnavailable, "storage_unavailable"},
+	{core.ErrTooManyRequests, http.StatusTooManyRequests, "too_many_requests"},
+	{core.ErrQuotaExceeded, http.StatusForbidden, "quota_exceeded"},
+}
+
+// ErrorHandler writes the last error added by the handlers with ctx.Error as
+// a problem+json response, unless the handler already wrote a response
+func ErrorHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		lastError := ctx.Errors.Last()
+		if lastError == nil || ctx.Writer.Written() {
+			return
+		}
+
+		problem := NewProblem(lastError.Err)
+		problem.Instance = ctx.Request.URL.Path
+
+		if problem.Status >= http.StatusInternalServerError {
+			slog.Error("Error handling request", "path", ctx.Request.URL.Path, "error", lastError.Err)
+		}
+
+		// Tells the clients when a time based quota is reset
+		if problem.Status == http.StatusTooManyRequests && problem.Quota != nil && problem.Quota.ResetAt != nil {
+			ctx.Header("Retry-After", strconv.Itoa(max(toSeconds(time.Until(*problem.Quota.ResetAt)), 1)))
+		}
+
+		ctx.Header("Content-Type", ProblemContentType)
+		ctx.AbortWithStatusJSON(problem.Status, problem)
+	}
+}
+
+// NewProblem maps the error to a problem. The validation and quota errors inform their
+// message as detail, other errors only inform the message of the core error, so internal
+// details like queries or file paths never reach the client
+func NewProblem(err error) Problem {
+	for _, mapping := range problemMappings {
+		if !errors.Is(err, mapping.err) {
+			continue
+		}
+
+		detail := mapping.err.Error()
+
+		var validationError *core.ValidationError
+		if errors.As(err, &validationError) {
+			detail = validationError.Message
+		}
+
+		var quotaError *core.QuotaError
+		if errors.As(err, &quotaError) {
+			problem := newProblem(mapping.status, mapping.code, quotaError.Error())
+			problem.Quota = newProblemQuota(quotaError)
+			return problem
+		}
+
+		return newProblem(mapping.status, mapping.code, detail)
+	}
+
+	return newProblem(http.StatusInternalServerError, "internal_error", "an unexpected error occurred. Try again later.")
+}
+
+func newProblem(status int, code string, detail string) Problem {
+	return Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+func newProblemQuota(err *core.QuotaError) *ProblemQuota {
+	quota := &ProblemQuota{
+		Name:      err.Quota,
+		Limit:     err.Limit,
+		Used:      err.Used,
+		Remaining: err.Remaining(),
+	}
+
+	if !err.ResetAt.IsZero() {
+		quota.ResetAt = &err.ResetAt
+	}
+
+	return quota
+}
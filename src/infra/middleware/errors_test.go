@@ -0,0 +1,143 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"errors"
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/infra/middleware"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveError(err error) (*httptest.ResponseRecorder, middleware.Problem) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+	router.GET("/requests", func(ctx *gin.Context) {
+		ctx.Error(err)
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/requests", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var problem middleware.Problem
+	_ = json.Unmarshal(w.Body.Bytes(), &problem)
+
+	return w, problem
+}
+
+func TestErrorHandler_CoreErrors(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{core.ErrDataNotFound, http.StatusNotFound, "not_found"},
+		{core.ErrConflictingData, http.StatusConflict, "conflict"},
+		{core.ErrUnauthorized, http.StatusUnauthorized, "unauthorized"},
+		{core.ErrForbidden, http.StatusForbidden, "forbidden"},
+		{core.ErrEmailNotVerified, http.StatusForbidden, "email_not_verified"},
+		{core.ErrInvalidRange, http.StatusRequestedRangeNotSatisfiable, "invalid_range"},
+		{core.ErrStorageUnavailable, http.StatusServiceUnavailable, "storage_unavailable"},
+		{fmt.Errorf("request 10: %w", core.ErrDataNotFound), http.StatusNotFound, "not_found"},
+	}
+
+	for _, testCase := range cases {
+		w, problem := serveError(testCase.err)
+
+		assert.Equal(t, testCase.status, w.Code)
+		assert.Equal(t, middleware.ProblemContentType, w.Header().Get("Content-Type"))
+		assert.Equal(t, testCase.status, problem.Status)
+		assert.Equal(t, testCase.code, problem.Code)
+		assert.Equal(t, "about:blank", problem.Type)
+		assert.Equal(t, http.StatusText(testCase.status), problem.Title)
+		assert.Equal(t, "/requests", problem.Instance)
+	}
+}
+
+func TestErrorHandler_WrappedErrorHidesDetails(t *testing.T) {
+	w, problem := serveError(fmt.Errorf("SELECT * FROM requests: %w", core.ErrDataNotFound))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, core.ErrDataNotFound.Error(), problem.Detail)
+}
+
+func TestErrorHandler_ValidationErrors(t *testing.T) {
+	cases := []struct {
+		err    *core.ValidationError
+		status int
+		code   string
+	}{
+		{core.NewValidationError(core.ErrFileTooLarge, "file size is greater than 500Mb"), http.StatusRequestEntityTooLarge, "file_too_large"},
+		{core.NewValidationError(core.ErrUnsupportedMediaType, "file extension not allowed"), http.StatusUnsupportedMediaType, "unsupported_media_type"},
+		{core.NewValidationError(core.ErrInvalidFile, "file is empty"), http.StatusBadRequest, "invalid_file"},
+		{core.NewValidationError(core.ErrInvalidInput, "video_file is required"), http.StatusBadRequest, "invalid_input"},
+	}
+
+	for _, testCase := range cases {
+		w, problem := serveError(testCase.err)
+
+		assert.Equal(t, testCase.status, w.Code)
+		assert.Equal(t, testCase.code, problem.Code)
+		assert.Equal(t, testCase.err.Message, problem.Detail)
+	}
+}
+
+func TestErrorHandler_QuotaErrors(t *testing.T) {
+	resetAt := time.Now().Add(time.Hour)
+
+	w, problem := serveError(core.NewQuotaError(core.ErrTooManyRequests, "requests_per_day", 20, 20, resetAt))
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "too_many_requests", problem.Code)
+	assert.Equal(t, "3600", w.Header().Get("Retry-After"))
+	assert.Equal(t, "requests_per_day", problem.Quota.Name)
+	assert.Equal(t, int64(20), problem.Quota.Limit)
+	assert.Equal(t, int64(0), problem.Quota.Remaining)
+	assert.True(t, resetAt.Equal(*problem.Quota.ResetAt))
+
+	w, problem = serveError(core.NewQuotaError(core.ErrQuotaExceeded, "bytes_per_month", 1000, 900, resetAt))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "quota_exceeded", problem.Code)
+	assert.Empty(t, w.Header().Get("Retry-After"))
+	assert.Equal(t, int64(100), problem.Quota.Remaining)
+
+	w, problem = serveError(core.NewQuotaError(core.ErrTooManyRequests, "concurrent_jobs", 3, 3, time.Time{}))
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Empty(t, w.Header().Get("Retry-After"))
+	assert.Nil(t, problem.Quota.ResetAt)
+}
+
+func TestErrorHandler_UnknownError(t *testing.T) {
+	w, problem := serveError(errors.New("connection refused to 10.0.0.1:5432"))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "internal_error", problem.Code)
+	assert.NotContains(t, problem.Detail, "10.0.0.1")
+}
+
+func TestErrorHandler_KeepsWrittenResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+	router.GET("/requests", func(ctx *gin.Context) {
+		ctx.Error(core.ErrDataNotFound)
+		ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/requests", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status": "ok"}`, w.Body.String())
+}
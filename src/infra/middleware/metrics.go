@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"example/web-service-gin/src/core/port"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels the requests that matched no route, so unknown paths don't create new series
+const unmatchedRoute = "unmatched"
+
+// Metrics records the method, the route pattern, the status and the latency of every request.
+// It must be the first middleware, so the status is the one written by the others. The request
+// is recorded in a defer, so a panic not recovered after it is still counted, as a 500
+func Metrics(metrics port.Metrics) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+
+		defer func() {
+			status := ctx.Writer.Status()
+			err := recover()
+			if err != nil {
+				status = http.StatusInternalServerError
+			}
+
+			route := ctx.FullPath()
+			if route == "" {
+				route = unmatchedRoute
+			}
+
+			metrics.ObserveHTTPRequest(ctx.Request.Method, route, status, time.Since(start))
+
+			if err != nil {
+				panic(err)
+			}
+		}()
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,96 @@
+package middleware_test
+
+import (
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/infra/middleware"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type observedRequest struct {
+	method string
+	route  string
+	status int
+}
+
+type fakeMetrics struct {
+	requests []observedRequest
+}
+
+func (m *fakeMetrics) ObserveHTTPRequest(method string, route string, status int, duration time.Duration) {
+	m.requests = append(m.requests, observedRequest{method, route, status})
+}
+
+func (m *fakeMetrics) ObserveUpload(bytes int64, duration time.Duration, err error) {}
+
+func (m *fakeMetrics) ObserveCall(service string, operation string, duration time.Duration, err error) {
+}
+
+func (m *fakeMetrics) ObserveMessagesReceived(queue string, count int) {}
+
+func (m *fakeMetrics) ObserveMessage(queue string, duration time.Duration, err error) {}
+
+func (m *fakeMetrics) ObserveProcessing(status entity.RequestStatus, duration time.Duration) {}
+
+func TestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	metrics := &fakeMetrics{}
+	router := gin.New()
+	router.Use(middleware.Metrics(metrics), middleware.ErrorHandler())
+	router.GET("/requests/:id", func(ctx *gin.Context) {
+		ctx.Error(core.ErrDataNotFound)
+	})
+
+	// When
+	for _, path := range []string{"/requests/10", "/requests/11", "/unknown/12"} {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// Then
+	assert.Equal(t, []observedRequest{
+		{http.MethodGet, "/requests/:id", http.StatusNotFound},
+		{http.MethodGet, "/requests/:id", http.StatusNotFound},
+		{http.MethodGet, "unmatched", http.StatusNotFound},
+	}, metrics.requests)
+}
+
+func TestMetrics_Panic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	metrics := &fakeMetrics{}
+	router := gin.New()
+	router.Use(middleware.Metrics(metrics), gin.Recovery())
+	router.GET("/requests/:id", func(ctx *gin.Context) {
+		panic("unexpected")
+	})
+
+	// When
+	req, _ := http.NewRequest(http.MethodGet, "/requests/10", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	// Then
+	assert.Equal(t, []observedRequest{{http.MethodGet, "/requests/:id", http.StatusInternalServerError}}, metrics.requests)
+}
+
+func TestMetrics_PanicNotRecovered(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	metrics := &fakeMetrics{}
+	router := gin.New()
+	router.Use(middleware.Metrics(metrics))
+	router.GET("/requests/:id", func(ctx *gin.Context) {
+		panic("unexpected")
+	})
+
+	// When
+	req, _ := http.NewRequest(http.MethodGet, "/requests/10", nil)
+	assert.Panics(t, func() { router.ServeHTTP(httptest.NewRecorder(), req) })
+
+	// Then
+	assert.Equal(t, []observedRequest{{http.MethodGet, "/requests/:id", http.StatusInternalServerError}}, metrics.requests)
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/core/port"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit limits the requests of each user to the limit of the route group, the public
+// routes are limited by IP. The RateLimit-* headers inform the limit and the remaining
+// requests, and the rejected requests answer 429 with Retry-After. A nil store or a limit
+// without requests disables it, and a failure of the store lets the request through
+func RateLimit(store port.RateLimitStore, group string, limit entity.RateLimit) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if store == nil || limit.Requests <= 0 {
+			ctx.Next()
+			return
+		}
+
+		result, err := store.Take(ctx, group+":"+rateLimitClient(ctx), limit)
+		if err != nil {
+			slog.Error("Error checking rate limit", "group", group, "error", err)
+			ctx.Next()
+			return
+		}
+
+		ctx.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Requests, toSeconds(limit.Period)))
+		ctx.Header("RateLimit-Limit", strconv.Itoa(limit.Requests))
+		ctx.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		ctx.Header("RateLimit-Reset", strconv.Itoa(toSeconds(result.ResetAfter)))
+
+		if !result.Allowed {
+			ctx.Header("Retry-After", strconv.Itoa(max(toSeconds(result.RetryAfter), 1)))
+			ctx.Error(core.NewValidationError(core.ErrTooManyRequests,
+				fmt.Sprintf("rate limit of %d requests per %s exceeded", limit.Requests, limit.Period)))
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// rateLimitClient identifies the authenticated user, or else the IP of the client
+func rateLimitClient(ctx *gin.Context) string {
+	if user := GetAuthUser(ctx); user != nil {
+		return "user:" + user.Id
+	}
+	return "ip:" + ctx.ClientIP()
+}
+
+// toSeconds rounds the duration up to whole seconds, as the headers inform
+func toSeconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
@@ -0,0 +1,144 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/infra/middleware"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRateLimitStore struct {
+	mock.Mock
+}
+
+func (m *MockRateLimitStore) Take(ctx context.Context, key string, limit entity.RateLimit) (*entity.RateLimitResult, error) {
+	args := m.Called(key, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.RateLimitResult), args.Error(1)
+}
+
+var testRateLimit = entity.RateLimit{Requests: 10, Period: time.Minute}
+
+func serveRateLimited(store *MockRateLimitStore, user *entity.User, headers ...string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.SetTrustedProxies(nil)
+	router.Use(middleware.ErrorHandler())
+	router.Use(func(ctx *gin.Context) {
+		if user != nil {
+			ctx.Set(middleware.AuthUserKey, user)
+		}
+	})
+	router.POST("/requests", middleware.RateLimit(store, "uploads", testRateLimit), func(ctx *gin.Context) {
+		ctx.Status(http.StatusCreated)
+	})
+
+	req, _ := http.NewRequest(http.MethodPost, "/requests", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestRateLimit_Allowed(t *testing.T) {
+	store := new(MockRateLimitStore)
+	store.On("Take", "uploads:user:123456", testRateLimit).
+		Return(&entity.RateLimitResult{Allowed: true, Remaining: 9, ResetAfter: 6 * time.Second}, nil)
+
+	w := serveRateLimited(store, &entity.User{Id: "123456"})
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "10;w=60", w.Header().Get("RateLimit-Policy"))
+	assert.Equal(t, "10", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "9", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "6", w.Header().Get("RateLimit-Reset"))
+	assert.Empty(t, w.Header().Get("Retry-After"))
+}
+
+func TestRateLimit_Rejected(t *testing.T) {
+	store := new(MockRateLimitStore)
+	store.On("Take", "uploads:ip:10.0.0.1", testRateLimit).
+		Return(&entity.RateLimitResult{ResetAfter: time.Minute, RetryAfter: 5500 * time.Millisecond}, nil)
+
+	w := serveRateLimited(store, nil)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, middleware.ProblemContentType, w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), `"code":"too_many_requests"`)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "6", w.Header().Get("Retry-After"))
+}
+
+func TestRateLimit_IgnoresForwardedForFromUntrustedProxies(t *testing.T) {
+	store := new(MockRateLimitStore)
+	store.On("Take", "uploads:ip:10.0.0.1", testRateLimit).
+		Return(&entity.RateLimitResult{Allowed: true, Remaining: 9}, nil)
+
+	w := serveRateLimited(store, nil, "X-Forwarded-For", "203.0.113.7")
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	store.AssertExpectations(t)
+}
+
+func TestRateLimit_BeforeAuthenticationUsesIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := new(MockRateLimitStore)
+	store.On("Take", "auth:ip:10.0.0.1", testRateLimit).
+		Return(&entity.RateLimitResult{ResetAfter: time.Minute, RetryAfter: time.Second}, nil)
+	authenticated := false
+
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+	router.GET("/requests", middleware.RateLimit(store, "auth", testRateLimit), func(ctx *gin.Context) {
+		authenticated = true
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/requests", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("Authorization", "Bearer invalid")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// The credentials aren't checked once the IP is over the limit
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.False(t, authenticated)
+}
+
+func TestRateLimit_StoreErrorLetsThrough(t *testing.T) {
+	store := new(MockRateLimitStore)
+	store.On("Take", mock.Anything, mock.Anything).Return(nil, errors.New("connection refused"))
+
+	w := serveRateLimited(store, nil)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+}
+
+func TestRateLimit_Disabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/files/*filepath", middleware.RateLimit(nil, "public", testRateLimit), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/files/video.zip", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+}
@@ -1,11 +1,14 @@
 package utils
 
 import (
+	"example/web-service-gin/src/core"
 	"example/web-service-gin/src/core/entity"
+	"example/web-service-gin/src/infra/configuration"
 	"fmt"
 	"github.com/MicahParks/keyfunc"
 	"github.com/golang-jwt/jwt/v4"
-	"log"
+	"log/slog"
+	"slices"
 	"strings"
 	"time"
 )
@@ -13,52 +16,228 @@ import (
 type JwtService struct {
 	jwksURL string
 	jwks    *keyfunc.JWKS
+	config  *configuration.Auth
+	parser  *jwt.Parser
+	now     func() time.Time
 }
 
-// NewJwtService creates a new instance of JwtService with url for public keys
-func NewJwtService(jwksURL string) (*JwtService, error) {
+// NewJwtService creates a new instance of JwtService with url for public keys. The keys are
+// fetched once and refreshed in background, a token signed with an unknown key (kid) also
+// triggers a refresh, limited to one each RefreshRateLimit so invalid tokens can't flood the JWKS
+func NewJwtService(conf *configuration.Auth) (*JwtService, error) {
 
-	// Updates each 1 hour
 	options := keyfunc.Options{
-		RefreshInterval: 1 * time.Hour,
+		RefreshInterval:   conf.RefreshInterval,
+		RefreshRateLimit:  conf.RefreshRateLimit,
+		RefreshTimeout:    10 * time.Second,
+		RefreshUnknownKID: true,
+		RefreshErrorHandler: func(err error) {
+			slog.Error("Error refreshing the JWKS", "url", conf.JwksUrl, "error", err)
+		},
 	}
 
-	jwks, err := keyfunc.Get(jwksURL, options)
+	jwks, err := keyfunc.Get(conf.JwksUrl, options)
 
 	if err != nil {
 		return nil, fmt.Errorf("error to retrive JWKS data: %w", err)
 	}
 
-	return &JwtService{jwksURL: jwksURL, jwks: jwks}, nil
+	return NewJwtServiceWithJwks(conf, jwks), nil
 }
 
-// GetUser Validates the token and get user data from JWT claims
+// NewJwtServiceWithJwks creates the service with an already loaded set of keys
+func NewJwtServiceWithJwks(conf *configuration.Auth, jwks *keyfunc.JWKS) *JwtService {
+	// The time claims are validated by validateClaims, with the configured clock skew
+	parser := jwt.NewParser(jwt.WithValidMethods(conf.AllowedAlgorithms), jwt.WithoutClaimsValidation())
+
+	return &JwtService{
+		jwksURL: conf.JwksUrl,
+		jwks:    jwks,
+		config:  conf,
+		parser:  parser,
+		now:     time.Now,
+	}
+}
+
+// Close stops the background refresh of the keys
+func (j *JwtService) Close() {
+	j.jwks.EndBackground()
+}
+
+// GetUser Validates the token and get user data from JWT claims, the
+// errors are variants of core.ErrUnauthorized telling why it was rejected
 func (j *JwtService) GetUser(jwtToken string) (user *entity.User, err error) {
 
 	cleanToken := removeBearerPrefix(jwtToken)
-	token, err := jwt.Parse(cleanToken, j.jwks.Keyfunc)
+	claims := jwt.MapClaims{}
+	token, err := j.parser.ParseWithClaims(cleanToken, claims, j.jwks.Keyfunc)
+
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("%w: %v", core.ErrInvalidToken, err)
+	}
 
+	err = j.validateClaims(claims)
 	if err != nil {
-		return nil, fmt.Errorf("error on JWT Token validation: %v", err)
+		return nil, err
+	}
+
+	return mapClaimsToUser(claims, j.config)
+}
+
+// validateClaims checks the time claims with the clock skew tolerance, the
+// issuer, the Cognito token_use and the client the token was issued to
+func (j *JwtService) validateClaims(claims jwt.MapClaims) error {
+	now := j.now()
+	skew := j.config.ClockSkew
+
+	expiresAt, err := numericClaim(claims, "exp", true)
+	if err != nil {
+		return err
+	}
+
+	if now.After(expiresAt.Add(skew)) {
+		return fmt.Errorf("%w: expired at %s", core.ErrTokenExpired, expiresAt.Format(time.RFC3339))
+	}
+
+	notBefore, err := numericClaim(claims, "nbf", false)
+	if err != nil {
+		return err
+	}
+
+	if now.Add(skew).Before(notBefore) {
+		return fmt.Errorf("%w: valid from %s", core.ErrTokenExpired, notBefore.Format(time.RFC3339))
+	}
+
+	issuedAt, err := numericClaim(claims, "iat", false)
+	if err != nil {
+		return err
+	}
+
+	if now.Add(skew).Before(issuedAt) {
+		return fmt.Errorf("%w: issued in the future", core.ErrTokenExpired)
+	}
+
+	if j.config.Issuer != "" {
+		issuer, _ := claims["iss"].(string)
+		if issuer != j.config.Issuer {
+			return fmt.Errorf("%w: %q", core.ErrInvalidIssuer, issuer)
+		}
+	}
+
+	tokenUse, _ := claims["token_use"].(string)
+	if len(j.config.TokenUse) > 0 && !slices.Contains(j.config.TokenUse, tokenUse) {
+		return fmt.Errorf("%w: %q", core.ErrInvalidTokenUse, tokenUse)
+	}
+
+	if len(j.config.ClientIds) > 0 && !slices.ContainsFunc(tokenClients(claims, tokenUse), j.isAllowedClient) {
+		return core.ErrInvalidAudience
 	}
 
-	if !token.Valid {
-		return nil, fmt.Errorf("JWT Token is invalid")
+	return nil
+}
+
+func (j *JwtService) isAllowedClient(clientId string) bool {
+	return slices.Contains(j.config.ClientIds, clientId)
+}
+
+// tokenClients returns the clients of the token, Cognito informs them on
+// the "aud" claim of the id tokens and on "client_id" of the access tokens
+func tokenClients(claims jwt.MapClaims, tokenUse string) []string {
+	if tokenUse == "access" {
+		clientId, _ := claims["client_id"].(string)
+		return []string{clientId}
 	}
 
-	// Acesse as claims do payload.
-	if claims, ok := token.Claims.(jwt.MapClaims); ok {
-		user = &entity.User{
-			Id:            claims["sub"].(string),
-			Email:         claims["email"].(string),
-			EmailVerified: claims["email_verified"].(bool),
+	switch audience := claims["aud"].(type) {
+	case string:
+		return []string{audience}
+	case []interface{}:
+		return toStrings(audience)
+	}
+
+	return nil
+}
+
+// numericClaim reads a time claim, a missing optional claim returns the zero time
+func numericClaim(claims jwt.MapClaims, name string, required bool) (time.Time, error) {
+	value, exists := claims[name]
+
+	if !exists {
+		if required {
+			return time.Time{}, fmt.Errorf("%w: %s", core.ErrMissingClaim, name)
 		}
+		return time.Time{}, nil
+	}
+
+	seconds, ok := value.(float64)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%w: %s", core.ErrMissingClaim, name)
+	}
+
+	return time.Unix(int64(seconds), 0), nil
+}
+
+// mapClaimsToUser reads the user from the claims without assuming their types, only
+// the subject is required as the access tokens don't inform the email
+func mapClaimsToUser(claims jwt.MapClaims, config *configuration.Auth) (*entity.User, error) {
+	subject, _ := claims["sub"].(string)
 
-		return user, nil
+	if subject == "" {
+		return nil, fmt.Errorf("%w: sub", core.ErrMissingClaim)
 	}
 
-	log.Fatalf("Failed to parse JWT Token")
-	return nil, nil
+	user := &entity.User{Id: subject}
+	user.Email, _ = claims["email"].(string)
+
+	// Cognito may inform the boolean claims as strings
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		user.EmailVerified = verified
+	case string:
+		user.EmailVerified = verified == "true"
+	}
+
+	// The notifications use the default locale when it is missing
+	user.Locale, _ = claims["locale"].(string)
+
+	if groups, ok := claims["cognito:groups"].([]interface{}); ok {
+		user.Groups = toStrings(groups)
+	}
+
+	// The organization and the plan drive the quotas and the deduplication, so they are read from the
+	// groups assigned by the administrators, never from custom attributes the users may write.
+	// Only the users of a team have an organization, and users without plan have the default plan
+	user.OrganizationId = groupSuffix(user.Groups, config.OrganizationGroupPrefix)
+	user.Plan = groupSuffix(user.Groups, config.PlanGroupPrefix)
+
+	return user, nil
+}
+
+// groupSuffix returns the rest of the name of the first group starting with the prefix, empty when
+// there is none or the prefix is empty
+func groupSuffix(groups []string, prefix string) string {
+	if prefix == "" {
+		return ""
+	}
+
+	for _, group := range groups {
+		if suffix, found := strings.CutPrefix(group, prefix); found && suffix != "" {
+			return suffix
+		}
+	}
+
+	return ""
+}
+
+// toStrings keeps the string values of a JSON array
+func toStrings(values []interface{}) []string {
+	var result []string
+	for _, value := range values {
+		if text, ok := value.(string); ok {
+			result = append(result, text)
+		}
+	}
+	return result
 }
 
 // removeBearerPrefix removes the "Bearer" prefix of token it exists
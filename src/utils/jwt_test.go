@@ -0,0 +1,226 @@
+package utils_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"example/web-service-gin/src/core"
+	"example/web-service-gin/src/infra/configuration"
+	"example/web-service-gin/src/utils"
+	"testing"
+	"time"
+
+	"github.com/MicahParks/keyfunc"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testKeyId    = "test-key"
+	testIssuer   = "https://cognito-idp.us-east-1.amazonaws.com/us-east-1_test"
+	testClientId = "test-client"
+)
+
+func setUpJwt(t *testing.T) (*utils.JwtService, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks := keyfunc.NewGiven(map[string]keyfunc.GivenKey{
+		testKeyId: keyfunc.NewGivenRSA(&key.PublicKey),
+	})
+
+	conf := &configuration.Auth{
+		Issuer:                  testIssuer,
+		ClientIds:               []string{testClientId},
+		TokenUse:                []string{"id", "access"},
+		AllowedAlgorithms:       []string{"RS256"},
+		ClockSkew:               time.Minute,
+		PlanGroupPrefix:         "plan:",
+		OrganizationGroupPrefix: "org:",
+	}
+
+	return utils.NewJwtServiceWithJwks(conf, jwks), key
+}
+
+func idTokenClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"sub":            "123456",
+		"email":          "user@example.com",
+		"email_verified": true,
+		"locale":         "pt-BR",
+		"cognito:groups": []string{"admin", "editors", "org:org-1", "plan:pro"},
+		"iss":            testIssuer,
+		"aud":            testClientId,
+		"token_use":      "id",
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+	}
+}
+
+func signToken(t *testing.T, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = testKeyId
+
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestGetUser_ValidIdToken(t *testing.T) {
+	service, key := setUpJwt(t)
+
+	user, err := service.GetUser("Bearer " + signToken(t, key, idTokenClaims()))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "123456", user.Id)
+	assert.Equal(t, "user@example.com", user.Email)
+	assert.True(t, user.EmailVerified)
+	assert.Equal(t, "org-1", user.OrganizationId)
+	assert.Equal(t, "pro", user.Plan)
+	assert.Equal(t, "pt-BR", user.Locale)
+	assert.Equal(t, []string{"admin", "editors", "org:org-1", "plan:pro"}, user.Groups)
+}
+
+func TestGetUser_IgnoresCustomAttributes(t *testing.T) {
+	service, key := setUpJwt(t)
+	claims := idTokenClaims()
+	claims["cognito:groups"] = []string{"admin"}
+	claims["custom:organization_id"] = "org-2"
+	claims["custom:plan"] = "enterprise"
+
+	user, err := service.GetUser(signToken(t, key, claims))
+
+	assert.NoError(t, err)
+	assert.Empty(t, user.OrganizationId)
+	assert.Empty(t, user.Plan)
+}
+
+func TestGetUser_ValidAccessToken(t *testing.T) {
+	service, key := setUpJwt(t)
+	claims := idTokenClaims()
+	delete(claims, "aud")
+	delete(claims, "email")
+	claims["token_use"] = "access"
+	claims["client_id"] = testClientId
+
+	user, err := service.GetUser(signToken(t, key, claims))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "123456", user.Id)
+	assert.Empty(t, user.Email)
+}
+
+func TestGetUser_EmailVerifiedAsString(t *testing.T) {
+	service, key := setUpJwt(t)
+	claims := idTokenClaims()
+	claims["email_verified"] = "true"
+
+	user, err := service.GetUser(signToken(t, key, claims))
+
+	assert.NoError(t, err)
+	assert.True(t, user.EmailVerified)
+}
+
+func TestGetUser_ExpiredWithinClockSkew(t *testing.T) {
+	service, key := setUpJwt(t)
+	claims := idTokenClaims()
+	claims["exp"] = time.Now().Add(-30 * time.Second).Unix()
+
+	_, err := service.GetUser(signToken(t, key, claims))
+
+	assert.NoError(t, err)
+}
+
+func TestGetUser_InvalidTokens(t *testing.T) {
+	service, key := setUpJwt(t)
+
+	tests := map[string]struct {
+		change   func(claims jwt.MapClaims)
+		expected error
+	}{
+		"expired": {
+			change:   func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Hour).Unix() },
+			expected: core.ErrTokenExpired,
+		},
+		"not valid yet": {
+			change:   func(claims jwt.MapClaims) { claims["nbf"] = time.Now().Add(time.Hour).Unix() },
+			expected: core.ErrTokenExpired,
+		},
+		"without expiration": {
+			change:   func(claims jwt.MapClaims) { delete(claims, "exp") },
+			expected: core.ErrMissingClaim,
+		},
+		"without subject": {
+			change:   func(claims jwt.MapClaims) { delete(claims, "sub") },
+			expected: core.ErrMissingClaim,
+		},
+		"subject with invalid type": {
+			change:   func(claims jwt.MapClaims) { claims["sub"] = 123456 },
+			expected: core.ErrMissingClaim,
+		},
+		"other issuer": {
+			change:   func(claims jwt.MapClaims) { claims["iss"] = "https://example.com" },
+			expected: core.ErrInvalidIssuer,
+		},
+		"other audience": {
+			change:   func(claims jwt.MapClaims) { claims["aud"] = "other-client" },
+			expected: core.ErrInvalidAudience,
+		},
+		"access token of other client": {
+			change: func(claims jwt.MapClaims) {
+				claims["token_use"] = "access"
+				claims["client_id"] = "other-client"
+			},
+			expected: core.ErrInvalidAudience,
+		},
+		"unknown token use": {
+			change:   func(claims jwt.MapClaims) { claims["token_use"] = "refresh" },
+			expected: core.ErrInvalidTokenUse,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			claims := idTokenClaims()
+			test.change(claims)
+
+			user, err := service.GetUser(signToken(t, key, claims))
+
+			assert.Nil(t, user)
+			assert.ErrorIs(t, err, test.expected)
+			assert.ErrorIs(t, err, core.ErrUnauthorized)
+		})
+	}
+}
+
+func TestGetUser_InvalidSignature(t *testing.T) {
+	service, _ := setUpJwt(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	_, err = service.GetUser(signToken(t, otherKey, idTokenClaims()))
+
+	assert.ErrorIs(t, err, core.ErrInvalidToken)
+}
+
+func TestGetUser_AlgorithmNotAllowed(t *testing.T) {
+	service, _ := setUpJwt(t)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, idTokenClaims())
+	token.Header["kid"] = testKeyId
+	signed, err := token.SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	_, err = service.GetUser(signed)
+
+	assert.ErrorIs(t, err, core.ErrInvalidToken)
+}
+
+func TestGetUser_MalformedToken(t *testing.T) {
+	service, _ := setUpJwt(t)
+
+	_, err := service.GetUser("Bearer not-a-token")
+
+	assert.True(t, errors.Is(err, core.ErrInvalidToken))
+}
@@ -1,8 +1,11 @@
 package mocks
 
 import (
+	"bytes"
+	"context"
 	"example/web-service-gin/src/core/entity"
 	"github.com/stretchr/testify/mock"
+	"mime/multipart"
 	"strings"
 	"time"
 )
@@ -90,3 +93,37 @@ func (m *MockJwtService) GetUser(token string) (*entity.User, error) {
 func MockGetJwks() string {
 	return "https://cognito-idp.us-east-1.amazonaws.com/us-east-1_ux5HPr3SJ/.well-known/jwks.json"
 }
+
+// MockGetFileHeader builds a multipart file header with the informed content, like the one received on uploads
+func MockGetFileHeader(filename string, content []byte) *multipart.FileHeader {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	fileWriter, _ := writer.CreateFormFile("video_file", filename)
+	_, _ = fileWriter.Write(content)
+	_ = writer.Close()
+
+	reader := multipart.NewReader(body, writer.Boundary())
+	form, err := reader.ReadForm(int64(len(content)) + 1024)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return form.File["video_file"][0]
+}
+
+type MockApiKeyAuthenticator struct {
+	mock.Mock
+}
+
+func (m *MockApiKeyAuthenticator) GetUser(ctx context.Context, key string) (*entity.User, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+func (m *MockApiKeyAuthenticator) RecordOwner(ctx context.Context, user *entity.User) {
+	m.Called(ctx, user)
+}
@@ -0,0 +1,127 @@
+package mocks
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// MockGetVideoContent returns a 10 seconds 1280x720 video of the informed container: mp4, mov, mkv, webm or avi
+func MockGetVideoContent(format string) []byte {
+	return MockGetVideo(format, 10*time.Second, 1280, 720)
+}
+
+// MockGetVideo returns the headers of a video with the informed duration and resolution, without frames
+func MockGetVideo(format string, duration time.Duration, width int, height int) []byte {
+	switch format {
+	case "mp4":
+		return mockIsoMedia("isom", "avc1", duration, width, height)
+	case "mov":
+		return mockIsoMedia("qt  ", "avc1", duration, width, height)
+	case "mkv":
+		return mockMatroska("matroska", "V_MPEG4/ISO/AVC", duration, width, height)
+	case "webm":
+		return mockMatroska("webm", "V_VP9", duration, width, height)
+	case "avi":
+		return mockAvi("H264", duration, width, height)
+	}
+
+	return []byte("not a video")
+}
+
+func mockIsoMedia(brand string, codec string, duration time.Duration, width int, height int) []byte {
+	const timescale = 1000
+
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[12:], timescale)
+	binary.BigEndian.PutUint32(mvhd[16:], uint32(duration.Milliseconds()))
+
+	tkhd := make([]byte, 84)
+	binary.BigEndian.PutUint32(tkhd[76:], uint32(width)<<16)
+	binary.BigEndian.PutUint32(tkhd[80:], uint32(height)<<16)
+
+	hdlr := append(make([]byte, 8), []byte("vide\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00")...)
+
+	sampleEntry := make([]byte, 78)
+	binary.BigEndian.PutUint16(sampleEntry[24:], uint16(width))
+	binary.BigEndian.PutUint16(sampleEntry[26:], uint16(height))
+	stsd := append([]byte{0, 0, 0, 0, 0, 0, 0, 1}, mockBox(codec, sampleEntry)...)
+
+	trak := mockBox("trak",
+		mockBox("tkhd", tkhd),
+		mockBox("mdia", mockBox("hdlr", hdlr), mockBox("minf", mockBox("stbl", mockBox("stsd", stsd)))))
+
+	return bytes.Join([][]byte{
+		mockBox("ftyp", []byte(brand+"\x00\x00\x02\x00"+brand)),
+		mockBox("mdat", make([]byte, 64)),
+		mockBox("moov", mockBox("mvhd", mvhd), trak),
+	}, nil)
+}
+
+func mockBox(boxType string, content ...[]byte) []byte {
+	data := bytes.Join(content, nil)
+	box := binary.BigEndian.AppendUint32(nil, uint32(8+len(data)))
+	return append(append(box, boxType...), data...)
+}
+
+func mockMatroska(docType string, codec string, duration time.Duration, width int, height int) []byte {
+	header := mockElement(0x1A45DFA3,
+		mockElement(0x4286, []byte{1}),
+		mockElement(0x4282, []byte(docType)))
+
+	durationMs := binary.BigEndian.AppendUint64(nil, math.Float64bits(float64(duration.Milliseconds())))
+	info := mockElement(0x1549A966,
+		mockElement(0x2AD7B1, binary.BigEndian.AppendUint32(nil, 1000000)),
+		mockElement(0x4489, durationMs))
+
+	video := mockElement(0xE0,
+		mockElement(0xB0, binary.BigEndian.AppendUint16(nil, uint16(width))),
+		mockElement(0xBA, binary.BigEndian.AppendUint16(nil, uint16(height))))
+
+	tracks := mockElement(0x1654AE6B,
+		mockElement(0xAE, mockElement(0x83, []byte{2}), mockElement(0x86, []byte("A_OPUS"))),
+		mockElement(0xAE, mockElement(0x83, []byte{1}), mockElement(0x86, []byte(codec)), video))
+
+	cluster := mockElement(0x1F43B675, make([]byte, 64))
+
+	return append(header, mockElement(0x18538067, info, tracks, cluster)...)
+}
+
+// mockElement encodes an EBML element, the size always uses 8 bytes
+func mockElement(id uint32, content ...[]byte) []byte {
+	data := bytes.Join(content, nil)
+
+	element := binary.BigEndian.AppendUint32(nil, id)
+	element = bytes.TrimLeft(element, "\x00")
+	element = append(element, 0x01)
+	element = append(element, binary.BigEndian.AppendUint64(nil, uint64(len(data)))[1:]...)
+
+	return append(element, data...)
+}
+
+func mockAvi(codec string, duration time.Duration, width int, height int) []byte {
+	const microSecondsPerFrame = 40000
+
+	avih := make([]byte, 56)
+	binary.LittleEndian.PutUint32(avih[0:], microSecondsPerFrame)
+	binary.LittleEndian.PutUint32(avih[16:], uint32(duration.Microseconds()/microSecondsPerFrame))
+	binary.LittleEndian.PutUint32(avih[32:], uint32(width))
+	binary.LittleEndian.PutUint32(avih[36:], uint32(height))
+
+	strh := append([]byte("vids"+codec), make([]byte, 48)...)
+	strf := make([]byte, 40)
+	copy(strf[16:], codec)
+
+	strl := mockChunk("LIST", []byte("strl"), mockChunk("strh", strh), mockChunk("strf", strf))
+	hdrl := mockChunk("LIST", []byte("hdrl"), mockChunk("avih", avih), strl)
+	movi := mockChunk("LIST", []byte("movi"), make([]byte, 64))
+
+	return mockChunk("RIFF", []byte("AVI "), hdrl, movi)
+}
+
+func mockChunk(chunkId string, content ...[]byte) []byte {
+	data := bytes.Join(content, nil)
+	chunk := append([]byte(chunkId), binary.LittleEndian.AppendUint32(nil, uint32(len(data)))...)
+	return append(chunk, data...)
+}
@@ -0,0 +1,67 @@
+package network_test
+
+import (
+	"context"
+	"example/web-service-gin/src/utils/network"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	tests := map[string]bool{
+		"93.184.216.34":      true,
+		"2606:4700::6810:1":  true,
+		"127.0.0.1":          false,
+		"::1":                false,
+		"10.1.2.3":           false,
+		"172.16.0.1":         false,
+		"192.168.1.1":        false,
+		"169.254.169.254":    false,
+		"fe80::1":            false,
+		"fd00:ec2::254":      false,
+		"0.0.0.0":            false,
+		"100.100.100.200":    false,
+		"::ffff:127.0.0.1":   false,
+		"::ffff:169.254.0.1": false,
+	}
+
+	for ip, public := range tests {
+		t.Run(ip, func(t *testing.T) {
+			assert.Equal(t, public, network.IsPublicIP(net.ParseIP(ip)))
+		})
+	}
+}
+
+func TestNewClient_RefusesNonPublicAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := network.NewClient(time.Second, false)
+
+	for _, url := range []string{server.URL, "http://169.254.169.254/latest/meta-data/"} {
+		t.Run(url, func(t *testing.T) {
+			request, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, url, nil)
+
+			_, err := client.Do(request)
+
+			assert.ErrorIs(t, err, network.ErrNonPublicAddress)
+		})
+	}
+}
+
+func TestNewClient_AllowPrivateNetworks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	response, err := network.NewClient(time.Second, true).Post(server.URL, "application/json", nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+}
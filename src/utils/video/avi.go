@@ -0,0 +1,98 @@
+package video
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+// probeAvi reads the main AVI header (avih) and the header of the first video stream (strl)
+func probeAvi(reader io.ReaderAt, size int64) (*Metadata, error) {
+	// The hdrl list is the first chunk after "RIFF" + size + "AVI "
+	header, err := readSection(reader, 12, min(12, size-12), size)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(header) < 12 || string(header[0:4]) != "LIST" || string(header[8:12]) != "hdrl" {
+		return nil, fmt.Errorf("hdrl list not found: %w", ErrMalformedContainer)
+	}
+
+	hdrl, err := readSection(reader, 24, int64(binary.LittleEndian.Uint32(header[4:8]))-4, size)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := &Metadata{}
+	foundVideo := false
+
+	err = eachChunk(hdrl, func(chunkId string, content []byte) {
+		switch {
+		case chunkId == "avih" && len(content) >= 40:
+			microSecondsPerFrame := binary.LittleEndian.Uint32(content[0:4])
+			totalFrames := binary.LittleEndian.Uint32(content[16:20])
+			metadata.Duration = time.Duration(microSecondsPerFrame) * time.Duration(totalFrames) * time.Microsecond
+			metadata.Width = int(binary.LittleEndian.Uint32(content[32:36]))
+			metadata.Height = int(binary.LittleEndian.Uint32(content[36:40]))
+
+		case chunkId == "LIST" && len(content) >= 4 && string(content[0:4]) == "strl" && !foundVideo:
+			foundVideo = parseAviStream(content[4:], metadata)
+		}
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !foundVideo {
+		return nil, ErrNoVideoStream
+	}
+
+	return metadata, nil
+}
+
+// parseAviStream fills the codec when the stream is a video, the compression of
+// the bitmap header (strf) is preferred over the handler of the stream header (strh)
+func parseAviStream(strl []byte, metadata *Metadata) bool {
+	isVideo := false
+	var handler, compression string
+
+	_ = eachChunk(strl, func(chunkId string, content []byte) {
+		switch {
+		case chunkId == "strh" && len(content) >= 8:
+			isVideo = string(content[0:4]) == "vids"
+			handler = string(content[4:8])
+		case chunkId == "strf" && len(content) >= 20:
+			compression = string(content[16:20])
+		}
+	})
+
+	if !isVideo {
+		return false
+	}
+
+	metadata.Codec = codecName(handler)
+	if codec := codecName(compression); codec != "" {
+		metadata.Codec = codec
+	}
+
+	return true
+}
+
+// eachChunk calls fn with the ID and content of each RIFF chunk inside data,
+// chunks are padded to an even size
+func eachChunk(data []byte, fn func(chunkId string, content []byte)) error {
+	for len(data) >= 8 {
+		chunkSize := int64(binary.LittleEndian.Uint32(data[4:8]))
+		if 8+chunkSize > int64(len(data)) {
+			return ErrMalformedContainer
+		}
+
+		fn(string(data[0:4]), data[8:8+chunkSize])
+
+		next := 8 + chunkSize + chunkSize%2
+		data = data[min(next, int64(len(data))):]
+	}
+	return nil
+}
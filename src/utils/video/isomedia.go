@@ -0,0 +1,197 @@
+package video
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+// probeIsoMedia finds the moov box on the top level of the file, it may be
+// after the media data, and reads the movie and video track headers
+func probeIsoMedia(reader io.ReaderAt, size int64) (*Metadata, error) {
+	var offset int64
+
+	for offset < size {
+		header, err := readSection(reader, offset, min(16, size-offset), size)
+		if err != nil {
+			return nil, err
+		}
+
+		boxType, headerSize, boxSize, ok := parseBoxHeader(header, size-offset)
+		if !ok {
+			return nil, ErrMalformedContainer
+		}
+
+		if boxType == "moov" {
+			moov, err := readSection(reader, offset+headerSize, boxSize-headerSize, size)
+			if err != nil {
+				return nil, err
+			}
+			return parseMoov(moov)
+		}
+
+		offset += boxSize
+	}
+
+	return nil, fmt.Errorf("moov box not found: %w", ErrMalformedContainer)
+}
+
+// parseBoxHeader reads the type and size of a box, including the 64 bits
+// "largesize" and the size zero of a box that goes until the end of the file
+func parseBoxHeader(data []byte, available int64) (string, int64, int64, bool) {
+	if len(data) < 8 {
+		return "", 0, 0, false
+	}
+
+	boxSize := int64(binary.BigEndian.Uint32(data))
+	boxType := string(data[4:8])
+	headerSize := int64(8)
+
+	switch boxSize {
+	case 0:
+		boxSize = available
+	case 1:
+		if len(data) < 16 {
+			return "", 0, 0, false
+		}
+		boxSize = int64(binary.BigEndian.Uint64(data[8:16]))
+		headerSize = 16
+	}
+
+	if boxSize < headerSize || boxSize > available {
+		return "", 0, 0, false
+	}
+
+	return boxType, headerSize, boxSize, true
+}
+
+// eachBox calls fn with the type and content of each box inside data
+func eachBox(data []byte, fn func(boxType string, content []byte)) error {
+	for len(data) > 0 {
+		boxType, headerSize, boxSize, ok := parseBoxHeader(data, int64(len(data)))
+		if !ok {
+			return ErrMalformedContainer
+		}
+
+		fn(boxType, data[headerSize:boxSize])
+		data = data[boxSize:]
+	}
+	return nil
+}
+
+// findBox returns the content of the first box on the informed path, e.g. "mdia", "minf", "stbl"
+func findBox(data []byte, path ...string) []byte {
+	for _, boxType := range path {
+		var found []byte
+		_ = eachBox(data, func(childType string, content []byte) {
+			if found == nil && childType == boxType {
+				found = content
+			}
+		})
+
+		if found == nil {
+			return nil
+		}
+		data = found
+	}
+	return data
+}
+
+func parseMoov(moov []byte) (*Metadata, error) {
+	metadata := &Metadata{}
+
+	mvhd := findBox(moov, "mvhd")
+	if mvhd == nil {
+		return nil, fmt.Errorf("mvhd box not found: %w", ErrMalformedContainer)
+	}
+
+	metadata.Duration = parseMvhdDuration(mvhd)
+
+	var tracks [][]byte
+	err := eachBox(moov, func(boxType string, content []byte) {
+		if boxType == "trak" {
+			tracks = append(tracks, content)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, trak := range tracks {
+		hdlr := findBox(trak, "mdia", "hdlr")
+		if len(hdlr) < 12 || string(hdlr[8:12]) != "vide" {
+			continue
+		}
+
+		metadata.Width, metadata.Height = parseTkhdDimensions(findBox(trak, "tkhd"))
+		codec, width, height := parseStsd(findBox(trak, "mdia", "minf", "stbl", "stsd"))
+		metadata.Codec = codecName(codec)
+
+		// Some encoders leave the track dimensions empty, the sample entry has the coded size
+		if metadata.Width == 0 || metadata.Height == 0 {
+			metadata.Width, metadata.Height = width, height
+		}
+
+		return metadata, nil
+	}
+
+	return nil, ErrNoVideoStream
+}
+
+// parseMvhdDuration reads the movie duration of a version 0 (32 bits) or version 1 (64 bits) header
+func parseMvhdDuration(mvhd []byte) time.Duration {
+	if len(mvhd) >= 32 && mvhd[0] == 1 {
+		return toDuration(float64(binary.BigEndian.Uint64(mvhd[24:32])), float64(binary.BigEndian.Uint32(mvhd[20:24])))
+	}
+
+	if len(mvhd) >= 20 {
+		duration := binary.BigEndian.Uint32(mvhd[16:20])
+		if duration == 0xFFFFFFFF {
+			return 0
+		}
+		return toDuration(float64(duration), float64(binary.BigEndian.Uint32(mvhd[12:16])))
+	}
+
+	return 0
+}
+
+// parseTkhdDimensions reads the track width and height, 16.16 fixed point numbers at the end of the header
+func parseTkhdDimensions(tkhd []byte) (int, int) {
+	offset := 76
+	if len(tkhd) > 0 && tkhd[0] == 1 {
+		offset = 88
+	}
+
+	if len(tkhd) < offset+8 {
+		return 0, 0
+	}
+
+	width := binary.BigEndian.Uint32(tkhd[offset:]) >> 16
+	height := binary.BigEndian.Uint32(tkhd[offset+4:]) >> 16
+	return int(width), int(height)
+}
+
+// parseStsd reads the codec (the type of the first sample entry) and its coded size
+func parseStsd(stsd []byte) (string, int, int) {
+	if len(stsd) < 8 {
+		return "", 0, 0
+	}
+
+	var codec string
+	var width, height int
+
+	_ = eachBox(stsd[8:], func(boxType string, content []byte) {
+		if codec != "" {
+			return
+		}
+
+		codec = boxType
+		if len(content) >= 28 {
+			width = int(binary.BigEndian.Uint16(content[24:26]))
+			height = int(binary.BigEndian.Uint16(content[26:28]))
+		}
+	})
+
+	return codec, width, height
+}
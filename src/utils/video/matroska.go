@@ -0,0 +1,205 @@
+package video
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Matroska element IDs used by the probe
+const (
+	ebmlSegment        = 0x18538067
+	ebmlInfo           = 0x1549A966
+	ebmlTimestampScale = 0x2AD7B1
+	ebmlDuration       = 0x4489
+	ebmlTracks         = 0x1654AE6B
+	ebmlTrackEntry     = 0xAE
+	ebmlTrackType      = 0x83
+	ebmlCodecId        = 0x86
+	ebmlVideo          = 0xE0
+	ebmlPixelWidth     = 0xB0
+	ebmlPixelHeight    = 0xBA
+	ebmlCluster        = 0x1F43B675
+
+	matroskaVideoTrack     = 1
+	defaultTimestampScale  = 1000000
+	maxElementHeaderLength = 12
+	unknownElementSize     = -1
+)
+
+// probeMatroska walks the top level elements of the Segment until the first
+// Cluster, reading the duration from Info and the video stream from Tracks
+func probeMatroska(reader io.ReaderAt, size int64) (*Metadata, error) {
+	_, headerLength, elementSize, err := readElementHeader(reader, 0, size)
+	if err != nil {
+		return nil, err
+	}
+
+	if elementSize == unknownElementSize {
+		return nil, fmt.Errorf("EBML header without size: %w", ErrMalformedContainer)
+	}
+
+	offset := int64(headerLength) + elementSize
+	id, headerLength, elementSize, err := readElementHeader(reader, offset, size)
+	if err != nil {
+		return nil, err
+	}
+
+	if id != ebmlSegment {
+		return nil, fmt.Errorf("segment element not found: %w", ErrMalformedContainer)
+	}
+
+	offset += int64(headerLength)
+	end := size
+	if elementSize != unknownElementSize {
+		end = min(offset+elementSize, size)
+	}
+
+	var info, tracks []byte
+
+	for offset < end && (info == nil || tracks == nil) {
+		id, headerLength, elementSize, err = readElementHeader(reader, offset, end)
+		if err != nil {
+			return nil, err
+		}
+
+		// The media starts on the clusters, the headers are always before them
+		if id == ebmlCluster || elementSize == unknownElementSize {
+			break
+		}
+
+		offset += int64(headerLength)
+
+		switch id {
+		case ebmlInfo:
+			info, err = readSection(reader, offset, elementSize, end)
+		case ebmlTracks:
+			tracks, err = readSection(reader, offset, elementSize, end)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		offset += elementSize
+	}
+
+	if tracks == nil {
+		return nil, fmt.Errorf("tracks element not found: %w", ErrMalformedContainer)
+	}
+
+	metadata, err := parseMatroskaTracks(tracks)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata.Duration = parseMatroskaDuration(info)
+	return metadata, nil
+}
+
+// readElementHeader reads the ID and the size of the element at the offset
+func readElementHeader(reader io.ReaderAt, offset int64, end int64) (int64, int, int64, error) {
+	if offset >= end {
+		return 0, 0, 0, ErrMalformedContainer
+	}
+
+	header, err := readSection(reader, offset, min(maxElementHeaderLength, end-offset), end)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	id, idLength := readVint(header, false)
+	if idLength == 0 {
+		return 0, 0, 0, ErrMalformedContainer
+	}
+
+	elementSize, sizeLength := readVint(header[idLength:], true)
+	if sizeLength == 0 {
+		return 0, 0, 0, ErrMalformedContainer
+	}
+
+	return id, idLength + sizeLength, elementSize, nil
+}
+
+// eachElement calls fn with the ID and content of each element inside data
+func eachElement(data []byte, fn func(id int64, content []byte)) error {
+	for len(data) > 0 {
+		id, idLength := readVint(data, false)
+		if idLength == 0 {
+			return ErrMalformedContainer
+		}
+
+		elementSize, sizeLength := readVint(data[idLength:], true)
+		start := idLength + sizeLength
+		if sizeLength == 0 || elementSize < 0 || int64(start)+elementSize > int64(len(data)) {
+			return ErrMalformedContainer
+		}
+
+		fn(id, data[start:start+int(elementSize)])
+		data = data[start+int(elementSize):]
+	}
+	return nil
+}
+
+// parseMatroskaDuration reads the Duration, a float in TimestampScale nanoseconds units
+func parseMatroskaDuration(info []byte) time.Duration {
+	scale := float64(defaultTimestampScale)
+	var duration float64
+
+	_ = eachElement(info, func(id int64, content []byte) {
+		switch id {
+		case ebmlTimestampScale:
+			scale = float64(readUint(content))
+		case ebmlDuration:
+			duration = readFloat(content)
+		}
+	})
+
+	return toDuration(duration*scale, float64(time.Second))
+}
+
+// parseMatroskaTracks returns the codec and pixel size of the first video track
+func parseMatroskaTracks(tracks []byte) (*Metadata, error) {
+	var metadata *Metadata
+
+	err := eachElement(tracks, func(id int64, content []byte) {
+		if id != ebmlTrackEntry || metadata != nil {
+			return
+		}
+
+		var trackType uint64
+		track := &Metadata{}
+
+		_ = eachElement(content, func(id int64, content []byte) {
+			switch id {
+			case ebmlTrackType:
+				trackType = readUint(content)
+			case ebmlCodecId:
+				track.Codec = codecName(string(content))
+			case ebmlVideo:
+				_ = eachElement(content, func(id int64, content []byte) {
+					switch id {
+					case ebmlPixelWidth:
+						track.Width = int(readUint(content))
+					case ebmlPixelHeight:
+						track.Height = int(readUint(content))
+					}
+				})
+			}
+		})
+
+		if trackType == matroskaVideoTrack {
+			metadata = track
+		}
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if metadata == nil {
+		return nil, ErrNoVideoStream
+	}
+
+	return metadata, nil
+}
@@ -0,0 +1,155 @@
+package video
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// Metadata contains the information of the first video stream of a file.
+// Duration is zero when the container doesn't inform it (e.g. live streams)
+type Metadata struct {
+	Format   Format
+	Duration time.Duration
+	Width    int
+	Height   int
+	Codec    string
+}
+
+var (
+	// ErrUnknownFormat is returned when the container is not recognized
+	ErrUnknownFormat = errors.New("unknown video format")
+	// ErrMalformedContainer is returned when the container structure can't be parsed
+	ErrMalformedContainer = errors.New("malformed video container")
+	// ErrNoVideoStream is returned when the container has no video track
+	ErrNoVideoStream = errors.New("no video stream found")
+)
+
+// maxHeaderSize limits the metadata section loaded in memory (moov box, Matroska
+// Info/Tracks and AVI hdrl list), the frames themselves are never read
+const maxHeaderSize = 32 << 20
+
+// Probe reads the duration, resolution and codec of a video without decoding it,
+// parsing the moov/mvhd/tkhd/stsd boxes of MP4/MOV, the Segment/Info/Tracks
+// elements of Matroska/WebM and the hdrl list of AVI
+func Probe(reader io.ReaderAt, size int64) (*Metadata, error) {
+	header := make([]byte, SniffLength)
+	read, err := reader.ReadAt(header, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	format := Detect(header[:read])
+	var metadata *Metadata
+
+	switch format {
+	case MP4, MOV:
+		metadata, err = probeIsoMedia(reader, size)
+	case Matroska, WebM:
+		metadata, err = probeMatroska(reader, size)
+	case AVI:
+		metadata, err = probeAvi(reader, size)
+	default:
+		return nil, ErrUnknownFormat
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error probing %s file: %w", format, err)
+	}
+
+	metadata.Format = format
+	return metadata, nil
+}
+
+// readSection loads a part of the file, refusing sections that are too big or out of the file
+func readSection(reader io.ReaderAt, offset int64, length int64, size int64) ([]byte, error) {
+	if offset < 0 || length < 0 || offset+length > size {
+		return nil, ErrMalformedContainer
+	}
+
+	if length > maxHeaderSize {
+		return nil, fmt.Errorf("metadata section of %d bytes: %w", length, ErrMalformedContainer)
+	}
+
+	data := make([]byte, length)
+	_, err := reader.ReadAt(data, offset)
+	if err != nil && !(errors.Is(err, io.EOF) && offset+length == size) {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// readUint reads a big-endian unsigned integer of up to 8 bytes
+func readUint(data []byte) uint64 {
+	var value uint64
+	for _, b := range data {
+		value = value<<8 | uint64(b)
+	}
+	return value
+}
+
+// readFloat reads a big-endian float of 4 or 8 bytes
+func readFloat(data []byte) float64 {
+	switch len(data) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(data)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(data))
+	}
+	return 0
+}
+
+// toDuration converts an amount of units of the informed scale (units per second)
+func toDuration(units float64, scale float64) time.Duration {
+	if scale <= 0 || units <= 0 || math.IsInf(units, 0) || math.IsNaN(units) {
+		return 0
+	}
+	return time.Duration(units / scale * float64(time.Second))
+}
+
+// codecNames maps the codec identifiers of each container to a common name
+var codecNames = map[string]string{
+	"avc1":             "h264",
+	"avc3":             "h264",
+	"h264":             "h264",
+	"x264":             "h264",
+	"v_mpeg4/iso/avc":  "h264",
+	"hvc1":             "hevc",
+	"hev1":             "hevc",
+	"hevc":             "hevc",
+	"v_mpegh/iso/hevc": "hevc",
+	"vp08":             "vp8",
+	"v_vp8":            "vp8",
+	"vp09":             "vp9",
+	"v_vp9":            "vp9",
+	"av01":             "av1",
+	"v_av1":            "av1",
+	"mp4v":             "mpeg4",
+	"xvid":             "mpeg4",
+	"divx":             "mpeg4",
+	"fmp4":             "mpeg4",
+	"v_mpeg4/iso/asp":  "mpeg4",
+	"mjpg":             "mjpeg",
+	"v_mjpeg":          "mjpeg",
+	"apch":             "prores",
+	"apcn":             "prores",
+	"apcs":             "prores",
+	"apco":             "prores",
+	"ap4h":             "prores",
+	"v_prores":         "prores",
+}
+
+// codecName returns the common name of the codec, or the identifier itself when it is unknown
+func codecName(identifier string) string {
+	identifier = strings.ToLower(strings.TrimRight(identifier, "\x00 "))
+
+	if name, found := codecNames[identifier]; found {
+		return name
+	}
+	return identifier
+}
@@ -0,0 +1,75 @@
+package video_test
+
+import (
+	"bytes"
+	"example/web-service-gin/src/utils/mocks"
+	"example/web-service-gin/src/utils/video"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func probe(content []byte) (*video.Metadata, error) {
+	return video.Probe(bytes.NewReader(content), int64(len(content)))
+}
+
+func TestProbe(t *testing.T) {
+	cases := map[string]video.Metadata{
+		"mp4":  {Format: video.MP4, Codec: "h264"},
+		"mov":  {Format: video.MOV, Codec: "h264"},
+		"mkv":  {Format: video.Matroska, Codec: "h264"},
+		"webm": {Format: video.WebM, Codec: "vp9"},
+		"avi":  {Format: video.AVI, Codec: "h264"},
+	}
+
+	for format, expected := range cases {
+		t.Run(format, func(t *testing.T) {
+			metadata, err := probe(mocks.MockGetVideo(format, 90*time.Second, 1920, 1080))
+
+			assert.NoError(t, err)
+			assert.Equal(t, expected.Format, metadata.Format)
+			assert.Equal(t, expected.Codec, metadata.Codec)
+			assert.Equal(t, 90*time.Second, metadata.Duration)
+			assert.Equal(t, 1920, metadata.Width)
+			assert.Equal(t, 1080, metadata.Height)
+		})
+	}
+}
+
+func TestProbe_UnknownFormat(t *testing.T) {
+	_, err := probe([]byte("MZ\x90\x00 not a video"))
+
+	assert.ErrorIs(t, err, video.ErrUnknownFormat)
+}
+
+func TestProbe_TruncatedFile(t *testing.T) {
+	for _, format := range []string{"mp4", "mkv", "avi"} {
+		t.Run(format, func(t *testing.T) {
+			content := mocks.MockGetVideoContent(format)
+
+			// Half of the mocked file ends inside the metadata section
+			_, err := probe(content[:len(content)/2])
+
+			assert.ErrorIs(t, err, video.ErrMalformedContainer)
+		})
+	}
+}
+
+func TestProbe_MissingMoov(t *testing.T) {
+	content := mocks.MockGetVideoContent("mp4")
+	moovStart := bytes.Index(content, []byte("moov")) - 4
+
+	_, err := probe(content[:moovStart])
+
+	assert.ErrorIs(t, err, video.ErrMalformedContainer)
+}
+
+func TestProbe_NoVideoStream(t *testing.T) {
+	content := mocks.MockGetVideoContent("mp4")
+	content = bytes.Replace(content, []byte("vide"), []byte("soun"), 1)
+
+	_, err := probe(content)
+
+	assert.ErrorIs(t, err, video.ErrNoVideoStream)
+}
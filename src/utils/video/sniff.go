@@ -0,0 +1,127 @@
+package video
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Format is a video container identified by its content
+type Format string
+
+const (
+	Unknown  Format = ""
+	MP4      Format = "mp4"
+	MOV      Format = "mov"
+	Matroska Format = "mkv"
+	WebM     Format = "webm"
+	AVI      Format = "avi"
+)
+
+// SniffLength is the amount of bytes from the beginning of the file Detect needs
+const SniffLength = 512
+
+const (
+	ebmlHeaderId = 0x1A45DFA3
+	ebmlDocType  = 0x4282
+)
+
+// Detect identifies the container looking at the magic bytes of the file header:
+// the ftyp box of MP4/MOV, the EBML header of Matroska/WebM and the RIFF header of AVI
+func Detect(header []byte) Format {
+	switch {
+	case len(header) >= 12 && bytes.Equal(header[4:8], []byte("ftyp")):
+		return detectIsoMedia(header)
+	case len(header) >= 4 && binary.BigEndian.Uint32(header) == ebmlHeaderId:
+		return detectEbml(header)
+	case len(header) >= 12 && bytes.Equal(header[0:4], []byte("RIFF")) && bytes.Equal(header[8:12], []byte("AVI ")):
+		return AVI
+	}
+
+	return Unknown
+}
+
+// detectIsoMedia tells MOV from MP4 by the major brand of the ftyp box
+func detectIsoMedia(header []byte) Format {
+	if bytes.Equal(header[8:12], []byte("qt  ")) {
+		return MOV
+	}
+	return MP4
+}
+
+// detectEbml reads the DocType element inside the EBML header
+func detectEbml(header []byte) Format {
+	offset := 4
+	headerSize, length := readVint(header[offset:], true)
+	if length == 0 {
+		return Unknown
+	}
+
+	offset += length
+	end := offset + int(headerSize)
+	if headerSize < 0 || end > len(header) {
+		end = len(header)
+	}
+
+	for offset < end {
+		id, idLength := readVint(header[offset:], false)
+		if idLength == 0 {
+			return Unknown
+		}
+		offset += idLength
+
+		size, sizeLength := readVint(header[offset:], true)
+		if sizeLength == 0 || size < 0 || offset+sizeLength+int(size) > len(header) {
+			return Unknown
+		}
+		offset += sizeLength
+
+		if id == ebmlDocType {
+			switch string(bytes.TrimRight(header[offset:offset+int(size)], "\x00")) {
+			case "matroska":
+				return Matroska
+			case "webm":
+				return WebM
+			}
+			return Unknown
+		}
+
+		offset += int(size)
+	}
+
+	return Unknown
+}
+
+// readVint reads an EBML variable length integer, returning its value and length.
+// Element IDs keep the length marker bits, while sizes have them removed.
+// An unknown size (all value bits set) is returned as -1
+func readVint(data []byte, removeMarker bool) (int64, int) {
+	if len(data) == 0 || data[0] == 0 {
+		return 0, 0
+	}
+
+	length := 1
+	for mask := byte(0x80); data[0]&mask == 0; mask >>= 1 {
+		length++
+	}
+
+	if length > 8 || length > len(data) {
+		return 0, 0
+	}
+
+	value := int64(data[0])
+	allOnes := data[0]&(0xFF>>length) == 0xFF>>length
+	if removeMarker {
+		value &= int64(0xFF >> length)
+	}
+
+	for i := 1; i < length; i++ {
+		value = value<<8 | int64(data[i])
+		allOnes = allOnes && data[i] == 0xFF
+	}
+
+	if removeMarker && allOnes {
+		return -1, length
+	}
+
+	return value, length
+}
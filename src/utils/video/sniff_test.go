@@ -0,0 +1,45 @@
+package video_test
+
+import (
+	"example/web-service-gin/src/utils/mocks"
+	"example/web-service-gin/src/utils/video"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect(t *testing.T) {
+	cases := map[string]video.Format{
+		"mp4":  video.MP4,
+		"mov":  video.MOV,
+		"mkv":  video.Matroska,
+		"webm": video.WebM,
+		"avi":  video.AVI,
+	}
+
+	for format, expected := range cases {
+		t.Run(format, func(t *testing.T) {
+			assert.Equal(t, expected, video.Detect(mocks.MockGetVideoContent(format)))
+		})
+	}
+}
+
+func TestDetect_Unknown(t *testing.T) {
+	assert.Equal(t, video.Unknown, video.Detect(nil))
+	assert.Equal(t, video.Unknown, video.Detect([]byte("MZ\x90\x00")))
+	assert.Equal(t, video.Unknown, video.Detect([]byte("RIFF\x24\x00\x00\x00WAVEfmt ")))
+}
+
+func TestDetect_TruncatedEbml(t *testing.T) {
+	// EBML header with the "webm" DocType cut in the middle
+	header := []byte{0x1A, 0x45, 0xDF, 0xA3, 0x8B, 0x42, 0x86, 0x81, 0x01, 0x42, 0x82, 0x84, 'w', 'e'}
+
+	assert.Equal(t, video.Unknown, video.Detect(header))
+	assert.Equal(t, video.Unknown, video.Detect(header[:5]))
+}
+
+func TestDetect_EbmlUnknownDocType(t *testing.T) {
+	header := []byte{0x1A, 0x45, 0xDF, 0xA3, 0x86, 0x42, 0x82, 0x83, 'f', 'o', 'o'}
+
+	assert.Equal(t, video.Unknown, video.Detect(header))
+}